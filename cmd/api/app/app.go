@@ -1,25 +1,35 @@
 package app
 
 import (
+	"context"
 	"log"
 
 	"github.com/afuradanime/backend/cmd/api/app/database"
 	"github.com/afuradanime/backend/config"
+	"github.com/afuradanime/backend/internal/adapters/events"
 	"github.com/afuradanime/backend/internal/adapters/middlewares"
 	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/interfaces"
 	"github.com/afuradanime/backend/internal/core/utils"
 	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/go-fuego/fuego"
 	"go.mongodb.org/mongo-driver/mongo"
-	"golang.org/x/oauth2"
 )
 
 type Application struct {
 	Config          *config.Config
-	OAuth2Config    *oauth2.Config
+	OAuthProviders  []config.OAuthProviderConfig // Login providers configured through the env
 	JWTConfig       *config.JWTConfig
+	KeyRing         *domain.KeyRing // Keys access tokens are signed and verified with
 	Mongo           *mongo.Database // The mongo database handle
 	ActivityTracker *domain.ActivityTracker
+	Events          interfaces.EventBroker    // Real-time events pushed to connected clients
+	Sessions        interfaces.SessionService // Shared so every middleware sees revocations right away
+	PersonalTokens  interfaces.PersonalTokenService
+	OAuth           interfaces.OAuthService
+	Tokens          middlewares.TokenAuthenticators // Every kind of bearer token the middlewares take
+	Terms           interfaces.TermsService         // Shared so a new version is enforced right away
+	UserLimiter     *middlewares.RateLimiter        // Shared so a user has one budget across every group
 }
 
 func New() *Application {
@@ -28,7 +38,7 @@ func New() *Application {
 	log.Println("Starting application in " + env + " environment...")
 
 	Config := config.Load()
-	OAuth2 := config.LoadOauth2()
+	OAuthProviders := config.LoadOAuthProviders()
 	JWTConfig := config.LoadJWTConfig()
 	log.Println("Config loaded successfully!")
 
@@ -45,10 +55,12 @@ func New() *Application {
 	utils.InitEncryption(Config.EncryptionKey)
 
 	app := &Application{
-		Mongo:        mongoClient.Database(Config.MongoDatabase),
-		Config:       Config,
-		OAuth2Config: OAuth2,
-		JWTConfig:    JWTConfig,
+		Mongo:          mongoClient.Database(Config.MongoDatabase),
+		Config:         Config,
+		OAuthProviders: OAuthProviders,
+		JWTConfig:      JWTConfig,
+		Events:         events.NewHub(),
+		KeyRing:        domain.NewKeyRing(),
 	}
 
 	if Config.ShouldBootstrap || env == "test" /* Always bootstrap on test */ {
@@ -56,6 +68,14 @@ func New() *Application {
 		Bootstrap(app.Mongo)
 	}
 
+	// Older data first, some indices can't be built until it's in shape
+	Migrate(context.Background(), app.Mongo)
+
+	// Creating an index that already exists does nothing, and searches can't run without theirs
+	BootstrapIndices(context.Background(), app.Mongo)
+
+	app.InitSigningKeys()
+
 	return app
 }
 
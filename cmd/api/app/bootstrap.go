@@ -12,6 +12,7 @@ import (
 	"github.com/afuradanime/backend/internal/core/services"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func Bootstrap(m *mongo.Database) {
@@ -23,8 +24,11 @@ func Bootstrap(m *mongo.Database) {
 	userRepo := repositories.NewUserRepository(m)
 	friendshipRepo := repositories.NewFriendshipRepository(m)
 
+	// Bootstrap the first terms of service, the seeded users have already agreed to them
+	termsVersion := BootstrapTerms(context.Background(), repositories.NewTermsRepository(m))
+
 	// Bootstrap users and get their auto-generated IDs
-	krayID, taikoID, testID := BootstrapUsers(context.Background(), userRepo)
+	krayID, taikoID, testID := BootstrapUsers(context.Background(), userRepo, termsVersion)
 
 	// Bootstrap friendships using the actual user IDs
 	BootstrapFriendships(context.Background(), friendshipRepo, krayID, taikoID, testID)
@@ -34,8 +38,8 @@ func Bootstrap(m *mongo.Database) {
 	BootstrapTranslations(context.Background(), descRepo)
 
 	// Bootstrap user reports
-	reportRepo := repositories.NewUserReportRepository(m)
-	BootstrapReports(context.Background(), reportRepo)
+	reportRepo := repositories.NewReportRepository(m)
+	BootstrapReports(context.Background(), reportRepo, repositories.NewModerationCaseRepository(m))
 
 	// Bootstrap a post conversation
 	postRepo := repositories.NewPostRepository(m)
@@ -50,13 +54,28 @@ func Bootstrap(m *mongo.Database) {
 
 	// Bootstrap groups
 	groupRepo := repositories.NewGroupRepository(m)
-	BootstrapGroups(context.Background(), groupRepo)
+	membershipRepo := repositories.NewGroupMembershipRepository(m)
+	BootstrapGroups(context.Background(), groupRepo, membershipRepo)
+
+	// Everything above is already in its current shape
+	BootstrapMigrations(context.Background(), m)
+}
+
+func BootstrapTerms(ctx context.Context, termsRepo *repositories.TermsRepository) int {
+
+	terms, err := domain.NewTermsOfService(1, "Be nice, don't post anything illegal and don't pretend to be someone else. "+
+		"We keep what you give us to run the site and nothing else, you can take a copy of it or delete your account at any time.", "", 1)
+	if err != nil {
+		panic(err)
+	}
 
-	// Database Indices
-	BootstrapIndices(context.Background(), m)
+	if err := termsRepo.CreateTerms(ctx, terms); err != nil {
+		panic(err)
+	}
+	return terms.Version
 }
 
-func BootstrapUsers(ctx context.Context, userRepo *repositories.UserRepository) (krayID, taikoID, testID int) {
+func BootstrapUsers(ctx context.Context, userRepo *repositories.UserRepository, termsVersion int) (krayID, taikoID, testID int) {
 
 	userKray, err := domain.NewUser("KrayRui", "kray@afurada.anime")
 	if err != nil {
@@ -74,7 +93,7 @@ func BootstrapUsers(ctx context.Context, userRepo *repositories.UserRepository)
 
 	userKray.UpdateAvatarURL("/pfps/d7dea5d3e09941f563dabf364b4db31cac63a5f1.png")
 	userKray.CreatedAt = time.Date(2025, 1, 25, 0, 0, 0, 0, time.UTC)
-	userKray.AcceptTermsOfService()
+	userKray.AcceptTermsOfService(termsVersion)
 
 	// Create user and get auto-generated ID
 	_, err = userRepo.CreateUser(ctx, userKray)
@@ -102,7 +121,7 @@ func BootstrapUsers(ctx context.Context, userRepo *repositories.UserRepository)
 	userTaiko.RewardBadge(value.UserBadgeSuperMegaIllyaFan)
 
 	userTaiko.CreatedAt = time.Date(2025, 1, 25, 0, 0, 0, 0, time.UTC)
-	userTaiko.AcceptTermsOfService()
+	userTaiko.AcceptTermsOfService(termsVersion)
 
 	// Create user and get auto-generated ID
 	_, err = userRepo.CreateUser(ctx, userTaiko)
@@ -124,7 +143,7 @@ func BootstrapUsers(ctx context.Context, userRepo *repositories.UserRepository)
 	userTest.RewardBadge(value.UserBadgeBrand)
 	userTest.AddRole(value.UserRoleModerator)
 	userTest.AddRole(value.UserRoleAdmin)
-	userTest.AcceptTermsOfService()
+	userTest.AcceptTermsOfService(termsVersion)
 
 	// Create user and get auto-generated ID
 	_, err = userRepo.CreateUser(ctx, userTest)
@@ -166,16 +185,24 @@ func BootstrapTranslations(ctx context.Context, translationRepo *repositories.De
 	}
 }
 
-func BootstrapReports(ctx context.Context, reportRepo *repositories.UserReportRepository) {
+func BootstrapReports(ctx context.Context, reportRepo *repositories.ReportRepository, caseRepo *repositories.ModerationCaseRepository) {
+
+	report := domain.NewUserReport(value.ReportReasonIllegalActivities, 1, 2, "")
+	moderationCase := domain.NewModerationCase(report)
+	moderationCase.ReportCount = 1
+	err := caseRepo.CreateCase(ctx, moderationCase)
+	if err != nil {
+		panic(err)
+	}
 
-	report := domain.NewUserReport(value.ReportReasonIllegalActivities, 1, 2)
-	err := reportRepo.CreateReport(ctx, report)
+	report.CaseID = moderationCase.ID
+	err = reportRepo.CreateReport(ctx, report)
 	if err != nil {
 		panic(err)
 	}
 }
 
-func BootstrapGroups(ctx context.Context, groupRepo *repositories.GroupRepository) {
+func BootstrapGroups(ctx context.Context, groupRepo *repositories.GroupRepository, membershipRepo *repositories.GroupMembershipRepository) {
 
 	general, _ := domain.NewGroup("Geral", "Grupo geral", "Regra número 1\n- Larp de fate é OBRIGATORIO", "http://localhost:5173/public/favicon.ico")
 	err := groupRepo.CreateGroup(ctx, general)
@@ -191,13 +218,24 @@ func BootstrapGroups(ctx context.Context, groupRepo *repositories.GroupRepositor
 
 	news, _ := domain.NewGroup("Notícias", "Canal principal de notícias do Afuradanime", "N/A", "http://localhost:5173/public/favicon.ico")
 	news.MakePrivate()
-	news.AddModerator(0)
+	news.SetOwner(0)
 	news.AddModerator(1)
 	news.AddModerator(2)
+	news.MemberCount = len(news.Moderators)
 	err = groupRepo.CreateGroup(ctx, news)
 	if err != nil {
 		panic(err)
 	}
+	for _, mod := range news.Moderators {
+		role := value.GroupRoleModerator
+		if news.IsOwner(mod) {
+			role = value.GroupRoleOwner
+		}
+		err = membershipRepo.CreateMembership(ctx, domain.NewGroupMembership(news.ID, mod, role))
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	group3, _ := domain.NewGroup("Recomendações", "Partilha o que tens visto e lido recentemente com o pessoal", "Partilha o que te faz feliz", "https://i.imgur.com/placeholder.png")
 	err = groupRepo.CreateGroup(ctx, group3)
@@ -294,4 +332,136 @@ func BootstrapIndices(ctx context.Context, m *mongo.Database) {
 		{Keys: bson.D{{Key: "receiver", Value: 1}, {Key: "seen", Value: 1}}},
 		{Keys: bson.D{{Key: "initiator", Value: 1}, {Key: "receiver", Value: 1}, {Key: "anime", Value: 1}}},
 	})
+
+	m.Collection("notifications").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "receiver", Value: 1}, {Key: "read", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+
+	m.Collection("reports").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "target_user", Value: 1}}},
+		{Keys: bson.D{{Key: "created_by", Value: 1}, {Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}}},
+		{Keys: bson.D{{Key: "case", Value: 1}}},
+	})
+
+	m.Collection("moderation_cases").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "target_user", Value: 1}, {Key: "status", Value: 1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "assigned_to", Value: 1}}},
+		// Only one case open at a time per target, open and in review are the lowest statuses
+		{
+			Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}, {Key: "target_user", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"status": bson.M{"$lte": value.CaseStatusInReview},
+			}),
+		},
+	})
+
+	m.Collection("audit_log").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "target_type", Value: 1}, {Key: "target_id", Value: 1}}},
+		{Keys: bson.D{{Key: "target_user", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+
+	m.Collection("filter_hits").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "action", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+
+	m.Collection("posts").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_by", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "held", Value: 1}, {Key: "created_at", Value: 1}}, Options: options.Index().SetPartialFilterExpression(bson.M{"held": true})},
+	})
+
+	// Expired sessions clean themselves up
+	m.Collection("sessions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "refresh_hash", Value: 1}}},
+		{Keys: bson.D{{Key: "previous_hash", Value: 1}}},
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "last_used_at", Value: -1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	// Archives aren't covered by the TTL, the cleanup job finds the expired ones through this
+	m.Collection("export_archives.files").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "metadata.expires_at", Value: 1}}},
+	})
+
+	// A provider account belongs to one user, and a user gets one account per provider
+	m.Collection("user_identities").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "provider", Value: 1}, {Key: "provider_id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "provider", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+
+	m.Collection("personal_tokens").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+
+	m.Collection("oauth_apps").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "owner", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+
+	m.Collection("oauth_grants").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "app", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "app", Value: 1}}},
+	})
+
+	// Codes and spent tokens clean themselves up
+	m.Collection("oauth_codes").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	m.Collection("oauth_tokens").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "access_hash", Value: 1}}},
+		{Keys: bson.D{{Key: "refresh_hash", Value: 1}}},
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "app", Value: 1}}},
+		{Keys: bson.D{{Key: "app", Value: 1}}},
+		{Keys: bson.D{{Key: "code", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	m.Collection("data_exports").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+
+	// Only the accounts waiting to be deleted
+	m.Collection("users").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "username_lower", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "deletion_requested_at", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+
+	m.Collection("sanctions").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "user", Value: 1}, {Key: "lifted_at", Value: 1}, {Key: "expires_at", Value: 1}}},
+		{Keys: bson.D{{Key: "lifted_at", Value: 1}, {Key: "expires_at", Value: 1}}},
+		{Keys: bson.D{{Key: "appeal.status", Value: 1}}},
+	})
+
+	m.Collection("groups").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "member_count", Value: -1}}},
+		{Keys: bson.D{{Key: "last_activity_at", Value: -1}}},
+		{Keys: bson.D{{Key: "anime", Value: 1}}},
+		{Keys: bson.D{{Key: "tags", Value: 1}}},
+		{Keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}}},
+	})
+
+	m.Collection("group_memberships").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "group", Value: 1}, {Key: "user", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user", Value: 1}}},
+	})
+
+	m.Collection("group_join_requests").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "group", Value: 1}, {Key: "user", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+
+	m.Collection("group_invites").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "group", Value: 1}}},
+	})
+
+	m.Collection("group_bans").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "group", Value: 1}, {Key: "user", Value: 1}}, Options: options.Index().SetUnique(true)},
+	})
+
+	m.Collection("group_moderation_log").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "group", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
 }
@@ -0,0 +1,385 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/services"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Brings data written by older versions up to date with the code. Each one runs once and is
+// recorded in the migrations collection, so they only ever get appended to this list
+type Migration struct {
+	Name string
+	Run  func(ctx context.Context, m *mongo.Database) error
+}
+
+var Migrations = []Migration{
+	{Name: "restrictions-to-sanctions", Run: MigrateRestrictionsToSanctions},
+	{Name: "group-memberships", Run: MigrateGroupMemberships},
+	{Name: "group-owners", Run: MigrateGroupOwners},
+	{Name: "reports-to-cases", Run: MigrateReportsToCases},
+	{Name: "usernames-lower", Run: MigrateUsernamesLower},
+	{Name: "terms-versions", Run: MigrateTermsVersions},
+}
+
+func Migrate(ctx context.Context, m *mongo.Database) {
+	applied := m.Collection("migrations")
+
+	for _, migration := range Migrations {
+		err := applied.FindOne(ctx, bson.M{"_id": migration.Name}).Err()
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			log.Fatal("Failed to check migration "+migration.Name+": ", err)
+		}
+
+		log.Println("Running migration " + migration.Name + "...")
+		if err := migration.Run(ctx, m); err != nil {
+			log.Fatal("Migration "+migration.Name+" failed: ", err)
+		}
+
+		if _, err := applied.InsertOne(ctx, bson.M{"_id": migration.Name, "applied_at": time.Now()}); err != nil {
+			log.Fatal("Failed to record migration "+migration.Name+": ", err)
+		}
+	}
+}
+
+// A freshly bootstrapped database has nothing to migrate
+func BootstrapMigrations(ctx context.Context, m *mongo.Database) {
+	documents := make([]any, 0, len(Migrations))
+	for _, migration := range Migrations {
+		documents = append(documents, bson.M{"_id": migration.Name, "applied_at": time.Now()})
+	}
+
+	if _, err := m.Collection("migrations").InsertMany(ctx, documents); err != nil {
+		panic(err)
+	}
+}
+
+// Accounts restricted with the old switch had no sanction behind them, so the next sanction
+// to end would have given their rights back. They get one that never runs out instead
+func MigrateRestrictionsToSanctions(ctx context.Context, m *mongo.Database) error {
+	sanctionRepo := repositories.NewSanctionRepository(m)
+
+	cursor, err := m.Collection("users").Find(ctx, bson.M{"$or": []bson.M{
+		{"can_post": false},
+		{"can_translate": false},
+	}})
+	if err != nil {
+		return err
+	}
+
+	var users []domain.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		active, err := sanctionRepo.GetActiveSanctions(ctx, user.ID)
+		if err != nil {
+			return err
+		}
+
+		blockPosting, blockTranslating := !user.CanPost, !user.CanTranslate
+		for _, sanction := range active {
+			blockPosting = blockPosting && !sanction.Type.BlocksPosting()
+			blockTranslating = blockTranslating && !sanction.Type.BlocksTranslating()
+		}
+
+		var sanctionType value.SanctionType
+		switch {
+		case blockPosting && blockTranslating:
+			sanctionType = value.SanctionRestricted
+		case blockPosting:
+			sanctionType = value.SanctionNoPosting
+		case blockTranslating:
+			sanctionType = value.SanctionNoTranslating
+		default:
+			continue
+		}
+
+		sanction, err := domain.NewSanction(user.ID, sanctionType, "Restricted before sanctions existed", domain.SYSTEM_USER_ID, 0)
+		if err != nil {
+			return err
+		}
+		if err := sanctionRepo.CreateSanction(ctx, sanction); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Groups from before memberships only knew their moderators, so everyone else would have been
+// locked out of posting. Whoever moderated or posted in one becomes a member, the banned stay out
+func MigrateGroupMemberships(ctx context.Context, m *mongo.Database) error {
+	memberships := m.Collection("group_memberships")
+
+	cursor, err := m.Collection("groups").Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+
+	var groups []domain.Group
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		roles, err := groupPostAuthors(ctx, m.Collection("posts"), group.ID)
+		if err != nil {
+			return err
+		}
+		for _, mod := range group.Moderators {
+			roles[mod] = value.GroupRoleModerator
+		}
+
+		cursor, err := m.Collection("group_bans").Find(ctx, bson.M{"group": group.ID})
+		if err != nil {
+			return err
+		}
+		var bans []domain.GroupBan
+		if err := cursor.All(ctx, &bans); err != nil {
+			return err
+		}
+		for _, ban := range bans {
+			delete(roles, ban.UserID)
+		}
+
+		// Existing memberships are left as they are
+		writes := make([]mongo.WriteModel, 0, len(roles))
+		for user, role := range roles {
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"group": group.ID, "user": user}).
+				SetUpdate(bson.M{"$setOnInsert": domain.NewGroupMembership(group.ID, user, role)}).
+				SetUpsert(true))
+		}
+		if len(writes) > 0 {
+			if _, err := memberships.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+				return err
+			}
+		}
+
+		count, err := memberships.CountDocuments(ctx, bson.M{"group": group.ID})
+		if err != nil {
+			return err
+		}
+		if _, err := m.Collection("groups").UpdateOne(ctx, bson.M{"_id": group.ID}, bson.M{"$set": bson.M{"member_count": count}}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Everyone who started a thread in the group or replied anywhere down one
+func groupPostAuthors(ctx context.Context, posts *mongo.Collection, groupID int) (map[int]value.GroupRole, error) {
+	authors := map[int]value.GroupRole{}
+
+	filter := bson.M{"parent_type": value.ParentTypeGroup, "parent_id": strconv.Itoa(groupID)}
+	for depth := 0; depth < services.MAX_THREAD_DEPTH; depth++ {
+		cursor, err := posts.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1, "created_by": 1}))
+		if err != nil {
+			return nil, err
+		}
+
+		var level []domain.Post
+		if err := cursor.All(ctx, &level); err != nil {
+			return nil, err
+		}
+		if len(level) == 0 {
+			break
+		}
+
+		ids := make([]string, 0, len(level))
+		for _, post := range level {
+			ids = append(ids, post.ID)
+			if post.CreatedBy != nil {
+				authors[*post.CreatedBy] = value.GroupRoleMember
+			}
+		}
+		filter = bson.M{"parent_type": value.ParentTypePost, "parent_id": bson.M{"$in": ids}}
+	}
+
+	return authors, nil
+}
+
+// Groups from before owners had nobody able to hand them over or delete them. Whoever created
+// the group takes it, or the first moderator when that was never recorded
+func MigrateGroupOwners(ctx context.Context, m *mongo.Database) error {
+	groups := m.Collection("groups")
+
+	cursor, err := groups.Find(ctx, bson.M{"$or": []bson.M{
+		{"owner": bson.M{"$exists": false}},
+		{"owner": 0},
+	}})
+	if err != nil {
+		return err
+	}
+
+	var legacy []struct {
+		ID         int   `bson:"_id"`
+		CreatedBy  int   `bson:"created_by"`
+		Moderators []int `bson:"mods"`
+	}
+	if err := cursor.All(ctx, &legacy); err != nil {
+		return err
+	}
+
+	for _, group := range legacy {
+		owner := group.CreatedBy
+		if owner == 0 && len(group.Moderators) > 0 {
+			owner = group.Moderators[0]
+		}
+		if owner == 0 {
+			continue
+		}
+
+		// The owner is always one of the moderators
+		if _, err := groups.UpdateOne(ctx, bson.M{"_id": group.ID}, bson.M{
+			"$set":      bson.M{"owner": owner},
+			"$addToSet": bson.M{"mods": owner},
+		}); err != nil {
+			return err
+		}
+
+		result, err := m.Collection("group_memberships").UpdateOne(ctx,
+			bson.M{"group": group.ID, "user": owner},
+			bson.M{"$set": bson.M{"role": value.GroupRoleOwner}, "$setOnInsert": bson.M{"joined_at": time.Now()}},
+			options.Update().SetUpsert(true))
+		if err != nil {
+			return err
+		}
+		if result.UpsertedCount > 0 {
+			if _, err := groups.UpdateOne(ctx, bson.M{"_id": group.ID}, bson.M{"$inc": bson.M{"member_count": 1}}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Reports filed before cases existed never show up in the queue. Each target gets its open case,
+// or a new one, with those reports counted in it
+func MigrateReportsToCases(ctx context.Context, m *mongo.Database) error {
+	caseRepo := repositories.NewModerationCaseRepository(m)
+	reports := m.Collection("reports")
+
+	cursor, err := reports.Find(ctx, bson.M{"$or": []bson.M{
+		{"case": bson.M{"$exists": false}},
+		{"case": 0},
+	}}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return err
+	}
+
+	var legacy []domain.Report
+	if err := cursor.All(ctx, &legacy); err != nil {
+		return err
+	}
+
+	for i := range legacy {
+		report := &legacy[i]
+
+		moderationCase, err := caseRepo.GetOpenCase(ctx, report)
+		if err != nil {
+			return err
+		}
+		if moderationCase == nil {
+			moderationCase = domain.NewModerationCase(report)
+			if err := caseRepo.CreateCase(ctx, moderationCase); err != nil {
+				return err
+			}
+		}
+
+		if _, err := reports.UpdateOne(ctx, bson.M{"_id": report.ID}, bson.M{"$set": bson.M{"case": moderationCase.ID}}); err != nil {
+			return err
+		}
+		if err := caseRepo.IncrementReportCount(ctx, moderationCase.ID, 1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Lookups and the unique index go through the lower-cased username. Names that only differed in
+// case could both be taken before, whoever registered later gets their ID appended
+func MigrateUsernamesLower(ctx context.Context, m *mongo.Database) error {
+	users := m.Collection("users")
+
+	cursor, err := users.Find(ctx, bson.M{}, options.Find().
+		SetProjection(bson.M{"_id": 1, "username": 1}).
+		SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+
+	var existing []struct {
+		ID       int    `bson:"_id"`
+		Username string `bson:"username"`
+	}
+	if err := cursor.All(ctx, &existing); err != nil {
+		return err
+	}
+
+	taken := make(map[string]bool, len(existing))
+	writes := make([]mongo.WriteModel, 0, len(existing))
+	for _, user := range existing {
+		username := user.Username
+		// The suffixed name may have been someone's all along, keep counting until it's free
+		for attempt := 0; taken[strings.ToLower(username)]; attempt++ {
+			suffix := "_" + strconv.Itoa(user.ID)
+			if attempt > 0 {
+				suffix += "_" + strconv.Itoa(attempt)
+			}
+			username = user.Username
+			if runes := []rune(username); len(runes)+len(suffix) > 64 {
+				username = string(runes[:64-len(suffix)])
+			}
+			username += suffix
+		}
+		taken[strings.ToLower(username)] = true
+
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": user.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"username": username, "username_lower": strings.ToLower(username)}}))
+	}
+
+	if len(writes) == 0 {
+		return nil
+	}
+	_, err = users.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// Acceptance used to be a yes or no, back when there was only ever one set of terms. Whoever
+// said yes agreed to what became the first version, everyone else is asked like a new user
+func MigrateTermsVersions(ctx context.Context, m *mongo.Database) error {
+	users := m.Collection("users")
+
+	if _, err := users.UpdateMany(ctx, bson.M{
+		"accepted_terms":         true,
+		"accepted_terms_version": bson.M{"$not": bson.M{"$gte": 1}},
+	}, bson.M{"$set": bson.M{"accepted_terms_version": 1}}); err != nil {
+		return err
+	}
+
+	_, err := users.UpdateMany(ctx, bson.M{"accepted_terms": bson.M{"$exists": true}}, bson.M{
+		"$unset": bson.M{"accepted_terms": ""},
+	})
+	return err
+}
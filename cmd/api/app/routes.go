@@ -1,8 +1,13 @@
 package app
 
 import (
+	"context"
+	"log"
+	"time"
+
 	"github.com/afuradanime/backend/internal/adapters/controllers"
 	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/adapters/oauth"
 	"github.com/afuradanime/backend/internal/adapters/repositories"
 	"github.com/afuradanime/backend/internal/core/domain"
 	"github.com/afuradanime/backend/internal/core/domain/value"
@@ -12,25 +17,73 @@ import (
 )
 
 func (a *Application) InitRoutes(s *fuego.Server) {
-	// Global rate limiter
-	globalLimiter := &middlewares.IPRateLimiter{Rps: 10, Burst: 30}
+	// Real client addresses when running behind our proxies
+	clientIPs, err := middlewares.NewClientIPResolver(a.Config.TrustedProxies)
+	if err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
+
+	// Global rate limiter, nobody is logged in yet at this point so it goes by IP
+	globalLimiter := middlewares.NewRateLimiter(middlewares.RateLimitPolicy{Rps: 10, Burst: 30})
+
+	// Same limit again per user, added right after the JWT middleware on every authenticated group
+	a.UserLimiter = middlewares.NewRateLimiter(middlewares.RateLimitPolicy{Rps: 10, Burst: 30})
 
 	// user activity tracker
 	a.ActivityTracker = domain.NewActivityTracker()
+	a.RegisterPresenceHooks()
+
+	// Writes need the current terms of service accepted
+	a.Terms = services.NewTermsService(repositories.NewTermsRepository(a.Mongo), repositories.NewUserRepository(a.Mongo),
+		services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo)))
+
+	// Sessions behind every access token
+	a.Sessions = services.NewSessionService(
+		repositories.NewSessionRepository(a.Mongo),
+		repositories.NewUserRepository(a.Mongo),
+		services.NewJWTService(a.JWTConfig, a.KeyRing, a.Terms),
+	)
+
+	// Personal tokens for scripts and bots and tokens issued to third party apps,
+	// both taken as bearer tokens where their scope allows
+	a.PersonalTokens = services.NewPersonalTokenService(repositories.NewPersonalTokenRepository(a.Mongo))
+	a.OAuth = services.NewOAuthService(
+		repositories.NewOAuthAppRepository(a.Mongo),
+		repositories.NewOAuthGrantRepository(a.Mongo),
+		repositories.NewOAuthCodeRepository(a.Mongo),
+		repositories.NewOAuthTokenRepository(a.Mongo),
+	)
+	a.Tokens = middlewares.TokenAuthenticators{a.PersonalTokens, a.OAuth}
+
+	// Lifts sanctions once they run out
+	a.StartSanctionExpiryJob()
+
+	// Keeps the JWT signing keys fresh
+	a.StartKeyRotationJob()
+
+	// Goes through accounts whose deletion grace period ran out
+	a.StartAccountDeletionJob()
+
+	// Clears out archives of data exports that expired
+	a.StartDataExportCleanupJob()
 
 	// Fuego uses package level Use function
 	fuego.Use(s,
 		middleware.Logger,
 		middleware.Recoverer,
 		// middlewares.CORSMiddleware,
+		middlewares.ClientIPMiddleware(clientIPs),
 		globalLimiter.Middleware,
-		middlewares.ActivityMiddleware(a.JWTConfig, a.ActivityTracker),
+		middlewares.ActivityMiddleware(a.KeyRing, a.ActivityTracker),
 	)
 
 	// Register Modules
 	a.RegisterAuthModule(s)
+	a.RegisterOAuthModule(s)
+	a.RegisterWellKnownModule(s)
 	a.RegisterAnimeModule(s)
 	a.RegisterUserModule(s)
+	a.RegisterTermsModule(s)
 	a.RegisterFriendsModule(s)
 	a.RegisterTranslationsModule(s)
 	a.RegisterAnimeListModule(s)
@@ -38,12 +91,17 @@ func (a *Application) InitRoutes(s *fuego.Server) {
 	a.RegisterGroupModule(s)
 	a.RegisterActivityModule(s)
 	a.RegisterPostModule(s)
+	a.RegisterNotificationModule(s)
+	a.RegisterEventsModule(s)
 
 	// Group for globally protected routes
 	protected := fuego.Group(s, "/")
-	fuego.Use(protected, middlewares.JWTMiddleware(a.JWTConfig, a.ActivityTracker))
+	fuego.Use(protected, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
 
 	a.RegisterReportsModule(protected)
+	a.RegisterModerationCasesModule(protected)
+	a.RegisterSanctionsModule(protected)
+	a.RegisterAuditModule(protected)
 	a.RegisterRecommendationsModule(protected)
 }
 
@@ -51,7 +109,8 @@ func (a *Application) RegisterTranslationsModule(s *fuego.Server) {
 	translationRepo := repositories.NewDescriptionTranslationRepository(a.Mongo)
 	userRepo := repositories.NewUserRepository(a.Mongo)
 	animeRepo := repositories.NewAnimeRepository()
-	translationService := services.NewDescriptionTranslationService(translationRepo, animeRepo, userRepo)
+	translationService := services.NewDescriptionTranslationService(translationRepo, animeRepo, userRepo,
+		services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo)))
 	translationController := controllers.NewDescriptionTranslationController(translationService)
 
 	g := fuego.Group(s, "/translations")
@@ -62,14 +121,15 @@ func (a *Application) RegisterTranslationsModule(s *fuego.Server) {
 
 	// Authenticated
 	userGroup := fuego.Group(g, "/")
-	fuego.Use(userGroup, middlewares.JWTMiddleware(a.JWTConfig, a.ActivityTracker))
+	fuego.Use(userGroup, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
 	fuego.Post(userGroup, "/anime/{animeID}", translationController.SubmitTranslation)
 
 	// Moderator only
 	modGroup := fuego.Group(g, "/")
 	fuego.Use(
 		modGroup,
-		middlewares.JWTMiddleware(a.JWTConfig, a.ActivityTracker),
+		middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms),
+		a.UserLimiter.Middleware,
 		middlewares.RequireRoleMiddleware(value.UserRoleModerator))
 	fuego.Put(modGroup, "/{id}/accept", translationController.AcceptTranslation)
 	fuego.Put(modGroup, "/{id}/reject", translationController.RejectTranslation)
@@ -78,8 +138,24 @@ func (a *Application) RegisterTranslationsModule(s *fuego.Server) {
 
 func (a *Application) RegisterUserModule(s *fuego.Server) {
 	userRepo := repositories.NewUserRepository(a.Mongo)
-	userService := services.NewUserService(userRepo)
+	auditService := services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo))
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+
+	sanctionService := services.NewSanctionService(repositories.NewSanctionRepository(a.Mongo), userRepo, notificationService, auditService)
+	sanctionController := controllers.NewSanctionController(sanctionService)
+
+	friendshipRepo := repositories.NewFriendshipRepository(a.Mongo)
+	friendshipService := services.NewFriendshipService(userRepo, friendshipRepo, notificationService)
+	presenceService := services.NewPresenceService(userRepo, friendshipService, a.ActivityTracker, a.Events)
+
+	userService := services.NewUserService(userRepo, auditService, sanctionService, presenceService)
 	userController := controllers.NewUserController(userService)
+	mentionService := services.NewMentionService(userRepo, friendshipService, presenceService)
+	mentionController := controllers.NewMentionController(mentionService)
+
+	deletionController := controllers.NewAccountDeletionController(a.newAccountDeletionService())
+
+	exportController := controllers.NewDataExportController(a.newDataExportService())
 
 	g := fuego.Group(s, "/users")
 
@@ -89,13 +165,60 @@ func (a *Application) RegisterUserModule(s *fuego.Server) {
 
 	// Authenticated
 	authGroup := fuego.Group(g, "/")
-	fuego.Use(authGroup, middlewares.JWTMiddleware(a.JWTConfig, a.ActivityTracker))
+	fuego.Use(authGroup, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
 	fuego.Put(authGroup, "/", userController.UpdateUserInfo)
+	fuego.Get(authGroup, "/mentions", mentionController.Autocomplete,
+		fuego.OptionQuery("q", "Username prefix to complete"),
+		fuego.OptionQuery("limit", "Maximum number of suggestions"),
+	)
+	fuego.Get(authGroup, "/me/sanctions", sanctionController.GetMySanctions)
+	fuego.Post(authGroup, "/me/sanctions/{id}/appeal", sanctionController.AppealSanction)
 
 	// Moderator
 	modGroup := fuego.Group(authGroup, "/")
 	fuego.Use(modGroup, middlewares.RequireRoleMiddleware(value.UserRoleModerator))
+	fuego.Get(modGroup, "/{id}/sanctions", sanctionController.GetUserSanctions)
 	fuego.Put(modGroup, "/{id}/restrict", userController.RestrictAccount)
+
+	// Own profile, readable with a token too
+	profileGroup := fuego.Group(g, "/")
+	fuego.Use(profileGroup,
+		middlewares.TokenMiddleware(a.Tokens, value.TokenScopeReadProfile),
+		middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
+	fuego.Get(profileGroup, "/me", userController.GetMe)
+
+	// Leaving or taking your data with you works without agreeing to the terms first
+	accountGroup := fuego.Group(g, "/")
+	fuego.Use(accountGroup, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, nil), a.UserLimiter.Middleware)
+	fuego.Post(accountGroup, "/me/deletion", deletionController.RequestDeletion)
+	fuego.Delete(accountGroup, "/me/deletion", deletionController.CancelDeletion)
+	fuego.Post(accountGroup, "/me/exports", exportController.RequestExport)
+	fuego.Get(accountGroup, "/me/exports", exportController.GetExports)
+	fuego.GetStd(accountGroup, "/me/exports/{id}", exportController.DownloadExport)
+}
+
+func (a *Application) RegisterTermsModule(s *fuego.Server) {
+	termsController := controllers.NewTermsController(a.Terms)
+
+	g := fuego.Group(s, "/terms")
+
+	// Public
+	fuego.Get(g, "/", termsController.GetCurrentTerms)
+	fuego.Get(g, "/history", termsController.GetTermsHistory)
+	fuego.Get(g, "/{version}", termsController.GetTerms)
+
+	// Accepting can't be gated behind having accepted
+	acceptGroup := fuego.Group(g, "/")
+	fuego.Use(acceptGroup, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, nil), a.UserLimiter.Middleware)
+	fuego.Post(acceptGroup, "/accept", termsController.AcceptTerms)
+
+	// Admin, everyone is asked to accept the new version
+	adminGroup := fuego.Group(g, "/")
+	fuego.Use(adminGroup,
+		middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms),
+		a.UserLimiter.Middleware,
+		middlewares.RequireRoleMiddleware(value.UserRoleAdmin))
+	fuego.Post(adminGroup, "/", termsController.PublishTerms)
 }
 
 func (a *Application) RegisterAnimeModule(s *fuego.Server) {
@@ -136,14 +259,15 @@ func (a *Application) RegisterAnimeModule(s *fuego.Server) {
 func (a *Application) RegisterFriendsModule(s *fuego.Server) {
 	friendshipRepo := repositories.NewFriendshipRepository(a.Mongo)
 	userRepo := repositories.NewUserRepository(a.Mongo)
-	friendshipService := services.NewFriendshipService(userRepo, friendshipRepo)
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	friendshipService := services.NewFriendshipService(userRepo, friendshipRepo, notificationService)
 	friendshipController := controllers.NewFriendshipController(friendshipService)
 
 	g := fuego.Group(s, "/friends")
 	fuego.Get(g, "/{userID}", friendshipController.ListFriends)
 
 	authGroup := fuego.Group(g, "/")
-	fuego.Use(authGroup, middlewares.JWTMiddleware(a.JWTConfig, a.ActivityTracker))
+	fuego.Use(authGroup, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
 	fuego.Put(authGroup, "/send/{receiver}", friendshipController.SendFriendRequest)
 	fuego.Put(authGroup, "/accept/{initiator}", friendshipController.AcceptFriendRequest)
 	fuego.Put(authGroup, "/decline/{initiator}", friendshipController.DeclineFriendRequest)
@@ -153,70 +277,244 @@ func (a *Application) RegisterFriendsModule(s *fuego.Server) {
 }
 
 func (a *Application) RegisterAuthModule(s *fuego.Server) {
-	jwtService := services.NewJWTService(a.JWTConfig)
-	userService := services.NewUserService(repositories.NewUserRepository(a.Mongo))
-	googleAuthController := controllers.NewGoogleAuthController(a.Config, a.OAuth2Config, jwtService, userService)
+	jwtService := services.NewJWTService(a.JWTConfig, a.KeyRing, a.Terms)
+	providers, err := oauth.NewRegistryFromConfig(a.OAuthProviders)
+	if err != nil {
+		log.Fatal("Failed to set up the login providers: ", err)
+	}
+	identityService := services.NewIdentityService(repositories.NewIdentityRepository(a.Mongo), repositories.NewUserRepository(a.Mongo))
+	authController := controllers.NewAuthController(a.Config, providers, jwtService, identityService, a.Sessions)
+	sessionController := controllers.NewSessionController(jwtService, a.Sessions)
+	tokenController := controllers.NewPersonalTokenController(a.PersonalTokens)
 
 	g := fuego.Group(s, "/auth")
-	authLimiter := &middlewares.IPRateLimiter{Rps: 0.5, Burst: 3}
+	authLimiter := middlewares.NewRateLimiter(middlewares.RateLimitPolicy{Rps: 0.5, Burst: 3})
 	fuego.Use(g, authLimiter.Middleware)
 
-	fuego.Get(g, "/google/login", googleAuthController.Login)
-	fuego.Get(g, "/google/callback", googleAuthController.Callback)
-	fuego.Get(g, "/me", googleAuthController.WhoAmI)
-	fuego.Get(g, "/logout", googleAuthController.Logout)
+	fuego.Get(g, "/providers", authController.GetProviders)
+	fuego.Get(g, "/{provider}/login", authController.Login)
+	// Optional auth, linking an account needs to know who's asking
+	fuego.Get(g, "/{provider}/callback", authController.Callback,
+		fuego.OptionMiddleware(middlewares.OptionalJWTMiddleware(a.KeyRing, a.Sessions)),
+	)
+	fuego.Get(g, "/me", authController.WhoAmI)
+	fuego.Get(g, "/logout", authController.Logout)
+	fuego.Post(g, "/refresh", sessionController.Refresh)
+
+	// Sessions of the logged user, DELETE on all of them logs out everywhere.
+	// Managing the account itself doesn't wait on the terms being accepted
+	sessionGroup := fuego.Group(g, "/")
+	fuego.Use(sessionGroup, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, nil), a.UserLimiter.Middleware)
+	fuego.Get(sessionGroup, "/sessions", sessionController.GetSessions)
+	fuego.Delete(sessionGroup, "/sessions", sessionController.RevokeAllSessions)
+	fuego.Delete(sessionGroup, "/sessions/{id}", sessionController.RevokeSession)
+
+	// Accounts on other providers that log into this one, the last one can't go
+	fuego.Get(sessionGroup, "/{provider}/link", authController.Link)
+	fuego.Get(sessionGroup, "/identities", authController.GetIdentities)
+	fuego.Delete(sessionGroup, "/identities/{provider}", authController.UnlinkIdentity)
+
+	// Personal tokens, managed from a logged in session only so a token can't mint more of itself
+	fuego.Get(sessionGroup, "/tokens", tokenController.GetTokens)
+	fuego.Post(sessionGroup, "/tokens", tokenController.CreateToken)
+	fuego.Delete(sessionGroup, "/tokens/{id}", tokenController.RevokeToken)
+}
+
+func (a *Application) RegisterOAuthModule(s *fuego.Server) {
+	oauthController := controllers.NewOAuthController(a.OAuth)
+
+	g := fuego.Group(s, "/oauth")
+
+	// Apps talk to this one directly, no cookies involved
+	tokenLimiter := middlewares.NewRateLimiter(middlewares.RateLimitPolicy{Rps: 1, Burst: 5})
+	fuego.PostStd(g, "/token", oauthController.Token,
+		fuego.OptionMiddleware(tokenLimiter.Middleware),
+		fuego.OptionDescription("Token endpoint, takes authorization_code (with PKCE) and refresh_token grants as a form"),
+	)
+
+	// Everything else is the user on the site, cookie only so apps can't grant themselves more
+	userGroup := fuego.Group(g, "/")
+	fuego.Use(userGroup, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
+	fuego.Get(userGroup, "/authorize", oauthController.GetConsent,
+		fuego.OptionQuery("client_id", "App asking for access"),
+		fuego.OptionQuery("redirect_uri", "One of the app's registered redirect URIs"),
+		fuego.OptionQuery("response_type", "Always code"),
+		fuego.OptionQuery("scope", "Space separated scopes"),
+		fuego.OptionQuery("state", "Handed back to the app untouched"),
+		fuego.OptionQuery("code_challenge", "PKCE challenge"),
+		fuego.OptionQuery("code_challenge_method", "Always S256"),
+	)
+	fuego.Post(userGroup, "/authorize", oauthController.Authorize)
+
+	fuego.Get(userGroup, "/apps", oauthController.GetApps)
+	fuego.Post(userGroup, "/apps", oauthController.RegisterApp)
+	fuego.Delete(userGroup, "/apps/{id}", oauthController.DeleteApp)
+
+	// Apps the user let in
+	fuego.Get(userGroup, "/grants", oauthController.GetGrants)
+	fuego.Delete(userGroup, "/grants/{appID}", oauthController.RevokeGrant)
 }
 
 func (a *Application) RegisterReportsModule(s *fuego.Server) {
-	reportRepo := repositories.NewUserReportRepository(a.Mongo)
+	reportRepo := repositories.NewReportRepository(a.Mongo)
 	userRepo := repositories.NewUserRepository(a.Mongo)
-	reportService := services.NewUserReportService(reportRepo, userRepo)
-	reportController := controllers.NewUserReportController(reportService)
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	auditService := services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo))
+	sanctionService := services.NewSanctionService(repositories.NewSanctionRepository(a.Mongo), userRepo, notificationService, auditService)
+	reportService := services.NewReportService(reportRepo, userRepo, repositories.NewPostRepository(a.Mongo),
+		repositories.NewGroupRepository(a.Mongo), repositories.NewDescriptionTranslationRepository(a.Mongo),
+		repositories.NewModerationCaseRepository(a.Mongo), sanctionService, auditService)
+	reportController := controllers.NewReportController(reportService)
 
 	g := fuego.Group(s, "/reports")
-	fuego.Post(g, "/{userID}", reportController.SubmitReport)
+
+	// Filing reports, slowed down per user so nobody floods the moderation queue
+	submitGroup := fuego.Group(g, "/")
+	reportLimiter := middlewares.NewRateLimiter(middlewares.RateLimitPolicy{Rps: 1.0 / 30, Burst: 5})
+	fuego.Use(submitGroup, reportLimiter.Middleware)
+	fuego.Post(submitGroup, "/{userID}", reportController.SubmitReport)
+	fuego.Post(submitGroup, "/posts/{id}", reportController.SubmitPostReport)
+	fuego.Post(submitGroup, "/groups/{id}", reportController.SubmitGroupReport)
+	fuego.Post(submitGroup, "/translations/{id}", reportController.SubmitTranslationReport)
 
 	modGroup := fuego.Group(g, "/")
 	fuego.Use(modGroup, middlewares.RequireRoleMiddleware(value.UserRoleModerator))
-	fuego.Get(modGroup, "/", reportController.GetReports)
+	fuego.Get(modGroup, "/", reportController.GetReports,
+		fuego.OptionQuery("targetType", "0 for users, 1 for posts, 2 for groups, 3 for translations"),
+	)
 	fuego.Get(modGroup, "/user/{userID}", reportController.GetReportsByTarget)
 	fuego.Delete(modGroup, "/{id}", reportController.DeleteReport)
 }
 
+func (a *Application) RegisterModerationCasesModule(s *fuego.Server) {
+	caseService := services.NewModerationCaseService(repositories.NewModerationCaseRepository(a.Mongo),
+		repositories.NewReportRepository(a.Mongo), repositories.NewUserRepository(a.Mongo),
+		services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo)))
+	caseController := controllers.NewModerationCaseController(caseService)
+
+	g := fuego.Group(s, "/cases")
+	fuego.Use(g, middlewares.RequireRoleMiddleware(value.UserRoleModerator))
+	fuego.Get(g, "/", caseController.GetCases,
+		fuego.OptionQuery("status", "0 open, 1 in review, 2 actioned, 3 dismissed"),
+		fuego.OptionQuery("assignee", "Only cases assigned to this moderator"),
+		fuego.OptionQuery("targetType", "0 for users, 1 for posts, 2 for groups, 3 for translations"),
+	)
+	fuego.Get(g, "/{id}", caseController.GetCase)
+	fuego.Put(g, "/{id}/assignee/{userID}", caseController.AssignCase)
+	fuego.Delete(g, "/{id}/assignee", caseController.UnassignCase)
+	fuego.Post(g, "/{id}/notes", caseController.AddNote)
+	fuego.Put(g, "/{id}/resolve", caseController.ResolveCase)
+	fuego.Put(g, "/{id}/reopen", caseController.ReopenCase)
+}
+
+func (a *Application) RegisterSanctionsModule(s *fuego.Server) {
+	userRepo := repositories.NewUserRepository(a.Mongo)
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	sanctionService := services.NewSanctionService(repositories.NewSanctionRepository(a.Mongo), userRepo, notificationService,
+		services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo)))
+	sanctionController := controllers.NewSanctionController(sanctionService)
+
+	g := fuego.Group(s, "/sanctions")
+	fuego.Use(g, middlewares.RequireRoleMiddleware(value.UserRoleModerator))
+	fuego.Post(g, "/", sanctionController.IssueSanction)
+	fuego.Delete(g, "/{id}", sanctionController.LiftSanction)
+	fuego.Get(g, "/appeals", sanctionController.GetPendingAppeals)
+	fuego.Put(g, "/{id}/appeal/accept", sanctionController.AcceptAppeal)
+	fuego.Put(g, "/{id}/appeal/reject", sanctionController.RejectAppeal)
+}
+
+func (a *Application) RegisterAuditModule(s *fuego.Server) {
+	auditController := controllers.NewAuditController(services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo)))
+
+	g := fuego.Group(s, "/audit")
+	fuego.Use(g, middlewares.RequireRoleMiddleware(value.UserRoleAdmin))
+	fuego.Get(g, "/", auditController.GetEntries,
+		fuego.OptionQuery("actor", "Only what this staff member did"),
+		fuego.OptionQuery("action", "1-2 translations, 3-6 sanctions and appeals, 7 reports, 8 badges, 9-10 group moderators, 11-12 held posts, 13 terms of service, 14 removed posts, 15 resolved cases, 16-18 filter rules, 19 deleted groups"),
+		fuego.OptionQuery("targetType", "1 users, 2 translations, 3 sanctions, 4 reports, 5 groups, 6 posts, 7 terms of service, 8 cases, 9 filter rules"),
+		fuego.OptionQuery("target", "ID of the target, along with targetType"),
+		fuego.OptionQuery("user", "Only entries concerning this user"),
+		fuego.OptionQuery("from", "First day, YYYY-MM-DD"),
+		fuego.OptionQuery("to", "Last day, YYYY-MM-DD"),
+	)
+}
+
 func (a *Application) RegisterPostModule(s *fuego.Server) {
 	postRepo := repositories.NewPostRepository(a.Mongo)
 	userRepo := repositories.NewUserRepository(a.Mongo)
-	friendshipSvc := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(a.Mongo))
-	groupSvc := services.NewGroupService(repositories.NewGroupRepository(a.Mongo), userRepo)
+	auditSvc := services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo))
+	notificationSvc := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	friendshipSvc := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(a.Mongo), notificationSvc)
+	groupSvc := services.NewGroupService(repositories.NewGroupRepository(a.Mongo),
+		repositories.NewGroupMembershipRepository(a.Mongo), repositories.NewGroupJoinRequestRepository(a.Mongo),
+		repositories.NewGroupInviteRepository(a.Mongo), repositories.NewGroupBanRepository(a.Mongo),
+		repositories.NewGroupModerationLogRepository(a.Mongo), userRepo, repositories.NewAnimeRepository(), postRepo, auditSvc)
+	sanctionSvc := services.NewSanctionService(repositories.NewSanctionRepository(a.Mongo), userRepo, notificationSvc, auditSvc)
+	reportSvc := services.NewReportService(repositories.NewReportRepository(a.Mongo), userRepo, postRepo,
+		repositories.NewGroupRepository(a.Mongo), repositories.NewDescriptionTranslationRepository(a.Mongo),
+		repositories.NewModerationCaseRepository(a.Mongo), sanctionSvc, auditSvc)
+	filterSvc := services.NewContentFilterService(repositories.NewFilterRuleRepository(a.Mongo),
+		repositories.NewFilterHitRepository(a.Mongo), postRepo, reportSvc, auditSvc)
 	postService := services.NewPostService(postRepo, userRepo, friendshipSvc,
-		services.NewAnimeService(repositories.NewAnimeRepository()), groupSvc)
+		services.NewAnimeService(repositories.NewAnimeRepository()), groupSvc, notificationSvc, a.Events, filterSvc, auditSvc)
 
 	postController := controllers.NewPostController(postService)
+	filterController := controllers.NewContentFilterController(filterSvc, postService)
 	
 	g := fuego.Group(s, "/posts")
 
-	// Public
-	fuego.Get(g, "/{post_id}", postController.GetPostById)
-    fuego.Get(g, "/{parent_id}/replies", postController.GetPostReplies)
+	// Public, optional auth lets members read private group threads
+	publicGroup := fuego.Group(g, "/")
+	fuego.Use(publicGroup, middlewares.OptionalJWTMiddleware(a.KeyRing, a.Sessions))
+	fuego.Get(publicGroup, "/{post_id}", postController.GetPostById)
+	fuego.Get(publicGroup, "/{parent_id}/replies", postController.GetPostReplies)
 
 	// Authenticated
 	authGroup := fuego.Group(g, "/")
-    fuego.Use(authGroup, middlewares.JWTMiddleware(a.JWTConfig, a.ActivityTracker))
-    fuego.Post(authGroup, "/", postController.CreatePost)
-    fuego.Post(authGroup, "/{post_id}/reply", postController.CreateReply)
-    fuego.Delete(authGroup, "/{post_id}", postController.DeletePost)
+    fuego.Use(authGroup,
+		middlewares.TokenMiddleware(a.Tokens, value.TokenScopePost),
+		middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
+
+	// Posts and replies share one limit per user, on top of the global one
+	postLimiter := middlewares.NewRateLimiter(middlewares.RateLimitPolicy{Rps: 0.2, Burst: 5})
+    fuego.Post(authGroup, "/", postController.CreatePost, fuego.OptionMiddleware(postLimiter.Middleware))
+    fuego.Post(authGroup, "/{post_id}/reply", postController.CreateReply, fuego.OptionMiddleware(postLimiter.Middleware))
+    fuego.Delete(authGroup, "/{post_id}", postController.DeletePost,
+		fuego.OptionQuery("reason", "Why a group moderator removed the post, goes in the group's moderation log"),
+	)
+
+	// Content filter, moderators go through what it caught and admins decide what it looks for
+	filterGroup := fuego.Group(s, "/filter")
+	fuego.Use(filterGroup, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
+
+	filterModGroup := fuego.Group(filterGroup, "/")
+	fuego.Use(filterModGroup, middlewares.RequireRoleMiddleware(value.UserRoleModerator))
+	fuego.Get(filterModGroup, "/hits", filterController.GetHits,
+		fuego.OptionQuery("action", "1 flagged, 2 held, 3 rejected"),
+	)
+	fuego.Get(filterModGroup, "/held", filterController.GetHeldPosts)
+	fuego.Put(filterModGroup, "/held/{post_id}/release", filterController.ReleaseHeldPost)
+	fuego.Delete(filterModGroup, "/held/{post_id}", filterController.RejectHeldPost)
+
+	filterAdminGroup := fuego.Group(filterGroup, "/")
+	fuego.Use(filterAdminGroup, middlewares.RequireRoleMiddleware(value.UserRoleAdmin))
+	fuego.Get(filterAdminGroup, "/rules", filterController.GetRules)
+	fuego.Post(filterAdminGroup, "/rules", filterController.CreateRule)
+	fuego.Put(filterAdminGroup, "/rules/{id}", filterController.UpdateRule)
+	fuego.Delete(filterAdminGroup, "/rules/{id}", filterController.DeleteRule)
 }
 
 func (a *Application) RegisterRecommendationsModule(s *fuego.Server) {
 	repo := repositories.NewRecommendationRepository(a.Mongo)
 	userRepo := repositories.NewUserRepository(a.Mongo)
-	friendshipSvc := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(a.Mongo))
+	notificationSvc := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	friendshipSvc := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(a.Mongo), notificationSvc)
 	ratingCacheRepo := repositories.NewRatingCacheRepository(a.Mongo)
 	ratingCacheService := services.NewRatingCacheService(*ratingCacheRepo)
 	animeListSvc := services.NewAnimeListService(repositories.NewAnimeListRepository(a.Mongo),
 	repositories.NewAnimeRepository(), ratingCacheService, userRepo)
 
-	service := services.NewRecommendationService(repo, userRepo, friendshipSvc, animeListSvc)
+	service := services.NewRecommendationService(repo, userRepo, friendshipSvc, animeListSvc, notificationSvc)
 	controller := controllers.NewRecommendationController(service)
 
 	g := fuego.Group(s, "/recommendations")
@@ -234,21 +532,26 @@ func (a *Application) RegisterAnimeListModule(s *fuego.Server) {
 	listService := services.NewAnimeListService(listRepo, animeRepo, ratingCacheService, userRepo)
 
 	// Build recommendation service for dismissal on add
-	friendshipSvc := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(a.Mongo))
+	notificationSvc := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	friendshipSvc := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(a.Mongo), notificationSvc)
 	recommendationRepo := repositories.NewRecommendationRepository(a.Mongo)
-	recommendationSvc := services.NewRecommendationService(recommendationRepo, userRepo, friendshipSvc, listService)
+	recommendationSvc := services.NewRecommendationService(recommendationRepo, userRepo, friendshipSvc, listService, notificationSvc)
 
 	listController := controllers.NewAnimeListController(listService, recommendationSvc)
 
 	// Public route with optional auth
 	g := fuego.Group(s, "/animelist")
 	optionalAuthGroup := fuego.Group(g, "/")
-	fuego.Use(optionalAuthGroup, middlewares.OptionalJWTMiddleware(a.JWTConfig))
+	fuego.Use(optionalAuthGroup,
+		middlewares.TokenMiddleware(a.Tokens, value.TokenScopeReadList),
+		middlewares.OptionalJWTMiddleware(a.KeyRing, a.Sessions))
 	fuego.Get(optionalAuthGroup, "/{userId}", listController.GetUserList)
 	
 	// Protected routes
 	authGroup := fuego.Group(g, "/")
-	fuego.Use(authGroup, middlewares.JWTMiddleware(a.JWTConfig, a.ActivityTracker))
+	fuego.Use(authGroup,
+		middlewares.TokenMiddleware(a.Tokens, value.TokenScopeWriteList),
+		middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
 	fuego.Post(authGroup, "/{userId}/{animeId}", listController.AddAnime)
 	fuego.Patch(authGroup, "/{userId}/progress/{animeId}", listController.UpdateProgress)
 	fuego.Patch(authGroup, "/{userId}/status/{animeId}", listController.UpdateStatus)
@@ -273,29 +576,243 @@ func (a *Application) RegisterRatingCacheModule(s *fuego.Server) {
 func (a *Application) RegisterGroupModule(s *fuego.Server) {
 	groupRepo := repositories.NewGroupRepository(a.Mongo)
 	userRepo := repositories.NewUserRepository(a.Mongo)
-	groupService := services.NewGroupService(groupRepo, userRepo)
+	membershipRepo := repositories.NewGroupMembershipRepository(a.Mongo)
+	joinRequestRepo := repositories.NewGroupJoinRequestRepository(a.Mongo)
+	inviteRepo := repositories.NewGroupInviteRepository(a.Mongo)
+	banRepo := repositories.NewGroupBanRepository(a.Mongo)
+	logRepo := repositories.NewGroupModerationLogRepository(a.Mongo)
+	groupService := services.NewGroupService(groupRepo, membershipRepo, joinRequestRepo, inviteRepo, banRepo, logRepo, userRepo,
+		repositories.NewAnimeRepository(), repositories.NewPostRepository(a.Mongo), services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo)))
 	groupController := controllers.NewGroupController(groupService)
 
 	g := fuego.Group(s, "/groups")
 
 	// Public
 	fuego.Get(g, "/{id}", groupController.GetGroupByID)
-	fuego.Get(g, "/", groupController.GetGroups)
+	fuego.Get(g, "/", groupController.GetGroups,
+		fuego.OptionQuery("q", "Search the name and description, groups matching more of the words come first"),
+		fuego.OptionQuery("anime", "Only groups about this anime"),
+		fuego.OptionQuery("tag", "Only groups with this anime tag"),
+		fuego.OptionQuery("sort", "members or activity, oldest groups first otherwise"),
+	)
+
+	// Public, but members of private groups are only shown to other members
+	optionalAuthGroup := fuego.Group(g, "/")
+	fuego.Use(optionalAuthGroup, middlewares.OptionalJWTMiddleware(a.KeyRing, a.Sessions))
+	fuego.Get(optionalAuthGroup, "/{id}/members", groupController.GetMembers)
 
 	authGroup := fuego.Group(g, "/")
 
 	// Authenticated
+	fuego.Use(authGroup, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
+	fuego.Post(authGroup, "/", groupController.CreateGroup)
+	fuego.Put(authGroup, "/{id}/join", groupController.JoinGroup)
+	fuego.Put(authGroup, "/{id}/leave", groupController.LeaveGroup)
+	fuego.Put(authGroup, "/{id}/requests", groupController.RequestToJoin)
+	fuego.Put(authGroup, "/invites/{code}/join", groupController.JoinWithInvite)
+
 	// Moderator actions (group-level, checked in service)
-	fuego.Use(authGroup, middlewares.JWTMiddleware(a.JWTConfig, a.ActivityTracker))
 	fuego.Put(authGroup, "/{id}", groupController.UpdateGroup)
+	fuego.Put(authGroup, "/{id}/categories", groupController.UpdateGroupCategories)
 	fuego.Put(authGroup, "/{id}/moderators", groupController.AddGroupModerator)
 	fuego.Delete(authGroup, "/{id}/moderators", groupController.RemoveGroupModerator)
+	fuego.Get(authGroup, "/{id}/requests", groupController.GetJoinRequests)
+	fuego.Put(authGroup, "/{id}/requests/{userID}/approve", groupController.ApproveJoinRequest)
+	fuego.Put(authGroup, "/{id}/requests/{userID}/decline", groupController.DeclineJoinRequest)
+	fuego.Post(authGroup, "/{id}/invites", groupController.CreateInvite)
+	fuego.Get(authGroup, "/{id}/invites", groupController.GetInvites)
+	fuego.Delete(authGroup, "/{id}/invites/{code}", groupController.RevokeInvite)
+	fuego.Get(authGroup, "/{id}/bans", groupController.GetBans)
+	fuego.Post(authGroup, "/{id}/bans/{userID}", groupController.BanMember)
+	fuego.Delete(authGroup, "/{id}/bans/{userID}", groupController.UnbanMember)
+	fuego.Post(authGroup, "/{id}/mutes/{userID}", groupController.MuteMember)
+	fuego.Delete(authGroup, "/{id}/mutes/{userID}", groupController.UnmuteMember)
+	fuego.Get(authGroup, "/{id}/log", groupController.GetModerationLog)
+
+	// Owner only, admins can step in
+	fuego.Post(authGroup, "/{id}/owner/{userID}", groupController.TransferOwnership)
+	fuego.Put(authGroup, "/{id}/owner/accept", groupController.AcceptOwnership)
+	fuego.Delete(authGroup, "/{id}/owner", groupController.CancelOwnershipTransfer)
+	fuego.Put(authGroup, "/{id}/archive", groupController.ArchiveGroup)
+	fuego.Delete(authGroup, "/{id}/archive", groupController.UnarchiveGroup)
+	fuego.Delete(authGroup, "/{id}", groupController.DeleteGroup)
+}
+
+func (a *Application) RegisterNotificationModule(s *fuego.Server) {
+	userRepo := repositories.NewUserRepository(a.Mongo)
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	notificationController := controllers.NewNotificationController(notificationService)
+
+	g := fuego.Group(s, "/notifications")
+	fuego.Use(g, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
+
+	fuego.Get(g, "/", notificationController.GetNotifications,
+		fuego.OptionQuery("unread", "Only return unread notifications (true/false)"),
+	)
+	fuego.Get(g, "/unread", notificationController.GetUnreadCount)
+	fuego.Put(g, "/read", notificationController.MarkAllAsRead)
+	fuego.Put(g, "/{id}/read", notificationController.MarkAsRead)
+	fuego.Get(g, "/preferences", notificationController.GetPreferences)
+	fuego.Put(g, "/preferences", notificationController.UpdatePreferences)
+}
+
+func (a *Application) RegisterEventsModule(s *fuego.Server) {
+	userRepo := repositories.NewUserRepository(a.Mongo)
+	notificationSvc := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	friendshipSvc := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(a.Mongo), notificationSvc)
+	postRepo := repositories.NewPostRepository(a.Mongo)
+	auditSvc := services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo))
+	groupSvc := services.NewGroupService(repositories.NewGroupRepository(a.Mongo),
+		repositories.NewGroupMembershipRepository(a.Mongo), repositories.NewGroupJoinRequestRepository(a.Mongo),
+		repositories.NewGroupInviteRepository(a.Mongo), repositories.NewGroupBanRepository(a.Mongo),
+		repositories.NewGroupModerationLogRepository(a.Mongo), userRepo, repositories.NewAnimeRepository(), postRepo, auditSvc)
+	sanctionSvc := services.NewSanctionService(repositories.NewSanctionRepository(a.Mongo), userRepo, notificationSvc, auditSvc)
+	reportSvc := services.NewReportService(repositories.NewReportRepository(a.Mongo), userRepo, postRepo,
+		repositories.NewGroupRepository(a.Mongo), repositories.NewDescriptionTranslationRepository(a.Mongo),
+		repositories.NewModerationCaseRepository(a.Mongo), sanctionSvc, auditSvc)
+	filterSvc := services.NewContentFilterService(repositories.NewFilterRuleRepository(a.Mongo),
+		repositories.NewFilterHitRepository(a.Mongo), postRepo, reportSvc, auditSvc)
+	postSvc := services.NewPostService(postRepo, userRepo, friendshipSvc,
+		services.NewAnimeService(repositories.NewAnimeRepository()), groupSvc, notificationSvc, a.Events, filterSvc, auditSvc)
+	eventsController := controllers.NewEventsController(a.Events, friendshipSvc, postSvc)
+
+	g := fuego.Group(s, "/events")
+	fuego.Use(g, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
+	fuego.GetStd(g, "/", eventsController.Stream,
+		fuego.OptionQuery("thread", "Thread being viewed as <parentType>:<parentId>, can be repeated"),
+		fuego.OptionHeader("Last-Event-ID", "Id of the last event received, to replay what was missed"),
+	)
+}
+
+// Wires the activity tracker to the rest of the app: presence changes are pushed
+// to friends and users dropped from the tracker get their "last seen" saved
+func (a *Application) RegisterPresenceHooks() {
+	userRepo := repositories.NewUserRepository(a.Mongo)
+	notificationSvc := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	friendshipSvc := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(a.Mongo), notificationSvc)
+	presenceSvc := services.NewPresenceService(userRepo, friendshipSvc, a.ActivityTracker, a.Events)
+
+	a.ActivityTracker.OnStatusChange(func(userID int, status value.ActivityStatus) {
+		if err := presenceSvc.PublishPresence(context.Background(), userID, status); err != nil {
+			log.Printf("Failed to publish presence of user %d: %v", userID, err)
+		}
+	})
+	a.ActivityTracker.OnEvict(func(userID int, lastSeen time.Time) {
+		if err := presenceSvc.RecordLastSeen(context.Background(), userID, lastSeen); err != nil {
+			log.Printf("Failed to record last seen of user %d: %v", userID, err)
+		}
+	})
+}
+
+func (a *Application) StartSanctionExpiryJob() {
+	userRepo := repositories.NewUserRepository(a.Mongo)
+	notificationSvc := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	sanctionSvc := services.NewSanctionService(repositories.NewSanctionRepository(a.Mongo), userRepo, notificationSvc,
+		services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo)))
+
+	sanctionSvc.StartExpiryJob(1 * time.Minute)
+}
+
+func (a *Application) newAccountDeletionService() *services.AccountDeletionService {
+	userRepo := repositories.NewUserRepository(a.Mongo)
+	ratingCacheRepo := repositories.NewRatingCacheRepository(a.Mongo)
+	animeListSvc := services.NewAnimeListService(repositories.NewAnimeListRepository(a.Mongo), repositories.NewAnimeRepository(),
+		services.NewRatingCacheService(*ratingCacheRepo), userRepo)
+
+	return services.NewAccountDeletionService(
+		userRepo,
+		repositories.NewIdentityRepository(a.Mongo),
+		a.Sessions,
+		repositories.NewPersonalTokenRepository(a.Mongo),
+		repositories.NewOAuthGrantRepository(a.Mongo),
+		repositories.NewOAuthTokenRepository(a.Mongo),
+		repositories.NewPostRepository(a.Mongo),
+		animeListSvc,
+		repositories.NewFriendshipRepository(a.Mongo),
+		repositories.NewRecommendationRepository(a.Mongo),
+		repositories.NewReportRepository(a.Mongo),
+		repositories.NewModerationCaseRepository(a.Mongo),
+		services.NewGroupService(repositories.NewGroupRepository(a.Mongo),
+			repositories.NewGroupMembershipRepository(a.Mongo), repositories.NewGroupJoinRequestRepository(a.Mongo),
+			repositories.NewGroupInviteRepository(a.Mongo), repositories.NewGroupBanRepository(a.Mongo),
+			repositories.NewGroupModerationLogRepository(a.Mongo), userRepo, repositories.NewAnimeRepository(),
+			repositories.NewPostRepository(a.Mongo), services.NewAuditService(repositories.NewAuditLogRepository(a.Mongo))),
+		repositories.NewDataExportRepository(a.Mongo),
+	)
+}
+
+func (a *Application) StartAccountDeletionJob() {
+	a.newAccountDeletionService().StartDeletionJob(10 * time.Minute)
+}
+
+func (a *Application) newDataExportService() *services.DataExportService {
+	userRepo := repositories.NewUserRepository(a.Mongo)
+
+	return services.NewDataExportService(
+		repositories.NewDataExportRepository(a.Mongo),
+		userRepo,
+		repositories.NewAnimeListRepository(a.Mongo),
+		repositories.NewPostRepository(a.Mongo),
+		repositories.NewFriendshipRepository(a.Mongo),
+		repositories.NewRecommendationRepository(a.Mongo),
+		repositories.NewDescriptionTranslationRepository(a.Mongo),
+		repositories.NewReportRepository(a.Mongo),
+		repositories.NewGroupMembershipRepository(a.Mongo),
+		repositories.NewIdentityRepository(a.Mongo),
+		repositories.NewSessionRepository(a.Mongo),
+		repositories.NewPersonalTokenRepository(a.Mongo),
+		repositories.NewOAuthGrantRepository(a.Mongo),
+		repositories.NewOAuthTokenRepository(a.Mongo),
+		repositories.NewSanctionRepository(a.Mongo),
+		services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events),
+	)
+}
+
+func (a *Application) StartDataExportCleanupJob() {
+	a.newDataExportService().StartCleanupJob(time.Hour)
+}
+
+func (a *Application) InitSigningKeys() {
+	signingKeySvc := services.NewSigningKeyService(repositories.NewSigningKeyRepository(a.Mongo), a.KeyRing, a.JWTConfig)
+	if err := signingKeySvc.Init(context.Background()); err != nil {
+		log.Fatal("Failed to load the JWT signing keys: ", err)
+	}
+}
+
+func (a *Application) StartKeyRotationJob() {
+	signingKeySvc := services.NewSigningKeyService(repositories.NewSigningKeyRepository(a.Mongo), a.KeyRing, a.JWTConfig)
+	signingKeySvc.StartRotationJob(1 * time.Minute)
+}
+
+// Public keys for anything else that wants to verify our tokens
+func (a *Application) RegisterWellKnownModule(s *fuego.Server) {
+	signingKeySvc := services.NewSigningKeyService(repositories.NewSigningKeyRepository(a.Mongo), a.KeyRing, a.JWTConfig)
+	jwksController := controllers.NewJWKSController(signingKeySvc)
+
+	g := fuego.Group(s, "/.well-known")
+	fuego.Get(g, "/jwks.json", jwksController.GetJWKS)
 }
 
 func (a *Application) RegisterActivityModule(s *fuego.Server) {
-	controller := controllers.NewActivityController(a.ActivityTracker)
+	userRepo := repositories.NewUserRepository(a.Mongo)
+	notificationSvc := services.NewNotificationService(repositories.NewNotificationRepository(a.Mongo), userRepo, a.Events)
+	friendshipSvc := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(a.Mongo), notificationSvc)
+	presenceSvc := services.NewPresenceService(userRepo, friendshipSvc, a.ActivityTracker, a.Events)
+	controller := controllers.NewActivityController(presenceSvc)
+	presenceController := controllers.NewPresenceController(a.ActivityTracker, a.Events, friendshipSvc, presenceSvc, a.Config.FrontendURL)
 
 	g := fuego.Group(s, "/activity")
-	fuego.Get(g, "/user/{userID}", controller.IsUserOnline)
-	fuego.Get(g, "/stats", controller.GetActivityStats)
+
+	// Public, but what's visible depends on who's asking
+	optionalAuthGroup := fuego.Group(g, "/")
+	fuego.Use(optionalAuthGroup, middlewares.OptionalJWTMiddleware(a.KeyRing, a.Sessions))
+	fuego.Get(optionalAuthGroup, "/user/{userID}", controller.IsUserOnline)
+	fuego.Get(optionalAuthGroup, "/stats", controller.GetActivityStats)
+
+	// Authenticated
+	authGroup := fuego.Group(g, "/")
+	fuego.Use(authGroup, middlewares.JWTMiddleware(a.KeyRing, a.ActivityTracker, a.Sessions, a.Terms), a.UserLimiter.Middleware)
+	fuego.GetStd(authGroup, "/ws", presenceController.Connect,
+		fuego.OptionQuery("status", "Initial status (2 online, 3 idle, 4 invisible)"),
+	)
 }
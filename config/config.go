@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/afuradanime/backend/internal/core/utils"
 	"github.com/joho/godotenv"
@@ -18,6 +19,7 @@ type Config struct {
 	MongoPassword         string
 	MongoDatabase         string
 	EncryptionKey         string
+	TrustedProxies        []string // Proxies allowed to tell us the client's address through X-Forwarded-For
 }
 
 func Load() *Config {
@@ -44,5 +46,6 @@ func Load() *Config {
 		MongoPassword:         os.Getenv("MONGO_PASSWORD"),
 		MongoDatabase:         os.Getenv("MONGO_DATABASE"),
 		EncryptionKey:         os.Getenv("ENCRYPTION_KET"),
+		TrustedProxies:        strings.Split(os.Getenv("TRUSTED_PROXIES"), ","),
 	}
 }
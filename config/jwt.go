@@ -9,10 +9,15 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// Access tokens are short lived, ExpirationHours is how long a session (its refresh token) lasts.
+// Secret is only used when Algorithm is HS256, the asymmetric ones get their keys generated and rotated
 type JWTConfig struct {
-	Secret          string
-	ExpirationHours int64
-	Issuer          string
+	Secret                  string
+	ExpirationHours         int64
+	AccessExpirationMinutes int64
+	Issuer                  string
+	Algorithm               string
+	KeyRotationDays         int64
 }
 
 func LoadJWTConfig() *JWTConfig {
@@ -34,10 +39,28 @@ func LoadJWTConfig() *JWTConfig {
 	expirationHours := JWTExpToInt64(os.Getenv("JWT_EXPIRATION_HOURS"))
 	issuer := os.Getenv("JWT_ISSUER")
 
+	accessExpirationMinutes := int64(15)
+	if exp := os.Getenv("JWT_ACCESS_EXPIRATION_MINUTES"); exp != "" {
+		accessExpirationMinutes = JWTExpToInt64(exp)
+	}
+
+	algorithm := os.Getenv("JWT_ALGORITHM")
+	if algorithm == "" {
+		algorithm = "EdDSA"
+	}
+
+	keyRotationDays := int64(30)
+	if days := os.Getenv("JWT_KEY_ROTATION_DAYS"); days != "" {
+		keyRotationDays = JWTExpToInt64(days)
+	}
+
 	return &JWTConfig{
-		Secret:          secret,
-		ExpirationHours: expirationHours,
-		Issuer:          issuer,
+		Secret:                  secret,
+		ExpirationHours:         expirationHours,
+		AccessExpirationMinutes: accessExpirationMinutes,
+		Issuer:                  issuer,
+		Algorithm:               algorithm,
+		KeyRotationDays:         keyRotationDays,
 	}
 
 	// return &JWTConfig{
@@ -51,7 +74,7 @@ func JWTExpToInt64(exp string) int64 {
 	var expInt int64
 	_, err := fmt.Sscanf(exp, "%d", &expInt)
 	if err != nil {
-		log.Fatalf("Error converting JWT_EXPIRATION_HOURS to int64: %v", err)
+		log.Fatalf("Error converting JWT setting %q to int64: %v", exp, err)
 	}
 	return expInt
 }
@@ -3,13 +3,30 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/afuradanime/backend/internal/core/utils"
 	"github.com/joho/godotenv"
-	"golang.org/x/oauth2"
 )
 
-func LoadOauth2() *oauth2.Config {
+const (
+	OAuthKindOIDC    = "oidc" // Anything with discovery, endpoints come from {Issuer}/.well-known/openid-configuration
+	OAuthKindDiscord = "discord"
+	OAuthKindGitHub  = "github"
+)
+
+type OAuthProviderConfig struct {
+	Name         string // What shows up in /auth/{provider}/login and in User.Provider
+	Kind         string
+	Issuer       string // OIDC only
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// Providers without a client ID are left out
+func LoadOAuthProviders() []OAuthProviderConfig {
 
 	env := utils.GetApplicationEnvironment()
 
@@ -24,17 +41,57 @@ func LoadOauth2() *oauth2.Config {
 		panic("No .env file")
 	}
 
-	return &oauth2.Config{
-		ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
-		ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
-		RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URI"),
-		Scopes: []string{
-			"https://www.googleapis.com/auth/userinfo.email",   // Required for email access
-			"https://www.googleapis.com/auth/userinfo.profile", // Required for profile access (name, picture, etc...
+	candidates := []OAuthProviderConfig{
+		{
+			Name:         "google",
+			Kind:         OAuthKindOIDC,
+			Issuer:       "https://accounts.google.com",
+			ClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
+			ClientSecret: os.Getenv("GOOGLE_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("GOOGLE_REDIRECT_URI"),
+			Scopes:       []string{"openid", "email", "profile"},
+		},
+		{
+			Name:         "discord",
+			Kind:         OAuthKindDiscord,
+			ClientID:     os.Getenv("DISCORD_CLIENT_ID"),
+			ClientSecret: os.Getenv("DISCORD_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("DISCORD_REDIRECT_URI"),
+			Scopes:       []string{"identify", "email"},
 		},
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  "https://accounts.google.com/o/oauth2/auth",
-			TokenURL: "https://oauth2.googleapis.com/token",
+		{
+			Name:         "github",
+			Kind:         OAuthKindGitHub,
+			ClientID:     os.Getenv("GITHUB_CLIENT_ID"),
+			ClientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("GITHUB_REDIRECT_URI"),
+			Scopes:       []string{"read:user", "user:email"},
 		},
 	}
+
+	// Any other OIDC provider, one is enough for now
+	if name := os.Getenv("OIDC_PROVIDER_NAME"); name != "" {
+		scopes := []string{"openid", "email", "profile"}
+		if s := os.Getenv("OIDC_SCOPES"); s != "" {
+			scopes = strings.Fields(s)
+		}
+
+		candidates = append(candidates, OAuthProviderConfig{
+			Name:         name,
+			Kind:         OAuthKindOIDC,
+			Issuer:       os.Getenv("OIDC_ISSUER"),
+			ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("OIDC_REDIRECT_URI"),
+			Scopes:       scopes,
+		})
+	}
+
+	providers := make([]OAuthProviderConfig, 0, len(candidates))
+	for _, provider := range candidates {
+		if provider.ClientID != "" {
+			providers = append(providers, provider)
+		}
+	}
+	return providers
 }
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/go-fuego/fuego"
+)
+
+type AccountDeletionController struct {
+	deletionService interfaces.AccountDeletionService
+}
+
+func NewAccountDeletionController(deletionService interfaces.AccountDeletionService) *AccountDeletionController {
+	return &AccountDeletionController{
+		deletionService: deletionService,
+	}
+}
+
+type AccountDeletionResponse struct {
+	DeletionDueAt time.Time `json:"DeletionDueAt"` // Can still be cancelled until then
+}
+
+func (c *AccountDeletionController) RequestDeletion(ctx fuego.ContextNoBody) (AccountDeletionResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return AccountDeletionResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	dueAt, err := c.deletionService.RequestDeletion(ctx.Context(), userID)
+	if err != nil {
+		return AccountDeletionResponse{}, accountDeletionError(err)
+	}
+	return AccountDeletionResponse{DeletionDueAt: dueAt}, nil
+}
+
+func (c *AccountDeletionController) CancelDeletion(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	if err := c.deletionService.CancelDeletion(ctx.Context(), userID); err != nil {
+		return nil, accountDeletionError(err)
+	}
+	return nil, nil
+}
+
+func accountDeletionError(err error) error {
+	var notFound domain_errors.UserNotFoundError
+	var alreadyRequested domain_errors.AccountDeletionAlreadyRequestedError
+	var noneRequested domain_errors.NoAccountDeletionRequestedError
+	var deleted domain_errors.AccountDeletedError
+
+	switch {
+	case errors.As(err, &notFound):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &alreadyRequested), errors.As(err, &noneRequested):
+		return fuego.ConflictError{Detail: err.Error()}
+	case errors.As(err, &deleted):
+		return fuego.BadRequestError{Detail: err.Error()}
+	default:
+		return fuego.InternalServerError{Detail: err.Error()}
+	}
+}
@@ -1,46 +1,62 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
 	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
 	"github.com/go-fuego/fuego"
 )
 
 type ActivityController struct {
-	tracker *domain.ActivityTracker
+	presenceService interfaces.PresenceService
 }
 
-func NewActivityController(tracker *domain.ActivityTracker) *ActivityController {
-	return &ActivityController{tracker: tracker}
-}
-
-type UserActivityResponse struct {
-	UserID   int `json:"user_id"`
-	IsOnline int `json:"is_online"`
+func NewActivityController(presenceService interfaces.PresenceService) *ActivityController {
+	return &ActivityController{presenceService: presenceService}
 }
 
 type ActivityStatsResponse struct {
 	OnlineCount int   `json:"online_count"`
-	OnlineUsers []int `json:"online_users"`
+	OnlineUsers []int `json:"online_users"` // Only the ones the viewer may see
+}
+
+func viewerFromContext(ctx fuego.ContextNoBody) *int {
+	if viewerID, ok := middlewares.GetUserIDFromContext(ctx.Context()); ok {
+		return &viewerID
+	}
+	return nil
 }
 
-func (c *ActivityController) IsUserOnline(ctx fuego.ContextNoBody) (UserActivityResponse, error) {
+func (c *ActivityController) IsUserOnline(ctx fuego.ContextNoBody) (*domain.UserPresence, error) {
 	userID, err := strconv.Atoi(ctx.PathParam("userID"))
 	if err != nil {
-		return UserActivityResponse{}, fuego.BadRequestError{Detail: "Invalid user ID"}
+		return nil, fuego.BadRequestError{Detail: "Invalid user ID"}
 	}
 
-	return UserActivityResponse{
-		UserID:   userID,
-		IsOnline: c.tracker.IsActive(userID),
-	}, nil
+	presence, err := c.presenceService.GetUserPresence(ctx.Context(), viewerFromContext(ctx), userID)
+	if err != nil {
+		var notFoundErr domain_errors.UserNotFoundError
+		if errors.As(err, &notFoundErr) {
+			return nil, fuego.NotFoundError{Detail: err.Error()}
+		}
+		return nil, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return presence, nil
 }
 
 func (c *ActivityController) GetActivityStats(ctx fuego.ContextNoBody) (ActivityStatsResponse, error) {
-	online := c.tracker.GetActiveUsers()
+	online, total, err := c.presenceService.GetVisibleOnlineUsers(ctx.Context(), viewerFromContext(ctx))
+	if err != nil {
+		return ActivityStatsResponse{}, fuego.InternalServerError{Detail: err.Error()}
+	}
+
 	return ActivityStatsResponse{
-		OnlineCount: len(online),
+		OnlineCount: total,
 		OnlineUsers: online,
 	}, nil
 }
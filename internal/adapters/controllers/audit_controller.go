@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"github.com/go-fuego/fuego"
+)
+
+type AuditController struct {
+	auditService interfaces.AuditService
+}
+
+func NewAuditController(auditService interfaces.AuditService) *AuditController {
+	return &AuditController{auditService: auditService}
+}
+
+type AuditListResponse struct {
+	Data       []*domain.AuditEntry `json:"data"`
+	Pagination utils.Pagination     `json:"pagination"`
+}
+
+func parseAuditFilters(ctx fuego.ContextNoBody) (filters.AuditFilter, error) {
+	var f filters.AuditFilter
+
+	if actorStr := ctx.QueryParam("actor"); actorStr != "" {
+		a, err := strconv.Atoi(actorStr)
+		if err != nil {
+			return f, fuego.BadRequestError{Detail: "Invalid actor"}
+		}
+		f.Actor = &a
+	}
+	if actionStr := ctx.QueryParam("action"); actionStr != "" {
+		a, err := strconv.ParseUint(actionStr, 10, 8)
+		if err != nil || !value.AuditAction(a).IsValid() {
+			return f, fuego.BadRequestError{Detail: "Invalid action"}
+		}
+		action := value.AuditAction(a)
+		f.Action = &action
+	}
+	if typeStr := ctx.QueryParam("targetType"); typeStr != "" {
+		t, err := strconv.ParseUint(typeStr, 10, 8)
+		if err != nil || !value.AuditTargetType(t).IsValid() {
+			return f, fuego.BadRequestError{Detail: "Invalid target type"}
+		}
+		targetType := value.AuditTargetType(t)
+		f.TargetType = &targetType
+	}
+	if target := ctx.QueryParam("target"); target != "" {
+		f.TargetID = &target
+	}
+	if userStr := ctx.QueryParam("user"); userStr != "" {
+		u, err := strconv.Atoi(userStr)
+		if err != nil {
+			return f, fuego.BadRequestError{Detail: "Invalid user"}
+		}
+		f.TargetUser = &u
+	}
+	if fromStr := ctx.QueryParam("from"); fromStr != "" {
+		from, err := time.Parse("2006-01-02", fromStr)
+		if err != nil {
+			return f, fuego.BadRequestError{Detail: "Invalid from date, use YYYY-MM-DD"}
+		}
+		f.From = &from
+	}
+	if toStr := ctx.QueryParam("to"); toStr != "" {
+		to, err := time.Parse("2006-01-02", toStr)
+		if err != nil {
+			return f, fuego.BadRequestError{Detail: "Invalid to date, use YYYY-MM-DD"}
+		}
+		// The whole day counts
+		to = to.Add(24 * time.Hour)
+		f.To = &to
+	}
+
+	return f, nil
+}
+
+func (c *AuditController) GetEntries(ctx fuego.ContextNoBody) (AuditListResponse, error) {
+	f, err := parseAuditFilters(ctx)
+	if err != nil {
+		return AuditListResponse{}, err
+	}
+
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+
+	entries, pagination, err := c.auditService.GetEntries(ctx.Context(), f, pageNumber, pageSize)
+	if err != nil {
+		return AuditListResponse{}, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return AuditListResponse{
+		Data:       entries,
+		Pagination: pagination,
+	}, nil
+}
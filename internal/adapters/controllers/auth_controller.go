@@ -0,0 +1,292 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/afuradanime/backend/config"
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/adapters/oauth"
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/services"
+	"github.com/go-fuego/fuego"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// Logs users in through whichever provider the route names, see oauth.Registry
+type AuthController struct {
+	config          *config.Config
+	providers       *oauth.Registry
+	jwtService      *services.JWTService
+	identityService interfaces.IdentityService
+	sessionService  interfaces.SessionService
+}
+
+type AuthProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+func NewAuthController(
+	config *config.Config,
+	providers *oauth.Registry,
+	jwtService *services.JWTService,
+	identityService interfaces.IdentityService,
+	sessionService interfaces.SessionService,
+) *AuthController {
+
+	return &AuthController{
+		config:          config,
+		providers:       providers,
+		jwtService:      jwtService,
+		identityService: identityService,
+		sessionService:  sessionService,
+	}
+}
+
+// The login flow only lives for as long as it takes to click through the provider
+const OAUTH_COOKIE_MAX_AGE = 10 * 60
+
+// random state generator for OAuth2 flow.
+func generateRandomState(length int) string {
+	b := make([]byte, length)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+func (ac *AuthController) provider(ctx fuego.ContextNoBody) (interfaces.AuthProvider, error) {
+	provider, ok := ac.providers.Get(ctx.PathParam("provider"))
+	if !ok {
+		return nil, fuego.NotFoundError{Detail: "Unknown login provider " + ctx.PathParam("provider")}
+	}
+	return provider, nil
+}
+
+func setOAuthCookie(w http.ResponseWriter, name, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/auth",
+		HttpOnly: true,
+		Secure:   false, // https
+		MaxAge:   maxAge,
+	})
+}
+
+func (ac *AuthController) GetProviders(ctx fuego.ContextNoBody) (AuthProvidersResponse, error) {
+	return AuthProvidersResponse{Providers: ac.providers.Names()}, nil
+}
+
+func (ac *AuthController) Login(ctx fuego.ContextNoBody) (any, error) {
+	return ac.startFlow(ctx, "")
+}
+
+// Same dance as logging in, except the callback adds the account to the logged user
+func (ac *AuthController) Link(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	return ac.startFlow(ctx, strconv.Itoa(userID))
+}
+
+func (ac *AuthController) startFlow(ctx fuego.ContextNoBody, linkUserID string) (any, error) {
+	provider, err := ac.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	state := generateRandomState(16)
+	verifier := oauth2.GenerateVerifier()
+	authURL, err := provider.AuthCodeURL(ctx.Context(), state, verifier)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to reach " + provider.Name() + ": " + err.Error()}
+	}
+
+	// store the state and the PKCE verifier in secure cookies
+	setOAuthCookie(ctx.Response(), "oauthstate", state, OAUTH_COOKIE_MAX_AGE)
+	setOAuthCookie(ctx.Response(), "oauthverifier", verifier, OAUTH_COOKIE_MAX_AGE)
+	if linkUserID != "" {
+		setOAuthCookie(ctx.Response(), "oauthlink", linkUserID, OAUTH_COOKIE_MAX_AGE)
+	} else {
+		setOAuthCookie(ctx.Response(), "oauthlink", "", -1)
+	}
+
+	// redirect the user to the provider's consent page
+	// this will then redirect back to our callback endpoint
+	return ctx.Redirect(307, authURL)
+}
+
+func (ac *AuthController) Logout(ctx fuego.ContextNoBody) (any, error) {
+	// Kill the session server side too, a copied cookie shouldn't outlive the logout
+	if cookie, err := ctx.Cookie(REFRESH_COOKIE); err == nil {
+		if err := ac.sessionService.RevokeByRefreshToken(ctx.Context(), cookie.Value); err != nil {
+			log.Printf("Failed to revoke session on logout: %v", err)
+		}
+	}
+
+	clearSessionCookies(ctx.Response())
+
+	return ctx.Redirect(307, ac.config.FrontendURL)
+}
+
+func (ac *AuthController) WhoAmI(ctx fuego.ContextNoBody) (jwt.Claims, error) {
+
+	cookie, err := ctx.Cookie("jwt")
+	if err != nil {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized, no JWT cookie found"}
+	}
+
+	claims, err := ac.jwtService.ValidateJWT(cookie.Value)
+	if err != nil || claims == nil {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized: Invalid JWT token"}
+	}
+
+	mapClaims, _ := claims.Claims.(jwt.MapClaims)
+	sessionID, _ := mapClaims["sid"].(string)
+	if !ac.sessionService.IsSessionActive(ctx.Context(), sessionID) {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized: Session revoked"}
+	}
+
+	// this returns the claims as JSON, see claims in jwt_service
+	return claims.Claims, nil
+}
+
+func (ac *AuthController) Callback(ctx fuego.ContextNoBody) (any, error) {
+	provider, err := ac.provider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cookie, err := ctx.Cookie("oauthstate")
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "State Cookie not found"}
+	}
+
+	// Compare our client defined state with the state returned by the provider
+	state := ctx.Request().FormValue("state")
+	if state != cookie.Value {
+		return nil, fuego.BadRequestError{Detail: "Invalid OAuth state"}
+	}
+
+	verifier := ""
+	if cookie, err := ctx.Cookie("oauthverifier"); err == nil {
+		verifier = cookie.Value
+	}
+
+	linkUserID := ""
+	if cookie, err := ctx.Cookie("oauthlink"); err == nil {
+		linkUserID = cookie.Value
+	}
+
+	// One shot, whatever happens next
+	setOAuthCookie(ctx.Response(), "oauthstate", "", -1)
+	setOAuthCookie(ctx.Response(), "oauthverifier", "", -1)
+	setOAuthCookie(ctx.Response(), "oauthlink", "", -1)
+
+	if reason := ctx.Request().FormValue("error"); reason != "" {
+		return nil, fuego.UnauthorizedError{Detail: provider.Name() + " login was not completed: " + reason}
+	}
+
+	identity, err := provider.Authenticate(ctx.Context(), ctx.Request().FormValue("code"), verifier)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to log in with " + provider.Name() + ": " + err.Error()}
+	}
+
+	if linkUserID != "" {
+		return ac.finishLink(ctx, linkUserID, identity)
+	}
+
+	// Check if user exists, if not create a new user
+	dbUser, firstLogin, err := ac.identityService.LoginWithProvider(ctx.Context(), identity)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to register user: " + err.Error()}
+	}
+
+	accessToken, refreshToken, err := ac.sessionService.StartSession(ctx.Context(), dbUser, ctx.Request().UserAgent(), middlewares.ClientIP(ctx.Request()))
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to start session: " + err.Error()}
+	}
+
+	// Short lived access token plus the refresh token that keeps renewing it
+	setSessionCookies(ctx.Response(), ac.jwtService, accessToken, refreshToken)
+
+	if firstLogin {
+		// Set first login cookie
+		http.SetCookie(ctx.Response(), &http.Cookie{
+			Name:     "first_login",
+			Value:    strconv.FormatBool(firstLogin),
+			Path:     "/",
+			HttpOnly: false,
+			MaxAge:   60, //Expire in 60 seconds
+		})
+	}
+
+	return ctx.Redirect(307, ac.config.FrontendURL)
+}
+
+// Whoever started the link must still be the one logged in when the provider sends them back
+func (ac *AuthController) finishLink(ctx fuego.ContextNoBody, linkUserID string, identity *domain.ProviderIdentity) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok || strconv.Itoa(userID) != linkUserID {
+		return nil, fuego.UnauthorizedError{Detail: "Log in again to link your " + identity.Provider + " account"}
+	}
+
+	if _, err := ac.identityService.LinkIdentity(ctx.Context(), userID, identity); err != nil {
+		return nil, identityError(err)
+	}
+
+	return ctx.Redirect(307, ac.config.FrontendURL)
+}
+
+func (ac *AuthController) GetIdentities(ctx fuego.ContextNoBody) ([]*domain.LinkedIdentity, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	identities, err := ac.identityService.GetIdentities(ctx.Context(), userID)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to fetch linked accounts: " + err.Error()}
+	}
+	return identities, nil
+}
+
+func (ac *AuthController) UnlinkIdentity(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	if err := ac.identityService.UnlinkIdentity(ctx.Context(), userID, ctx.PathParam("provider")); err != nil {
+		return nil, identityError(err)
+	}
+	return nil, nil
+}
+
+func identityError(err error) error {
+	var notFound domain_errors.IdentityNotFoundError
+	var alreadyLinked domain_errors.IdentityAlreadyLinkedError
+	var linkedToAnother domain_errors.IdentityLinkedToAnotherUserError
+	var providerLinked domain_errors.ProviderAlreadyLinkedError
+	var lastIdentity domain_errors.CantUnlinkLastIdentityError
+
+	switch {
+	case errors.As(err, &notFound):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &alreadyLinked), errors.As(err, &linkedToAnother), errors.As(err, &providerLinked):
+		return fuego.ConflictError{Detail: err.Error()}
+	case errors.As(err, &lastIdentity):
+		return fuego.BadRequestError{Detail: err.Error()}
+	default:
+		return fuego.InternalServerError{Detail: err.Error()}
+	}
+}
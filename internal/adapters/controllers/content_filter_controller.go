@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"github.com/go-fuego/fuego"
+)
+
+type ContentFilterController struct {
+	filterService interfaces.ContentFilterService
+	postService   interfaces.PostService
+}
+
+func NewContentFilterController(filterService interfaces.ContentFilterService, postService interfaces.PostService) *ContentFilterController {
+	return &ContentFilterController{filterService: filterService, postService: postService}
+}
+
+func (c *ContentFilterController) GetRules(ctx fuego.ContextNoBody) ([]*domain.FilterRule, error) {
+	rules, err := c.filterService.GetRules(ctx.Context())
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: err.Error()}
+	}
+	return rules, nil
+}
+
+type CreateFilterRuleBody struct {
+	Kind        value.FilterRuleKind `json:"Kind"`
+	Pattern     string               `json:"Pattern"`   // Word and regex rules
+	Threshold   int                  `json:"Threshold"` // Heuristics
+	Action      value.FilterAction   `json:"Action"`
+	Description string               `json:"Description"`
+}
+
+func (c *ContentFilterController) CreateRule(ctx fuego.ContextWithBody[CreateFilterRuleBody]) (*domain.FilterRule, error) {
+	adminID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	rule, err := c.filterService.CreateRule(ctx.Context(), body.Kind, body.Pattern, body.Threshold, body.Action, body.Description, adminID)
+	if err != nil {
+		return nil, filterError(err)
+	}
+	return rule, nil
+}
+
+type UpdateFilterRuleBody struct {
+	Pattern     string             `json:"Pattern"`
+	Threshold   int                `json:"Threshold"`
+	Action      value.FilterAction `json:"Action"`
+	Description string             `json:"Description"`
+	Enabled     bool               `json:"Enabled"`
+}
+
+func (c *ContentFilterController) UpdateRule(ctx fuego.ContextWithBody[UpdateFilterRuleBody]) (*domain.FilterRule, error) {
+	adminID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid rule ID"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	rule, err := c.filterService.UpdateRule(ctx.Context(), id, body.Pattern, body.Threshold, body.Action, body.Description, body.Enabled, adminID)
+	if err != nil {
+		return nil, filterError(err)
+	}
+	return rule, nil
+}
+
+func (c *ContentFilterController) DeleteRule(ctx fuego.ContextNoBody) (any, error) {
+	adminID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid rule ID"}
+	}
+
+	if err := c.filterService.DeleteRule(ctx.Context(), id, adminID); err != nil {
+		return nil, filterError(err)
+	}
+	return nil, nil
+}
+
+type FilterHitListResponse struct {
+	Data       []*domain.FilterHit `json:"data"`
+	Pagination utils.Pagination    `json:"pagination"`
+}
+
+func (c *ContentFilterController) GetHits(ctx fuego.ContextNoBody) (FilterHitListResponse, error) {
+	var action *value.FilterAction
+	if actionStr := ctx.QueryParam("action"); actionStr != "" {
+		a, err := strconv.ParseUint(actionStr, 10, 8)
+		if err != nil || !value.FilterAction(a).IsValid() {
+			return FilterHitListResponse{}, fuego.BadRequestError{Detail: "Invalid action"}
+		}
+		parsed := value.FilterAction(a)
+		action = &parsed
+	}
+
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+
+	hits, pagination, err := c.filterService.GetHits(ctx.Context(), action, pageNumber, pageSize)
+	if err != nil {
+		return FilterHitListResponse{}, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return FilterHitListResponse{
+		Data:       hits,
+		Pagination: pagination,
+	}, nil
+}
+
+type HeldPostListResponse struct {
+	Data       []*domain.Post   `json:"data"`
+	Pagination utils.Pagination `json:"pagination"`
+}
+
+func (c *ContentFilterController) GetHeldPosts(ctx fuego.ContextNoBody) (HeldPostListResponse, error) {
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+
+	posts, pagination, err := c.postService.GetHeldPosts(ctx.Context(), pageNumber, pageSize)
+	if err != nil {
+		return HeldPostListResponse{}, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return HeldPostListResponse{
+		Data:       posts,
+		Pagination: pagination,
+	}, nil
+}
+
+func (c *ContentFilterController) ReleaseHeldPost(ctx fuego.ContextNoBody) (any, error) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	if err := c.postService.ReleaseHeldPost(ctx.Context(), ctx.PathParam("post_id"), modID); err != nil {
+		return nil, filterError(err)
+	}
+	return nil, nil
+}
+
+func (c *ContentFilterController) RejectHeldPost(ctx fuego.ContextNoBody) (any, error) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	if err := c.postService.RejectHeldPost(ctx.Context(), ctx.PathParam("post_id"), modID); err != nil {
+		return nil, filterError(err)
+	}
+	return nil, nil
+}
+
+func filterError(err error) error {
+	var (
+		ruleNotFoundErr domain_errors.FilterRuleNotFoundError
+		postNotFoundErr domain_errors.PostNotFoundError
+		notHeldErr      domain_errors.PostNotHeldError
+	)
+	switch {
+	case errors.As(err, &ruleNotFoundErr), errors.As(err, &postNotFoundErr):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &notHeldErr):
+		return fuego.ConflictError{Detail: err.Error()}
+	}
+	return fuego.BadRequestError{Detail: err.Error()}
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/go-fuego/fuego"
+)
+
+type DataExportController struct {
+	exportService interfaces.DataExportService
+}
+
+func NewDataExportController(exportService interfaces.DataExportService) *DataExportController {
+	return &DataExportController{
+		exportService: exportService,
+	}
+}
+
+func (c *DataExportController) RequestExport(ctx fuego.ContextNoBody) (*domain.DataExport, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	export, err := c.exportService.RequestExport(ctx.Context(), userID)
+	if err != nil {
+		return nil, dataExportError(err)
+	}
+	return export, nil
+}
+
+func (c *DataExportController) GetExports(ctx fuego.ContextNoBody) ([]*domain.DataExport, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	exports, err := c.exportService.GetExports(ctx.Context(), userID)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to fetch data exports: " + err.Error()}
+	}
+	return exports, nil
+}
+
+// Plain handler, the archive goes out as is and not as JSON
+func (c *DataExportController) DownloadExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	export, archive, err := c.exportService.DownloadExport(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		var withStatus fuego.ErrorWithStatus
+		if errors.As(dataExportError(err), &withStatus) {
+			status = withStatus.StatusCode()
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	defer archive.Close()
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="afuradanime-`+export.ID+`.zip"`)
+	w.Header().Set("Content-Length", strconv.Itoa(export.Size))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, archive)
+}
+
+func dataExportError(err error) error {
+	var notFound domain_errors.DataExportNotFoundError
+	var userNotFound domain_errors.UserNotFoundError
+	var notReady domain_errors.DataExportNotReadyError
+	var inProgress domain_errors.DataExportInProgressError
+	var cooldown domain_errors.DataExportCooldownError
+	var deleted domain_errors.AccountDeletedError
+
+	switch {
+	case errors.As(err, &notFound), errors.As(err, &userNotFound):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &notReady), errors.As(err, &inProgress):
+		return fuego.ConflictError{Detail: err.Error()}
+	case errors.As(err, &cooldown):
+		return fuego.HTTPError{Status: http.StatusTooManyRequests, Detail: err.Error()}
+	case errors.As(err, &deleted):
+		return fuego.BadRequestError{Detail: err.Error()}
+	default:
+		return fuego.InternalServerError{Detail: err.Error()}
+	}
+}
@@ -0,0 +1,163 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+const SSE_HEARTBEAT_INTERVAL = 25 * time.Second
+const SSE_RETRY_MILLISECONDS = 3000
+const MAX_WATCHED_THREADS = 20
+
+type EventsController struct {
+	events            interfaces.EventBroker
+	friendshipService interfaces.FriendshipService
+	postService       interfaces.PostService
+}
+
+func NewEventsController(
+	events interfaces.EventBroker,
+	friendshipService interfaces.FriendshipService,
+	postService interfaces.PostService,
+) *EventsController {
+	return &EventsController{
+		events:            events,
+		friendshipService: friendshipService,
+		postService:       postService,
+	}
+}
+
+// Server-Sent Events stream for the logged user
+// Always carries their notifications and their friends' presence changes,
+// threads being viewed are passed as ?thread=<parentType>:<parentId>, repeated
+func (c *EventsController) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	topics := []string{domain.UserTopic(userID)}
+
+	threads := r.URL.Query()["thread"]
+	if len(threads) > MAX_WATCHED_THREADS {
+		http.Error(w, "Too many threads, max "+strconv.Itoa(MAX_WATCHED_THREADS), http.StatusBadRequest)
+		return
+	}
+	for _, thread := range threads {
+		parentType, parentID, ok := strings.Cut(thread, ":")
+		t, err := strconv.Atoi(parentType)
+		if !ok || err != nil || parentID == "" {
+			http.Error(w, "Invalid thread "+thread+", expected <parentType>:<parentId>", http.StatusBadRequest)
+			return
+		}
+
+		// Threads of private groups are for their members only
+		if err := c.postService.CanViewThread(r.Context(), value.PostParentType(t), parentID, &userID); err != nil {
+			http.Error(w, "Cannot watch thread "+thread+": "+err.Error(), http.StatusForbidden)
+			return
+		}
+		topics = append(topics, domain.ThreadTopic(value.PostParentType(t), parentID))
+	}
+
+	friends, err := fetchFriendIDs(r.Context(), c.friendshipService, userID)
+	if err != nil {
+		http.Error(w, "Failed to fetch friends: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for _, friendID := range friends {
+		topics = append(topics, domain.PresenceTopic(friendID))
+	}
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Don't let nginx buffer the stream
+	w.WriteHeader(http.StatusOK)
+
+	// Browsers resend the id of the last event they saw when reconnecting
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+
+	subscription, missed := c.events.Subscribe(topics, lastEventID)
+	defer subscription.Close()
+
+	fmt.Fprintf(w, "retry: %d\n\n", SSE_RETRY_MILLISECONDS)
+	for _, event := range missed {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(SSE_HEARTBEAT_INTERVAL)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return // Dropped by the broker, the client will reconnect and replay
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// Walks every page of the friend list, real-time features need all of them at once
+func fetchFriendIDs(ctx context.Context, friendshipService interfaces.FriendshipService, userID int) ([]int, error) {
+	ids := []int{}
+	for page := 1; ; page++ {
+		friends, pagination, err := friendshipService.GetFriendList(ctx, userID, page, utils.MAX_PAGE_SIZE)
+		if err != nil {
+			return nil, err
+		}
+		for _, friend := range friends {
+			ids = append(ids, friend.ID)
+		}
+		if page >= pagination.TotalPages {
+			return ids, nil
+		}
+	}
+}
+
+func writeEvent(w http.ResponseWriter, event *domain.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
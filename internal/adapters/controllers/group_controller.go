@@ -1,10 +1,15 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/afuradanime/backend/internal/adapters/middlewares"
 	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
 	"github.com/afuradanime/backend/internal/core/interfaces"
 	"github.com/afuradanime/backend/internal/core/utils"
 	"github.com/go-fuego/fuego"
@@ -23,9 +28,37 @@ type GroupListResponse struct {
 	Pagination utils.Pagination `json:"pagination"`
 }
 
+func parseGroupFilters(ctx fuego.ContextNoBody) filters.GroupFilter {
+	var f filters.GroupFilter
+
+	if q := strings.TrimSpace(ctx.QueryParam("q")); q != "" {
+		f.Query = &q
+	}
+	if animeStr := ctx.QueryParam("anime"); animeStr != "" {
+		if a, err := strconv.ParseUint(animeStr, 10, 32); err == nil {
+			a32 := uint32(a)
+			f.AnimeID = &a32
+		}
+	}
+	if tagStr := ctx.QueryParam("tag"); tagStr != "" {
+		if t, err := strconv.ParseUint(tagStr, 10, 32); err == nil {
+			t32 := uint32(t)
+			f.TagID = &t32
+		}
+	}
+	switch ctx.QueryParam("sort") {
+	case "members":
+		f.Sort = filters.GroupSortMembers
+	case "activity":
+		f.Sort = filters.GroupSortActivity
+	}
+
+	return f
+}
+
 func (gc *GroupController) GetGroups(ctx fuego.ContextNoBody) (GroupListResponse, error) {
 	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
-	groups, pagination, err := gc.groupService.GetGroups(ctx.Context(), pageNumber, pageSize)
+	groups, pagination, err := gc.groupService.GetGroups(ctx.Context(), parseGroupFilters(ctx), pageNumber, pageSize)
 	if err != nil {
 		return GroupListResponse{}, fuego.InternalServerError{Detail: "Failed to retrieve groups"}
 	}
@@ -47,6 +80,66 @@ func (gc *GroupController) GetGroupByID(ctx fuego.ContextNoBody) (*domain.Group,
 	return group, nil
 }
 
+type CreateGroupBody struct {
+	Name        string `json:"Name"`
+	Description string `json:"Description"`
+	Rules       string `json:"Rules"`
+	Icon        string `json:"Icon"`
+	Public      *bool  `json:"Public"` // Defaults to public
+
+	AnimeID *uint32  `json:"AnimeID"`
+	Tags    []uint32 `json:"Tags"`
+}
+
+func (gc *GroupController) CreateGroup(ctx fuego.ContextWithBody[CreateGroupBody]) (*domain.Group, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+	public := true
+	if body.Public != nil {
+		public = *body.Public
+	}
+	group, err := gc.groupService.CreateGroup(ctx.Context(), body.Name, body.Description, body.Rules, body.Icon, public,
+		body.AnimeID, body.Tags, userID)
+	if err != nil {
+		var notFoundErr domain_errors.UserNotFoundError
+		if errors.As(err, &notFoundErr) {
+			return nil, fuego.NotFoundError{Detail: err.Error()}
+		}
+		return nil, fuego.BadRequestError{Detail: err.Error()}
+	}
+	return group, nil
+}
+
+type UpdateGroupCategoriesBody struct {
+	AnimeID *uint32  `json:"AnimeID"`
+	Tags    []uint32 `json:"Tags"`
+}
+
+func (gc *GroupController) UpdateGroupCategories(ctx fuego.ContextWithBody[UpdateGroupCategoriesBody]) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+	if err := gc.groupService.UpdateGroupCategories(ctx.Context(), groupID, body.AnimeID, body.Tags, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
 type UpdateGroupBody struct {
 	Name        *string `json:"Name"`
 	Description *string `json:"Description"`
@@ -130,3 +223,479 @@ func (gc *GroupController) RemoveGroupModerator(ctx fuego.ContextWithBody[Modify
 	}
 	return nil, nil
 }
+
+type GroupMembersResponse struct {
+	Data       []*domain.GroupMembership `json:"data"`
+	Pagination utils.Pagination          `json:"pagination"`
+}
+
+func (gc *GroupController) GetMembers(ctx fuego.ContextNoBody) (GroupMembersResponse, error) {
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return GroupMembersResponse{}, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+	members, pagination, err := gc.groupService.GetMembers(ctx.Context(), groupID, viewerFromContext(ctx), pageNumber, pageSize)
+	if err != nil {
+		return GroupMembersResponse{}, groupError(err)
+	}
+	return GroupMembersResponse{
+		Data:       members,
+		Pagination: pagination,
+	}, nil
+}
+
+func (gc *GroupController) JoinGroup(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	if err := gc.groupService.JoinGroup(ctx.Context(), groupID, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) LeaveGroup(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	if err := gc.groupService.LeaveGroup(ctx.Context(), groupID, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) RequestToJoin(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	if err := gc.groupService.RequestToJoin(ctx.Context(), groupID, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+type GroupJoinRequestsResponse struct {
+	Data       []*domain.GroupJoinRequest `json:"data"`
+	Pagination utils.Pagination           `json:"pagination"`
+}
+
+func (gc *GroupController) GetJoinRequests(ctx fuego.ContextNoBody) (GroupJoinRequestsResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return GroupJoinRequestsResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return GroupJoinRequestsResponse{}, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+	requests, pagination, err := gc.groupService.GetJoinRequests(ctx.Context(), groupID, userID, pageNumber, pageSize)
+	if err != nil {
+		return GroupJoinRequestsResponse{}, groupError(err)
+	}
+	return GroupJoinRequestsResponse{
+		Data:       requests,
+		Pagination: pagination,
+	}, nil
+}
+
+func (gc *GroupController) ApproveJoinRequest(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	requester, err := strconv.Atoi(ctx.PathParam("userID"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid user ID"}
+	}
+	if err := gc.groupService.ApproveJoinRequest(ctx.Context(), groupID, requester, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) DeclineJoinRequest(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	requester, err := strconv.Atoi(ctx.PathParam("userID"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid user ID"}
+	}
+	if err := gc.groupService.DeclineJoinRequest(ctx.Context(), groupID, requester, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+type CreateInviteBody struct {
+	MaxUses      int `json:"MaxUses"`      // 0 for unlimited
+	ExpiresInHrs int `json:"ExpiresInHrs"` // 0 for the default, capped at 30 days
+}
+
+func (gc *GroupController) CreateInvite(ctx fuego.ContextWithBody[CreateInviteBody]) (*domain.GroupInvite, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+	ttl := time.Duration(body.ExpiresInHrs) * time.Hour
+	invite, err := gc.groupService.CreateInvite(ctx.Context(), groupID, userID, body.MaxUses, ttl)
+	if err != nil {
+		return nil, groupError(err)
+	}
+	return invite, nil
+}
+
+func (gc *GroupController) GetInvites(ctx fuego.ContextNoBody) ([]*domain.GroupInvite, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	invites, err := gc.groupService.GetInvites(ctx.Context(), groupID, userID)
+	if err != nil {
+		return nil, groupError(err)
+	}
+	return invites, nil
+}
+
+func (gc *GroupController) RevokeInvite(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	if err := gc.groupService.RevokeInvite(ctx.Context(), groupID, ctx.PathParam("code"), userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) JoinWithInvite(ctx fuego.ContextNoBody) (*domain.Group, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	group, err := gc.groupService.JoinWithInvite(ctx.Context(), ctx.PathParam("code"), userID)
+	if err != nil {
+		return nil, groupError(err)
+	}
+	return group, nil
+}
+
+type SanctionBody struct {
+	Reason       string `json:"Reason"`
+	DurationMins int    `json:"DurationMins"` // Mutes only
+}
+
+// Group id and target user id, shared by all the sanction endpoints
+func sanctionParams(ctx interface{ PathParam(string) string }) (int, int, error) {
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return 0, 0, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	target, err := strconv.Atoi(ctx.PathParam("userID"))
+	if err != nil {
+		return 0, 0, fuego.BadRequestError{Detail: "Invalid user ID"}
+	}
+	return groupID, target, nil
+}
+
+func (gc *GroupController) BanMember(ctx fuego.ContextWithBody[SanctionBody]) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, target, err := sanctionParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+	if err := gc.groupService.BanMember(ctx.Context(), groupID, target, userID, body.Reason); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) UnbanMember(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, target, err := sanctionParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := gc.groupService.UnbanMember(ctx.Context(), groupID, target, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+type GroupBansResponse struct {
+	Data       []*domain.GroupBan `json:"data"`
+	Pagination utils.Pagination   `json:"pagination"`
+}
+
+func (gc *GroupController) GetBans(ctx fuego.ContextNoBody) (GroupBansResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return GroupBansResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return GroupBansResponse{}, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+	bans, pagination, err := gc.groupService.GetBans(ctx.Context(), groupID, userID, pageNumber, pageSize)
+	if err != nil {
+		return GroupBansResponse{}, groupError(err)
+	}
+	return GroupBansResponse{
+		Data:       bans,
+		Pagination: pagination,
+	}, nil
+}
+
+func (gc *GroupController) MuteMember(ctx fuego.ContextWithBody[SanctionBody]) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, target, err := sanctionParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+	duration := time.Duration(body.DurationMins) * time.Minute
+	if err := gc.groupService.MuteMember(ctx.Context(), groupID, target, userID, body.Reason, duration); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) UnmuteMember(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, target, err := sanctionParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := gc.groupService.UnmuteMember(ctx.Context(), groupID, target, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+type GroupModerationLogResponse struct {
+	Data       []*domain.GroupModerationEntry `json:"data"`
+	Pagination utils.Pagination               `json:"pagination"`
+}
+
+func (gc *GroupController) GetModerationLog(ctx fuego.ContextNoBody) (GroupModerationLogResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return GroupModerationLogResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return GroupModerationLogResponse{}, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+	entries, pagination, err := gc.groupService.GetModerationLog(ctx.Context(), groupID, userID, pageNumber, pageSize)
+	if err != nil {
+		return GroupModerationLogResponse{}, groupError(err)
+	}
+	return GroupModerationLogResponse{
+		Data:       entries,
+		Pagination: pagination,
+	}, nil
+}
+
+// Maps the group domain errors to their http counterparts
+func (gc *GroupController) TransferOwnership(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, newOwner, err := sanctionParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := gc.groupService.TransferOwnership(ctx.Context(), groupID, newOwner, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) AcceptOwnership(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	if err := gc.groupService.AcceptOwnership(ctx.Context(), groupID, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) CancelOwnershipTransfer(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	if err := gc.groupService.CancelOwnershipTransfer(ctx.Context(), groupID, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) ArchiveGroup(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	if err := gc.groupService.ArchiveGroup(ctx.Context(), groupID, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) UnarchiveGroup(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	if err := gc.groupService.UnarchiveGroup(ctx.Context(), groupID, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func (gc *GroupController) DeleteGroup(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+	if err := gc.groupService.DeleteGroup(ctx.Context(), groupID, userID); err != nil {
+		return nil, groupError(err)
+	}
+	return nil, nil
+}
+
+func groupError(err error) error {
+	var (
+		notFoundErr        domain_errors.GroupNotFoundError
+		requestNotFoundErr domain_errors.JoinRequestNotFoundError
+		inviteNotFoundErr  domain_errors.GroupInviteNotFoundError
+		alreadyMemberErr   domain_errors.AlreadyGroupMemberError
+		alreadyAskedErr    domain_errors.JoinRequestAlreadySentError
+		noModsLeftErr      domain_errors.NoModeratorsLeftError
+		notMemberErr       domain_errors.NotGroupMemberError
+		privateErr         domain_errors.GroupIsPrivateError
+		membershipErr      domain_errors.GroupMembershipRequiredError
+		unauthorizedErr    domain_errors.UnauthorizedError
+		inviteExpiredErr   domain_errors.GroupInviteExpiredError
+		userNotFoundErr    domain_errors.UserNotFoundError
+		alreadyBannedErr   domain_errors.AlreadyBannedFromGroupError
+		notBannedErr       domain_errors.NotBannedFromGroupError
+		bannedErr          domain_errors.GroupBannedError
+		mutedErr           domain_errors.GroupMutedError
+		sanctionModErr     domain_errors.CannotSanctionModeratorError
+		muteDurationErr    domain_errors.InvalidMuteDurationError
+		tooManyTagsErr     domain_errors.TooManyGroupTagsError
+		invalidTagErr      domain_errors.InvalidGroupTagError
+		animeNotFoundErr   domain_errors.AnimeNotFoundError
+		ownerStepDownErr   domain_errors.OwnerCannotStepDownError
+		alreadyOwnerErr    domain_errors.AlreadyGroupOwnerError
+		noTransferErr      domain_errors.NoOwnershipTransferError
+		archivedErr        domain_errors.GroupArchivedError
+		notArchivedErr     domain_errors.GroupNotArchivedError
+	)
+	switch {
+	case errors.As(err, &notFoundErr), errors.As(err, &requestNotFoundErr), errors.As(err, &inviteNotFoundErr),
+		errors.As(err, &userNotFoundErr):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &alreadyMemberErr), errors.As(err, &alreadyAskedErr), errors.As(err, &noModsLeftErr),
+		errors.As(err, &alreadyBannedErr), errors.As(err, &ownerStepDownErr), errors.As(err, &alreadyOwnerErr),
+		errors.As(err, &notArchivedErr):
+		return fuego.ConflictError{Detail: err.Error()}
+	case errors.As(err, &notMemberErr), errors.As(err, &notBannedErr), errors.As(err, &sanctionModErr),
+		errors.As(err, &muteDurationErr), errors.As(err, &tooManyTagsErr), errors.As(err, &invalidTagErr),
+		errors.As(err, &animeNotFoundErr), errors.As(err, &noTransferErr):
+		return fuego.BadRequestError{Detail: err.Error()}
+	case errors.As(err, &privateErr), errors.As(err, &membershipErr), errors.As(err, &unauthorizedErr),
+		errors.As(err, &bannedErr), errors.As(err, &mutedErr), errors.As(err, &archivedErr):
+		return fuego.ForbiddenError{Detail: err.Error()}
+	case errors.As(err, &inviteExpiredErr):
+		return fuego.HTTPError{Status: 410, Detail: err.Error()}
+	}
+	return fuego.InternalServerError{Detail: err.Error()}
+}
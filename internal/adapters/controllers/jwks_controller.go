@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/go-fuego/fuego"
+)
+
+type JWKSController struct {
+	signingKeyService interfaces.SigningKeyService
+}
+
+func NewJWKSController(signingKeyService interfaces.SigningKeyService) *JWKSController {
+	return &JWKSController{signingKeyService: signingKeyService}
+}
+
+// Every public key a token of ours could currently be signed with, pick by the token's kid
+func (c *JWKSController) GetJWKS(ctx fuego.ContextNoBody) (domain.JWKSet, error) {
+	// Well under domain.SIGNING_KEY_ACTIVATION_DELAY, so new keys are known before they sign anything
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.signingKeyService.GetJWKS(), nil
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/services"
+	"github.com/go-fuego/fuego"
+)
+
+type MentionController struct {
+	mentionService interfaces.MentionService
+}
+
+func NewMentionController(s interfaces.MentionService) *MentionController {
+	return &MentionController{mentionService: s}
+}
+
+func (c *MentionController) Autocomplete(ctx fuego.ContextNoBody) ([]*domain.User, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	limit := services.MAX_MENTION_SUGGESTIONS
+	if l := ctx.QueryParam("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil {
+			return nil, fuego.BadRequestError{Detail: "Invalid limit"}
+		}
+		limit = parsed
+	}
+
+	users, err := c.mentionService.Autocomplete(ctx.Context(), userID, ctx.QueryParam("q"), limit)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return users, nil
+}
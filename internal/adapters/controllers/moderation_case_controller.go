@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"github.com/go-fuego/fuego"
+)
+
+type ModerationCaseController struct {
+	caseService interfaces.ModerationCaseService
+}
+
+func NewModerationCaseController(caseService interfaces.ModerationCaseService) *ModerationCaseController {
+	return &ModerationCaseController{caseService: caseService}
+}
+
+type CaseListResponse struct {
+	Data       []*domain.ModerationCase `json:"data"`
+	Pagination utils.Pagination         `json:"pagination"`
+}
+
+func parseCaseFilters(ctx fuego.ContextNoBody) (filters.CaseFilter, error) {
+	var f filters.CaseFilter
+
+	if statusStr := ctx.QueryParam("status"); statusStr != "" {
+		s, err := strconv.ParseUint(statusStr, 10, 8)
+		if err != nil || !value.CaseStatus(s).IsValid() {
+			return f, fuego.BadRequestError{Detail: "Invalid status"}
+		}
+		status := value.CaseStatus(s)
+		f.Status = &status
+	}
+	if assigneeStr := ctx.QueryParam("assignee"); assigneeStr != "" {
+		a, err := strconv.Atoi(assigneeStr)
+		if err != nil {
+			return f, fuego.BadRequestError{Detail: "Invalid assignee"}
+		}
+		f.AssignedTo = &a
+	}
+	if typeStr := ctx.QueryParam("targetType"); typeStr != "" {
+		t, err := strconv.ParseUint(typeStr, 10, 8)
+		if err != nil || !value.ReportTargetType(t).IsValid() {
+			return f, fuego.BadRequestError{Detail: "Invalid target type"}
+		}
+		targetType := value.ReportTargetType(t)
+		f.TargetType = &targetType
+	}
+
+	return f, nil
+}
+
+func (c *ModerationCaseController) GetCases(ctx fuego.ContextNoBody) (CaseListResponse, error) {
+	f, err := parseCaseFilters(ctx)
+	if err != nil {
+		return CaseListResponse{}, err
+	}
+
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+
+	cases, pagination, err := c.caseService.GetCases(ctx.Context(), f, pageNumber, pageSize)
+	if err != nil {
+		return CaseListResponse{}, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return CaseListResponse{
+		Data:       cases,
+		Pagination: pagination,
+	}, nil
+}
+
+type CaseResponse struct {
+	Case    *domain.ModerationCase `json:"case"`
+	Reports []domain.Report        `json:"reports"`
+}
+
+func (c *ModerationCaseController) GetCase(ctx fuego.ContextNoBody) (CaseResponse, error) {
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return CaseResponse{}, fuego.BadRequestError{Detail: "Invalid case ID"}
+	}
+
+	moderationCase, reports, err := c.caseService.GetCase(ctx.Context(), id)
+	if err != nil {
+		return CaseResponse{}, caseError(err)
+	}
+
+	return CaseResponse{
+		Case:    moderationCase,
+		Reports: reports,
+	}, nil
+}
+
+func (c *ModerationCaseController) AssignCase(ctx fuego.ContextNoBody) (any, error) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid case ID"}
+	}
+
+	assignee, err := strconv.Atoi(ctx.PathParam("userID"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid user ID"}
+	}
+
+	if err := c.caseService.AssignCase(ctx.Context(), id, assignee, modID); err != nil {
+		return nil, caseError(err)
+	}
+
+	return nil, nil
+}
+
+func (c *ModerationCaseController) UnassignCase(ctx fuego.ContextNoBody) (any, error) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid case ID"}
+	}
+
+	if err := c.caseService.UnassignCase(ctx.Context(), id, modID); err != nil {
+		return nil, caseError(err)
+	}
+
+	return nil, nil
+}
+
+type CaseNoteBody struct {
+	Text string `json:"Text"`
+}
+
+func (c *ModerationCaseController) AddNote(ctx fuego.ContextWithBody[CaseNoteBody]) (any, error) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid case ID"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	if err := c.caseService.AddNote(ctx.Context(), id, body.Text, modID); err != nil {
+		return nil, caseError(err)
+	}
+
+	return nil, nil
+}
+
+type ResolveCaseBody struct {
+	Outcome value.CaseOutcome `json:"Outcome"`
+	Note    string            `json:"Note"` // Optional
+}
+
+func (c *ModerationCaseController) ResolveCase(ctx fuego.ContextWithBody[ResolveCaseBody]) (any, error) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid case ID"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	if err := c.caseService.ResolveCase(ctx.Context(), id, body.Outcome, body.Note, modID); err != nil {
+		return nil, caseError(err)
+	}
+
+	return nil, nil
+}
+
+func (c *ModerationCaseController) ReopenCase(ctx fuego.ContextNoBody) (any, error) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid case ID"}
+	}
+
+	if err := c.caseService.ReopenCase(ctx.Context(), id, modID); err != nil {
+		return nil, caseError(err)
+	}
+
+	return nil, nil
+}
+
+func caseError(err error) error {
+	var (
+		notFoundErr     domain_errors.CaseNotFoundError
+		userNotFoundErr domain_errors.UserNotFoundError
+		resolvedErr     domain_errors.CaseAlreadyResolvedError
+		notResolvedErr  domain_errors.CaseNotResolvedError
+		alreadyOpenErr  domain_errors.CaseAlreadyOpenError
+		outcomeErr      domain_errors.InvalidCaseOutcomeError
+		assigneeErr     domain_errors.CaseAssigneeNotModeratorError
+	)
+	switch {
+	case errors.As(err, &notFoundErr), errors.As(err, &userNotFoundErr):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &resolvedErr), errors.As(err, &notResolvedErr), errors.As(err, &alreadyOpenErr):
+		return fuego.ConflictError{Detail: err.Error()}
+	case errors.As(err, &outcomeErr), errors.As(err, &assigneeErr):
+		return fuego.BadRequestError{Detail: err.Error()}
+	}
+	return fuego.BadRequestError{Detail: err.Error()}
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"github.com/go-fuego/fuego"
+)
+
+type NotificationController struct {
+	notificationService interfaces.NotificationService
+}
+
+func NewNotificationController(s interfaces.NotificationService) *NotificationController {
+	return &NotificationController{notificationService: s}
+}
+
+type NotificationListResponse struct {
+	Data       []*domain.Notification `json:"data"`
+	Pagination utils.Pagination       `json:"pagination"`
+}
+
+func (c *NotificationController) GetNotifications(ctx fuego.ContextNoBody) (NotificationListResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return NotificationListResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+	unreadOnly := ctx.QueryParam("unread") == "true"
+
+	notifications, pagination, err := c.notificationService.GetNotifications(ctx.Context(), userID, unreadOnly, pageNumber, pageSize)
+	if err != nil {
+		return NotificationListResponse{}, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return NotificationListResponse{
+		Data:       notifications,
+		Pagination: pagination,
+	}, nil
+}
+
+func (c *NotificationController) GetUnreadCount(ctx fuego.ContextNoBody) (*domain.UnreadNotifications, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	unread, err := c.notificationService.GetUnreadCount(ctx.Context(), userID)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return unread, nil
+}
+
+func (c *NotificationController) MarkAsRead(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	err := c.notificationService.MarkAsRead(ctx.Context(), userID, ctx.PathParam("id"))
+	if err != nil {
+		var notFoundErr domain_errors.NotificationNotFoundError
+		if errors.As(err, &notFoundErr) {
+			return nil, fuego.NotFoundError{Detail: err.Error()}
+		}
+		return nil, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return nil, nil
+}
+
+func (c *NotificationController) MarkAllAsRead(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	if err := c.notificationService.MarkAllAsRead(ctx.Context(), userID); err != nil {
+		return nil, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return nil, nil
+}
+
+func (c *NotificationController) GetPreferences(ctx fuego.ContextNoBody) ([]domain.NotificationPreference, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	preferences, err := c.notificationService.GetPreferences(ctx.Context(), userID)
+	if err != nil {
+		return nil, fuego.NotFoundError{Detail: err.Error()}
+	}
+
+	return preferences, nil
+}
+
+func (c *NotificationController) UpdatePreferences(ctx fuego.ContextWithBody[[]domain.NotificationPreference]) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	preferences, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	if err := c.notificationService.UpdatePreferences(ctx.Context(), userID, preferences); err != nil {
+		return nil, fuego.BadRequestError{Detail: err.Error()}
+	}
+
+	return nil, nil
+}
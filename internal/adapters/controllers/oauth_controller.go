@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/go-fuego/fuego"
+)
+
+type OAuthController struct {
+	oauthService interfaces.OAuthService
+}
+
+func NewOAuthController(oauthService interfaces.OAuthService) *OAuthController {
+	return &OAuthController{
+		oauthService: oauthService,
+	}
+}
+
+type RegisterOAuthAppBody struct {
+	Name         string   `json:"Name"`
+	Description  string   `json:"Description"`
+	Homepage     string   `json:"Homepage"`
+	RedirectURIs []string `json:"RedirectURIs"`
+	Public       bool     `json:"Public"` // No client secret, for apps that can't keep one
+}
+
+type RegisteredOAuthAppResponse struct {
+	*domain.OAuthApp
+	ClientSecret string `json:"ClientSecret,omitempty"` // Shown this one time only
+}
+
+type AuthorizeBody struct {
+	domain.AuthorizationRequest
+	Approve bool `json:"approve"`
+}
+
+type AuthorizeResponse struct {
+	RedirectURL string `json:"RedirectURL"` // Where the frontend sends the user next
+}
+
+// Token endpoint responses, field names as RFC 6749 has them
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+}
+
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func (c *OAuthController) RegisterApp(ctx fuego.ContextWithBody[RegisterOAuthAppBody]) (RegisteredOAuthAppResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return RegisteredOAuthAppResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return RegisteredOAuthAppResponse{}, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	app, secret, err := c.oauthService.RegisterApp(ctx.Context(), userID, body.Name, body.Description, body.Homepage, body.RedirectURIs, body.Public)
+	if err != nil {
+		return RegisteredOAuthAppResponse{}, oauthError(err)
+	}
+	return RegisteredOAuthAppResponse{OAuthApp: app, ClientSecret: secret}, nil
+}
+
+func (c *OAuthController) GetApps(ctx fuego.ContextNoBody) ([]*domain.OAuthApp, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	apps, err := c.oauthService.GetApps(ctx.Context(), userID)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to fetch apps: " + err.Error()}
+	}
+	return apps, nil
+}
+
+func (c *OAuthController) DeleteApp(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	if err := c.oauthService.DeleteApp(ctx.Context(), userID, ctx.PathParam("id")); err != nil {
+		return nil, oauthError(err)
+	}
+	return nil, nil
+}
+
+// Everything the consent screen shows, the frontend passes the app's query string along as is
+func (c *OAuthController) GetConsent(ctx fuego.ContextNoBody) (*domain.OAuthConsent, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	request := domain.AuthorizationRequest{
+		ClientID:            ctx.QueryParam("client_id"),
+		RedirectURI:         ctx.QueryParam("redirect_uri"),
+		ResponseType:        ctx.QueryParam("response_type"),
+		Scope:               ctx.QueryParam("scope"),
+		State:               ctx.QueryParam("state"),
+		CodeChallenge:       ctx.QueryParam("code_challenge"),
+		CodeChallengeMethod: ctx.QueryParam("code_challenge_method"),
+	}
+
+	consent, err := c.oauthService.GetConsent(ctx.Context(), userID, request)
+	if err != nil {
+		return nil, oauthError(err)
+	}
+	return consent, nil
+}
+
+// The user's answer on the consent screen, either way the app hears back through its redirect URI
+func (c *OAuthController) Authorize(ctx fuego.ContextWithBody[AuthorizeBody]) (AuthorizeResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return AuthorizeResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return AuthorizeResponse{}, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	params := url.Values{}
+	if body.Approve {
+		code, err := c.oauthService.Authorize(ctx.Context(), userID, body.AuthorizationRequest)
+		if err != nil {
+			return AuthorizeResponse{}, oauthError(err)
+		}
+		params.Set("code", code)
+	} else {
+		// Still has to be an app and redirect URI we know, or this is an open redirect
+		if _, err := c.oauthService.GetConsent(ctx.Context(), userID, body.AuthorizationRequest); err != nil {
+			return AuthorizeResponse{}, oauthError(err)
+		}
+		params.Set("error", domain_errors.OAuthAccessDenied)
+	}
+	if body.State != "" {
+		params.Set("state", body.State)
+	}
+
+	redirect, err := url.Parse(body.RedirectURI)
+	if err != nil {
+		return AuthorizeResponse{}, fuego.BadRequestError{Detail: "Invalid redirect URI"}
+	}
+	query := redirect.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	redirect.RawQuery = query.Encode()
+
+	return AuthorizeResponse{RedirectURL: redirect.String()}, nil
+}
+
+// Token endpoint, form encoded as the spec wants, apps authenticate with basic auth or form fields
+func (c *OAuthController) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, domain_errors.OAuthError{Code: domain_errors.OAuthInvalidRequest, Description: "Invalid form body"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		// Basic auth credentials are form encoded first, see RFC 6749 section 2.3.1
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+
+	var token *domain.OAuthToken
+	var access, refresh string
+	var err error
+
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		token, access, refresh, err = c.oauthService.ExchangeCode(r.Context(), clientID, clientSecret,
+			r.PostForm.Get("code"), r.PostForm.Get("redirect_uri"), r.PostForm.Get("code_verifier"))
+	case "refresh_token":
+		token, access, refresh, err = c.oauthService.RefreshToken(r.Context(), clientID, clientSecret, r.PostForm.Get("refresh_token"))
+	default:
+		err = domain_errors.OAuthError{Code: domain_errors.OAuthUnsupportedGrantType, Description: "Only authorization_code and refresh_token are supported"}
+	}
+	if err != nil {
+		writeOAuthError(w, err)
+		return
+	}
+
+	scopes := make([]string, 0, len(token.Scopes))
+	for _, scope := range token.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	writeOAuthJSON(w, http.StatusOK, OAuthTokenResponse{
+		AccessToken:  access,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(domain.OAUTH_ACCESS_TOKEN_LIFETIME.Seconds()),
+		RefreshToken: refresh,
+		Scope:        strings.Join(scopes, " "),
+	})
+}
+
+func writeOAuthJSON(w http.ResponseWriter, status int, body any) {
+	// Tokens must never end up in a cache
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeOAuthError(w http.ResponseWriter, err error) {
+	var oauthErr domain_errors.OAuthError
+	if !errors.As(err, &oauthErr) {
+		writeOAuthJSON(w, http.StatusInternalServerError, OAuthErrorResponse{Error: "server_error"})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == domain_errors.OAuthInvalidClient {
+		status = http.StatusUnauthorized
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	writeOAuthJSON(w, status, OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+}
+
+func (c *OAuthController) GetGrants(ctx fuego.ContextNoBody) ([]*domain.OAuthGrant, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	grants, err := c.oauthService.GetGrants(ctx.Context(), userID)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to fetch authorized apps: " + err.Error()}
+	}
+	return grants, nil
+}
+
+func (c *OAuthController) RevokeGrant(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	if err := c.oauthService.RevokeGrant(ctx.Context(), userID, ctx.PathParam("appID")); err != nil {
+		return nil, oauthError(err)
+	}
+	return nil, nil
+}
+
+func oauthError(err error) error {
+	var appNotFound domain_errors.OAuthAppNotFoundError
+	var grantNotFound domain_errors.OAuthGrantNotFoundError
+	var invalidName domain_errors.InvalidOAuthAppNameError
+	var descriptionTooLong domain_errors.OAuthAppDescriptionTooLongError
+	var invalidHomepage domain_errors.InvalidOAuthAppHomepageError
+	var invalidRedirect domain_errors.InvalidRedirectURIError
+	var tooManyRedirects domain_errors.TooManyRedirectURIsError
+	var tooManyApps domain_errors.TooManyOAuthAppsError
+	var oauthErr domain_errors.OAuthError
+
+	switch {
+	case errors.As(err, &appNotFound), errors.As(err, &grantNotFound):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &invalidName), errors.As(err, &descriptionTooLong), errors.As(err, &invalidHomepage),
+		errors.As(err, &invalidRedirect), errors.As(err, &tooManyRedirects):
+		return fuego.BadRequestError{Detail: err.Error()}
+	case errors.As(err, &tooManyApps):
+		return fuego.ConflictError{Detail: err.Error()}
+	case errors.As(err, &oauthErr):
+		return fuego.BadRequestError{Title: oauthErr.Code, Detail: oauthErr.Description}
+	default:
+		return fuego.InternalServerError{Detail: err.Error()}
+	}
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/go-fuego/fuego"
+)
+
+type PersonalTokenController struct {
+	tokenService interfaces.PersonalTokenService
+}
+
+func NewPersonalTokenController(tokenService interfaces.PersonalTokenService) *PersonalTokenController {
+	return &PersonalTokenController{
+		tokenService: tokenService,
+	}
+}
+
+type CreatePersonalTokenBody struct {
+	Name          string             `json:"Name"`
+	Scopes        []value.TokenScope `json:"Scopes"`
+	ExpiresInDays *int               `json:"ExpiresInDays"` // Never expires if not set
+}
+
+type CreatedPersonalTokenResponse struct {
+	*domain.PersonalToken
+	Token string `json:"Token"` // Shown this one time only
+}
+
+func (c *PersonalTokenController) CreateToken(ctx fuego.ContextWithBody[CreatePersonalTokenBody]) (CreatedPersonalTokenResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return CreatedPersonalTokenResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return CreatedPersonalTokenResponse{}, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	var expiresAt *time.Time
+	if body.ExpiresInDays != nil {
+		if *body.ExpiresInDays <= 0 {
+			return CreatedPersonalTokenResponse{}, fuego.BadRequestError{Detail: domain_errors.InvalidTokenExpiryError{}.Error()}
+		}
+		at := time.Now().AddDate(0, 0, *body.ExpiresInDays)
+		expiresAt = &at
+	}
+
+	token, plain, err := c.tokenService.CreateToken(ctx.Context(), userID, body.Name, body.Scopes, expiresAt)
+	if err != nil {
+		return CreatedPersonalTokenResponse{}, personalTokenError(err)
+	}
+
+	return CreatedPersonalTokenResponse{PersonalToken: token, Token: plain}, nil
+}
+
+func (c *PersonalTokenController) GetTokens(ctx fuego.ContextNoBody) ([]*domain.PersonalToken, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	tokens, err := c.tokenService.GetTokens(ctx.Context(), userID)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to fetch tokens: " + err.Error()}
+	}
+	return tokens, nil
+}
+
+func (c *PersonalTokenController) RevokeToken(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	if err := c.tokenService.RevokeToken(ctx.Context(), userID, ctx.PathParam("id")); err != nil {
+		return nil, personalTokenError(err)
+	}
+	return nil, nil
+}
+
+func personalTokenError(err error) error {
+	var notFound domain_errors.PersonalTokenNotFoundError
+	var invalidScope domain_errors.InvalidTokenScopeError
+	var noScopes domain_errors.NoTokenScopesError
+	var invalidName domain_errors.InvalidTokenNameError
+	var invalidExpiry domain_errors.InvalidTokenExpiryError
+	var tooMany domain_errors.TooManyPersonalTokensError
+
+	switch {
+	case errors.As(err, &notFound):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &invalidScope), errors.As(err, &noScopes), errors.As(err, &invalidName), errors.As(err, &invalidExpiry):
+		return fuego.BadRequestError{Detail: err.Error()}
+	case errors.As(err, &tooMany):
+		return fuego.ConflictError{Detail: err.Error()}
+	default:
+		return fuego.InternalServerError{Detail: err.Error()}
+	}
+}
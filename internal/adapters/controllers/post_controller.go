@@ -23,9 +23,13 @@ func NewPostController(postService interfaces.PostService) *PostController {
 func (c *PostController) GetPostById(ctx fuego.ContextNoBody) (*domain.Post, error) {
 	postId := ctx.PathParam("post_id")
 
-	post, err := c.postService.GetPostById(ctx.Context(), postId)
-	if errors.Is(err, domain_errors.PostNotFoundError{}) {
+	post, err := c.postService.GetPostById(ctx.Context(), postId, viewerFromContext(ctx))
+	var membershipErr domain_errors.GroupMembershipRequiredError
+	var notFoundErr domain_errors.PostNotFoundError
+	if errors.As(err, &notFoundErr) {
 		return nil, fuego.NotFoundError{Detail: err.Error()}
+	} else if errors.As(err, &membershipErr) {
+		return nil, fuego.ForbiddenError{Detail: err.Error()}
 	} else if err != nil {
 		return nil, fuego.InternalServerError{Detail: "Internal error when fetching post: " + err.Error()}
 	}
@@ -51,7 +55,9 @@ func (c *PostController) CreatePost(ctx fuego.ContextWithBody[CreatePostBody]) (
 	}
 
 	post, err := c.postService.CreatePost(ctx.Context(), body.ParentID, body.ParentType, body.Text, posterId)
-	if err != nil {
+	if isGroupAccessError(err) {
+		return nil, fuego.ForbiddenError{Detail: err.Error()}
+	} else if err != nil {
 		return nil, fuego.BadRequestError{Detail: "Failed to create post: " + err.Error()}
 	}
 
@@ -66,7 +72,10 @@ func (c *PostController) DeletePost(ctx fuego.ContextNoBody) (any, error) {
 		return nil, fuego.ForbiddenError{Detail: "Not logged in, cannot delete post"}
 	}
 
-	err := c.postService.DeletePost(ctx.Context(), postId, deleterId)
+	// Only used when a group moderator removes someone else's post
+	reason := ctx.QueryParam("reason")
+
+	err := c.postService.DeletePost(ctx.Context(), postId, deleterId, reason)
 	if errors.Is(err, domain_errors.PostNotFoundError{}) {
 		return nil, fuego.NotFoundError{Detail: err.Error()}
 	} else if errors.Is(err, domain_errors.PostDeletedError{}) {
@@ -89,9 +98,13 @@ func (c *PostController) GetPostReplies(ctx fuego.ContextNoBody) ([]*domain.Post
 	}
 	parentType := value.PostParentType(parentTypeInt)
 
-	replies, err := c.postService.GetPostReplies(ctx.Context(), parentId, parentType)
-	if errors.Is(err, domain_errors.PostNotFoundError{}) {
+	replies, err := c.postService.GetPostReplies(ctx.Context(), parentId, parentType, viewerFromContext(ctx))
+	var membershipErr domain_errors.GroupMembershipRequiredError
+	var groupNotFoundErr domain_errors.GroupNotFoundError
+	if errors.Is(err, domain_errors.PostNotFoundError{}) || errors.As(err, &groupNotFoundErr) {
 		return nil, fuego.NotFoundError{Detail: err.Error()}
+	} else if errors.As(err, &membershipErr) {
+		return nil, fuego.ForbiddenError{Detail: err.Error()}
 	} else if err != nil {
 		return nil, fuego.InternalServerError{Detail: "Internal error when fetching post replies: " + err.Error()}
 	}
@@ -117,9 +130,19 @@ func (c *PostController) CreateReply(ctx fuego.ContextWithBody[CreateReplyBody])
 	post, err := c.postService.CreateReply(ctx.Context(), replyToPostID, body.Text, posterId)
 	if errors.Is(err, domain_errors.PostNotFoundError{}) {
 		return nil, fuego.NotFoundError{Detail: err.Error()}
+	} else if isGroupAccessError(err) {
+		return nil, fuego.ForbiddenError{Detail: err.Error()}
 	} else if err != nil {
 		return nil, fuego.BadRequestError{Detail: "Failed to create reply: " + err.Error()}
 	}
 
 	return post, nil
 }
+
+// Kept out of a group's threads, either not a member or sanctioned there
+func isGroupAccessError(err error) bool {
+	var membershipErr domain_errors.GroupMembershipRequiredError
+	var bannedErr domain_errors.GroupBannedError
+	var mutedErr domain_errors.GroupMutedError
+	return errors.As(err, &membershipErr) || errors.As(err, &bannedErr) || errors.As(err, &mutedErr)
+}
@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/gorilla/websocket"
+)
+
+const PRESENCE_PING_INTERVAL = 30 * time.Second
+const PRESENCE_HEARTBEAT_TIMEOUT = 75 * time.Second // No heartbeat or pong for this long and the connection is dropped
+const PRESENCE_WRITE_TIMEOUT = 10 * time.Second
+
+// Messages sent by the client
+type PresenceClientMessage struct {
+	Type   string               `json:"type"`             // "heartbeat" or "status"
+	Status value.ActivityStatus `json:"status,omitempty"` // Only for "status"
+}
+
+// Messages sent by the server
+type PresenceServerMessage struct {
+	Type  string                `json:"type"` // "presence" or "error"
+	Data  *domain.PresenceEvent `json:"data,omitempty"`
+	Error string                `json:"error,omitempty"`
+}
+
+type PresenceController struct {
+	tracker           *domain.ActivityTracker
+	events            interfaces.EventBroker
+	friendshipService interfaces.FriendshipService
+	presenceService   interfaces.PresenceService
+	upgrader          websocket.Upgrader
+}
+
+func NewPresenceController(
+	tracker *domain.ActivityTracker,
+	events interfaces.EventBroker,
+	friendshipService interfaces.FriendshipService,
+	presenceService interfaces.PresenceService,
+	frontendURL string,
+) *PresenceController {
+	return &PresenceController{
+		tracker:           tracker,
+		events:            events,
+		friendshipService: friendshipService,
+		presenceService:   presenceService,
+		upgrader: websocket.Upgrader{
+			// The connection is authenticated by cookie, so other sites must not be able to open it
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				return origin == "" || origin == frontendURL || origin == "http://"+r.Host || origin == "https://"+r.Host
+			},
+		},
+	}
+}
+
+// WebSocket presence channel, the client keeps it open while the site is open,
+// sends heartbeats and its status, and receives its friends' presence changes
+func (c *PresenceController) Connect(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middlewares.GetUserIDFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Clients that want to stay hidden can say so before anyone sees them
+	status := value.Online
+	if s := r.URL.Query().Get("status"); s != "" {
+		parsed, err := strconv.Atoi(s)
+		if err != nil || !value.ActivityStatus(parsed).IsSelectable() {
+			http.Error(w, "Invalid status", http.StatusBadRequest)
+			return
+		}
+		status = value.ActivityStatus(parsed)
+	}
+
+	friends, err := fetchFriendIDs(r.Context(), c.friendshipService, userID)
+	if err != nil {
+		http.Error(w, "Failed to fetch friends: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	conn, err := c.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // The upgrader already replied
+	}
+	defer conn.Close()
+
+	c.tracker.Connect(userID, status)
+	defer c.tracker.Disconnect(userID)
+
+	topics := make([]string, 0, len(friends))
+	for _, friendID := range friends {
+		topics = append(topics, domain.PresenceTopic(friendID))
+	}
+	subscription, _ := c.events.Subscribe(topics, "")
+	defer subscription.Close()
+
+	// Only the writer goroutine touches the connection for writing
+	outgoing := make(chan PresenceServerMessage, len(friends)+8)
+	done := make(chan struct{})
+	defer close(done)
+	go c.writeLoop(conn, subscription, outgoing, done)
+
+	// Start off with where every friend is at, as far as they let us know
+	for _, friendID := range friends {
+		presence, err := c.presenceService.GetUserPresence(r.Context(), &userID, friendID)
+		if err != nil {
+			continue
+		}
+		outgoing <- PresenceServerMessage{
+			Type: "presence",
+			Data: &domain.PresenceEvent{UserID: friendID, Status: presence.Status},
+		}
+	}
+
+	conn.SetReadDeadline(time.Now().Add(PRESENCE_HEARTBEAT_TIMEOUT))
+	conn.SetPongHandler(func(string) error {
+		c.tracker.Heartbeat(userID)
+		return conn.SetReadDeadline(time.Now().Add(PRESENCE_HEARTBEAT_TIMEOUT))
+	})
+
+	for {
+		var message PresenceClientMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			return // Closed, timed out or garbage, either way we're done
+		}
+		conn.SetReadDeadline(time.Now().Add(PRESENCE_HEARTBEAT_TIMEOUT))
+
+		switch message.Type {
+		case "heartbeat":
+			c.tracker.Heartbeat(userID)
+		case "status":
+			if !message.Status.IsSelectable() {
+				c.send(outgoing, PresenceServerMessage{Type: "error", Error: "Invalid status"})
+				continue
+			}
+			c.tracker.SetStatus(userID, message.Status)
+		default:
+			c.send(outgoing, PresenceServerMessage{Type: "error", Error: "Unknown message type " + message.Type})
+		}
+	}
+}
+
+// Replies never block the reader, if the writer is that far behind the message is dropped
+func (c *PresenceController) send(outgoing chan PresenceServerMessage, message PresenceServerMessage) {
+	select {
+	case outgoing <- message:
+	default:
+	}
+}
+
+func (c *PresenceController) writeLoop(
+	conn *websocket.Conn,
+	subscription interfaces.EventSubscription,
+	outgoing chan PresenceServerMessage,
+	done chan struct{},
+) {
+	ping := time.NewTicker(PRESENCE_PING_INTERVAL)
+	defer ping.Stop()
+
+	// Any write failure closes the connection, which ends the read loop as well
+	write := func(message any) bool {
+		conn.SetWriteDeadline(time.Now().Add(PRESENCE_WRITE_TIMEOUT))
+		if err := conn.WriteJSON(message); err != nil {
+			conn.Close()
+			return false
+		}
+		return true
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case message := <-outgoing:
+			if !write(message) {
+				return
+			}
+		case event, ok := <-subscription.Events():
+			if !ok {
+				conn.Close()
+				return
+			}
+			presence, ok := event.Data.(domain.PresenceEvent)
+			if !ok {
+				continue
+			}
+			if !write(PresenceServerMessage{Type: "presence", Data: &presence}) {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(PRESENCE_WRITE_TIMEOUT)); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
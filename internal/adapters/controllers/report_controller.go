@@ -1,30 +1,32 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/afuradanime/backend/internal/adapters/middlewares"
 	"github.com/afuradanime/backend/internal/adapters/repositories"
 	"github.com/afuradanime/backend/internal/core/domain"
 	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
 	"github.com/afuradanime/backend/internal/core/interfaces"
 	"github.com/afuradanime/backend/internal/core/utils"
 	"github.com/go-fuego/fuego"
 )
 
-type UserReportController struct {
-	reportService interfaces.UserReportService
+type ReportController struct {
+	reportService interfaces.ReportService
 }
 
-func NewUserReportController(reportService interfaces.UserReportService) *UserReportController {
-	return &UserReportController{reportService: reportService}
+func NewReportController(reportService interfaces.ReportService) *ReportController {
+	return &ReportController{reportService: reportService}
 }
 
 type SubmitReportBody struct {
 	Reason value.ReportReason `json:"Reason"`
 }
 
-func (c *UserReportController) SubmitReport(ctx fuego.ContextWithBody[SubmitReportBody]) (any, error) {
+func (c *ReportController) SubmitReport(ctx fuego.ContextWithBody[SubmitReportBody]) (any, error) {
 	reporterID, ok := middlewares.GetUserIDFromContext(ctx.Context())
 	if !ok {
 		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
@@ -41,25 +43,117 @@ func (c *UserReportController) SubmitReport(ctx fuego.ContextWithBody[SubmitRepo
 	}
 
 	if err := c.reportService.SubmitReport(ctx.Context(), body.Reason, targetID, reporterID); err != nil {
-		return nil, fuego.InternalServerError{Detail: err.Error()}
+		return nil, reportError(err)
 	}
 
 	return nil, nil
 }
 
+func (c *ReportController) SubmitPostReport(ctx fuego.ContextWithBody[SubmitReportBody]) (any, error) {
+	reporterID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	if err := c.reportService.SubmitPostReport(ctx.Context(), body.Reason, ctx.PathParam("id"), reporterID); err != nil {
+		return nil, reportError(err)
+	}
+
+	return nil, nil
+}
+
+func (c *ReportController) SubmitGroupReport(ctx fuego.ContextWithBody[SubmitReportBody]) (any, error) {
+	reporterID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	groupID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid group ID"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	if err := c.reportService.SubmitGroupReport(ctx.Context(), body.Reason, groupID, reporterID); err != nil {
+		return nil, reportError(err)
+	}
+
+	return nil, nil
+}
+
+func (c *ReportController) SubmitTranslationReport(ctx fuego.ContextWithBody[SubmitReportBody]) (any, error) {
+	reporterID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	translationID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid translation ID"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	if err := c.reportService.SubmitTranslationReport(ctx.Context(), body.Reason, translationID, reporterID); err != nil {
+		return nil, reportError(err)
+	}
+
+	return nil, nil
+}
+
+func reportError(err error) error {
+	var (
+		notFoundErr domain_errors.ReportTargetNotFoundError
+		deletedErr  domain_errors.PostDeletedError
+		yourselfErr domain_errors.CannotReportYourselfError
+		alreadyErr  domain_errors.AlreadyReportedError
+	)
+	switch {
+	case errors.As(err, &notFoundErr):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &deletedErr), errors.As(err, &yourselfErr):
+		return fuego.BadRequestError{Detail: err.Error()}
+	case errors.As(err, &alreadyErr):
+		return fuego.ConflictError{Detail: err.Error()}
+	}
+	return fuego.InternalServerError{Detail: err.Error()}
+}
+
 type ReportListResponse struct {
 	Data       []repositories.ReportResult `json:"data"`
 	Pagination utils.Pagination            `json:"pagination"`
 }
 
-func (c *UserReportController) GetReports(ctx fuego.ContextNoBody) (ReportListResponse, error) {
+func (c *ReportController) GetReports(ctx fuego.ContextNoBody) (ReportListResponse, error) {
 	if !middlewares.IsLoggedUserOfRole(ctx.Context(), value.UserRoleModerator) {
 		return ReportListResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
 	}
 
+	var targetType *value.ReportTargetType
+	if typeStr := ctx.QueryParam("targetType"); typeStr != "" {
+		t, err := strconv.ParseUint(typeStr, 10, 8)
+		if err != nil || !value.ReportTargetType(t).IsValid() {
+			return ReportListResponse{}, fuego.BadRequestError{Detail: "Invalid target type"}
+		}
+		t8 := value.ReportTargetType(t)
+		targetType = &t8
+	}
+
 	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
 
-	results, pagination, err := c.reportService.GetReports(ctx.Context(), pageNumber, pageSize)
+	results, pagination, err := c.reportService.GetReports(ctx.Context(), targetType, pageNumber, pageSize)
 	if err != nil {
 		return ReportListResponse{}, fuego.InternalServerError{Detail: err.Error()}
 	}
@@ -71,11 +165,11 @@ func (c *UserReportController) GetReports(ctx fuego.ContextNoBody) (ReportListRe
 }
 
 type ReportByTargetListResponse struct {
-	Data       []domain.UserReport `json:"data"`
-	Pagination utils.Pagination    `json:"pagination"`
+	Data       []domain.Report  `json:"data"`
+	Pagination utils.Pagination `json:"pagination"`
 }
 
-func (c *UserReportController) GetReportsByTarget(ctx fuego.ContextNoBody) (ReportByTargetListResponse, error) {
+func (c *ReportController) GetReportsByTarget(ctx fuego.ContextNoBody) (ReportByTargetListResponse, error) {
 	if !middlewares.IsLoggedUserOfRole(ctx.Context(), value.UserRoleModerator) {
 		return ReportByTargetListResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
 	}
@@ -98,7 +192,7 @@ func (c *UserReportController) GetReportsByTarget(ctx fuego.ContextNoBody) (Repo
 	}, nil
 }
 
-func (c *UserReportController) DeleteReport(ctx fuego.ContextNoBody) (any, error) {
+func (c *ReportController) DeleteReport(ctx fuego.ContextNoBody) (any, error) {
 	if !middlewares.IsLoggedUserOfRole(ctx.Context(), value.UserRoleModerator) {
 		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
 	}
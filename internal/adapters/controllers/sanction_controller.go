@@ -0,0 +1,203 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"github.com/go-fuego/fuego"
+)
+
+type SanctionController struct {
+	sanctionService interfaces.SanctionService
+}
+
+func NewSanctionController(sanctionService interfaces.SanctionService) *SanctionController {
+	return &SanctionController{sanctionService: sanctionService}
+}
+
+type SanctionListResponse struct {
+	Data       []*domain.Sanction `json:"data"`
+	Pagination utils.Pagination   `json:"pagination"`
+}
+
+type IssueSanctionBody struct {
+	UserID        int                `json:"UserID"`
+	Type          value.SanctionType `json:"Type"`
+	Reason        string             `json:"Reason"`
+	DurationHours int                `json:"DurationHours"` // 0 for a permanent sanction
+}
+
+func (c *SanctionController) IssueSanction(ctx fuego.ContextWithBody[IssueSanctionBody]) (*domain.Sanction, error) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	duration := time.Duration(body.DurationHours) * time.Hour
+	sanction, err := c.sanctionService.IssueSanction(ctx.Context(), body.UserID, body.Type, body.Reason, duration, modID)
+	if err != nil {
+		return nil, sanctionError(err)
+	}
+
+	return sanction, nil
+}
+
+func (c *SanctionController) LiftSanction(ctx fuego.ContextNoBody) (any, error) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid sanction ID"}
+	}
+
+	if err := c.sanctionService.LiftSanction(ctx.Context(), id, modID); err != nil {
+		return nil, sanctionError(err)
+	}
+
+	return nil, nil
+}
+
+// A user's whole sanction history, for moderators
+func (c *SanctionController) GetUserSanctions(ctx fuego.ContextNoBody) (SanctionListResponse, error) {
+	userID, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return SanctionListResponse{}, fuego.BadRequestError{Detail: "Invalid user ID"}
+	}
+
+	return c.listSanctions(ctx, userID)
+}
+
+// Lets users see why they can't post or translate anymore, and for how long
+func (c *SanctionController) GetMySanctions(ctx fuego.ContextNoBody) (SanctionListResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return SanctionListResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	return c.listSanctions(ctx, userID)
+}
+
+func (c *SanctionController) listSanctions(ctx fuego.ContextNoBody, userID int) (SanctionListResponse, error) {
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+
+	sanctions, pagination, err := c.sanctionService.GetUserSanctions(ctx.Context(), userID, pageNumber, pageSize)
+	if err != nil {
+		return SanctionListResponse{}, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return SanctionListResponse{
+		Data:       sanctions,
+		Pagination: pagination,
+	}, nil
+}
+
+type AppealSanctionBody struct {
+	Text string `json:"Text"`
+}
+
+func (c *SanctionController) AppealSanction(ctx fuego.ContextWithBody[AppealSanctionBody]) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid sanction ID"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	if err := c.sanctionService.AppealSanction(ctx.Context(), id, body.Text, userID); err != nil {
+		return nil, sanctionError(err)
+	}
+
+	return nil, nil
+}
+
+func (c *SanctionController) GetPendingAppeals(ctx fuego.ContextNoBody) (SanctionListResponse, error) {
+	pageNumber, pageSize := utils.GetPaginationParams(ctx, 20)
+
+	sanctions, pagination, err := c.sanctionService.GetPendingAppeals(ctx.Context(), pageNumber, pageSize)
+	if err != nil {
+		return SanctionListResponse{}, fuego.InternalServerError{Detail: err.Error()}
+	}
+
+	return SanctionListResponse{
+		Data:       sanctions,
+		Pagination: pagination,
+	}, nil
+}
+
+type ReviewAppealBody struct {
+	Response string `json:"Response"` // Optional, shown to the user
+}
+
+func (c *SanctionController) AcceptAppeal(ctx fuego.ContextWithBody[ReviewAppealBody]) (any, error) {
+	return c.reviewAppeal(ctx, true)
+}
+
+func (c *SanctionController) RejectAppeal(ctx fuego.ContextWithBody[ReviewAppealBody]) (any, error) {
+	return c.reviewAppeal(ctx, false)
+}
+
+func (c *SanctionController) reviewAppeal(ctx fuego.ContextWithBody[ReviewAppealBody], accepted bool) (any, error) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	id, err := strconv.Atoi(ctx.PathParam("id"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid sanction ID"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	if err := c.sanctionService.ReviewAppeal(ctx.Context(), id, accepted, body.Response, modID); err != nil {
+		return nil, sanctionError(err)
+	}
+
+	return nil, nil
+}
+
+func sanctionError(err error) error {
+	var (
+		notFoundErr     domain_errors.SanctionNotFoundError
+		userNotFoundErr domain_errors.UserNotFoundError
+		adminErr        domain_errors.CantRestrictAnAdmin
+		notActiveErr    domain_errors.SanctionNotActiveError
+		appealedErr     domain_errors.AlreadyAppealedError
+		notPendingErr   domain_errors.AppealNotPendingError
+	)
+	switch {
+	case errors.As(err, &notFoundErr), errors.As(err, &userNotFoundErr):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &adminErr):
+		return fuego.ForbiddenError{Detail: err.Error()}
+	case errors.As(err, &notActiveErr), errors.As(err, &appealedErr), errors.As(err, &notPendingErr):
+		return fuego.ConflictError{Detail: err.Error()}
+	}
+	return fuego.BadRequestError{Detail: err.Error()}
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/services"
+	"github.com/go-fuego/fuego"
+)
+
+// The refresh cookie only ever needs to reach the auth endpoints
+const REFRESH_COOKIE = "refresh_token"
+const REFRESH_COOKIE_PATH = "/auth"
+
+type SessionController struct {
+	jwtService     *services.JWTService
+	sessionService interfaces.SessionService
+}
+
+func NewSessionController(jwtService *services.JWTService, sessionService interfaces.SessionService) *SessionController {
+	return &SessionController{
+		jwtService:     jwtService,
+		sessionService: sessionService,
+	}
+}
+
+type SessionResponse struct {
+	*domain.Session
+	Current bool `json:"Current"` // The session making the request
+}
+
+type RevokedSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// Swaps the refresh cookie for a new access token and a new refresh cookie
+func (c *SessionController) Refresh(ctx fuego.ContextNoBody) (any, error) {
+	cookie, err := ctx.Cookie(REFRESH_COOKIE)
+	if err != nil || cookie.Value == "" {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized, no refresh token provided"}
+	}
+
+	accessToken, refreshToken, err := c.sessionService.Refresh(ctx.Context(), cookie.Value)
+	if err != nil {
+		clearSessionCookies(ctx.Response())
+		return nil, sessionError(err)
+	}
+
+	setSessionCookies(ctx.Response(), c.jwtService, accessToken, refreshToken)
+	return nil, nil
+}
+
+func (c *SessionController) GetSessions(ctx fuego.ContextNoBody) ([]SessionResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+	currentID, _ := middlewares.GetSessionIDFromContext(ctx.Context())
+
+	sessions, err := c.sessionService.GetSessions(ctx.Context(), userID)
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to fetch sessions: " + err.Error()}
+	}
+
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, SessionResponse{Session: session, Current: session.ID == currentID})
+	}
+	return response, nil
+}
+
+func (c *SessionController) RevokeSession(ctx fuego.ContextNoBody) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	sessionID := ctx.PathParam("id")
+	if err := c.sessionService.RevokeSession(ctx.Context(), sessionID, userID); err != nil {
+		return nil, sessionError(err)
+	}
+
+	if currentID, _ := middlewares.GetSessionIDFromContext(ctx.Context()); currentID == sessionID {
+		clearSessionCookies(ctx.Response())
+	}
+	return nil, nil
+}
+
+// Log out everywhere, this device included
+func (c *SessionController) RevokeAllSessions(ctx fuego.ContextNoBody) (RevokedSessionsResponse, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return RevokedSessionsResponse{}, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	count, err := c.sessionService.RevokeAllSessions(ctx.Context(), userID)
+	if err != nil {
+		return RevokedSessionsResponse{}, fuego.InternalServerError{Detail: "Failed to revoke sessions: " + err.Error()}
+	}
+
+	clearSessionCookies(ctx.Response())
+	return RevokedSessionsResponse{Revoked: count}, nil
+}
+
+func setSessionCookies(w http.ResponseWriter, jwtService *services.JWTService, accessToken, refreshToken string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "jwt",
+		Value:    accessToken,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   false, // https
+		MaxAge:   int(jwtService.AccessTokenLifetime().Seconds()),
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     REFRESH_COOKIE,
+		Value:    refreshToken,
+		Path:     REFRESH_COOKIE_PATH,
+		HttpOnly: true,
+		Secure:   false, // https
+		MaxAge:   int(jwtService.SessionLifetime().Seconds()),
+	})
+}
+
+func clearSessionCookies(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "jwt",
+		Value:    "",
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   false, // https
+		MaxAge:   -1,    // delete the cookie immediately
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     REFRESH_COOKIE,
+		Value:    "",
+		Path:     REFRESH_COOKIE_PATH,
+		HttpOnly: true,
+		Secure:   false, // https
+		MaxAge:   -1,
+	})
+}
+
+func sessionError(err error) error {
+	var notFound domain_errors.SessionNotFoundError
+	var invalidRefresh domain_errors.InvalidRefreshTokenError
+
+	switch {
+	case errors.As(err, &notFound):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &invalidRefresh):
+		return fuego.UnauthorizedError{Detail: err.Error()}
+	default:
+		return fuego.InternalServerError{Detail: err.Error()}
+	}
+}
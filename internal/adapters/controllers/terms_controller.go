@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/go-fuego/fuego"
+)
+
+type TermsController struct {
+	termsService interfaces.TermsService
+}
+
+func NewTermsController(termsService interfaces.TermsService) *TermsController {
+	return &TermsController{
+		termsService: termsService,
+	}
+}
+
+type PublishTermsBody struct {
+	Content string `json:"Content"`
+	Summary string `json:"Summary"` // What changed, shown when asking users to accept again
+}
+
+type AcceptTermsBody struct {
+	Version int `json:"Version"`
+}
+
+func (c *TermsController) GetCurrentTerms(ctx fuego.ContextNoBody) (*domain.TermsOfService, error) {
+	terms, err := c.termsService.GetCurrentTerms(ctx.Context())
+	if err != nil {
+		return nil, termsError(err)
+	}
+	return terms, nil
+}
+
+func (c *TermsController) GetTermsHistory(ctx fuego.ContextNoBody) ([]*domain.TermsOfService, error) {
+	history, err := c.termsService.GetTermsHistory(ctx.Context())
+	if err != nil {
+		return nil, fuego.InternalServerError{Detail: "Failed to fetch terms of service: " + err.Error()}
+	}
+	return history, nil
+}
+
+func (c *TermsController) GetTerms(ctx fuego.ContextNoBody) (*domain.TermsOfService, error) {
+	version, err := strconv.Atoi(ctx.PathParam("version"))
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid version"}
+	}
+
+	terms, err := c.termsService.GetTerms(ctx.Context(), version)
+	if err != nil {
+		return nil, termsError(err)
+	}
+	return terms, nil
+}
+
+func (c *TermsController) PublishTerms(ctx fuego.ContextWithBody[PublishTermsBody]) (*domain.TermsOfService, error) {
+	adminID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	terms, err := c.termsService.PublishTerms(ctx.Context(), body.Content, body.Summary, adminID)
+	if err != nil {
+		return nil, termsError(err)
+	}
+	return terms, nil
+}
+
+func (c *TermsController) AcceptTerms(ctx fuego.ContextWithBody[AcceptTermsBody]) (any, error) {
+	userID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	body, err := ctx.Body()
+	if err != nil {
+		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
+	}
+
+	if err := c.termsService.AcceptTerms(ctx.Context(), userID, body.Version); err != nil {
+		return nil, termsError(err)
+	}
+	return nil, nil
+}
+
+func termsError(err error) error {
+	var notFound domain_errors.TermsNotFoundError
+	var nonePublished domain_errors.NoTermsPublishedError
+	var userNotFound domain_errors.UserNotFoundError
+	var invalidContent domain_errors.InvalidTermsContentError
+	var summaryTooLong domain_errors.TermsSummaryTooLongError
+	var conflict domain_errors.TermsVersionConflictError
+	var outdated domain_errors.OutdatedTermsError
+
+	switch {
+	case errors.As(err, &notFound), errors.As(err, &nonePublished), errors.As(err, &userNotFound):
+		return fuego.NotFoundError{Detail: err.Error()}
+	case errors.As(err, &invalidContent), errors.As(err, &summaryTooLong):
+		return fuego.BadRequestError{Detail: err.Error()}
+	case errors.As(err, &conflict), errors.As(err, &outdated):
+		return fuego.ConflictError{Detail: err.Error()}
+	default:
+		return fuego.InternalServerError{Detail: err.Error()}
+	}
+}
@@ -1,12 +1,14 @@
 package controllers
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
 	"github.com/afuradanime/backend/internal/adapters/middlewares"
 	"github.com/afuradanime/backend/internal/core/domain"
 	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
 	"github.com/afuradanime/backend/internal/core/interfaces"
 	"github.com/afuradanime/backend/internal/core/utils"
 	"github.com/go-fuego/fuego"
@@ -72,6 +74,21 @@ func (uc *UserController) GetUserByID(ctx fuego.ContextNoBody) (*domain.User, er
 	return user, nil
 }
 
+// The logged user's own profile, mostly here so tokens with the profile scope have something to read
+func (uc *UserController) GetMe(ctx fuego.ContextNoBody) (*domain.User, error) {
+	id, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
+		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
+	}
+
+	user, err := uc.userService.GetUserByID(ctx.Context(), id)
+	if err != nil {
+		return nil, fuego.NotFoundError{Detail: err.Error()}
+	}
+
+	return user, nil
+}
+
 type UpdateUserInfoBody struct {
 	Email                 	*string   `json:"Email"`
 	Username              	*string   `json:"Username"`
@@ -83,7 +100,7 @@ type UpdateUserInfoBody struct {
 	AllowsRecommendations 	*bool     `json:"AllowsRecommendations"`
 	ListPrivate			 	*bool     `json:"ListPrivate"`
 	AvatarURL 			  	*string	  `json:"AvatarURL"`
-	AcceptedTermsOfService  *bool	  `json:"AcceptedTermsOfService"`
+	PresenceVisibility      *value.PresenceVisibility `json:"PresenceVisibility"`
 }
 
 func (uc *UserController) UpdateUserInfo(ctx fuego.ContextWithBody[UpdateUserInfoBody]) (any, error) {
@@ -118,8 +135,11 @@ func (uc *UserController) UpdateUserInfo(ctx fuego.ContextWithBody[UpdateUserInf
 		updateData.AllowsRecommendations,
 		updateData.ListPrivate,
 		updateData.AvatarURL,
-		updateData.AcceptedTermsOfService,
+		updateData.PresenceVisibility,
 	)
+	if errors.As(err, &domain_errors.UsernameTakenError{}) {
+		return nil, fuego.ConflictError{Detail: err.Error()}
+	}
 	if err != nil {
 		return nil, fuego.InternalServerError{Detail: err.Error()}
 	}
@@ -132,8 +152,10 @@ type RestrictAccountBody struct {
 	CanTranslate bool `json:"CanTranslate"`
 }
 
+// Same as issuing or lifting sanctions by hand, see UserService.RestrictAccount
 func (uc *UserController) RestrictAccount(ctx fuego.ContextWithBody[RestrictAccountBody]) (any, error) {
-	if !middlewares.IsLoggedUserOfRole(ctx.Context(), value.UserRoleModerator) {
+	modID, ok := middlewares.GetUserIDFromContext(ctx.Context())
+	if !ok {
 		return nil, fuego.UnauthorizedError{Detail: "Unauthorized"}
 	}
 
@@ -147,8 +169,8 @@ func (uc *UserController) RestrictAccount(ctx fuego.ContextWithBody[RestrictAcco
 		return nil, fuego.BadRequestError{Detail: "Invalid request body"}
 	}
 
-	if err := uc.userService.RestrictAccount(ctx.Context(), targetID, body.CanPost, body.CanTranslate); err != nil {
-		return nil, fuego.InternalServerError{Detail: err.Error()}
+	if err := uc.userService.RestrictAccount(ctx.Context(), targetID, body.CanPost, body.CanTranslate, modID); err != nil {
+		return nil, sanctionError(err)
 	}
 
 	return nil, nil
@@ -0,0 +1,132 @@
+package events
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+const HISTORY_SIZE = 1024    // How many events are kept around for Last-Event-ID replay
+const SUBSCRIBER_BUFFER = 64 // How many undelivered events a subscriber may have before being dropped
+
+// In-process pub/sub hub, good enough while we run a single instance
+// Event ids are "<epoch>-<sequence>", the epoch changes on every restart so
+// ids handed out by a previous process are never mistaken for current ones
+type Hub struct {
+	mu          sync.Mutex
+	epoch       string
+	sequence    uint64
+	subscribers map[string]map[*subscription]struct{}
+	history     *utils.RingBuffer[*domain.Event]
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		subscribers: make(map[string]map[*subscription]struct{}),
+		history:     utils.NewRingBuffer[*domain.Event](HISTORY_SIZE),
+	}
+}
+
+func (h *Hub) Publish(topic string, eventType value.EventType, data any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.sequence++
+	event := &domain.Event{
+		ID:        h.epoch + "-" + strconv.FormatUint(h.sequence, 10),
+		Topic:     topic,
+		Type:      eventType,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}
+	h.history.Add(event)
+
+	for sub := range h.subscribers[topic] {
+		select {
+		case sub.events <- event:
+		default:
+			// Subscriber isn't keeping up, drop it, it can reconnect and replay what it missed
+			h.remove(sub)
+		}
+	}
+}
+
+func (h *Hub) Subscribe(topics []string, lastEventID string) (interfaces.EventSubscription, []*domain.Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &subscription{
+		hub:    h,
+		topics: topics,
+		events: make(chan *domain.Event, SUBSCRIBER_BUFFER),
+	}
+
+	for _, topic := range topics {
+		if h.subscribers[topic] == nil {
+			h.subscribers[topic] = make(map[*subscription]struct{})
+		}
+		h.subscribers[topic][sub] = struct{}{}
+	}
+
+	// Registering and replaying under the same lock means nothing can slip in between
+	return sub, h.replay(topics, lastEventID)
+}
+
+func (h *Hub) replay(topics []string, lastEventID string) []*domain.Event {
+	epoch, seq, ok := strings.Cut(lastEventID, "-")
+	if !ok || epoch != h.epoch {
+		return nil
+	}
+
+	last, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	missed := []*domain.Event{}
+	for _, event := range h.history.Get() {
+		_, eventSeq, _ := strings.Cut(event.ID, "-")
+		n, _ := strconv.ParseUint(eventSeq, 10, 64)
+		if n > last && slices.Contains(topics, event.Topic) {
+			missed = append(missed, event)
+		}
+	}
+
+	return missed
+}
+
+// Must be called with the lock held
+func (h *Hub) remove(sub *subscription) {
+	for _, topic := range sub.topics {
+		delete(h.subscribers[topic], sub)
+		if len(h.subscribers[topic]) == 0 {
+			delete(h.subscribers, topic)
+		}
+	}
+	sub.once.Do(func() { close(sub.events) })
+}
+
+type subscription struct {
+	hub    *Hub
+	topics []string
+	events chan *domain.Event
+	once   sync.Once
+}
+
+func (s *subscription) Events() <-chan *domain.Event {
+	return s.events
+}
+
+func (s *subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
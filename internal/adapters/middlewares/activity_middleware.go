@@ -2,20 +2,26 @@ package middlewares
 
 import (
 	"net/http"
+	"strings"
 
-	"github.com/afuradanime/backend/config"
 	"github.com/afuradanime/backend/internal/core/domain"
 	"github.com/afuradanime/backend/internal/core/domain/value"
 	"github.com/afuradanime/backend/internal/core/utils"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func ActivityMiddleware(cfg *config.JWTConfig, tracker *domain.ActivityTracker) func(http.Handler) http.Handler {
+// The presence socket sets the status itself, recording it as online here would show
+// users who connect as invisible to their friends for a moment
+func isPresenceConnect(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+func ActivityMiddleware(keys *domain.KeyRing, tracker *domain.ActivityTracker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			cookie, err := r.Cookie("jwt")
-			if err == nil && cookie.Value != "" {
-				parsed, err := utils.GetParsedJWTClaims(cookie.Value, cfg.Secret)
+			if err == nil && cookie.Value != "" && !isPresenceConnect(r) {
+				parsed, err := utils.GetParsedJWTClaims(cookie.Value, keys.Keyfunc)
 				if err == nil && parsed.Valid {
 					if claims, ok := parsed.Claims.(jwt.MapClaims); ok {
 						if idValue, ok := claims["id"]; ok {
@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+const ClientIPKey contextKey = "clientIP"
+
+// Works out who's really on the other end. Only proxies we run get to say who they forwarded for,
+// anyone else could put whatever they want in X-Forwarded-For
+type ClientIPResolver struct {
+	trusted []netip.Prefix
+}
+
+// Takes addresses and CIDR ranges, an empty list trusts nobody and X-Forwarded-For is ignored
+func NewClientIPResolver(trustedProxies []string) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{}
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, err
+			}
+			addr = addr.Unmap()
+			resolver.trusted = append(resolver.trusted, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, err
+		}
+		resolver.trusted = append(resolver.trusted, prefix.Masked())
+	}
+	return resolver, nil
+}
+
+func (c *ClientIPResolver) isTrusted(addr netip.Addr) bool {
+	for _, prefix := range c.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Walks X-Forwarded-For from the right, every proxy appends who it got the request from,
+// so the first address that isn't one of ours is the client. The left end is whatever the
+// client felt like sending, it's only reached when every hop before it is trusted
+func (c *ClientIPResolver) ClientIP(r *http.Request) string {
+	remote, ok := remoteAddr(r)
+	if !ok {
+		return r.RemoteAddr
+	}
+	if !c.isTrusted(remote) {
+		return remote.String()
+	}
+
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			// Garbage in the chain, don't trust anything past it
+			break
+		}
+
+		client = addr.Unmap()
+		if !c.isTrusted(client) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// Just the address, the port changes on every connection
+func remoteAddr(r *http.Request) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func ClientIPMiddleware(resolver *ClientIPResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), ClientIPKey, resolver.ClientIP(r))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Where the request came from, straight from the connection if ClientIPMiddleware didn't run
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(ClientIPKey).(string); ok {
+		return ip
+	}
+	if addr, ok := remoteAddr(r); ok {
+		return addr.String()
+	}
+	return r.RemoteAddr
+}
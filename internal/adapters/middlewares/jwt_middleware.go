@@ -2,11 +2,9 @@ package middlewares
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"slices"
 
-	"github.com/afuradanime/backend/config"
 	"github.com/afuradanime/backend/internal/core/domain"
 	"github.com/afuradanime/backend/internal/core/domain/value"
 	"github.com/afuradanime/backend/internal/core/utils"
@@ -18,9 +16,26 @@ type contextKey string
 const (
 	UserIDKey    			contextKey = "userID"
 	UserRolesKey 			contextKey = "userRoles"
-	UserAcceptedTermsKey 	contextKey = "userAcceptedTerms"
+	UserAcceptedTermsKey 	contextKey = "userAcceptedTerms" // The terms of service version, not a yes or no
+	SessionIDKey 			contextKey = "sessionID"
 )
 
+// Tells whether the session behind a token was revoked, middlewares don't get to know about services
+type SessionValidator interface {
+	IsSessionActive(ctx context.Context, sessionID string) bool
+}
+
+// Knows which terms of service version is current and who agreed to it
+type TermsChecker interface {
+	CurrentTermsVersion(ctx context.Context) int
+	HasAcceptedTerms(ctx context.Context, userID int) bool
+}
+
+func GetSessionIDFromContext(ctx context.Context) (string, bool) {
+	sessionID, ok := ctx.Value(SessionIDKey).(string)
+	return sessionID, ok
+}
+
 func GetUserIDFromContext(ctx context.Context) (int, bool) {
 	userID, ok := ctx.Value(UserIDKey).(int)
 	return userID, ok
@@ -35,10 +50,27 @@ func GetUserRolesFromContext(ctx context.Context) ([]value.UserRole, bool) {
 	return utils.DecodeRoleList(roles), ok
 }
 
-func GetAcceptedTermsFromContext(ctx context.Context) bool {
-    accepted, ok := ctx.Value(UserAcceptedTermsKey).(bool)
-	log.Print("Accepted: %d", accepted)
-    return ok && accepted
+func GetAcceptedTermsFromContext(ctx context.Context) (int, bool) {
+	version, ok := ctx.Value(UserAcceptedTermsKey).(int)
+	return version, ok
+}
+
+// Reading never needs the terms accepted, only changing things does
+func isWriteRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// The token knows what was accepted when it was issued, the database is only asked when
+// that's behind, someone who just accepted shouldn't have to refresh first
+func hasAcceptedTerms(ctx context.Context, terms TermsChecker, userID int) bool {
+	if version, ok := GetAcceptedTermsFromContext(ctx); ok && version >= terms.CurrentTermsVersion(ctx) {
+		return true
+	}
+	return terms.HasAcceptedTerms(ctx, userID)
 }
 
 func IsLoggedUserOfRole(ctx context.Context, role value.UserRole) bool {
@@ -51,9 +83,15 @@ func IsLoggedUserOfRole(ctx context.Context, role value.UserRole) bool {
 	return slices.Contains(roles, role)
 }
 
-func OptionalJWTMiddleware(config *config.JWTConfig) func(http.Handler) http.Handler {
+func OptionalJWTMiddleware(keys *domain.KeyRing, sessions SessionValidator) func(http.Handler) http.Handler {
     return func(next http.Handler) http.Handler {
         return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            // Already authenticated by a bearer token
+            if _, ok := GetTokenScopesFromContext(r.Context()); ok {
+                next.ServeHTTP(w, r)
+                return
+            }
+
             cookie, err := r.Cookie("jwt")
             if err != nil || cookie.Value == "" {
                 // No cookie, continue without user in context
@@ -61,7 +99,7 @@ func OptionalJWTMiddleware(config *config.JWTConfig) func(http.Handler) http.Han
                 return
             }
 
-            parsed, err := utils.GetParsedJWTClaims(cookie.Value, config.Secret)
+            parsed, err := utils.GetParsedJWTClaims(cookie.Value, keys.Keyfunc)
             if err != nil || !parsed.Valid {
                 // Invalid token, still continue without blocking
                 next.ServeHTTP(w, r)
@@ -74,7 +112,14 @@ func OptionalJWTMiddleware(config *config.JWTConfig) func(http.Handler) http.Han
                 return
             }
 
-            ctx := r.Context()
+            // Revoked sessions are treated like being logged out
+            sessionID, _ := claims["sid"].(string)
+            if !sessions.IsSessionActive(r.Context(), sessionID) {
+                next.ServeHTTP(w, r)
+                return
+            }
+
+            ctx := context.WithValue(r.Context(), SessionIDKey, sessionID)
 
             if idValue, ok := claims["id"]; ok {
                 if userID, ok := idValue.(float64); ok {
@@ -84,8 +129,8 @@ func OptionalJWTMiddleware(config *config.JWTConfig) func(http.Handler) http.Han
             if roles, ok := claims["role"]; ok {
                 ctx = context.WithValue(ctx, UserRolesKey, roles)
             }
-            if acceptedTerms, ok := claims["acceptedTermsOfService"]; ok {
-                ctx = context.WithValue(ctx, UserAcceptedTermsKey, acceptedTerms.(bool))
+            if version, ok := claims["tos"].(float64); ok {
+                ctx = context.WithValue(ctx, UserAcceptedTermsKey, int(version))
             }
 
             next.ServeHTTP(w, r.WithContext(ctx))
@@ -93,7 +138,9 @@ func OptionalJWTMiddleware(config *config.JWTConfig) func(http.Handler) http.Han
     }
 }
 
-func JWTMiddleware(cfg *config.JWTConfig, tracker *domain.ActivityTracker) func(http.Handler) http.Handler {
+// Writes are refused until the current terms of service are accepted. Routes that have to stay
+// reachable regardless, like accepting them or leaving the platform, pass nil terms
+func JWTMiddleware(keys *domain.KeyRing, tracker *domain.ActivityTracker, sessions SessionValidator, terms TermsChecker) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -102,6 +149,17 @@ func JWTMiddleware(cfg *config.JWTConfig, tracker *domain.ActivityTracker) func(
 				return
 			}
 
+			// Already authenticated by a bearer token, scripts don't count as being online
+			if _, ok := GetTokenScopesFromContext(r.Context()); ok {
+				userID, _ := GetUserIDFromContext(r.Context())
+				if terms != nil && isWriteRequest(r) && !terms.HasAcceptedTerms(r.Context(), userID) {
+					http.Error(w, "Terms of service not accepted", http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			cookie, err := r.Cookie("jwt")
 			if err != nil {
 				http.Error(w, "Unauthorized, no token provided", http.StatusUnauthorized)
@@ -114,7 +172,7 @@ func JWTMiddleware(cfg *config.JWTConfig, tracker *domain.ActivityTracker) func(
 				return
 			}
 
-			parsed, err := utils.GetParsedJWTClaims(tokenString, cfg.Secret)
+			parsed, err := utils.GetParsedJWTClaims(tokenString, keys.Keyfunc)
 			if err != nil || !parsed.Valid {
 				http.Error(w, "Unauthorized, invalid token", http.StatusUnauthorized)
 				return
@@ -138,23 +196,32 @@ func JWTMiddleware(cfg *config.JWTConfig, tracker *domain.ActivityTracker) func(
 				return
 			}
 
+			// Logged out, logged out everywhere or caught reusing a refresh token
+			sessionID, _ := claims["sid"].(string)
+			if !sessions.IsSessionActive(r.Context(), sessionID) {
+				http.Error(w, "Unauthorized, session revoked", http.StatusUnauthorized)
+				return
+			}
+
 			ctx := context.WithValue(r.Context(), UserIDKey, int(userID))
+			ctx = context.WithValue(ctx, SessionIDKey, sessionID)
 			roles, ok := claims["role"]
 			if ok {
 				ctx = context.WithValue(ctx, UserRolesKey, roles)
 			}
 
-			acceptedTerms, ok := claims["acceptedTermsOfService"]
-			if ok {
-				ctx = context.WithValue(ctx, UserAcceptedTermsKey, acceptedTerms.(bool))
+			if version, ok := claims["tos"].(float64); ok {
+				ctx = context.WithValue(ctx, UserAcceptedTermsKey, int(version))
 			}
-			
-			// if !GetAcceptedTermsFromContext(r.Context()) {
-			// 	http.Error(w, "Terms of service not accepted", http.StatusForbidden)
-			// 	return
-			// }
 
-			tracker.RecordActivity(int(userID), value.Online)
+			if terms != nil && isWriteRequest(r) && !hasAcceptedTerms(ctx, terms, int(userID)) {
+				http.Error(w, "Terms of service not accepted", http.StatusForbidden)
+				return
+			}
+
+			if !isPresenceConnect(r) {
+				tracker.RecordActivity(int(userID), value.Online)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -11,10 +11,42 @@ import (
 )
 
 var (
-	userMentionRegex  = regexp.MustCompile(`@(\d+)`)     // Matches @ followed by digits
-	animeMentionRegex = regexp.MustCompile(`\[#(\d+)\]`) // Matches [# followed by digits and ]
+	userMentionRegex = regexp.MustCompile(`@(\d+)\b`) // Matches @ followed by digits
+	// Matches @ followed by a non numeric username, or by any username within [] for those with spaces
+	usernameMentionRegex = regexp.MustCompile(`(^|[^\w@])@(?:\[([\p{L}0-9 _\-]+)\]|([\p{L}0-9_\-]*[\p{L}_\-][\p{L}0-9_\-]*))`)
+	animeMentionRegex    = regexp.MustCompile(`\[#(\d+)\]`) // Matches [# followed by digits and ]
 )
 
+// Rewrites @username and @[user name] mentions into their @id form, so the stored post
+// keeps pointing at the same user even if they change their username later on
+// Mentions of unknown usernames are left untouched
+func ResolveUsernameMentions(ctx context.Context, text string, userRepo interfaces.UserRepository) string {
+	resolved := map[string]string{}
+
+	return usernameMentionRegex.ReplaceAllStringFunc(text, func(match string) string {
+		groups := usernameMentionRegex.FindStringSubmatch(match)
+		prefix, username := groups[1], groups[2]+groups[3]
+		username = strings.TrimSpace(username)
+
+		key := strings.ToLower(username)
+		if id, ok := resolved[key]; ok {
+			if id == "" {
+				return match
+			}
+			return prefix + "@" + id
+		}
+
+		user, err := userRepo.GetUserByUsername(ctx, username)
+		if err != nil || user == nil {
+			resolved[key] = ""
+			return match // skip this mention
+		}
+
+		resolved[key] = strconv.Itoa(user.ID)
+		return prefix + "@" + resolved[key]
+	})
+}
+
 // Replace custom markup with real data
 func ParsePost(
 	post *domain.Post,
@@ -27,17 +59,26 @@ func ParsePost(
 	}
 
 	userIds, animeIds := ExtractMentions(*post.Text)
+	usernames := map[string]string{}
 	for _, u := range userIds {
 		user, err := userRepo.GetUserById(ctx, u)
 		if err != nil || user == nil {
 			continue // skip this mention
 		}
-		replaced := strings.ReplaceAll(
-			*post.Text, "@"+strconv.Itoa(u),
-			"<a href=\"/profile/"+strconv.Itoa(u)+"\">@"+string(user.Username)+"</a>",
-		)
-		post.Text = &replaced
+		usernames[strconv.Itoa(u)] = string(user.Username)
 	}
+
+	// Replace by match instead of ReplaceAll so @1 doesn't eat into @12
+	replaced := userMentionRegex.ReplaceAllStringFunc(*post.Text, func(match string) string {
+		id := match[1:]
+		username, ok := usernames[id]
+		if !ok {
+			return match
+		}
+		return "<a href=\"/profile/" + id + "\">@" + username + "</a>"
+	})
+	post.Text = &replaced
+
 	for _, a := range animeIds {
 		anime, err := animeRepo.FetchAnimeByID(uint32(a))
 		if err != nil || anime == nil {
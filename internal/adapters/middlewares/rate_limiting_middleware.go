@@ -1,29 +1,112 @@
 package middlewares
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/time/rate"
 )
 
-// Rate limiting by IP
-type IPRateLimiter struct {
-	limiters sync.Map // concurrent safe map
-	Rps      float64  // How many token refreshes an ip gets per second (e.g: Burst = 10 means new token every 100ms)
-	Burst    int      // How many requests can be made "instantly"
+// Limiters nobody used for this long are dropped, unless their bucket takes longer than that to fill back up
+const RATE_LIMIT_IDLE_TIMEOUT = 10 * time.Minute
+const RATE_LIMIT_SWEEP_INTERVAL = time.Minute
+
+// How fast a single client can go, rate.Limiter uses https://en.wikipedia.org/wiki/Token_bucket
+type RateLimitPolicy struct {
+	Rps   float64 // How many token refreshes a client gets per second (e.g: Rps = 10 means new token every 100ms)
+	Burst int     // How many requests can be made "instantly"
+}
+
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen atomic.Int64 // Unix nanoseconds
 }
 
-// rate.Limiter uses https://en.wikipedia.org/wiki/Token_bucket
-func (l *IPRateLimiter) get(ip string) *rate.Limiter {
-	v, _ := l.limiters.LoadOrStore(ip, rate.NewLimiter(rate.Limit(l.Rps), l.Burst))
-	return v.(*rate.Limiter)
+// Rate limiting by user when logged in and by IP otherwise, each limiter has its own buckets
+// so a route with a stricter policy doesn't eat into the global one
+type RateLimiter struct {
+	policy      RateLimitPolicy
+	idleTimeout time.Duration
+	limiters    sync.Map // concurrent safe map
 }
 
-func (l *IPRateLimiter) Middleware(next http.Handler) http.Handler {
+func NewRateLimiter(policy RateLimitPolicy) *RateLimiter {
+	refill := time.Duration(float64(policy.Burst) / policy.Rps * float64(time.Second))
+
+	l := &RateLimiter{
+		policy:      policy,
+		idleTimeout: max(RATE_LIMIT_IDLE_TIMEOUT, refill),
+	}
+	go l.sweep()
+	return l
+}
+
+// Goes by user when placed behind the JWT middleware, anywhere else there's nobody
+// in the context yet and it falls back to the IP
+func rateLimitKey(r *http.Request) string {
+	if userID, ok := GetUserIDFromContext(r.Context()); ok {
+		return "user:" + strconv.Itoa(userID)
+	}
+	return "ip:" + ClientIP(r)
+}
+
+func (l *RateLimiter) get(key string, now time.Time) *rate.Limiter {
+	v, ok := l.limiters.Load(key)
+	if !ok {
+		v, _ = l.limiters.LoadOrStore(key, &limiterEntry{
+			limiter: rate.NewLimiter(rate.Limit(l.policy.Rps), l.policy.Burst),
+		})
+	}
+
+	entry := v.(*limiterEntry)
+	entry.lastSeen.Store(now.UnixNano())
+	return entry.limiter
+}
+
+// A dropped limiter would've been full again by now, starting over with a fresh one changes nothing
+func (l *RateLimiter) sweep() {
+	ticker := time.NewTicker(RATE_LIMIT_SWEEP_INTERVAL)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		cutoff := now.Add(-l.idleTimeout).UnixNano()
+		l.limiters.Range(func(key, v any) bool {
+			if v.(*limiterEntry).lastSeen.Load() < cutoff {
+				l.limiters.Delete(key)
+			}
+			return true
+		})
+	}
+}
+
+// Seconds until this many tokens are back, rounded up
+func (l *RateLimiter) secondsUntil(tokens float64) int {
+	if tokens <= 0 {
+		return 0
+	}
+	return int(math.Ceil(tokens / l.policy.Rps))
+}
+
+// Headers from https://datatracker.ietf.org/doc/draft-ietf-httpapi-ratelimit-headers/, a stricter
+// route limiter runs after the global one and its numbers are the ones that stick
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := r.RemoteAddr
-		if !l.get(ip).Allow() {
+		now := time.Now()
+		limiter := l.get(rateLimitKey(r), now)
+		allowed := limiter.AllowN(now, 1)
+		tokens := limiter.TokensAt(now)
+
+		h := w.Header()
+		h.Set("RateLimit-Policy", strconv.Itoa(l.policy.Burst)+";w="+strconv.Itoa(l.secondsUntil(float64(l.policy.Burst))))
+		h.Set("RateLimit-Limit", strconv.Itoa(l.policy.Burst))
+		h.Set("RateLimit-Remaining", strconv.Itoa(max(0, int(tokens))))
+		h.Set("RateLimit-Reset", strconv.Itoa(l.secondsUntil(float64(l.policy.Burst)-tokens)))
+
+		if !allowed {
+			h.Set("Retry-After", strconv.Itoa(max(1, l.secondsUntil(1-tokens))))
 			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
 			return
 		}
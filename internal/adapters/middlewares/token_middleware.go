@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+)
+
+const TokenScopesKey contextKey = "tokenScopes"
+
+// Resolves bearer tokens to their owner and scopes, middlewares don't get to know about services
+type TokenAuthenticator interface {
+	AuthenticateToken(ctx context.Context, token string) (int, []value.TokenScope, bool)
+}
+
+// Only set when the request came in with a bearer token instead of the session cookie
+func GetTokenScopesFromContext(ctx context.Context) ([]value.TokenScope, bool) {
+	scopes, ok := ctx.Value(TokenScopesKey).([]value.TokenScope)
+	return scopes, ok
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
+
+// Lets bearer tokens with the given scope through on the routes it's used on, goes before the JWT middlewares,
+// which leave token requests alone. Routes without it only ever take the cookie
+func TokenMiddleware(tokens TokenAuthenticator, scope value.TokenScope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := bearerToken(r)
+			if token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, scopes, ok := tokens.AuthenticateToken(r.Context(), token)
+			if !ok {
+				http.Error(w, "Unauthorized, invalid token", http.StatusUnauthorized)
+				return
+			}
+
+			if !slices.Contains(scopes, scope) {
+				http.Error(w, "Forbidden, token is missing the "+string(scope)+" scope", http.StatusForbidden)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			ctx = context.WithValue(ctx, TokenScopesKey, scopes)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Tries each kind of bearer token in turn, each one only looks at tokens carrying its own prefix
+type TokenAuthenticators []TokenAuthenticator
+
+func (a TokenAuthenticators) AuthenticateToken(ctx context.Context, token string) (int, []value.TokenScope, bool) {
+	for _, authenticator := range a {
+		if userID, scopes, ok := authenticator.AuthenticateToken(ctx, token); ok {
+			return userID, scopes, true
+		}
+	}
+	return 0, nil, false
+}
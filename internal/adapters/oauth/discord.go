@@ -0,0 +1,63 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+const DISCORD_API_URL = "https://discord.com/api"
+const DISCORD_CDN_URL = "https://cdn.discordapp.com"
+
+type discordUser struct {
+	ID         string `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name"`
+	Email      string `json:"email"`
+	Verified   bool   `json:"verified"`
+	Avatar     string `json:"avatar"`
+}
+
+func NewDiscordProvider(name, clientID, clientSecret, redirectURL string, scopes []string) *OAuth2Provider {
+	return &OAuth2Provider{
+		name: name,
+		conf: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+			Endpoint:     endpoints.Discord,
+		},
+		identify: fetchDiscordIdentity,
+	}
+}
+
+func fetchDiscordIdentity(ctx context.Context, client *http.Client) (*domain.ProviderIdentity, error) {
+	var user discordUser
+	if err := getJSON(ctx, client, DISCORD_API_URL+"/users/@me", &user); err != nil {
+		return nil, err
+	}
+	if user.ID == "" {
+		return nil, errors.New("Discord user has no ID")
+	}
+
+	name := user.GlobalName
+	if name == "" {
+		name = user.Username
+	}
+
+	identity := &domain.ProviderIdentity{
+		Subject:       user.ID,
+		Email:         user.Email,
+		EmailVerified: user.Verified,
+		Name:          name,
+	}
+	if user.Avatar != "" {
+		identity.AvatarURL = DISCORD_CDN_URL + "/avatars/" + user.ID + "/" + user.Avatar + ".png"
+	}
+	return identity, nil
+}
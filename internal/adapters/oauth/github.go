@@ -0,0 +1,76 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/endpoints"
+)
+
+const GITHUB_API_URL = "https://api.github.com"
+
+type githubUser struct {
+	ID        int64  `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url"`
+}
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func NewGitHubProvider(name, clientID, clientSecret, redirectURL string, scopes []string) *OAuth2Provider {
+	return &OAuth2Provider{
+		name: name,
+		conf: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+			Endpoint:     endpoints.GitHub,
+		},
+		identify: fetchGitHubIdentity,
+	}
+}
+
+func fetchGitHubIdentity(ctx context.Context, client *http.Client) (*domain.ProviderIdentity, error) {
+	var user githubUser
+	if err := getJSON(ctx, client, GITHUB_API_URL+"/user", &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, errors.New("GitHub user has no ID")
+	}
+
+	// The profile email is whatever the user made public, if anything, the primary one is what we want
+	var emails []githubEmail
+	if err := getJSON(ctx, client, GITHUB_API_URL+"/user/emails", &emails); err != nil {
+		return nil, err
+	}
+
+	name := user.Name
+	if name == "" {
+		name = user.Login
+	}
+
+	identity := &domain.ProviderIdentity{
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Name:      name,
+		AvatarURL: user.AvatarURL,
+	}
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = email.Email
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,39 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"golang.org/x/oauth2"
+)
+
+// Plain OAuth2 providers without OIDC, each one has its own API to ask who logged in
+type OAuth2Provider struct {
+	name     string
+	conf     *oauth2.Config
+	identify func(ctx context.Context, client *http.Client) (*domain.ProviderIdentity, error)
+}
+
+func (p *OAuth2Provider) Name() string {
+	return p.name
+}
+
+// No PKCE here, not every one of these supports it and the state cookie covers us
+func (p *OAuth2Provider) AuthCodeURL(ctx context.Context, state, verifier string) (string, error) {
+	return p.conf.AuthCodeURL(state), nil
+}
+
+func (p *OAuth2Provider) Authenticate(ctx context.Context, code, verifier string) (*domain.ProviderIdentity, error) {
+	token, err := p.conf.Exchange(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := p.identify(ctx, p.conf.Client(ctx, token))
+	if err != nil {
+		return nil, err
+	}
+	identity.Provider = p.name
+	return identity, nil
+}
@@ -0,0 +1,308 @@
+package oauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// An unknown kid refetches the issuer's keys, but not more often than this
+const OIDC_JWKS_REFRESH_INTERVAL = time.Minute
+
+// The bits of the discovery document we care about
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Keys as issuers publish them, see RFC 7517 and RFC 7518
+type oidcJWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Crv string `json:"crv"` // EC and OKP
+	X   string `json:"x"`   // EC and OKP
+	Y   string `json:"y"`   // EC
+	N   string `json:"n"`   // RSA
+	E   string `json:"e"`   // RSA
+}
+
+type oidcIDTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+}
+
+type oidcUserInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     any    `json:"email_verified"` // Some issuers send "true" as a string
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	Picture           string `json:"picture"`
+}
+
+// Any OpenID Connect issuer, the endpoints come from its discovery document. Who logged in
+// comes from the ID token, checked against the issuer's keys, and the rest of the profile
+// from the userinfo endpoint
+type OIDCProvider struct {
+	name   string
+	issuer string
+	conf   oauth2.Config
+
+	// Discovery happens on first use so a provider being down doesn't stop us from starting
+	mu         sync.Mutex
+	discovered *oidcDiscovery
+
+	keysMu        sync.Mutex
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+func NewOIDCProvider(name, issuer, clientID, clientSecret, redirectURL string, scopes []string) *OIDCProvider {
+	return &OIDCProvider{
+		name:   name,
+		issuer: strings.TrimSuffix(issuer, "/"),
+		conf: oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Scopes:       scopes,
+		},
+	}
+}
+
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovered != nil {
+		return p.discovered, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, http.DefaultClient, p.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, err
+	}
+
+	// The document has to be about the issuer we asked, otherwise anyone serving it could speak for them
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("Discovery document of %s is for issuer %s", p.issuer, discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserinfoEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("Discovery document of " + p.issuer + " is missing endpoints")
+	}
+
+	p.discovered = &discovery
+	return p.discovered, nil
+}
+
+func (p *OIDCProvider) config(discovery *oidcDiscovery) *oauth2.Config {
+	conf := p.conf
+	conf.Endpoint = oauth2.Endpoint{
+		AuthURL:  discovery.AuthorizationEndpoint,
+		TokenURL: discovery.TokenEndpoint,
+	}
+	return &conf
+}
+
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, verifier string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return p.config(discovery).AuthCodeURL(state, oauth2.S256ChallengeOption(verifier),
+		oauth2.SetAuthURLParam("nonce", oidcNonce(verifier))), nil
+}
+
+// The verifier already lives in the flow cookie, so an ID token whose nonce comes from it
+// can only have been issued for this browser's login
+func oidcNonce(verifier string) string {
+	sum := sha256.Sum256([]byte("nonce:" + verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (p *OIDCProvider) Authenticate(ctx context.Context, code, verifier string) (*domain.ProviderIdentity, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := p.config(discovery)
+	token, err := conf.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, errors.New(p.issuer + " sent no ID token")
+	}
+	claims, err := p.verifyIDToken(ctx, discovery, rawIDToken, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var info oidcUserInfo
+	if err := getJSON(ctx, conf.Client(ctx, token), discovery.UserinfoEndpoint, &info); err != nil {
+		return nil, err
+	}
+
+	// Userinfo that's about somebody else than the ID token can't be trusted, see OIDC Core 5.3.2
+	if info.Subject != claims.Subject {
+		return nil, errors.New("Userinfo of " + p.issuer + " is not about the ID token's subject")
+	}
+
+	name := info.Name
+	if name == "" {
+		name = info.PreferredUsername
+	}
+
+	return &domain.ProviderIdentity{
+		Provider:      p.name,
+		Subject:       info.Subject,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified == true || info.EmailVerified == "true",
+		Name:          name,
+		AvatarURL:     info.Picture,
+	}, nil
+}
+
+// Signed by the issuer, for us, not expired and for this very login, see OIDC Core 3.1.3.7
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, raw, verifier string) (*oidcIDTokenClaims, error) {
+	var claims oidcIDTokenClaims
+	_, err := jwt.ParseWithClaims(raw, &claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, discovery, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.conf.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid ID token from %s: %w", p.issuer, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("ID token of " + p.issuer + " has no subject")
+	}
+	if claims.AuthorizedParty != "" && claims.AuthorizedParty != p.conf.ClientID {
+		return nil, errors.New("ID token of " + p.issuer + " was issued to another client")
+	}
+	if claims.Nonce != oidcNonce(verifier) {
+		return nil, errors.New("ID token of " + p.issuer + " is not for this login")
+	}
+	return &claims, nil
+}
+
+// Keys are cached, one we don't know yet means the issuer rotated and they're fetched again
+func (p *OIDCProvider) key(ctx context.Context, discovery *oidcDiscovery, kid string) (any, error) {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < OIDC_JWKS_REFRESH_INTERVAL {
+		return nil, errors.New("No key " + kid + " in the key set of " + p.issuer)
+	}
+
+	var set struct {
+		Keys []oidcJWK `json:"keys"`
+	}
+	if err := getJSON(ctx, http.DefaultClient, discovery.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		// Keys we can't read are skipped, the issuer may well publish kinds we don't support
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, errors.New("No key " + kid + " in the key set of " + p.issuer)
+}
+
+// Tokens without a kid are fine as long as the issuer has a single key
+func (p *OIDCProvider) lookupKey(kid string) (any, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (k oidcJWK) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) > 4 {
+			return nil, errors.New("Malformed RSA key " + k.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.New("Unsupported curve " + k.Crv)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil || len(x) != size || len(y) != size {
+			return nil, errors.New("Malformed EC key " + k.Kid)
+		}
+		// Also makes sure the point is on the curve
+		return ecdsa.ParseUncompressedPublicKey(curve, append(append([]byte{4}, x...), y...))
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if k.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("Unsupported or malformed OKP key " + k.Kid)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, errors.New("Unsupported key type " + k.Kty)
+}
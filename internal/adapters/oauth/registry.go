@@ -0,0 +1,79 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/afuradanime/backend/config"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+)
+
+// The login providers we know of, looked up by the {provider} in the auth routes
+type Registry struct {
+	providers map[string]interfaces.AuthProvider
+}
+
+func NewRegistry(providers ...interfaces.AuthProvider) *Registry {
+	registry := &Registry{providers: make(map[string]interfaces.AuthProvider, len(providers))}
+	for _, provider := range providers {
+		registry.providers[provider.Name()] = provider
+	}
+	return registry
+}
+
+func NewRegistryFromConfig(configs []config.OAuthProviderConfig) (*Registry, error) {
+	providers := make([]interfaces.AuthProvider, 0, len(configs))
+	for _, conf := range configs {
+		switch conf.Kind {
+		case config.OAuthKindOIDC:
+			providers = append(providers, NewOIDCProvider(conf.Name, conf.Issuer, conf.ClientID, conf.ClientSecret, conf.RedirectURL, conf.Scopes))
+		case config.OAuthKindDiscord:
+			providers = append(providers, NewDiscordProvider(conf.Name, conf.ClientID, conf.ClientSecret, conf.RedirectURL, conf.Scopes))
+		case config.OAuthKindGitHub:
+			providers = append(providers, NewGitHubProvider(conf.Name, conf.ClientID, conf.ClientSecret, conf.RedirectURL, conf.Scopes))
+		default:
+			return nil, fmt.Errorf("Unknown kind %q for login provider %s", conf.Kind, conf.Name)
+		}
+	}
+	return NewRegistry(providers...), nil
+}
+
+func (r *Registry) Get(name string) (interfaces.AuthProvider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GETs a provider API and decodes the JSON answer, anything but a 200 is an error
+func getJSON(ctx context.Context, client *http.Client, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered with status %d", url, res.StatusCode)
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("Failed decoding the response of %s: %w", url, err)
+	}
+	return nil
+}
@@ -42,3 +42,8 @@ func (r *AnimeListRepository) SaveUserList(ctx context.Context, list *domain.Use
 	_, err := r.collection.ReplaceOne(ctx, filter, list, opts)
 	return err
 }
+
+func (r *AnimeListRepository) DeleteUserList(ctx context.Context, userID int) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"user_id": userID})
+	return err
+}
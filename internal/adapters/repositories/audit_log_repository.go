@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Append only, entries are never updated or removed
+type AuditLogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAuditLogRepository(db *mongo.Database) *AuditLogRepository {
+	return &AuditLogRepository{
+		collection: db.Collection("audit_log"),
+	}
+}
+
+func (r *AuditLogRepository) CreateEntry(ctx context.Context, entry *domain.AuditEntry) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *AuditLogRepository) GetEntries(ctx context.Context, f filters.AuditFilter, pageNumber, pageSize int) ([]*domain.AuditEntry, utils.Pagination, error) {
+	skip := (pageNumber - 1) * pageSize
+
+	filter := bson.M{}
+	if f.Actor != nil {
+		filter["actor"] = *f.Actor
+	}
+	if f.Action != nil {
+		filter["action"] = *f.Action
+	}
+	if f.TargetType != nil {
+		filter["target_type"] = *f.TargetType
+	}
+	if f.TargetID != nil {
+		filter["target_id"] = *f.TargetID
+	}
+	if f.TargetUser != nil {
+		filter["target_user"] = *f.TargetUser
+	}
+	if f.From != nil || f.To != nil {
+		createdAt := bson.M{}
+		if f.From != nil {
+			createdAt["$gte"] = *f.From
+		}
+		if f.To != nil {
+			createdAt["$lt"] = *f.To
+		}
+		filter["created_at"] = createdAt
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*domain.AuditEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	return entries, utils.Pagination{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Export metadata lives in its own collection with a TTL on it, the archives themselves go to
+// GridFS under the export's ID, they can easily outgrow what a single document can hold
+type DataExportRepository struct {
+	collection *mongo.Collection
+	archives   *gridfs.Bucket
+}
+
+func NewDataExportRepository(db *mongo.Database) *DataExportRepository {
+	archives, err := gridfs.NewBucket(db, options.GridFSBucket().SetName("export_archives"))
+	if err != nil {
+		panic(err) // Only fails on invalid options
+	}
+
+	return &DataExportRepository{
+		collection: db.Collection("data_exports"),
+		archives:   archives,
+	}
+}
+
+func (r *DataExportRepository) CreateExport(ctx context.Context, export *domain.DataExport) error {
+	_, err := r.collection.InsertOne(ctx, export)
+	return err
+}
+
+func (r *DataExportRepository) GetExport(ctx context.Context, id string) (*domain.DataExport, error) {
+	var export domain.DataExport
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&export)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+// Newest first
+func (r *DataExportRepository) GetUserExports(ctx context.Context, userID int) ([]*domain.DataExport, error) {
+	findOpts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"user": userID}, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var exports []*domain.DataExport
+	if err := cursor.All(ctx, &exports); err != nil {
+		return nil, err
+	}
+	return exports, nil
+}
+
+func (r *DataExportRepository) UpdateExport(ctx context.Context, export *domain.DataExport) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": export.ID}, export)
+	return err
+}
+
+// The expiry goes along with the file, the TTL monitor only ever sees the metadata.
+// Bucket deadlines stick around for every later call on it, so the upload isn't tied to ctx
+func (r *DataExportRepository) SaveArchive(ctx context.Context, exportID string, archive []byte, expiresAt time.Time) error {
+	return r.archives.UploadFromStreamWithID(exportID, exportID+".zip", bytes.NewReader(archive),
+		options.GridFSUpload().SetMetadata(bson.M{"expires_at": expiresAt}))
+}
+
+// Nil when the archive is gone, the caller closes it
+func (r *DataExportRepository) OpenArchive(ctx context.Context, exportID string) (io.ReadCloser, error) {
+	stream, err := r.archives.OpenDownloadStream(exportID)
+	if err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return stream, nil
+}
+
+func (r *DataExportRepository) deleteArchive(ctx context.Context, exportID any) error {
+	err := r.archives.DeleteContext(ctx, exportID)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Archives past their expiry, returns how many went
+func (r *DataExportRepository) DeleteExpiredArchives(ctx context.Context, now time.Time) (int, error) {
+	cursor, err := r.archives.FindContext(ctx, bson.M{"metadata.expires_at": bson.M{"$lte": now}})
+	if err != nil {
+		return 0, err
+	}
+
+	var files []struct {
+		ID any `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &files); err != nil {
+		return 0, err
+	}
+
+	for _, file := range files {
+		if err := r.deleteArchive(ctx, file.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(files), nil
+}
+
+func (r *DataExportRepository) DeleteUserExports(ctx context.Context, userID int) error {
+	exports, err := r.GetUserExports(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if err := r.deleteArchive(ctx, export.ID); err != nil {
+			return err
+		}
+	}
+
+	_, err = r.collection.DeleteMany(ctx, bson.M{"user": userID})
+	return err
+}
@@ -283,3 +283,17 @@ func (r *DescriptionTranslationRepository) DeleteTranslation(ctx context.Context
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
+
+func (r *DescriptionTranslationRepository) GetAllTranslationsByUser(ctx context.Context, userID int) ([]domain.DescriptionTranslation, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"created_by": userID}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var translations []domain.DescriptionTranslation
+	if err := cursor.All(ctx, &translations); err != nil {
+		return nil, err
+	}
+	return translations, nil
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FilterHitRepository struct {
+	collection *mongo.Collection
+}
+
+func NewFilterHitRepository(db *mongo.Database) *FilterHitRepository {
+	return &FilterHitRepository{
+		collection: db.Collection("filter_hits"),
+	}
+}
+
+func (r *FilterHitRepository) CreateHit(ctx context.Context, hit *domain.FilterHit) error {
+	_, err := r.collection.InsertOne(ctx, hit)
+	return err
+}
+
+func (r *FilterHitRepository) GetHits(ctx context.Context, action *value.FilterAction, pageNumber, pageSize int) ([]*domain.FilterHit, utils.Pagination, error) {
+	skip := (pageNumber - 1) * pageSize
+
+	filter := bson.M{}
+	if action != nil {
+		filter["action"] = *action
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var hits []*domain.FilterHit
+	if err := cursor.All(ctx, &hits); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	return hits, utils.Pagination{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FilterRuleRepository struct {
+	collection        *mongo.Collection
+	counterCollection *mongo.Collection
+}
+
+func NewFilterRuleRepository(db *mongo.Database) *FilterRuleRepository {
+	return &FilterRuleRepository{
+		collection:        db.Collection("filter_rules"),
+		counterCollection: db.Collection("counters"),
+	}
+}
+
+func (r *FilterRuleRepository) getNextSequence(ctx context.Context, name string) (int, error) {
+	filter := bson.M{"_id": name}
+	update := bson.M{"$inc": bson.M{"seq": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var result Counter
+	err := r.counterCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&result)
+	if err != nil {
+		return 0, err
+	}
+	return result.Seq, nil
+}
+
+func (r *FilterRuleRepository) CreateRule(ctx context.Context, rule *domain.FilterRule) error {
+	nextID, err := r.getNextSequence(ctx, "filter_rule_id")
+	if err != nil {
+		return err
+	}
+	rule.ID = nextID
+	_, err = r.collection.InsertOne(ctx, rule)
+	return err
+}
+
+func (r *FilterRuleRepository) GetRule(ctx context.Context, id int) (*domain.FilterRule, error) {
+	var rule domain.FilterRule
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&rule)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *FilterRuleRepository) UpdateRule(ctx context.Context, rule *domain.FilterRule) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": rule.ID}, rule)
+	return err
+}
+
+func (r *FilterRuleRepository) DeleteRule(ctx context.Context, id int) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// The rule set is small, no paging needed
+func (r *FilterRuleRepository) GetRules(ctx context.Context, enabledOnly bool) ([]*domain.FilterRule, error) {
+	filter := bson.M{}
+	if enabledOnly {
+		filter["enabled"] = true
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []*domain.FilterRule
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
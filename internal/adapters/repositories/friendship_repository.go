@@ -2,6 +2,8 @@ package repositories
 
 import (
 	"context"
+	"regexp"
+	"strings"
 
 	"github.com/afuradanime/backend/internal/core/domain"
 	"github.com/afuradanime/backend/internal/core/domain/value"
@@ -170,6 +172,54 @@ func (r *FriendshipRepository) GetFriends(ctx context.Context, userId int, pageN
 	}, nil
 }
 
+// Friends whose username starts with the prefix, matched in the same query that finds them
+func (r *FriendshipRepository) SearchFriends(ctx context.Context, userId int, prefix string, limit int) ([]domain.User, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"$or": []bson.M{
+				{"initiator": userId},
+				{"receiver": userId},
+			},
+			"status": value.FriendshipStatusAccepted,
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"friend_id": bson.M{
+				"$cond": bson.M{
+					"if":   bson.M{"$eq": []interface{}{"$initiator", userId}},
+					"then": "$receiver",
+					"else": "$initiator",
+				},
+			},
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "users",
+			"localField":   "friend_id",
+			"foreignField": "_id",
+			"as":           "friend",
+		}}},
+		{{Key: "$unwind", Value: "$friend"}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$friend"}}},
+		{{Key: "$match", Value: bson.M{
+			"username_lower": bson.M{"$regex": "^" + regexp.QuoteMeta(strings.ToLower(prefix))},
+			"deleted_at":     nil,
+		}}},
+		{{Key: "$sort", Value: bson.M{"username_lower": 1}}},
+		{{Key: "$limit", Value: int64(limit)}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []domain.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *FriendshipRepository) GetPendingFriendRequests(ctx context.Context, userId int, pageNumber, pageSize int) ([]domain.User, utils.Pagination, error) {
 	skip := (pageNumber - 1) * pageSize
 
@@ -242,3 +292,33 @@ func (r *FriendshipRepository) GetPendingFriendRequests(ctx context.Context, use
 		TotalPages: totalPages,
 	}, nil
 }
+
+// Every friendship, pending request and block the user is on either side of
+func (r *FriendshipRepository) DeleteUserFriendships(ctx context.Context, userID int) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"initiator": userID},
+			{"receiver": userID},
+		},
+	})
+	return err
+}
+
+func (r *FriendshipRepository) GetUserFriendships(ctx context.Context, userID int) ([]*domain.Friendship, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"$or": []bson.M{
+			{"initiator": userID},
+			{"receiver": userID},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var friendships []*domain.Friendship
+	if err := cursor.All(ctx, &friendships); err != nil {
+		return nil, err
+	}
+	return friendships, nil
+}
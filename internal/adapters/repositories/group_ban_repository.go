@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GroupBanRepository struct {
+	collection *mongo.Collection
+}
+
+func NewGroupBanRepository(db *mongo.Database) *GroupBanRepository {
+	return &GroupBanRepository{
+		collection: db.Collection("group_bans"),
+	}
+}
+
+func (r *GroupBanRepository) CreateBan(ctx context.Context, ban *domain.GroupBan) error {
+	_, err := r.collection.InsertOne(ctx, ban)
+	return err
+}
+
+func (r *GroupBanRepository) GetBan(ctx context.Context, groupId, userId int) (*domain.GroupBan, error) {
+	var ban domain.GroupBan
+	err := r.collection.FindOne(ctx, bson.M{
+		"group": groupId,
+		"user":  userId,
+	}).Decode(&ban)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Not banned
+		}
+		return nil, err
+	}
+	return &ban, nil
+}
+
+func (r *GroupBanRepository) DeleteBan(ctx context.Context, groupId, userId int) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{
+		"group": groupId,
+		"user":  userId,
+	})
+	return err
+}
+
+func (r *GroupBanRepository) GetBans(ctx context.Context, groupId int, pageNumber, pageSize int) ([]*domain.GroupBan, utils.Pagination, error) {
+	skip := (pageNumber - 1) * pageSize
+	filter := bson.M{"group": groupId}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var bans []*domain.GroupBan
+	if err := cursor.All(ctx, &bans); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	return bans, utils.Pagination{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (r *GroupBanRepository) DeleteGroupBans(ctx context.Context, groupId int) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"group": groupId})
+	return err
+}
@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GroupInviteRepository struct {
+	collection *mongo.Collection
+}
+
+func NewGroupInviteRepository(db *mongo.Database) *GroupInviteRepository {
+	return &GroupInviteRepository{
+		collection: db.Collection("group_invites"),
+	}
+}
+
+func (r *GroupInviteRepository) CreateInvite(ctx context.Context, invite *domain.GroupInvite) error {
+	_, err := r.collection.InsertOne(ctx, invite)
+	return err
+}
+
+func (r *GroupInviteRepository) GetInvite(ctx context.Context, code string) (*domain.GroupInvite, error) {
+	var invite domain.GroupInvite
+	err := r.collection.FindOne(ctx, bson.M{"_id": code}).Decode(&invite)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *GroupInviteRepository) GetInvites(ctx context.Context, groupId int) ([]*domain.GroupInvite, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"group": groupId},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	invites := make([]*domain.GroupInvite, 0)
+	if err := cursor.All(ctx, &invites); err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+// Takes one use out of the invite, only if it's still usable. Done in a single update
+// so two people racing for the last use can't both get in
+func (r *GroupInviteRepository) UseInvite(ctx context.Context, code string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{
+		"_id":        code,
+		"expires_at": bson.M{"$gt": time.Now()},
+		"$or": []bson.M{
+			{"max_uses": 0},
+			{"$expr": bson.M{"$lt": bson.A{"$uses", "$max_uses"}}},
+		},
+	}, bson.M{"$inc": bson.M{"uses": 1}})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *GroupInviteRepository) DeleteInvite(ctx context.Context, code string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": code})
+	return err
+}
+
+func (r *GroupInviteRepository) DeleteGroupInvites(ctx context.Context, groupId int) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"group": groupId})
+	return err
+}
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GroupJoinRequestRepository struct {
+	collection *mongo.Collection
+}
+
+func NewGroupJoinRequestRepository(db *mongo.Database) *GroupJoinRequestRepository {
+	return &GroupJoinRequestRepository{
+		collection: db.Collection("group_join_requests"),
+	}
+}
+
+func (r *GroupJoinRequestRepository) CreateRequest(ctx context.Context, request *domain.GroupJoinRequest) error {
+	_, err := r.collection.InsertOne(ctx, request)
+	return err
+}
+
+func (r *GroupJoinRequestRepository) GetRequest(ctx context.Context, groupId, userId int) (*domain.GroupJoinRequest, error) {
+	var request domain.GroupJoinRequest
+	err := r.collection.FindOne(ctx, bson.M{
+		"group": groupId,
+		"user":  userId,
+	}).Decode(&request)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *GroupJoinRequestRepository) DeleteRequest(ctx context.Context, groupId, userId int) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{
+		"group": groupId,
+		"user":  userId,
+	})
+	return err
+}
+
+func (r *GroupJoinRequestRepository) GetRequests(ctx context.Context, groupId int, pageNumber, pageSize int) ([]*domain.GroupJoinRequest, utils.Pagination, error) {
+	skip := (pageNumber - 1) * pageSize
+	filter := bson.M{"group": groupId}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	// Oldest first, they've been waiting the longest
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var requests []*domain.GroupJoinRequest
+	if err := cursor.All(ctx, &requests); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	return requests, utils.Pagination{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (r *GroupJoinRequestRepository) DeleteGroupRequests(ctx context.Context, groupId int) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"group": groupId})
+	return err
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GroupMembershipRepository struct {
+	collection *mongo.Collection
+}
+
+func NewGroupMembershipRepository(db *mongo.Database) *GroupMembershipRepository {
+	return &GroupMembershipRepository{
+		collection: db.Collection("group_memberships"),
+	}
+}
+
+// The unique index settles two joins racing each other, only one of them gets in
+func (r *GroupMembershipRepository) CreateMembership(ctx context.Context, membership *domain.GroupMembership) error {
+	_, err := r.collection.InsertOne(ctx, membership)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain_errors.AlreadyGroupMemberError{}
+	}
+	return err
+}
+
+func (r *GroupMembershipRepository) GetMembership(ctx context.Context, groupId, userId int) (*domain.GroupMembership, error) {
+	var membership domain.GroupMembership
+	err := r.collection.FindOne(ctx, bson.M{
+		"group": groupId,
+		"user":  userId,
+	}).Decode(&membership)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Not a member
+		}
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+func (r *GroupMembershipRepository) UpdateMembership(ctx context.Context, membership *domain.GroupMembership) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{
+		"group": membership.GroupID,
+		"user":  membership.UserID,
+	}, bson.M{
+		"$set": bson.M{
+			"role":        membership.Role,
+			"muted_until": membership.MutedUntil,
+		},
+	})
+	return err
+}
+
+func (r *GroupMembershipRepository) DeleteMembership(ctx context.Context, groupId, userId int) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{
+		"group": groupId,
+		"user":  userId,
+	})
+	return err
+}
+
+func (r *GroupMembershipRepository) GetMembers(ctx context.Context, groupId int, pageNumber, pageSize int) ([]*domain.GroupMembership, utils.Pagination, error) {
+	skip := (pageNumber - 1) * pageSize
+	filter := bson.M{"group": groupId}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	// Moderators first, then by seniority
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "role", Value: -1}, {Key: "joined_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var members []*domain.GroupMembership
+	if err := cursor.All(ctx, &members); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	return members, utils.Pagination{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (r *GroupMembershipRepository) GetUserMemberships(ctx context.Context, userId int) ([]*domain.GroupMembership, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user": userId})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var memberships []*domain.GroupMembership
+	if err := cursor.All(ctx, &memberships); err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (r *GroupMembershipRepository) DeleteGroupMemberships(ctx context.Context, groupId int) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"group": groupId})
+	return err
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Kept like the audit log, see AuditLogRepository
+type GroupModerationLogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewGroupModerationLogRepository(db *mongo.Database) *GroupModerationLogRepository {
+	return &GroupModerationLogRepository{
+		collection: db.Collection("group_moderation_log"),
+	}
+}
+
+func (r *GroupModerationLogRepository) CreateEntry(ctx context.Context, entry *domain.GroupModerationEntry) error {
+	_, err := r.collection.InsertOne(ctx, entry)
+	return err
+}
+
+func (r *GroupModerationLogRepository) GetEntries(ctx context.Context, groupId int, pageNumber, pageSize int) ([]*domain.GroupModerationEntry, utils.Pagination, error) {
+	skip := (pageNumber - 1) * pageSize
+	filter := bson.M{"group": groupId}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*domain.GroupModerationEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	return entries, utils.Pagination{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
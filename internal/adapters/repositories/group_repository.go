@@ -2,15 +2,19 @@ package repositories
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
 	"github.com/afuradanime/backend/internal/core/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const MAX_GROUP_SEARCH_WORDS = 8
+
 type GroupRepository struct {
 	collection        *mongo.Collection
 	counterCollection *mongo.Collection
@@ -53,7 +57,8 @@ func (r *GroupRepository) CreateGroup(ctx context.Context, group *domain.Group)
 func (r *GroupRepository) GetGroup(ctx context.Context, groupId int) (*domain.Group, error) {
 	var group domain.Group
 	err := r.collection.FindOne(ctx, bson.M{
-		"_id": groupId,
+		"_id":        groupId,
+		"deleted_at": nil,
 	}).Decode(&group)
 
 	if err != nil {
@@ -63,17 +68,61 @@ func (r *GroupRepository) GetGroup(ctx context.Context, groupId int) (*domain.Gr
 	return &group, nil
 }
 
-func (r *GroupRepository) GetGroups(ctx context.Context, pageNumber, pageSize int) ([]*domain.Group, utils.Pagination, error) {
+// Searched through the text index on name and description, so "one piece fans" finds "Fans of One Piece".
+// Any of the words is enough, the groups matching more of them come first
+func buildGroupFilter(f filters.GroupFilter) bson.M {
+	filter := bson.M{"deleted_at": nil}
+
+	if f.Query != nil {
+		words := strings.Fields(*f.Query)
+		if len(words) > MAX_GROUP_SEARCH_WORDS {
+			words = words[:MAX_GROUP_SEARCH_WORDS]
+		}
+
+		if len(words) > 0 {
+			filter["$text"] = bson.M{"$search": strings.Join(words, " ")}
+		}
+	}
+
+	if f.AnimeID != nil {
+		filter["anime"] = *f.AnimeID
+	}
+
+	if f.TagID != nil {
+		filter["tags"] = *f.TagID
+	}
+
+	return filter
+}
+
+// A search is sorted by relevance unless another order was asked for
+func groupSort(f filters.GroupFilter) bson.D {
+	switch f.Sort {
+	case filters.GroupSortMembers:
+		return bson.D{{Key: "member_count", Value: -1}, {Key: "_id", Value: 1}}
+	case filters.GroupSortActivity:
+		return bson.D{{Key: "last_activity_at", Value: -1}, {Key: "_id", Value: 1}}
+	default:
+		if f.Query != nil && len(strings.Fields(*f.Query)) > 0 {
+			return bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}, {Key: "_id", Value: 1}}
+		}
+		return bson.D{{Key: "_id", Value: 1}}
+	}
+}
+
+func (r *GroupRepository) GetGroups(ctx context.Context, f filters.GroupFilter, pageNumber, pageSize int) ([]*domain.Group, utils.Pagination, error) {
 	skip := (pageNumber - 1) * pageSize
+	filter := buildGroupFilter(f)
 
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, utils.Pagination{}, err
 	}
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
 		SetSkip(int64(skip)).
-		SetLimit(int64(pageSize)),
+		SetLimit(int64(pageSize)).
+		SetSort(groupSort(f)),
 	)
 	if err != nil {
 		return nil, utils.Pagination{}, err
@@ -95,8 +144,45 @@ func (r *GroupRepository) GetGroups(ctx context.Context, pageNumber, pageSize in
 
 func (r *GroupRepository) UpdateGroup(ctx context.Context, group *domain.Group) error {
 	filter := bson.M{"_id": group.ID}
-	update := bson.M{"$set": group}
+
+	// The member count is left out, it only moves through IncrementMemberCount
+	update := bson.M{"$set": bson.M{
+		"name":          group.Name,
+		"icon":          group.Icon,
+		"description":   group.Description,
+		"rules":         group.Rules,
+		"public":        group.Public,
+		"mods":          group.Moderators,
+		"owner":         group.OwnerID,
+		"pending_owner": group.PendingOwnerID,
+		"anime":         group.AnimeID,
+		"tags":          group.Tags,
+		"archived_at":   group.ArchivedAt,
+		"deleted_at":    group.DeletedAt,
+	}}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
 	return err
 }
+
+func (r *GroupRepository) IncrementMemberCount(ctx context.Context, groupId int, delta int) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": groupId},
+		bson.M{"$inc": bson.M{"member_count": delta}},
+	)
+	return err
+}
+
+func (r *GroupRepository) UpdateLastActivity(ctx context.Context, groupId int, at time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": groupId},
+		bson.M{"$max": bson.M{"last_activity_at": at}},
+	)
+	return err
+}
+
+// Gone for good, groups are normally deleted through UpdateGroup and kept on record
+func (r *GroupRepository) DeleteGroup(ctx context.Context, groupId int) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": groupId})
+	return err
+}
@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type IdentityRepository struct {
+	collection *mongo.Collection
+}
+
+func NewIdentityRepository(db *mongo.Database) *IdentityRepository {
+	return &IdentityRepository{
+		collection: db.Collection("user_identities"),
+	}
+}
+
+// The unique indices have the last word on who owns what, two racing links can't both win
+func (r *IdentityRepository) CreateIdentity(ctx context.Context, identity *domain.LinkedIdentity) error {
+	_, err := r.collection.InsertOne(ctx, identity)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain_errors.IdentityLinkedToAnotherUserError{}
+	}
+	return err
+}
+
+func (r *IdentityRepository) GetIdentity(ctx context.Context, provider, providerID string) (*domain.LinkedIdentity, error) {
+	var identity domain.LinkedIdentity
+	err := r.collection.FindOne(ctx, bson.M{
+		"provider":    provider,
+		"provider_id": providerID,
+	}).Decode(&identity)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) GetUserIdentities(ctx context.Context, userID int) ([]*domain.LinkedIdentity, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user": userID}, options.Find().SetSort(bson.D{{Key: "linked_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var identities []*domain.LinkedIdentity
+	if err := cursor.All(ctx, &identities); err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *IdentityRepository) MarkUsed(ctx context.Context, identity *domain.LinkedIdentity) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{
+		"provider":    identity.Provider,
+		"provider_id": identity.ProviderID,
+	}, bson.M{
+		"$set": bson.M{"last_used_at": identity.LastUsedAt},
+	})
+	return err
+}
+
+func (r *IdentityRepository) DeleteIdentity(ctx context.Context, userID int, provider string) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"user": userID, "provider": provider})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+func (r *IdentityRepository) DeleteUserIdentities(ctx context.Context, userID int) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user": userID})
+	return err
+}
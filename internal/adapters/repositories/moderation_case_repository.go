@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ModerationCaseRepository struct {
+	collection        *mongo.Collection
+	counterCollection *mongo.Collection
+}
+
+func NewModerationCaseRepository(db *mongo.Database) *ModerationCaseRepository {
+	return &ModerationCaseRepository{
+		collection:        db.Collection("moderation_cases"),
+		counterCollection: db.Collection("counters"),
+	}
+}
+
+func (r *ModerationCaseRepository) getNextSequence(ctx context.Context, name string) (int, error) {
+	filter := bson.M{"_id": name}
+	update := bson.M{"$inc": bson.M{"seq": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var result Counter
+	err := r.counterCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&result)
+	if err != nil {
+		return 0, err
+	}
+	return result.Seq, nil
+}
+
+func (r *ModerationCaseRepository) CreateCase(ctx context.Context, moderationCase *domain.ModerationCase) error {
+	nextID, err := r.getNextSequence(ctx, "case_id")
+	if err != nil {
+		return err
+	}
+	moderationCase.ID = nextID
+	_, err = r.collection.InsertOne(ctx, moderationCase)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain_errors.CaseAlreadyOpenError{}
+	}
+	return err
+}
+
+func (r *ModerationCaseRepository) GetCase(ctx context.Context, id int) (*domain.ModerationCase, error) {
+	var moderationCase domain.ModerationCase
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&moderationCase)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &moderationCase, nil
+}
+
+// The case still waiting on a decision for whatever the report is about, if there's one
+func (r *ModerationCaseRepository) GetOpenCase(ctx context.Context, report *domain.Report) (*domain.ModerationCase, error) {
+	filter := bson.M{
+		"target_type": report.TargetType,
+		"status":      bson.M{"$in": bson.A{value.CaseStatusOpen, value.CaseStatusInReview}},
+	}
+	if report.TargetType == value.ReportTargetUser {
+		filter["target_user"] = report.TargetUser
+	} else {
+		filter["target_id"] = report.TargetID
+	}
+
+	var moderationCase domain.ModerationCase
+	err := r.collection.FindOne(ctx, filter).Decode(&moderationCase)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &moderationCase, nil
+}
+
+// Notes and the report count are left out, they have their own atomic updates
+func (r *ModerationCaseRepository) UpdateCase(ctx context.Context, moderationCase *domain.ModerationCase) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": moderationCase.ID}, bson.M{
+		"$set": bson.M{
+			"status":      moderationCase.Status,
+			"assigned_to": moderationCase.AssignedTo,
+			"outcome":     moderationCase.Outcome,
+			"resolved_by": moderationCase.ResolvedBy,
+			"resolved_at": moderationCase.ResolvedAt,
+			"updated_at":  moderationCase.UpdatedAt,
+		},
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return domain_errors.CaseAlreadyOpenError{}
+	}
+	return err
+}
+
+func (r *ModerationCaseRepository) AddNote(ctx context.Context, id int, note domain.CaseNote) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$push": bson.M{"notes": note},
+		"$set":  bson.M{"updated_at": note.CreatedAt},
+	})
+	return err
+}
+
+func (r *ModerationCaseRepository) IncrementReportCount(ctx context.Context, id int, delta int) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$inc": bson.M{"report_count": delta},
+		"$set": bson.M{"updated_at": time.Now()},
+	})
+	return err
+}
+
+func (r *ModerationCaseRepository) GetCases(ctx context.Context, f filters.CaseFilter, pageNumber, pageSize int) ([]*domain.ModerationCase, utils.Pagination, error) {
+	skip := (pageNumber - 1) * pageSize
+
+	filter := bson.M{}
+	if f.Status != nil {
+		filter["status"] = *f.Status
+	}
+	if f.AssignedTo != nil {
+		filter["assigned_to"] = *f.AssignedTo
+	}
+	if f.TargetType != nil {
+		filter["target_type"] = *f.TargetType
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	// It's a queue, whoever has been waiting the longest goes first
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var cases []*domain.ModerationCase
+	if err := cursor.All(ctx, &cases); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	return cases, utils.Pagination{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
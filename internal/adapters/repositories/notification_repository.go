@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewNotificationRepository(db *mongo.Database) *NotificationRepository {
+	return &NotificationRepository{
+		collection: db.Collection("notifications"),
+	}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification *domain.Notification) error {
+	_, err := r.collection.InsertOne(ctx, notification)
+	return err
+}
+
+func (r *NotificationRepository) GetById(ctx context.Context, id string) (*domain.Notification, error) {
+	var notification domain.Notification
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&notification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &notification, nil
+}
+
+func (r *NotificationRepository) GetForUser(ctx context.Context, receiverID int, unreadOnly bool, pageNumber, pageSize int) ([]*domain.Notification, utils.Pagination, error) {
+	skip := (pageNumber - 1) * pageSize
+
+	filter := bson.M{"receiver": receiverID}
+	if unreadOnly {
+		filter["read"] = false
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}),
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var notifications []*domain.Notification
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	return notifications, utils.Pagination{PageNumber: pageNumber, PageSize: pageSize, TotalPages: totalPages}, nil
+}
+
+func (r *NotificationRepository) CountUnread(ctx context.Context, receiverID int) (*domain.UnreadNotifications, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"receiver": receiverID, "read": false}}},
+		{{Key: "$group", Value: bson.M{"_id": "$type", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Type  value.NotificationType `bson:"_id"`
+		Count int                    `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	unread := &domain.UnreadNotifications{ByType: make(map[value.NotificationType]int)}
+	for _, result := range results {
+		unread.ByType[result.Type] = result.Count
+		unread.Total += result.Count
+	}
+
+	return unread, nil
+}
+
+func (r *NotificationRepository) MarkRead(ctx context.Context, id string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"read": true}})
+	return err
+}
+
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, receiverID int) error {
+	_, err := r.collection.UpdateMany(ctx,
+		bson.M{"receiver": receiverID, "read": false},
+		bson.M{"$set": bson.M{"read": true}},
+	)
+	return err
+}
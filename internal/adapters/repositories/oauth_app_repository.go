@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OAuthAppRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOAuthAppRepository(db *mongo.Database) *OAuthAppRepository {
+	return &OAuthAppRepository{
+		collection: db.Collection("oauth_apps"),
+	}
+}
+
+func (r *OAuthAppRepository) CreateApp(ctx context.Context, app *domain.OAuthApp) error {
+	_, err := r.collection.InsertOne(ctx, app)
+	return err
+}
+
+func (r *OAuthAppRepository) GetApp(ctx context.Context, id string) (*domain.OAuthApp, error) {
+	var app domain.OAuthApp
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&app)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &app, nil
+}
+
+func (r *OAuthAppRepository) GetOwnerApps(ctx context.Context, ownerID int) ([]*domain.OAuthApp, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"owner": ownerID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var apps []*domain.OAuthApp
+	if err := cursor.All(ctx, &apps); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+func (r *OAuthAppRepository) CountOwnerApps(ctx context.Context, ownerID int) (int, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"owner": ownerID})
+	return int(count), err
+}
+
+func (r *OAuthAppRepository) DeleteApp(ctx context.Context, id string, ownerID int) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id, "owner": ownerID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
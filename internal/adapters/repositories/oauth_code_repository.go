@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OAuthCodeRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOAuthCodeRepository(db *mongo.Database) *OAuthCodeRepository {
+	return &OAuthCodeRepository{
+		collection: db.Collection("oauth_codes"),
+	}
+}
+
+func (r *OAuthCodeRepository) CreateCode(ctx context.Context, code *domain.OAuthAuthorizationCode) error {
+	_, err := r.collection.InsertOne(ctx, code)
+	return err
+}
+
+func (r *OAuthCodeRepository) GetCode(ctx context.Context, hash string) (*domain.OAuthAuthorizationCode, error) {
+	var code domain.OAuthAuthorizationCode
+	err := r.collection.FindOne(ctx, bson.M{"_id": hash}).Decode(&code)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// Marks the code as used and hands it back, nil if it's missing or someone got to it first
+func (r *OAuthCodeRepository) RedeemCode(ctx context.Context, hash string, at time.Time) (*domain.OAuthAuthorizationCode, error) {
+	var code domain.OAuthAuthorizationCode
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{
+		"_id":     hash,
+		"used_at": nil,
+	}, bson.M{
+		"$set": bson.M{"used_at": at},
+	}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&code)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &code, nil
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OAuthGrantRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOAuthGrantRepository(db *mongo.Database) *OAuthGrantRepository {
+	return &OAuthGrantRepository{
+		collection: db.Collection("oauth_grants"),
+	}
+}
+
+// One grant per user and app, asking again just updates its scopes
+func (r *OAuthGrantRepository) SaveGrant(ctx context.Context, grant *domain.OAuthGrant) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{
+		"user": grant.UserID,
+		"app":  grant.AppID,
+	}, bson.M{
+		"$set": bson.M{
+			"app_name":   grant.AppName,
+			"scopes":     grant.Scopes,
+			"updated_at": grant.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        grant.ID,
+			"created_at": grant.CreatedAt,
+		},
+	}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *OAuthGrantRepository) GetGrant(ctx context.Context, userID int, appID string) (*domain.OAuthGrant, error) {
+	var grant domain.OAuthGrant
+	err := r.collection.FindOne(ctx, bson.M{"user": userID, "app": appID}).Decode(&grant)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &grant, nil
+}
+
+func (r *OAuthGrantRepository) GetUserGrants(ctx context.Context, userID int) ([]*domain.OAuthGrant, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user": userID},
+		options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var grants []*domain.OAuthGrant
+	if err := cursor.All(ctx, &grants); err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+func (r *OAuthGrantRepository) DeleteGrant(ctx context.Context, userID int, appID string) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"user": userID, "app": appID})
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount == 1, nil
+}
+
+func (r *OAuthGrantRepository) DeleteAppGrants(ctx context.Context, appID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"app": appID})
+	return err
+}
+
+func (r *OAuthGrantRepository) DeleteUserGrants(ctx context.Context, userID int) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"user": userID})
+	return err
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OAuthTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOAuthTokenRepository(db *mongo.Database) *OAuthTokenRepository {
+	return &OAuthTokenRepository{
+		collection: db.Collection("oauth_tokens"),
+	}
+}
+
+func (r *OAuthTokenRepository) CreateToken(ctx context.Context, token *domain.OAuthToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *OAuthTokenRepository) findOne(ctx context.Context, filter bson.M) (*domain.OAuthToken, error) {
+	var token domain.OAuthToken
+	err := r.collection.FindOne(ctx, filter).Decode(&token)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *OAuthTokenRepository) GetTokenByAccessHash(ctx context.Context, hash string) (*domain.OAuthToken, error) {
+	return r.findOne(ctx, bson.M{"access_hash": hash})
+}
+
+func (r *OAuthTokenRepository) GetTokenByRefreshHash(ctx context.Context, hash string) (*domain.OAuthToken, error) {
+	return r.findOne(ctx, bson.M{"refresh_hash": hash})
+}
+
+// Only goes through if nobody refreshed in the meantime, so a refresh token can't be spent twice
+func (r *OAuthTokenRepository) RotateToken(ctx context.Context, token *domain.OAuthToken, oldRefreshHash string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{
+		"_id":          token.ID,
+		"refresh_hash": oldRefreshHash,
+		"revoked_at":   nil,
+	}, bson.M{
+		"$set": bson.M{
+			"access_hash":       token.AccessHash,
+			"access_expires_at": token.AccessExpiresAt,
+			"refresh_hash":      token.RefreshHash,
+			"expires_at":        token.ExpiresAt,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *OAuthTokenRepository) MarkUsed(ctx context.Context, id string, at time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"last_used_at": at},
+	})
+	return err
+}
+
+func (r *OAuthTokenRepository) RevokeGrantTokens(ctx context.Context, userID int, appID string, at time.Time) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"user": userID, "app": appID, "revoked_at": nil}, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	return err
+}
+
+func (r *OAuthTokenRepository) RevokeAppTokens(ctx context.Context, appID string, at time.Time) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"app": appID, "revoked_at": nil}, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	return err
+}
+
+func (r *OAuthTokenRepository) RevokeCodeTokens(ctx context.Context, codeID string, at time.Time) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"code": codeID, "revoked_at": nil}, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	return err
+}
+
+func (r *OAuthTokenRepository) RevokeUserTokens(ctx context.Context, userID int, at time.Time) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"user": userID, "revoked_at": nil}, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	return err
+}
+
+// Revoked ones included, newest first
+func (r *OAuthTokenRepository) GetUserTokens(ctx context.Context, userID int) ([]*domain.OAuthToken, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user": userID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tokens []*domain.OAuthToken
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
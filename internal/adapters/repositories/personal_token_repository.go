@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PersonalTokenRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPersonalTokenRepository(db *mongo.Database) *PersonalTokenRepository {
+	return &PersonalTokenRepository{
+		collection: db.Collection("personal_tokens"),
+	}
+}
+
+func (r *PersonalTokenRepository) CreateToken(ctx context.Context, token *domain.PersonalToken) error {
+	_, err := r.collection.InsertOne(ctx, token)
+	return err
+}
+
+func (r *PersonalTokenRepository) GetTokenByHash(ctx context.Context, hash string) (*domain.PersonalToken, error) {
+	var token domain.PersonalToken
+	err := r.collection.FindOne(ctx, bson.M{"hash": hash}).Decode(&token)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Revoked ones are left out, expired ones stay so users can see why their script stopped working
+func (r *PersonalTokenRepository) GetUserTokens(ctx context.Context, userID int) ([]*domain.PersonalToken, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"user":       userID,
+		"revoked_at": nil,
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var tokens []*domain.PersonalToken
+	if err := cursor.All(ctx, &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+func (r *PersonalTokenRepository) CountUserTokens(ctx context.Context, userID int) (int, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"user": userID, "revoked_at": nil})
+	return int(count), err
+}
+
+func (r *PersonalTokenRepository) MarkUsed(ctx context.Context, id string, at time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{"last_used_at": at},
+	})
+	return err
+}
+
+// Scoped to the owner, so nobody revokes someone else's token by guessing its ID
+func (r *PersonalTokenRepository) RevokeToken(ctx context.Context, id string, userID int, at time.Time) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "user": userID, "revoked_at": nil}, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *PersonalTokenRepository) RevokeUserTokens(ctx context.Context, userID int, at time.Time) error {
+	_, err := r.collection.UpdateMany(ctx, bson.M{"user": userID, "revoked_at": nil}, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	return err
+}
@@ -3,9 +3,11 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/afuradanime/backend/internal/core/domain"
 	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -75,6 +77,12 @@ func (r *PostRepository) UpdatePost(ctx context.Context, post *domain.Post) erro
 		"$unset": bson.M{},
 	}
 
+	if post.Held {
+		update["$set"].(bson.M)["held"] = true
+	} else {
+		update["$unset"].(bson.M)["held"] = ""
+	}
+
 	// Support soft deletes
 	if post.Text == nil {
 		update["$unset"].(bson.M)["text"] = ""
@@ -93,3 +101,110 @@ func (r *PostRepository) AddReplyToPost(ctx context.Context, parentPostID string
 	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": parentPostID}, bson.M{"$push": bson.M{"posts": replyID}})
 	return err
 }
+
+// Hard deletes every thread under the parent, replies included however deep they go
+func (r *PostRepository) DeleteThreads(ctx context.Context, parentID string, parentType value.PostParentType) (int64, error) {
+	ids, err := r.collection.Distinct(ctx, "_id", bson.M{
+		"parent_id":   parentID,
+		"parent_type": parentType,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Walk down one level of replies at a time, older replies don't know their root
+	all := ids
+	for frontier := ids; len(frontier) > 0; {
+		frontier, err = r.collection.Distinct(ctx, "_id", bson.M{
+			"parent_id":   bson.M{"$in": frontier},
+			"parent_type": value.ParentTypePost,
+		})
+		if err != nil {
+			return 0, err
+		}
+		all = append(all, frontier...)
+	}
+
+	if len(all) == 0 {
+		return 0, nil
+	}
+
+	result, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": all}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// How many times the user already posted this exact text since the given time
+func (r *PostRepository) CountPostsWithText(ctx context.Context, userID int, text string, since time.Time) (int, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"created_by": userID,
+		"text":       text,
+		"created_at": bson.M{"$gte": since},
+	})
+	return int(count), err
+}
+
+// Oldest first, they've been waiting the longest
+func (r *PostRepository) GetHeldPosts(ctx context.Context, pageNumber, pageSize int) ([]*domain.Post, utils.Pagination, error) {
+	skip := (pageNumber - 1) * pageSize
+	filter := bson.M{"held": true}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(bson.D{{Key: "created_at", Value: 1}}),
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var posts []*domain.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	return posts, utils.Pagination{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// Soft deletes every post the user wrote the way Post.Delete does, threads keep their shape
+func (r *PostRepository) AnonymiseUserPosts(ctx context.Context, userID int) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx, bson.M{"created_by": userID}, bson.M{
+		"$unset": bson.M{
+			"text":       "",
+			"created_by": "",
+			"held":       "",
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
+
+// Everything the user wrote that's still up, oldest first
+func (r *PostRepository) GetUserPosts(ctx context.Context, userID int) ([]*domain.Post, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"created_by": userID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var posts []*domain.Post
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
@@ -76,3 +76,34 @@ func (r *RecommendationRepository) DismissRecommendation(ctx context.Context, re
 	})
 	return err
 }
+
+// Sent and received alike
+func (r *RecommendationRepository) DeleteUserRecommendations(ctx context.Context, userID int) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{
+		"$or": []bson.M{
+			{"initiator": userID},
+			{"receiver": userID},
+		},
+	})
+	return err
+}
+
+// Sent and received, dismissed ones are long gone
+func (r *RecommendationRepository) GetAllForUser(ctx context.Context, userID int) ([]*domain.Recommendation, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"$or": []bson.M{
+			{"initiator": userID},
+			{"receiver": userID},
+		},
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var recommendations []*domain.Recommendation
+	if err := cursor.All(ctx, &recommendations); err != nil {
+		return nil, err
+	}
+	return recommendations, nil
+}
@@ -2,33 +2,35 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
 	"github.com/afuradanime/backend/internal/core/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-type UserReportRepository struct {
+type ReportRepository struct {
 	collection        *mongo.Collection
 	counterCollection *mongo.Collection
 }
 
 type ReportResult struct {
-	Report   domain.UserReport
+	Report   domain.Report
 	Reporter *domain.User
-	Target   *domain.User
+	Target   *domain.User // The user behind the target, if there's one
 }
 
-func NewUserReportRepository(db *mongo.Database) *UserReportRepository {
-	return &UserReportRepository{
+func NewReportRepository(db *mongo.Database) *ReportRepository {
+	return &ReportRepository{
 		collection:        db.Collection("reports"),
 		counterCollection: db.Collection("counters"),
 	}
 }
 
-func (r *UserReportRepository) getNextSequence(ctx context.Context, name string) (int, error) {
+func (r *ReportRepository) getNextSequence(ctx context.Context, name string) (int, error) {
 	filter := bson.M{"_id": name}
 	update := bson.M{"$inc": bson.M{"seq": 1}}
 	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
@@ -40,7 +42,7 @@ func (r *UserReportRepository) getNextSequence(ctx context.Context, name string)
 	return result.Seq, nil
 }
 
-func (r *UserReportRepository) CreateReport(ctx context.Context, report *domain.UserReport) error {
+func (r *ReportRepository) CreateReport(ctx context.Context, report *domain.Report) error {
 	nextID, err := r.getNextSequence(ctx, "report_id")
 	if err != nil {
 		return err
@@ -50,8 +52,8 @@ func (r *UserReportRepository) CreateReport(ctx context.Context, report *domain.
 	return err
 }
 
-func (r *UserReportRepository) GetReportByID(ctx context.Context, id int) (*domain.UserReport, error) {
-	var report domain.UserReport
+func (r *ReportRepository) GetReportByID(ctx context.Context, id int) (*domain.Report, error) {
+	var report domain.Report
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&report)
 	if err != nil {
 		return nil, err
@@ -59,15 +61,27 @@ func (r *UserReportRepository) GetReportByID(ctx context.Context, id int) (*doma
 	return &report, nil
 }
 
-func (r *UserReportRepository) DeleteReport(ctx context.Context, id int) error {
+func (r *ReportRepository) DeleteReport(ctx context.Context, id int) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
 
-func (r *UserReportRepository) GetReports(ctx context.Context, pageNumber, pageSize int) ([]ReportResult, utils.Pagination, error) {
+// Older reports have no target type stored, they count as user reports
+func targetTypeFilter(targetType value.ReportTargetType) any {
+	if targetType == value.ReportTargetUser {
+		return bson.M{"$in": bson.A{targetType, nil}}
+	}
+	return targetType
+}
+
+func (r *ReportRepository) GetReports(ctx context.Context, targetType *value.ReportTargetType, pageNumber, pageSize int) ([]ReportResult, utils.Pagination, error) {
 	skip := (pageNumber - 1) * pageSize
 
-	matchStage := bson.D{{Key: "$match", Value: bson.M{}}}
+	match := bson.M{}
+	if targetType != nil {
+		match["target_type"] = targetTypeFilter(*targetType)
+	}
+	matchStage := bson.D{{Key: "$match", Value: match}}
 
 	lookupReporter := bson.D{{Key: "$lookup", Value: bson.M{
 		"from":         "users",
@@ -121,9 +135,9 @@ func (r *UserReportRepository) GetReports(ctx context.Context, pageNumber, pageS
 	}
 
 	var results []struct {
-		domain.UserReport `bson:",inline"`
-		Reporter          []domain.User `bson:"reporter"`
-		Target            []domain.User `bson:"target"`
+		domain.Report `bson:",inline"`
+		Reporter      []domain.User `bson:"reporter"`
+		Target        []domain.User `bson:"target"`
 	}
 	if err := cursor.All(ctx, &results); err != nil {
 		return nil, utils.Pagination{}, err
@@ -131,7 +145,7 @@ func (r *UserReportRepository) GetReports(ctx context.Context, pageNumber, pageS
 
 	reports := make([]ReportResult, len(results))
 	for i, r := range results {
-		reports[i] = ReportResult{Report: r.UserReport}
+		reports[i] = ReportResult{Report: r.Report}
 		if len(r.Reporter) > 0 {
 			reports[i].Reporter = &r.Reporter[0]
 		}
@@ -148,7 +162,7 @@ func (r *UserReportRepository) GetReports(ctx context.Context, pageNumber, pageS
 	}, nil
 }
 
-func (r *UserReportRepository) GetReportsByTarget(ctx context.Context, targetUserID int, pageNumber, pageSize int) ([]domain.UserReport, utils.Pagination, error) {
+func (r *ReportRepository) GetReportsByTarget(ctx context.Context, targetUserID int, pageNumber, pageSize int) ([]domain.Report, utils.Pagination, error) {
 	skip := (pageNumber - 1) * pageSize
 	filter := bson.M{"target_user": targetUserID}
 
@@ -168,7 +182,7 @@ func (r *UserReportRepository) GetReportsByTarget(ctx context.Context, targetUse
 		return nil, utils.Pagination{}, err
 	}
 
-	var reports []domain.UserReport
+	var reports []domain.Report
 	if err := cursor.All(ctx, &reports); err != nil {
 		return nil, utils.Pagination{}, err
 	}
@@ -181,17 +195,78 @@ func (r *UserReportRepository) GetReportsByTarget(ctx context.Context, targetUse
 	}, nil
 }
 
-func (r *UserReportRepository) HasReported(ctx context.Context, reporterID, targetUserID int) (bool, error) {
+func (r *ReportRepository) HasReported(ctx context.Context, reporterID, targetUserID int) (bool, error) {
 	count, err := r.collection.CountDocuments(ctx, bson.M{
 		"created_by":  reporterID,
+		"target_type": targetTypeFilter(value.ReportTargetUser),
 		"target_user": targetUserID,
 	})
 	return count > 0, err
 }
 
-func (r *UserReportRepository) CountReportsByTarget(ctx context.Context, targetUserID int) (int, error) {
+func (r *ReportRepository) HasReportedContent(ctx context.Context, reporterID int, targetType value.ReportTargetType, targetID string) (bool, error) {
 	count, err := r.collection.CountDocuments(ctx, bson.M{
-		"target_user": targetUserID,
+		"created_by":  reporterID,
+		"target_type": targetType,
+		"target_id":   targetID,
+	})
+	return count > 0, err
+}
+
+// Counts the different users who reported the user or anything they posted since the given time.
+// The content filter's reports don't count, and neither does the excluded reporter
+func (r *ReportRepository) CountReportersByTarget(ctx context.Context, targetUserID, excluding int, since time.Time) (int, error) {
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		bson.D{{Key: "$match", Value: bson.M{
+			"target_user": targetUserID,
+			"created_by":  bson.M{"$nin": []int{domain.SYSTEM_USER_ID, excluding}},
+			"created_at":  bson.M{"$gte": since},
+		}}},
+		bson.D{{Key: "$group", Value: bson.M{"_id": "$created_by"}}},
+		bson.D{{Key: "$count", Value: "total"}},
 	})
-	return int(count), err
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Total int `bson:"total"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return 0, err
+	}
+
+	if len(result) == 0 {
+		return 0, nil
+	}
+	return result[0].Total, nil
+}
+
+func (r *ReportRepository) GetReportsByCase(ctx context.Context, caseID int) ([]domain.Report, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"case": caseID}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reports []domain.Report
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (r *ReportRepository) GetReportsByReporter(ctx context.Context, reporterID int) ([]domain.Report, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"created_by": reporterID}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reports []domain.Report
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+	return reports, nil
 }
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SanctionRepository struct {
+	collection        *mongo.Collection
+	counterCollection *mongo.Collection
+}
+
+func NewSanctionRepository(db *mongo.Database) *SanctionRepository {
+	return &SanctionRepository{
+		collection:        db.Collection("sanctions"),
+		counterCollection: db.Collection("counters"),
+	}
+}
+
+func (r *SanctionRepository) getNextSequence(ctx context.Context, name string) (int, error) {
+	filter := bson.M{"_id": name}
+	update := bson.M{"$inc": bson.M{"seq": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var result Counter
+	err := r.counterCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&result)
+	if err != nil {
+		return 0, err
+	}
+	return result.Seq, nil
+}
+
+func (r *SanctionRepository) CreateSanction(ctx context.Context, sanction *domain.Sanction) error {
+	nextID, err := r.getNextSequence(ctx, "sanction_id")
+	if err != nil {
+		return err
+	}
+	sanction.ID = nextID
+	_, err = r.collection.InsertOne(ctx, sanction)
+	return err
+}
+
+func (r *SanctionRepository) GetSanction(ctx context.Context, id int) (*domain.Sanction, error) {
+	var sanction domain.Sanction
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&sanction)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &sanction, nil
+}
+
+func (r *SanctionRepository) UpdateSanction(ctx context.Context, sanction *domain.Sanction) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": sanction.ID}, bson.M{
+		"$set": bson.M{
+			"lifted_at": sanction.LiftedAt,
+			"lifted_by": sanction.LiftedBy,
+			"appeal":    sanction.Appeal,
+		},
+	})
+	return err
+}
+
+func (r *SanctionRepository) GetActiveSanctions(ctx context.Context, userID int) ([]*domain.Sanction, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"user":      userID,
+		"lifted_at": nil,
+		"$or": bson.A{
+			bson.M{"expires_at": nil},
+			bson.M{"expires_at": bson.M{"$gt": time.Now()}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sanctions []*domain.Sanction
+	if err := cursor.All(ctx, &sanctions); err != nil {
+		return nil, err
+	}
+	return sanctions, nil
+}
+
+// Ran out but nobody lifted them yet
+func (r *SanctionRepository) GetExpiredSanctions(ctx context.Context, now time.Time) ([]*domain.Sanction, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"lifted_at":  nil,
+		"expires_at": bson.M{"$lte": now},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sanctions []*domain.Sanction
+	if err := cursor.All(ctx, &sanctions); err != nil {
+		return nil, err
+	}
+	return sanctions, nil
+}
+
+func (r *SanctionRepository) GetSanctionsByUser(ctx context.Context, userID int, pageNumber, pageSize int) ([]*domain.Sanction, utils.Pagination, error) {
+	return r.find(ctx, bson.M{"user": userID}, bson.M{"_id": -1}, pageNumber, pageSize)
+}
+
+// Oldest appeals first, they've been waiting the longest
+func (r *SanctionRepository) GetPendingAppeals(ctx context.Context, pageNumber, pageSize int) ([]*domain.Sanction, utils.Pagination, error) {
+	return r.find(ctx, bson.M{"appeal.status": value.AppealPending}, bson.M{"appeal.created_at": 1}, pageNumber, pageSize)
+}
+
+func (r *SanctionRepository) find(ctx context.Context, filter bson.M, sort bson.M, pageNumber, pageSize int) ([]*domain.Sanction, utils.Pagination, error) {
+	skip := (pageNumber - 1) * pageSize
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(pageSize)).
+		SetSort(sort),
+	)
+	if err != nil {
+		return nil, utils.Pagination{}, err
+	}
+	defer cursor.Close(ctx)
+
+	var sanctions []*domain.Sanction
+	if err := cursor.All(ctx, &sanctions); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	totalPages := (int(total) + pageSize - 1) / pageSize
+	return sanctions, utils.Pagination{
+		PageNumber: pageNumber,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}, nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SessionRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSessionRepository(db *mongo.Database) *SessionRepository {
+	return &SessionRepository{
+		collection: db.Collection("sessions"),
+	}
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *domain.Session) error {
+	_, err := r.collection.InsertOne(ctx, session)
+	return err
+}
+
+func (r *SessionRepository) findOne(ctx context.Context, filter bson.M) (*domain.Session, error) {
+	var session domain.Session
+	err := r.collection.FindOne(ctx, filter).Decode(&session)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) GetSession(ctx context.Context, id string) (*domain.Session, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+func (r *SessionRepository) GetSessionByRefreshHash(ctx context.Context, hash string) (*domain.Session, error) {
+	return r.findOne(ctx, bson.M{"refresh_hash": hash})
+}
+
+func (r *SessionRepository) GetSessionByPreviousHash(ctx context.Context, hash string) (*domain.Session, error) {
+	return r.findOne(ctx, bson.M{"previous_hash": hash})
+}
+
+// Only goes through if nobody rotated the token in the meantime, so a refresh token can't be spent twice
+func (r *SessionRepository) RotateSession(ctx context.Context, session *domain.Session, oldHash string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{
+		"_id":          session.ID,
+		"refresh_hash": oldHash,
+		"revoked_at":   nil,
+	}, bson.M{
+		"$set": bson.M{
+			"refresh_hash":  session.RefreshHash,
+			"previous_hash": session.PreviousHash,
+			"rotated_at":    session.RotatedAt,
+			"last_used_at":  session.LastUsedAt,
+			"expires_at":    session.ExpiresAt,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount == 1, nil
+}
+
+func (r *SessionRepository) RevokeSession(ctx context.Context, id string, at time.Time) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "revoked_at": nil}, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	return err
+}
+
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, userID int, at time.Time) (int, error) {
+	result, err := r.collection.UpdateMany(ctx, bson.M{"user": userID, "revoked_at": nil}, bson.M{
+		"$set": bson.M{"revoked_at": at},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(result.ModifiedCount), nil
+}
+
+func (r *SessionRepository) GetActiveSessions(ctx context.Context, userID int) ([]*domain.Session, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"user":       userID,
+		"revoked_at": nil,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}, options.Find().SetSort(bson.D{{Key: "last_used_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []*domain.Session
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SigningKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSigningKeyRepository(db *mongo.Database) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		collection: db.Collection("signing_keys"),
+	}
+}
+
+func (r *SigningKeyRepository) CreateKey(ctx context.Context, key *domain.SigningKey) error {
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
+
+// Every key still verifying, newest first
+func (r *SigningKeyRepository) GetKeys(ctx context.Context) ([]*domain.SigningKey, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"$or": bson.A{
+			bson.M{"expires_at": nil},
+			bson.M{"expires_at": bson.M{"$gt": time.Now()}},
+		},
+	}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var keys []*domain.SigningKey
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *SigningKeyRepository) RetireKey(ctx context.Context, key *domain.SigningKey) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": key.ID, "retired_at": nil}, bson.M{
+		"$set": bson.M{
+			"retired_at": key.RetiredAt,
+			"expires_at": key.ExpiresAt,
+		},
+	})
+	return err
+}
+
+func (r *SigningKeyRepository) DeleteExpiredKeys(ctx context.Context, now time.Time) (int, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"expires_at": bson.M{"$lte": now}})
+	if err != nil {
+		return 0, err
+	}
+	return int(result.DeletedCount), nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TermsRepository struct {
+	collection *mongo.Collection
+}
+
+func NewTermsRepository(db *mongo.Database) *TermsRepository {
+	return &TermsRepository{
+		collection: db.Collection("terms_of_service"),
+	}
+}
+
+// The version is the _id, two admins publishing at once can't both get the same number
+func (r *TermsRepository) CreateTerms(ctx context.Context, terms *domain.TermsOfService) error {
+	_, err := r.collection.InsertOne(ctx, terms)
+	if mongo.IsDuplicateKeyError(err) {
+		return domain_errors.TermsVersionConflictError{}
+	}
+	return err
+}
+
+func (r *TermsRepository) GetTerms(ctx context.Context, version int) (*domain.TermsOfService, error) {
+	var terms domain.TermsOfService
+	err := r.collection.FindOne(ctx, bson.M{"_id": version}).Decode(&terms)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &terms, nil
+}
+
+func (r *TermsRepository) GetLatestTerms(ctx context.Context) (*domain.TermsOfService, error) {
+	var terms domain.TermsOfService
+	err := r.collection.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})).Decode(&terms)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &terms, nil
+}
+
+// Newest first, without the text, that's fetched one version at a time
+func (r *TermsRepository) GetTermsHistory(ctx context.Context) ([]*domain.TermsOfService, error) {
+	findOpts := options.Find().
+		SetProjection(bson.M{"content": 0}).
+		SetSort(bson.D{{Key: "_id", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var history []*domain.TermsOfService
+	if err := cursor.All(ctx, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
@@ -3,9 +3,13 @@ package repositories
 import (
 	"context"
 	"errors"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
 	"github.com/afuradanime/backend/internal/core/utils"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -17,6 +21,16 @@ type Counter struct {
 	Seq int    `bson:"seq"`
 }
 
+// Usernames are unique regardless of case, the lower-cased copy is what gets indexed and looked up
+type storedUser struct {
+	domain.User   `bson:",inline"`
+	UsernameLower string `bson:"username_lower"`
+}
+
+func usernameKey(username value.Username) string {
+	return strings.ToLower(string(username))
+}
+
 type UserRepository struct {
 	collection        *mongo.Collection
 	counterCollection *mongo.Collection
@@ -45,12 +59,15 @@ func (r *UserRepository) getNextSequence(ctx context.Context, name string) (int,
 func (r *UserRepository) GetUsers(ctx context.Context, pageNumber, pageSize int) ([]*domain.User, utils.Pagination, error) {
 	skip := (pageNumber - 1) * pageSize
 
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	// Deleted accounts are only reachable by ID
+	filter := bson.M{"deleted_at": nil}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, utils.Pagination{}, err
 	}
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
 		SetSkip(int64(skip)).
 		SetLimit(int64(pageSize)),
 	)
@@ -81,6 +98,7 @@ func (r *UserRepository) SearchByUsername(ctx context.Context, username string,
 			"$regex":   username,
 			"$options": "i",
 		},
+		"deleted_at": nil,
 	}
 
 	total, err := r.collection.CountDocuments(ctx, filter)
@@ -111,6 +129,60 @@ func (r *UserRepository) SearchByUsername(ctx context.Context, username string,
 	}, nil
 }
 
+func (r *UserRepository) SearchByUsernamePrefix(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+	return r.searchByUsernamePrefix(ctx, prefix, nil, limit)
+}
+
+// Same as SearchByUsernamePrefix, but only among the given users
+func (r *UserRepository) SearchByUsernamePrefixAmong(ctx context.Context, prefix string, ids []int, limit int) ([]*domain.User, error) {
+	return r.searchByUsernamePrefix(ctx, prefix, ids, limit)
+}
+
+func (r *UserRepository) searchByUsernamePrefix(ctx context.Context, prefix string, ids []int, limit int) ([]*domain.User, error) {
+
+	// Anchored on the lower-cased username, so the index does the work
+	filter := bson.M{
+		"username_lower": bson.M{"$regex": "^" + regexp.QuoteMeta(strings.ToLower(prefix))},
+		"deleted_at":     nil,
+	}
+	if ids != nil {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, options.Find().
+		SetLimit(int64(limit)).
+		SetSort(bson.M{"username_lower": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []*domain.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	var user domain.User
+
+	// Exact match, but usernames are case-insensitive for lookups
+	err := r.collection.FindOne(ctx, bson.M{
+		"username_lower": strings.ToLower(username),
+	}).Decode(&user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // User not found
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 func (r *UserRepository) GetUserById(ctx context.Context, id int) (*domain.User, error) {
 	var user domain.User
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
@@ -124,11 +196,29 @@ func (r *UserRepository) GetUserById(ctx context.Context, id int) (*domain.User,
 	return &user, nil
 }
 
+func (r *UserRepository) GetUsersByIds(ctx context.Context, ids []int) ([]*domain.User, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []*domain.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
 func (r *UserRepository) GetUserByProvider(ctx context.Context, provider string, providerID string) (*domain.User, error) {
 	var user domain.User
 	err := r.collection.FindOne(
 		ctx, bson.M{"provider": provider, "provider_id": providerID}).Decode(&user)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil // Nobody logged in with it yet
+		}
 		return nil, err
 	}
 
@@ -145,7 +235,10 @@ func (r *UserRepository) CreateUser(ctx context.Context, user *domain.User) (*do
 	user.ID = nextID
 	user.CreatedAt = time.Now()
 
-	_, err = r.collection.InsertOne(ctx, user)
+	_, err = r.collection.InsertOne(ctx, storedUser{User: *user, UsernameLower: usernameKey(user.Username)})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, domain_errors.UsernameTakenError{}
+	}
 	return user, err
 }
 
@@ -155,6 +248,7 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user *domain.User) erro
         bson.M{"_id": user.ID},
         bson.M{"$set": bson.M{
             "username":               user.Username,
+            "username_lower":         usernameKey(user.Username),
             "email":                  user.Email,
             "avatar_url":             user.AvatarURL,
             "location":               user.Location,
@@ -166,11 +260,93 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user *domain.User) erro
             "private_list":           user.PrivateAnimeList,
             "can_post":               user.CanPost,
             "can_translate":          user.CanTranslate,
-            "accepted_terms":         user.AcceptedTermsOfService,
+            "accepted_terms_version": user.AcceptedTermsVersion,
+            "accepted_terms_at":      user.AcceptedTermsAt,
             "roles":                  user.Roles,
             "badges":                 user.Badges,
             "last_login":             user.LastLogin,
+            "presence_visibility":    user.PresenceVisibility,
+            "disabled_notifications": user.DisabledNotifications,
         }},
     )
+    if mongo.IsDuplicateKeyError(err) {
+        return domain_errors.UsernameTakenError{}
+    }
     return err
-}
\ No newline at end of file
+}
+// Written on its own, the activity tracker calls this in the background and
+// shouldn't race with whatever UpdateUser is doing
+func (r *UserRepository) UpdateLastSeen(ctx context.Context, id int, lastSeen time.Time) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"last_seen": lastSeen}},
+	)
+	return err
+}
+
+// Set to request a deletion, nil to cancel it
+func (r *UserRepository) SetDeletionRequestedAt(ctx context.Context, id int, at *time.Time) error {
+	update := bson.M{"$set": bson.M{"deletion_requested_at": at}}
+	if at == nil {
+		update = bson.M{"$unset": bson.M{"deletion_requested_at": ""}}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "deleted_at": nil}, update)
+	return err
+}
+
+func (r *UserRepository) GetUsersDueForDeletion(ctx context.Context, requestedBefore time.Time) ([]*domain.User, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"deletion_requested_at": bson.M{"$lte": requestedBefore},
+		"deleted_at":            nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []*domain.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Only for accounts that never got to be used, everyone else is anonymised instead
+func (r *UserRepository) DeleteUser(ctx context.Context, id int) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// Writes out an anonymised user, UpdateUser leaves the login and deletion fields alone
+func (r *UserRepository) AnonymiseUser(ctx context.Context, user *domain.User) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": user.ID}, bson.M{
+		"$set": bson.M{
+			"username":               user.Username,
+			"username_lower":         usernameKey(user.Username),
+			"email":                  user.Email,
+			"avatar_url":             user.AvatarURL,
+			"location":               user.Location,
+			"pronouns":               user.Pronouns,
+			"birthday":               user.Birthday,
+			"socials":                user.Socials,
+			"allows_friend_requests": user.AllowsFriendRequests,
+			"allows_recommendations": user.AllowsRecommendations,
+			"private_list":           user.PrivateAnimeList,
+			"can_post":               user.CanPost,
+			"can_translate":          user.CanTranslate,
+			"roles":                  user.Roles,
+			"badges":                 user.Badges,
+			"presence_visibility":    user.PresenceVisibility,
+			"disabled_notifications": user.DisabledNotifications,
+			"deleted_at":             user.DeletedAt,
+		},
+		"$unset": bson.M{
+			"provider":              "",
+			"provider_id":           "",
+			"last_seen":             "",
+			"deletion_requested_at": "",
+		},
+	})
+	return err
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+// One staff action on the platform, written once and never touched again
+type AuditEntry struct {
+	ID         string                `json:"ID" bson:"_id"`
+	Actor      int                   `json:"Actor" bson:"actor"`
+	Action     value.AuditAction     `json:"Action" bson:"action"`
+	TargetType value.AuditTargetType `json:"TargetType" bson:"target_type"`
+	TargetID   string                `json:"TargetID" bson:"target_id"`
+
+	// Whoever ends up on the receiving end, set on every entry that concerns a user
+	TargetUser *int   `json:"TargetUser,omitempty" bson:"target_user,omitempty"`
+	Details    string `json:"Details,omitempty" bson:"details,omitempty"`
+
+	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+}
+
+func NewAuditEntry(actor int, action value.AuditAction, targetType value.AuditTargetType, targetID string) *AuditEntry {
+	return &AuditEntry{
+		ID:         utils.GenerateRandomID(),
+		Actor:      actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		CreatedAt:  time.Now(),
+	}
+}
+
+func (e *AuditEntry) OnUser(userID int) *AuditEntry {
+	e.TargetUser = &userID
+	return e
+}
+
+func (e *AuditEntry) WithDetails(details string) *AuditEntry {
+	e.Details = details
+	return e
+}
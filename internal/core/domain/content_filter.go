@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+const REPEATED_TEXT_WINDOW = 24 * time.Hour
+
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://\S+|\bwww\.\S+`)
+
+type FilterRule struct {
+	ID          int                  `json:"ID" bson:"_id"`
+	Kind        value.FilterRuleKind `json:"Kind" bson:"kind"`
+	Pattern     string               `json:"Pattern,omitempty" bson:"pattern,omitempty"`
+	Threshold   int                  `json:"Threshold,omitempty" bson:"threshold,omitempty"`
+	Action      value.FilterAction   `json:"Action" bson:"action"`
+	Description string               `json:"Description,omitempty" bson:"description,omitempty"`
+	Enabled     bool                 `json:"Enabled" bson:"enabled"`
+
+	CreatedBy int       `json:"CreatedBy" bson:"created_by"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+	UpdatedAt time.Time `json:"UpdatedAt" bson:"updated_at"`
+
+	matcher *regexp.Regexp // Word and regex rules, see Compile
+}
+
+func NewFilterRule(kind value.FilterRuleKind, pattern string, threshold int, action value.FilterAction, description string, createdBy int) (*FilterRule, error) {
+	rule := &FilterRule{
+		Kind:      kind,
+		Enabled:   true,
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+	if err := rule.Update(pattern, threshold, action, description); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (r *FilterRule) Update(pattern string, threshold int, action value.FilterAction, description string) error {
+	if !r.Kind.IsValid() {
+		return domain_errors.InvalidFilterRuleError{Reason: "unknown rule kind"}
+	}
+	if !action.IsValid() {
+		return domain_errors.InvalidFilterRuleError{Reason: "unknown action"}
+	}
+
+	pattern = strings.TrimSpace(pattern)
+	if r.Kind.HasPattern() {
+		if pattern == "" {
+			return domain_errors.InvalidFilterRuleError{Reason: "a pattern is required"}
+		}
+		threshold = 0
+	} else {
+		if threshold <= 0 {
+			return domain_errors.InvalidFilterRuleError{Reason: "a positive threshold is required"}
+		}
+		pattern = ""
+	}
+
+	r.Pattern = pattern
+	r.Threshold = threshold
+	if err := r.Compile(); err != nil {
+		return err
+	}
+
+	r.Action = action
+	r.Description = description
+	r.UpdatedAt = time.Now()
+	return nil
+}
+
+// Word and regex rules only match once compiled, rules read from the database have to be compiled before use
+func (r *FilterRule) Compile() error {
+	switch r.Kind {
+	case value.FilterRuleWord:
+		// Letters around the word mean it's part of another one, \b only knows ASCII
+		r.matcher = regexp.MustCompile(`(?i)(^|[^\p{L}\p{N}_])` + regexp.QuoteMeta(r.Pattern) + `($|[^\p{L}\p{N}_])`)
+	case value.FilterRuleRegex:
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return domain_errors.InvalidFilterRuleError{Reason: "invalid regex: " + err.Error()}
+		}
+		r.matcher = re
+	}
+	return nil
+}
+
+func (r *FilterRule) SetEnabled(enabled bool) {
+	r.Enabled = enabled
+	r.UpdatedAt = time.Now()
+}
+
+// Whatever the rules get to look at besides the text itself
+type FilterInput struct {
+	Text             string
+	AccountAge       time.Duration
+	RecentDuplicates int // Posts with the exact same text by the same user in the last day
+}
+
+func CountLinks(text string) int {
+	return len(linkPattern.FindAllStringIndex(text, -1))
+}
+
+func (r *FilterRule) Matches(input FilterInput) bool {
+	switch r.Kind {
+	case value.FilterRuleWord, value.FilterRuleRegex:
+		return r.matcher != nil && r.matcher.MatchString(input.Text)
+	case value.FilterRuleLinkCount:
+		return CountLinks(input.Text) > r.Threshold
+	case value.FilterRuleRepeatedText:
+		return input.RecentDuplicates >= r.Threshold
+	case value.FilterRuleNewAccountLinks:
+		return input.AccountAge < time.Duration(r.Threshold)*time.Hour && CountLinks(input.Text) > 0
+	}
+	return false
+}
+
+func (r *FilterRule) String() string {
+	if r.Description != "" {
+		return r.Description
+	}
+	if r.Kind.HasPattern() {
+		return r.Pattern
+	}
+	return "Rule " + strconv.Itoa(r.ID)
+}
+
+// The outcome of running a post through every rule
+type FilterVerdict struct {
+	Action  value.FilterAction
+	Rules   []int
+	Reasons []string
+}
+
+func (v *FilterVerdict) Apply(rule *FilterRule) {
+	v.Rules = append(v.Rules, rule.ID)
+	v.Reasons = append(v.Reasons, rule.String())
+	if rule.Action > v.Action {
+		v.Action = rule.Action
+	}
+}
+
+// A post that tripped the filter, rejected ones are only ever seen here
+type FilterHit struct {
+	ID       string             `json:"ID" bson:"_id"`
+	PostID   string             `json:"PostID,omitempty" bson:"post,omitempty"`
+	UserID   int                `json:"UserID" bson:"user"`
+	Action   value.FilterAction `json:"Action" bson:"action"`
+	Rules    []int              `json:"Rules" bson:"rules"`
+	Reasons  []string           `json:"Reasons" bson:"reasons"`
+	Snapshot string             `json:"Snapshot" bson:"snapshot"`
+
+	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+}
+
+func NewFilterHit(verdict FilterVerdict, postID string, userID int, snapshot string) *FilterHit {
+	return &FilterHit{
+		ID:        utils.GenerateRandomID(),
+		PostID:    postID,
+		UserID:    userID,
+		Action:    verdict.Action,
+		Rules:     verdict.Rules,
+		Reasons:   verdict.Reasons,
+		Snapshot:  snapshot,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+const (
+	DATA_EXPORT_LIFETIME      = 3 * 24 * time.Hour // Mongo drops the export after this, the archive goes with the next cleanup
+	DATA_EXPORT_COOLDOWN      = 24 * time.Hour     // Building one isn't cheap, one a day is plenty
+	DATA_EXPORT_BUILD_TIMEOUT = time.Hour          // Anything still pending after this died with the server
+)
+
+// A DataExport is a copy of everything we keep about a user, put together in the background.
+// The archive itself is stored apart and only read on download
+type DataExport struct {
+	ID     string                 `json:"ID" bson:"_id"`
+	UserID int                    `json:"UserID" bson:"user"`
+	Status value.DataExportStatus `json:"Status" bson:"status"`
+	Size   int                    `json:"Size" bson:"size"`
+
+	CreatedAt time.Time  `json:"CreatedAt" bson:"created_at"`
+	ReadyAt   *time.Time `json:"ReadyAt,omitempty" bson:"ready_at,omitempty"`
+	ExpiresAt time.Time  `json:"ExpiresAt" bson:"expires_at"`
+}
+
+func NewDataExport(userID int) *DataExport {
+	now := time.Now()
+	return &DataExport{
+		ID:        utils.GenerateRandomID(),
+		UserID:    userID,
+		Status:    value.DataExportPending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(DATA_EXPORT_LIFETIME),
+	}
+}
+
+func (e *DataExport) IsPending() bool {
+	return e.Status == value.DataExportPending
+}
+
+// The TTL monitor only runs every so often, don't hand out archives it hasn't gotten to yet
+func (e *DataExport) IsAvailable() bool {
+	return e.Status == value.DataExportReady && time.Now().Before(e.ExpiresAt)
+}
+
+// Lifetime counts from when it's ready, a slow build shouldn't eat into it
+func (e *DataExport) MarkReady(size int) {
+	now := time.Now()
+	e.Status = value.DataExportReady
+	e.Size = size
+	e.ReadyAt = &now
+	e.ExpiresAt = now.Add(DATA_EXPORT_LIFETIME)
+}
+
+func (e *DataExport) MarkFailed() {
+	e.Status = value.DataExportFailed
+}
+
+// Failed exports don't count, whatever went wrong wasn't the user's fault
+func (e *DataExport) BlocksNewExport() bool {
+	if e.IsPending() {
+		return time.Since(e.CreatedAt) < DATA_EXPORT_BUILD_TIMEOUT
+	}
+	return e.Status == value.DataExportReady && time.Since(e.CreatedAt) < DATA_EXPORT_COOLDOWN
+}
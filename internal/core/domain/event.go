@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+)
+
+// An Event is something that happened which connected clients may want to hear about right away.
+// Events are published on a topic, clients only receive events for the topics they subscribed to
+type Event struct {
+	ID        string          `json:"id"`
+	Topic     string          `json:"topic"`
+	Type      value.EventType `json:"type"`
+	Data      any             `json:"data"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// Presence change of a user, published on their presence topic
+type PresenceEvent struct {
+	UserID int                  `json:"userId"`
+	Status value.ActivityStatus `json:"status"`
+}
+
+// Events addressed to a single user, such as notifications
+func UserTopic(userID int) string {
+	return "user:" + strconv.Itoa(userID)
+}
+
+// Presence changes of a single user, subscribed to by their friends
+func PresenceTopic(userID int) string {
+	return "presence:" + strconv.Itoa(userID)
+}
+
+// New posts under a parent, so whoever is viewing a thread gets its replies live
+func ThreadTopic(parentType value.PostParentType, parentID string) string {
+	return "thread:" + strconv.Itoa(int(parentType)) + ":" + parentID
+}
@@ -0,0 +1,17 @@
+package filters
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+)
+
+type AuditFilter struct {
+	Actor      *int
+	Action     *value.AuditAction
+	TargetType *value.AuditTargetType
+	TargetID   *string
+	TargetUser *int // Entries about this user, whatever the target
+	From       *time.Time
+	To         *time.Time
+}
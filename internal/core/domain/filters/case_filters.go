@@ -0,0 +1,9 @@
+package filters
+
+import "github.com/afuradanime/backend/internal/core/domain/value"
+
+type CaseFilter struct {
+	Status     *value.CaseStatus
+	AssignedTo *int
+	TargetType *value.ReportTargetType
+}
@@ -0,0 +1,16 @@
+package filters
+
+type GroupSort uint8
+
+const (
+	GroupSortDefault  GroupSort = iota // Oldest groups first
+	GroupSortMembers                   // Biggest groups first
+	GroupSortActivity                  // Most recently posted in first
+)
+
+type GroupFilter struct {
+	Query   *string // Every word has to show up in the name or the description
+	AnimeID *uint32
+	TagID   *uint32
+	Sort    GroupSort
+}
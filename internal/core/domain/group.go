@@ -15,11 +15,24 @@ type Group struct {
 	Rules       value.LongStr `json:"Rules" bson:"rules"`
 	Public      bool          `json:"Public" bson:"public"`
 
-	Moderators []int `json:"Mods" bson:"mods"`
-
-	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+	// The owner is always one of the moderators, the only one that can hand the group over or get rid of it
+	OwnerID        int   `json:"Owner" bson:"owner"`
+	PendingOwnerID *int  `json:"PendingOwner,omitempty" bson:"pending_owner,omitempty"` // Offered the group, hasn't accepted yet
+	Moderators     []int `json:"Mods" bson:"mods"`
+	MemberCount    int   `json:"MemberCount" bson:"member_count"`
+
+	// Optional categorization, a group can be about a franchise and/or some anime tags
+	AnimeID *uint32  `json:"AnimeID,omitempty" bson:"anime,omitempty"`
+	Tags    []uint32 `json:"Tags" bson:"tags"`
+
+	CreatedAt      time.Time  `json:"CreatedAt" bson:"created_at"`
+	LastActivityAt time.Time  `json:"LastActivityAt" bson:"last_activity_at"`
+	ArchivedAt     *time.Time `json:"ArchivedAt,omitempty" bson:"archived_at,omitempty"` // Archived groups are read only
+	DeletedAt      *time.Time `json:"-" bson:"deleted_at,omitempty"`                     // Gone for everyone, what was said in it stays on record
 }
 
+const MAX_GROUP_TAGS = 5
+
 func NewGroup(name, description, rules, icon string) (*Group, error) {
 	nameVal, err := value.NewTinyStr(name)
 	if err != nil {
@@ -42,13 +55,15 @@ func NewGroup(name, description, rules, icon string) (*Group, error) {
 	}
 
 	return &Group{
-		Name:        *nameVal,
-		Icon:        *iconVal,
-		Description: *descVal,
-		Rules:       *rulesVal,
-		Moderators:  make([]int, 0),
-		Public:      true,
-		CreatedAt:   time.Now(),
+		Name:           *nameVal,
+		Icon:           *iconVal,
+		Description:    *descVal,
+		Rules:          *rulesVal,
+		Moderators:     make([]int, 0),
+		Tags:           make([]uint32, 0),
+		Public:         true,
+		CreatedAt:      time.Now(),
+		LastActivityAt: time.Now(),
 	}, nil
 }
 
@@ -111,6 +126,10 @@ func (g *Group) AddModerator(userID int) error {
 
 func (g *Group) RemoveModerator(userID int) error {
 
+	if g.IsOwner(userID) {
+		return domain_errors.OwnerCannotStepDownError{}
+	}
+
 	if len(g.Moderators) == 1 {
 		return domain_errors.NoModeratorsLeftError{}
 	}
@@ -145,3 +164,90 @@ func (g *Group) IsModerator(userID int) bool {
 func (g *Group) MakePrivate() {
 	g.Public = false
 }
+
+func (g *Group) UpdateAnime(animeID *uint32) {
+	g.AnimeID = animeID
+}
+
+func (g *Group) UpdateTags(tags []uint32) error {
+
+	unique := make([]uint32, 0, len(tags))
+	seen := make(map[uint32]bool, len(tags))
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+
+	if len(unique) > MAX_GROUP_TAGS {
+		return domain_errors.TooManyGroupTagsError{}
+	}
+
+	g.Tags = unique
+	return nil
+}
+
+// Makes the user the owner, moderating comes with it
+func (g *Group) SetOwner(userID int) {
+	if !g.IsModerator(userID) {
+		g.Moderators = append(g.Moderators, userID)
+	}
+	g.OwnerID = userID
+	g.PendingOwnerID = nil
+}
+
+func (g *Group) IsOwner(userID int) bool {
+	return g.OwnerID == userID
+}
+
+// Nothing changes hands until the new owner accepts
+func (g *Group) OfferOwnership(userID int) error {
+	if g.IsOwner(userID) {
+		return domain_errors.AlreadyGroupOwnerError{}
+	}
+	g.PendingOwnerID = &userID
+	return nil
+}
+
+func (g *Group) AcceptOwnership(userID int) error {
+	if g.PendingOwnerID == nil || *g.PendingOwnerID != userID {
+		return domain_errors.NoOwnershipTransferError{}
+	}
+	g.SetOwner(userID)
+	return nil
+}
+
+func (g *Group) CancelOwnershipTransfer() error {
+	if g.PendingOwnerID == nil {
+		return domain_errors.NoOwnershipTransferError{}
+	}
+	g.PendingOwnerID = nil
+	return nil
+}
+
+func (g *Group) Archive() error {
+	if g.IsArchived() {
+		return domain_errors.GroupArchivedError{GroupID: g.ID}
+	}
+	now := time.Now()
+	g.ArchivedAt = &now
+	return nil
+}
+
+func (g *Group) Unarchive() error {
+	if !g.IsArchived() {
+		return domain_errors.GroupNotArchivedError{}
+	}
+	g.ArchivedAt = nil
+	return nil
+}
+
+func (g *Group) IsArchived() bool {
+	return g.ArchivedAt != nil
+}
+
+func (g *Group) Delete() {
+	now := time.Now()
+	g.DeletedAt = &now
+}
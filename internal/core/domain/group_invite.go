@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+const GROUP_INVITE_CODE_LENGTH = 16
+const GROUP_INVITE_DEFAULT_TTL = 7 * 24 * time.Hour
+const GROUP_INVITE_MAX_TTL = 30 * 24 * time.Hour
+
+// An invitation link to a group, the code is what goes in the link
+type GroupInvite struct {
+	Code      string `json:"Code" bson:"_id"`
+	GroupID   int    `json:"GroupID" bson:"group"`
+	CreatedBy int    `json:"CreatedBy" bson:"created_by"`
+
+	Uses    int `json:"Uses" bson:"uses"`
+	MaxUses int `json:"MaxUses" bson:"max_uses"` // 0 means unlimited
+
+	ExpiresAt time.Time `json:"ExpiresAt" bson:"expires_at"`
+	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+}
+
+func NewGroupInvite(groupID, createdBy, maxUses int, ttl time.Duration) *GroupInvite {
+
+	if ttl <= 0 {
+		ttl = GROUP_INVITE_DEFAULT_TTL
+	}
+	if ttl > GROUP_INVITE_MAX_TTL {
+		ttl = GROUP_INVITE_MAX_TTL
+	}
+	if maxUses < 0 {
+		maxUses = 0
+	}
+
+	return &GroupInvite{
+		Code:      utils.GenerateRandomToken(GROUP_INVITE_CODE_LENGTH),
+		GroupID:   groupID,
+		CreatedBy: createdBy,
+		MaxUses:   maxUses,
+		ExpiresAt: time.Now().Add(ttl),
+		CreatedAt: time.Now(),
+	}
+}
+
+func (i *GroupInvite) IsUsable() bool {
+	if time.Now().After(i.ExpiresAt) {
+		return false
+	}
+	return i.MaxUses == 0 || i.Uses < i.MaxUses
+}
@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// Someone asking to be let into a private group, gone once a moderator answers
+type GroupJoinRequest struct {
+	GroupID int `json:"GroupID" bson:"group"`
+	UserID  int `json:"UserID" bson:"user"`
+
+	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+}
+
+func NewGroupJoinRequest(groupID, userID int) *GroupJoinRequest {
+	return &GroupJoinRequest{
+		GroupID:   groupID,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+)
+
+type GroupMembership struct {
+	GroupID int             `json:"GroupID" bson:"group"`
+	UserID  int             `json:"UserID" bson:"user"`
+	Role    value.GroupRole `json:"Role" bson:"role"`
+
+	MutedUntil *time.Time `json:"MutedUntil,omitempty" bson:"muted_until,omitempty"`
+
+	JoinedAt time.Time `json:"JoinedAt" bson:"joined_at"`
+}
+
+func NewGroupMembership(groupID, userID int, role value.GroupRole) *GroupMembership {
+	return &GroupMembership{
+		GroupID:  groupID,
+		UserID:   userID,
+		Role:     role,
+		JoinedAt: time.Now(),
+	}
+}
+
+// Owners moderate as well
+func (m *GroupMembership) IsModerator() bool {
+	return m.Role >= value.GroupRoleModerator
+}
+
+func (m *GroupMembership) Mute(until time.Time) {
+	m.MutedUntil = &until
+}
+
+func (m *GroupMembership) Unmute() {
+	m.MutedUntil = nil
+}
+
+// Mutes run out on their own, nobody has to come back and lift them
+func (m *GroupMembership) IsMuted() bool {
+	return m.MutedUntil != nil && time.Now().Before(*m.MutedUntil)
+}
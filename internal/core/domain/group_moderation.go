@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+const GROUP_MUTE_MAX_DURATION = 30 * 24 * time.Hour
+
+// A user kept out of a group, bans don't expire, a moderator has to lift them
+type GroupBan struct {
+	GroupID  int    `json:"GroupID" bson:"group"`
+	UserID   int    `json:"UserID" bson:"user"`
+	BannedBy int    `json:"BannedBy" bson:"banned_by"`
+	Reason   string `json:"Reason" bson:"reason"`
+
+	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+}
+
+func NewGroupBan(groupID, userID, bannedBy int, reason string) *GroupBan {
+	return &GroupBan{
+		GroupID:   groupID,
+		UserID:    userID,
+		BannedBy:  bannedBy,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+}
+
+// One line of a group's moderation log, only the fields that make sense for the action are set
+type GroupModerationEntry struct {
+	ID        string                      `json:"ID" bson:"_id"`
+	GroupID   int                         `json:"GroupID" bson:"group"`
+	Moderator int                         `json:"Moderator" bson:"moderator"`
+	Action    value.GroupModerationAction `json:"Action" bson:"action"`
+	Reason    string                      `json:"Reason,omitempty" bson:"reason,omitempty"`
+
+	TargetUser *int       `json:"TargetUser,omitempty" bson:"target_user,omitempty"`
+	TargetPost string     `json:"TargetPost,omitempty" bson:"target_post,omitempty"`
+	Snapshot   string     `json:"Snapshot,omitempty" bson:"snapshot,omitempty"` // What a removed post said
+	ExpiresAt  *time.Time `json:"ExpiresAt,omitempty" bson:"expires_at,omitempty"`
+
+	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+}
+
+func NewGroupModerationEntry(groupID, moderator int, action value.GroupModerationAction, reason string) *GroupModerationEntry {
+	return &GroupModerationEntry{
+		ID:        utils.GenerateRandomID(),
+		GroupID:   groupID,
+		Moderator: moderator,
+		Action:    action,
+		Reason:    reason,
+		CreatedAt: time.Now(),
+	}
+}
+
+func (e *GroupModerationEntry) OnUser(userID int) *GroupModerationEntry {
+	e.TargetUser = &userID
+	return e
+}
+
+func (e *GroupModerationEntry) OnPost(postID, snapshot string) *GroupModerationEntry {
+	e.TargetPost = postID
+	e.Snapshot = snapshot
+	return e
+}
+
+func (e *GroupModerationEntry) Until(expiresAt time.Time) *GroupModerationEntry {
+	e.ExpiresAt = &expiresAt
+	return e
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// A provider account that logs into one of ours, a user can have one per provider
+type LinkedIdentity struct {
+	UserID     int        `json:"UserID" bson:"user"`
+	Provider   string     `json:"Provider" bson:"provider"`
+	ProviderID string     `json:"-" bson:"provider_id"`
+	Name       string     `json:"Name" bson:"name"` // Whatever the provider calls them, so they can tell accounts apart
+	LinkedAt   time.Time  `json:"LinkedAt" bson:"linked_at"`
+	LastUsedAt *time.Time `json:"LastUsedAt,omitempty" bson:"last_used_at,omitempty"`
+}
+
+func NewLinkedIdentity(userID int, identity *ProviderIdentity) *LinkedIdentity {
+	return &LinkedIdentity{
+		UserID:     userID,
+		Provider:   identity.Provider,
+		ProviderID: identity.Subject,
+		Name:       identity.Name,
+		LinkedAt:   time.Now(),
+	}
+}
+
+func (i *LinkedIdentity) MarkUsed() {
+	now := time.Now()
+	i.LastUsedAt = &now
+}
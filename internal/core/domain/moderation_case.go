@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+)
+
+// A case gathers every report against the same target until the team deals with it
+type ModerationCase struct {
+	ID int `json:"ID" bson:"_id"`
+
+	// Same meaning as in Report
+	TargetType value.ReportTargetType `json:"TargetType" bson:"target_type"`
+	TargetID   string                 `json:"TargetID,omitempty" bson:"target_id,omitempty"`
+	TargetUser *int                   `json:"TargetUser,omitempty" bson:"target_user,omitempty"`
+
+	Status      value.CaseStatus `json:"Status" bson:"status"`
+	AssignedTo  *int             `json:"AssignedTo,omitempty" bson:"assigned_to,omitempty"`
+	ReportCount int              `json:"ReportCount" bson:"report_count"`
+
+	// Only moderators ever see these
+	Notes []CaseNote `json:"Notes" bson:"notes"`
+
+	Outcome    *value.CaseOutcome `json:"Outcome,omitempty" bson:"outcome,omitempty"`
+	ResolvedBy *int               `json:"ResolvedBy,omitempty" bson:"resolved_by,omitempty"`
+	ResolvedAt *time.Time         `json:"ResolvedAt,omitempty" bson:"resolved_at,omitempty"`
+
+	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+	UpdatedAt time.Time `json:"UpdatedAt" bson:"updated_at"`
+}
+
+type CaseNote struct {
+	Author    int           `json:"Author" bson:"author"`
+	Text      value.LongStr `json:"Text" bson:"text"`
+	CreatedAt time.Time     `json:"CreatedAt" bson:"created_at"`
+}
+
+// Opens a case for whatever the report is about
+func NewModerationCase(report *Report) *ModerationCase {
+	return &ModerationCase{
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		TargetUser: report.TargetUser,
+		Status:     value.CaseStatusOpen,
+		Notes:      make([]CaseNote, 0),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// Whoever picks a case up is reviewing it
+func (c *ModerationCase) Assign(moderator int) error {
+	if c.Status.IsResolved() {
+		return domain_errors.CaseAlreadyResolvedError{}
+	}
+	c.AssignedTo = &moderator
+	c.Status = value.CaseStatusInReview
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *ModerationCase) Unassign() error {
+	if c.Status.IsResolved() {
+		return domain_errors.CaseAlreadyResolvedError{}
+	}
+	c.AssignedTo = nil
+	c.Status = value.CaseStatusOpen
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *ModerationCase) AddNote(author int, text string) error {
+	t, err := value.NewLongStr(text)
+	if err != nil {
+		return err
+	}
+	c.Notes = append(c.Notes, CaseNote{Author: author, Text: *t, CreatedAt: time.Now()})
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+func (c *ModerationCase) Resolve(outcome value.CaseOutcome, moderator int) error {
+	if !outcome.IsValid() {
+		return domain_errors.InvalidCaseOutcomeError{}
+	}
+	if c.Status.IsResolved() {
+		return domain_errors.CaseAlreadyResolvedError{}
+	}
+
+	now := time.Now()
+	c.Status = outcome.Status()
+	c.Outcome = &outcome
+	c.ResolvedBy = &moderator
+	c.ResolvedAt = &now
+	c.UpdatedAt = now
+	return nil
+}
+
+// Back in the queue, with whoever had it still on it
+func (c *ModerationCase) Reopen() error {
+	if !c.Status.IsResolved() {
+		return domain_errors.CaseNotResolvedError{}
+	}
+
+	c.Status = value.CaseStatusOpen
+	if c.AssignedTo != nil {
+		c.Status = value.CaseStatusInReview
+	}
+	c.Outcome = nil
+	c.ResolvedBy = nil
+	c.ResolvedAt = nil
+	c.UpdatedAt = time.Now()
+	return nil
+}
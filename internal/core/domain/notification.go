@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+// A Notification tells a user that someone (the actor) did something that concerns them.
+// Target points at whatever the notification is about, its meaning depends on the type:
+// the anime id for recommendations, the post id for replies and mentions, empty for friendships
+type Notification struct {
+	ID       string                 `json:"ID" bson:"_id"`
+	Receiver int                    `json:"Receiver" bson:"receiver"`
+	Actor    int                    `json:"Actor" bson:"actor"`
+	Type     value.NotificationType `json:"Type" bson:"type"`
+	Target   string                 `json:"Target,omitempty" bson:"target,omitempty"`
+	Read     bool                   `json:"Read" bson:"read"`
+
+	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+}
+
+type NotificationPreference struct {
+	Type    value.NotificationType `json:"Type"`
+	Enabled bool                   `json:"Enabled"`
+}
+
+type UnreadNotifications struct {
+	Total  int                            `json:"Total"`
+	ByType map[value.NotificationType]int `json:"ByType"`
+}
+
+func NewNotification(receiver int, actor int, notificationType value.NotificationType, target string) *Notification {
+	return &Notification{
+		ID:        utils.GenerateRandomID(),
+		Receiver:  receiver,
+		Actor:     actor,
+		Type:      notificationType,
+		Target:    target,
+		Read:      false,
+		CreatedAt: time.Now(),
+	}
+}
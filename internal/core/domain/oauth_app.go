@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+const OAUTH_CLIENT_SECRET_PREFIX = "afa_ocs_"
+const OAUTH_CLIENT_SECRET_LENGTH = 40
+const MAX_OAUTH_APPS = 10
+const MAX_OAUTH_APP_NAME_LENGTH = 64
+const MAX_OAUTH_APP_DESCRIPTION_LENGTH = 500
+const MAX_OAUTH_REDIRECT_URIS = 10
+
+// Third party app users can grant access to their account, the ID doubles as the client_id
+type OAuthApp struct {
+	ID          string `json:"ID" bson:"_id"`
+	OwnerID     int    `json:"OwnerID" bson:"owner"`
+	Name        string `json:"Name" bson:"name"`
+	Description string `json:"Description" bson:"description"`
+	Homepage    string `json:"Homepage,omitempty" bson:"homepage,omitempty"`
+
+	// Exact matches only, no prefixes or wildcards
+	RedirectURIs []string `json:"RedirectURIs" bson:"redirect_uris"`
+
+	// Browser extensions and mobile apps can't keep a secret, they rely on PKCE alone
+	Public     bool   `json:"Public" bson:"public"`
+	SecretHash string `json:"-" bson:"secret_hash,omitempty"`
+
+	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
+}
+
+// Returns the app along with its plain client secret, empty for public apps and only shown once
+func NewOAuthApp(ownerID int, name, description, homepage string, redirectURIs []string, public bool) (*OAuthApp, string) {
+	app := &OAuthApp{
+		ID:           utils.GenerateRandomID(),
+		OwnerID:      ownerID,
+		Name:         name,
+		Description:  description,
+		Homepage:     homepage,
+		RedirectURIs: redirectURIs,
+		Public:       public,
+		CreatedAt:    time.Now(),
+	}
+	if public {
+		return app, ""
+	}
+
+	secret := OAUTH_CLIENT_SECRET_PREFIX + utils.GenerateRandomToken(OAUTH_CLIENT_SECRET_LENGTH)
+	app.SecretHash = utils.HashToken(secret)
+	return app, secret
+}
+
+func (a *OAuthApp) HasRedirectURI(uri string) bool {
+	return slices.Contains(a.RedirectURIs, uri)
+}
+
+// Https anywhere, plain http only back to the same machine, and custom schemes for native apps
+func IsValidRedirectURI(uri string) bool {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme == "" || parsed.Fragment != "" {
+		return false
+	}
+
+	switch parsed.Scheme {
+	case "https":
+		return parsed.Host != ""
+	case "http":
+		host := parsed.Hostname()
+		return host == "localhost" || host == "127.0.0.1" || host == "::1"
+	case "javascript", "data", "file", "vbscript":
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"slices"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+const OAUTH_CODE_LENGTH = 40
+const OAUTH_CODE_LIFETIME = 5 * time.Minute
+
+// PKCE verifiers are 43 to 128 characters long, see RFC 7636
+const PKCE_VERIFIER_MIN_LENGTH = 43
+const PKCE_VERIFIER_MAX_LENGTH = 128
+const PKCE_METHOD_S256 = "S256"
+
+// What an app asks for when it sends the user over, straight from the query string
+type AuthorizationRequest struct {
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	ResponseType        string `json:"response_type"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+}
+
+// What the consent screen needs to show the user
+type OAuthConsent struct {
+	App     *OAuthApp          `json:"App"`
+	Scopes  []value.TokenScope `json:"Scopes"`
+	Granted bool               `json:"Granted"` // The user already allowed all of these, the screen can be skipped
+}
+
+// A user's standing permission for an app, revoking it logs the app out
+type OAuthGrant struct {
+	ID        string             `json:"ID" bson:"_id"`
+	UserID    int                `json:"UserID" bson:"user"`
+	AppID     string             `json:"AppID" bson:"app"`
+	AppName   string             `json:"AppName" bson:"app_name"`
+	Scopes    []value.TokenScope `json:"Scopes" bson:"scopes"`
+	CreatedAt time.Time          `json:"CreatedAt" bson:"created_at"`
+	UpdatedAt time.Time          `json:"UpdatedAt" bson:"updated_at"`
+}
+
+func NewOAuthGrant(userID int, app *OAuthApp, scopes []value.TokenScope) *OAuthGrant {
+	now := time.Now()
+	return &OAuthGrant{
+		ID:        utils.GenerateRandomID(),
+		UserID:    userID,
+		AppID:     app.ID,
+		AppName:   app.Name,
+		Scopes:    scopes,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// Asking again for more scopes adds to what was already allowed
+func (g *OAuthGrant) AddScopes(scopes []value.TokenScope) {
+	for _, scope := range scopes {
+		if !slices.Contains(g.Scopes, scope) {
+			g.Scopes = append(g.Scopes, scope)
+		}
+	}
+	g.UpdatedAt = time.Now()
+}
+
+func (g *OAuthGrant) Covers(scopes []value.TokenScope) bool {
+	for _, scope := range scopes {
+		if !slices.Contains(g.Scopes, scope) {
+			return false
+		}
+	}
+	return true
+}
+
+// One shot code handed to the app through the redirect, only its hash is stored
+type OAuthAuthorizationCode struct {
+	Hash          string             `bson:"_id"`
+	AppID         string             `bson:"app"`
+	UserID        int                `bson:"user"`
+	RedirectURI   string             `bson:"redirect_uri"`
+	Scopes        []value.TokenScope `bson:"scopes"`
+	CodeChallenge string             `bson:"code_challenge"`
+	CreatedAt     time.Time          `bson:"created_at"`
+	ExpiresAt     time.Time          `bson:"expires_at"`
+	UsedAt        *time.Time         `bson:"used_at,omitempty"`
+}
+
+func NewOAuthAuthorizationCode(appID string, userID int, redirectURI string, scopes []value.TokenScope, challenge string) (*OAuthAuthorizationCode, string) {
+	code := utils.GenerateRandomToken(OAUTH_CODE_LENGTH)
+	now := time.Now()
+
+	return &OAuthAuthorizationCode{
+		Hash:          utils.HashToken(code),
+		AppID:         appID,
+		UserID:        userID,
+		RedirectURI:   redirectURI,
+		Scopes:        scopes,
+		CodeChallenge: challenge,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(OAUTH_CODE_LIFETIME),
+	}, code
+}
+
+func (c *OAuthAuthorizationCode) IsExpired() bool {
+	return !time.Now().Before(c.ExpiresAt)
+}
+
+// S256 only, the verifier has to hash to the challenge sent with the authorization request
+func (c *OAuthAuthorizationCode) VerifyChallenge(verifier string) bool {
+	if len(verifier) < PKCE_VERIFIER_MIN_LENGTH || len(verifier) > PKCE_VERIFIER_MAX_LENGTH {
+		return false
+	}
+	return PKCEChallenge(verifier) == c.CodeChallenge
+}
+
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
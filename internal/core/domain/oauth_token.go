@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+// Prefixes tell them apart from personal tokens, and make leaked ones easy to spot
+const OAUTH_ACCESS_TOKEN_PREFIX = "afa_oat_"
+const OAUTH_REFRESH_TOKEN_PREFIX = "afa_ort_"
+const OAUTH_TOKEN_LENGTH = 40
+const OAUTH_ACCESS_TOKEN_LIFETIME = time.Hour
+const OAUTH_REFRESH_TOKEN_LIFETIME = 60 * 24 * time.Hour
+const OAUTH_TOKEN_USE_INTERVAL = time.Minute
+
+// Access and refresh token pair issued to an app, refreshing swaps both but keeps the record
+type OAuthToken struct {
+	ID     string             `json:"ID" bson:"_id"`
+	AppID  string             `json:"AppID" bson:"app"`
+	UserID int                `json:"UserID" bson:"user"`
+	Scopes []value.TokenScope `json:"Scopes" bson:"scopes"`
+	CodeID string             `json:"-" bson:"code,omitempty"` // The code it came from, in case that code gets replayed
+
+	AccessHash      string    `json:"-" bson:"access_hash"`
+	AccessExpiresAt time.Time `json:"-" bson:"access_expires_at"`
+	RefreshHash     string    `json:"-" bson:"refresh_hash"`
+
+	CreatedAt  time.Time  `json:"CreatedAt" bson:"created_at"`
+	LastUsedAt *time.Time `json:"LastUsedAt,omitempty" bson:"last_used_at,omitempty"`
+	ExpiresAt  time.Time  `json:"ExpiresAt" bson:"expires_at"` // When the refresh token runs out
+	RevokedAt  *time.Time `json:"RevokedAt,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// Returns the record along with the plain access and refresh tokens
+func NewOAuthToken(appID string, userID int, scopes []value.TokenScope, codeID string) (*OAuthToken, string, string) {
+	token := &OAuthToken{
+		ID:        utils.GenerateRandomID(),
+		AppID:     appID,
+		UserID:    userID,
+		Scopes:    scopes,
+		CodeID:    codeID,
+		CreatedAt: time.Now(),
+	}
+	access, refresh := token.Rotate()
+	return token, access, refresh
+}
+
+// New pair of tokens, the old ones stop working once this is saved
+func (t *OAuthToken) Rotate() (string, string) {
+	access := OAUTH_ACCESS_TOKEN_PREFIX + utils.GenerateRandomToken(OAUTH_TOKEN_LENGTH)
+	refresh := OAUTH_REFRESH_TOKEN_PREFIX + utils.GenerateRandomToken(OAUTH_TOKEN_LENGTH)
+	now := time.Now()
+
+	t.AccessHash = utils.HashToken(access)
+	t.AccessExpiresAt = now.Add(OAUTH_ACCESS_TOKEN_LIFETIME)
+	t.RefreshHash = utils.HashToken(refresh)
+	t.ExpiresAt = now.Add(OAUTH_REFRESH_TOKEN_LIFETIME)
+	return access, refresh
+}
+
+func (t *OAuthToken) IsAccessValid() bool {
+	return t.RevokedAt == nil && time.Now().Before(t.AccessExpiresAt)
+}
+
+func (t *OAuthToken) IsRefreshValid() bool {
+	return t.RevokedAt == nil && time.Now().Before(t.ExpiresAt)
+}
+
+func (t *OAuthToken) ShouldRecordUse(now time.Time) bool {
+	return t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= OAUTH_TOKEN_USE_INTERVAL
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"slices"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+// The prefix makes leaked tokens easy to spot, and tells them apart from other bearer tokens
+const PERSONAL_TOKEN_PREFIX = "afa_pat_"
+const PERSONAL_TOKEN_LENGTH = 40
+const MAX_PERSONAL_TOKENS = 20
+const MAX_PERSONAL_TOKEN_NAME_LENGTH = 64
+
+// Scripts can hammer the API, last use is only written down this often
+const PERSONAL_TOKEN_USE_INTERVAL = time.Minute
+
+// User generated token for scripts and bots, limited to the scopes it was created with
+type PersonalToken struct {
+	ID     string `json:"ID" bson:"_id"`
+	UserID int    `json:"UserID" bson:"user"`
+	Name   string `json:"Name" bson:"name"`
+
+	// Only the hash is stored, the hint is enough for users to tell their tokens apart
+	Hash string `json:"-" bson:"hash"`
+	Hint string `json:"Hint" bson:"hint"`
+
+	Scopes     []value.TokenScope `json:"Scopes" bson:"scopes"`
+	CreatedAt  time.Time          `json:"CreatedAt" bson:"created_at"`
+	LastUsedAt *time.Time         `json:"LastUsedAt,omitempty" bson:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time         `json:"ExpiresAt,omitempty" bson:"expires_at,omitempty"` // Never expires if not set
+	RevokedAt  *time.Time         `json:"RevokedAt,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// Returns the token along with its plain value, which is only shown once
+func NewPersonalToken(userID int, name string, scopes []value.TokenScope, expiresAt *time.Time) (*PersonalToken, string) {
+	token := PERSONAL_TOKEN_PREFIX + utils.GenerateRandomToken(PERSONAL_TOKEN_LENGTH)
+
+	return &PersonalToken{
+		ID:        utils.GenerateRandomID(),
+		UserID:    userID,
+		Name:      name,
+		Hash:      utils.HashToken(token),
+		Hint:      token[len(token)-4:],
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+		ExpiresAt: expiresAt,
+	}, token
+}
+
+func (t *PersonalToken) IsActive() bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || time.Now().Before(*t.ExpiresAt))
+}
+
+func (t *PersonalToken) HasScope(scope value.TokenScope) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+func (t *PersonalToken) ShouldRecordUse(now time.Time) bool {
+	return t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= PERSONAL_TOKEN_USE_INTERVAL
+}
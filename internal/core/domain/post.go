@@ -21,6 +21,7 @@ type Post struct {
 	Posts         []string  `json:"posts,omitempty" bson:"posts,omitempty"`                    // List of reply ids, used to easily fetch all replies to a post
 	CreatedAt     time.Time `json:"createdAt" bson:"created_at"`
 	CreatedBy     *int      `json:"createdBy,omitempty" bson:"created_by,omitempty"`
+	Held          bool      `json:"held,omitempty" bson:"held,omitempty"` // Caught by the content filter, waiting on a moderator
 }
 
 func NewPost(parentId string, parentType value.PostParentType, text string, createdBy int) *Post {
@@ -42,6 +43,9 @@ func NewReply(replyTo *Post, text string, createdBy int) *Post {
 	newPost.ParentType = value.ParentTypePost // since it's a reply, the parent type is always Post
 	newPost.Text = &text
 	newPost.TopMostPostId = replyTo.TopMostPostId // the top most post id is inherited from the post being replied to
+	if newPost.TopMostPostId == nil {
+		newPost.TopMostPostId = &replyTo.ID // unless that one is the top most post
+	}
 	newPost.CreatedAt = time.Now()
 	newPost.CreatedBy = &createdBy
 	return &newPost
@@ -50,10 +54,24 @@ func NewReply(replyTo *Post, text string, createdBy int) *Post {
 func (p *Post) Delete() {
 	p.Text = nil
 	p.CreatedBy = nil
+	p.Held = false
+}
+
+func (p *Post) Hold() {
+	p.Held = true
+}
+
+func (p *Post) Release() {
+	p.Held = false
+}
+
+// Held posts are only there for their author until they're released
+func (p *Post) IsVisibleTo(viewerId *int) bool {
+	return !p.Held || (viewerId != nil && p.CreatedBy != nil && *p.CreatedBy == *viewerId)
 }
 
 func (p *Post) IsReply() bool {
-	return p.ParentType == value.ParentTypePost && p.TopMostPostId == nil
+	return p.ParentType == value.ParentTypePost
 }
 
 func (p *Post) IsDeleted() bool {
@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+)
+
+// What a viewer gets to know about a user's presence
+type UserPresence struct {
+	UserID    int                  `json:"user_id"`
+	Status    value.ActivityStatus `json:"is_online"`           // Named like this for older clients
+	Connected bool                 `json:"connected"`           // Has a live presence connection
+	LastSeen  *time.Time           `json:"last_seen,omitempty"` // Only when offline and known
+	Hidden    bool                 `json:"hidden"`              // The user doesn't share their presence with the viewer
+}
@@ -0,0 +1,11 @@
+package domain
+
+// Whoever an external login provider says just logged in
+type ProviderIdentity struct {
+	Provider      string
+	Subject       string // Stable ID on the provider's side, never the email
+	Email         string
+	EmailVerified bool
+	Name          string
+	AvatarURL     string
+}
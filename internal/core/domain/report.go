@@ -6,19 +6,49 @@ import (
 	"github.com/afuradanime/backend/internal/core/domain/value"
 )
 
-type UserReport struct {
-	ID         int                `json:"ID" bson:"_id"`
-	Reason     value.ReportReason `json:"Reason" bson:"reason"`
-	TargetUser int                `json:"TargetUser" bson:"target_user"`
+type Report struct {
+	ID     int                `json:"ID" bson:"_id"`
+	Reason value.ReportReason `json:"Reason" bson:"reason"`
+
+	// Users are identified by TargetUser alone, everything else by TargetID
+	TargetType value.ReportTargetType `json:"TargetType" bson:"target_type"`
+	TargetID   string                 `json:"TargetID,omitempty" bson:"target_id,omitempty"`
+	TargetUser *int                   `json:"TargetUser,omitempty" bson:"target_user,omitempty"` // Whoever is behind the target, groups have nobody in particular
+
+	// What the target looked like when it was reported, it may be edited or gone by the time someone looks
+	Snapshot string `json:"Snapshot,omitempty" bson:"snapshot,omitempty"`
+
+	CaseID int `json:"CaseID,omitempty" bson:"case,omitempty"` // Older reports were never filed into a case
 
 	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
 	CreatedBy int       `json:"CreatedBy" bson:"created_by"`
 }
 
-func NewUserReport(reason value.ReportReason, targetUser, reporter int) *UserReport {
-	return &UserReport{
+func NewUserReport(reason value.ReportReason, targetUser, reporter int, snapshot string) *Report {
+	return &Report{
+		Reason:     reason,
+		TargetType: value.ReportTargetUser,
+		TargetUser: &targetUser,
+		Snapshot:   snapshot,
+		CreatedAt:  time.Now(),
+		CreatedBy:  reporter,
+	}
+}
+
+func NewContentReport(
+	reason value.ReportReason,
+	targetType value.ReportTargetType,
+	targetID string,
+	targetUser *int,
+	snapshot string,
+	reporter int,
+) *Report {
+	return &Report{
 		Reason:     reason,
+		TargetType: targetType,
+		TargetID:   targetID,
 		TargetUser: targetUser,
+		Snapshot:   snapshot,
 		CreatedAt:  time.Now(),
 		CreatedBy:  reporter,
 	}
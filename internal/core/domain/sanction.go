@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+)
+
+// Sanctions issued by the platform itself, like after piling up reports
+const SYSTEM_USER_ID = 0
+
+const SANCTION_MAX_DURATION = 365 * 24 * time.Hour
+
+type Sanction struct {
+	ID     int                `json:"ID" bson:"_id"`
+	UserID int                `json:"UserID" bson:"user"`
+	Type   value.SanctionType `json:"Type" bson:"type"`
+	Reason value.LongStr      `json:"Reason" bson:"reason"`
+
+	IssuedBy  int        `json:"IssuedBy" bson:"issued_by"`
+	StartsAt  time.Time  `json:"StartsAt" bson:"starts_at"`
+	ExpiresAt *time.Time `json:"ExpiresAt,omitempty" bson:"expires_at,omitempty"` // Permanent if missing
+
+	// Lifted early by a moderator, an accepted appeal, or the expiry job once it runs out
+	LiftedAt *time.Time `json:"LiftedAt,omitempty" bson:"lifted_at,omitempty"`
+	LiftedBy *int       `json:"LiftedBy,omitempty" bson:"lifted_by,omitempty"`
+
+	Appeal *SanctionAppeal `json:"Appeal,omitempty" bson:"appeal,omitempty"`
+}
+
+// One shot per sanction, whatever the moderators say goes
+type SanctionAppeal struct {
+	Text       value.LongStr      `json:"Text" bson:"text"`
+	Status     value.AppealStatus `json:"Status" bson:"status"`
+	CreatedAt  time.Time          `json:"CreatedAt" bson:"created_at"`
+	ReviewedBy *int               `json:"ReviewedBy,omitempty" bson:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time         `json:"ReviewedAt,omitempty" bson:"reviewed_at,omitempty"`
+	Response   string             `json:"Response,omitempty" bson:"response,omitempty"`
+}
+
+// A zero duration never runs out
+func NewSanction(userID int, sanctionType value.SanctionType, reason string, issuer int, duration time.Duration) (*Sanction, error) {
+	if !sanctionType.IsValid() {
+		return nil, domain_errors.InvalidSanctionTypeError{}
+	}
+	if duration < 0 || duration > SANCTION_MAX_DURATION {
+		return nil, domain_errors.InvalidSanctionDurationError{}
+	}
+
+	reasonVal, err := value.NewLongStr(reason)
+	if err != nil {
+		return nil, err
+	}
+
+	sanction := &Sanction{
+		UserID:   userID,
+		Type:     sanctionType,
+		Reason:   *reasonVal,
+		IssuedBy: issuer,
+		StartsAt: time.Now(),
+	}
+	if duration > 0 {
+		expiresAt := sanction.StartsAt.Add(duration)
+		sanction.ExpiresAt = &expiresAt
+	}
+
+	return sanction, nil
+}
+
+func (s *Sanction) IsActive() bool {
+	if s.LiftedAt != nil {
+		return false
+	}
+	return s.ExpiresAt == nil || time.Now().Before(*s.ExpiresAt)
+}
+
+func (s *Sanction) Lift(by int) error {
+	if s.LiftedAt != nil {
+		return domain_errors.SanctionNotActiveError{}
+	}
+	now := time.Now()
+	s.LiftedAt = &now
+	s.LiftedBy = &by
+	return nil
+}
+
+func (s *Sanction) FileAppeal(text string) error {
+	if !s.IsActive() {
+		return domain_errors.SanctionNotActiveError{}
+	}
+	if s.Appeal != nil {
+		return domain_errors.AlreadyAppealedError{}
+	}
+
+	textVal, err := value.NewLongStr(text)
+	if err != nil {
+		return err
+	}
+
+	s.Appeal = &SanctionAppeal{
+		Text:      *textVal,
+		Status:    value.AppealPending,
+		CreatedAt: time.Now(),
+	}
+	return nil
+}
+
+// An accepted appeal lifts the sanction on the spot
+func (s *Sanction) ReviewAppeal(accepted bool, reviewer int, response string) error {
+	if s.Appeal == nil || s.Appeal.Status != value.AppealPending {
+		return domain_errors.AppealNotPendingError{}
+	}
+
+	now := time.Now()
+	s.Appeal.ReviewedBy = &reviewer
+	s.Appeal.ReviewedAt = &now
+	s.Appeal.Response = response
+
+	if !accepted {
+		s.Appeal.Status = value.AppealRejected
+		return nil
+	}
+
+	s.Appeal.Status = value.AppealAccepted
+	if s.LiftedAt == nil {
+		return s.Lift(reviewer)
+	}
+	return nil
+}
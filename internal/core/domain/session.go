@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+const REFRESH_TOKEN_LENGTH = 48
+
+// A rotated refresh token showing up again this soon is most likely two tabs refreshing at once,
+// after that it's treated as stolen and the whole session goes
+const REFRESH_REUSE_GRACE = 30 * time.Second
+
+// One logged in device, access tokens are short lived and get renewed through the refresh token,
+// revoking the session stops both
+type Session struct {
+	ID     string `json:"ID" bson:"_id"`
+	UserID int    `json:"UserID" bson:"user"`
+
+	// Only hashes are stored, the previous one is kept around to notice reuse
+	RefreshHash  string     `json:"-" bson:"refresh_hash"`
+	PreviousHash string     `json:"-" bson:"previous_hash,omitempty"`
+	RotatedAt    *time.Time `json:"-" bson:"rotated_at,omitempty"`
+
+	UserAgent  string    `json:"UserAgent" bson:"user_agent"`
+	IP         string    `json:"IP" bson:"ip"`
+	CreatedAt  time.Time `json:"CreatedAt" bson:"created_at"`
+	LastUsedAt time.Time `json:"LastUsedAt" bson:"last_used_at"`
+	ExpiresAt  time.Time `json:"ExpiresAt" bson:"expires_at"`
+
+	RevokedAt *time.Time `json:"RevokedAt,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// Returns the session along with the plain refresh token, which is never seen again
+func NewSession(userID int, userAgent, ip string, lifetime time.Duration) (*Session, string) {
+	token := utils.GenerateRandomToken(REFRESH_TOKEN_LENGTH)
+	now := time.Now()
+
+	return &Session{
+		ID:          utils.GenerateRandomID(),
+		UserID:      userID,
+		RefreshHash: utils.HashToken(token),
+		UserAgent:   userAgent,
+		IP:          ip,
+		CreatedAt:   now,
+		LastUsedAt:  now,
+		ExpiresAt:   now.Add(lifetime),
+	}, token
+}
+
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// Swaps the refresh token for a new one and pushes the expiry forward
+func (s *Session) Rotate(lifetime time.Duration) string {
+	token := utils.GenerateRandomToken(REFRESH_TOKEN_LENGTH)
+	now := time.Now()
+
+	s.PreviousHash = s.RefreshHash
+	s.RefreshHash = utils.HashToken(token)
+	s.RotatedAt = &now
+	s.LastUsedAt = now
+	s.ExpiresAt = now.Add(lifetime)
+	return token
+}
+
+func (s *Session) Revoke() {
+	if s.RevokedAt != nil {
+		return
+	}
+	now := time.Now()
+	s.RevokedAt = &now
+}
+
+// Whether an old refresh token coming back is still within the benefit of the doubt
+func (s *Session) InReuseGrace() bool {
+	return s.RotatedAt != nil && time.Since(*s.RotatedAt) < REFRESH_REUSE_GRACE
+}
@@ -0,0 +1,318 @@
+package domain
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	SigningAlgorithmHS256 = "HS256" // The old shared secret, can't be published
+	SigningAlgorithmRS256 = "RS256"
+	SigningAlgorithmEdDSA = "EdDSA"
+)
+
+const RSA_KEY_BITS = 2048
+
+// New keys are published this long before they start signing, so verifiers caching
+// the key set already know them by the time the first token shows up
+const SIGNING_KEY_ACTIVATION_DELAY = 10 * time.Minute
+
+func IsAsymmetricAlgorithm(algorithm string) bool {
+	return algorithm == SigningAlgorithmRS256 || algorithm == SigningAlgorithmEdDSA
+}
+
+// A key pair tokens get signed with, identified in the token header by its kid.
+// Retired keys stop signing but keep verifying until the tokens they signed have expired
+type SigningKey struct {
+	ID         string     `json:"ID" bson:"_id"` // kid
+	Algorithm  string     `json:"Algorithm" bson:"algorithm"`
+	PrivateKey string     `json:"-" bson:"private_key"` // PKCS8, encrypted
+	PublicKey  []byte     `json:"-" bson:"public_key"`  // PKIX
+	CreatedAt  time.Time  `json:"CreatedAt" bson:"created_at"`
+	RetiredAt  *time.Time `json:"RetiredAt,omitempty" bson:"retired_at,omitempty"`
+	ExpiresAt  *time.Time `json:"ExpiresAt,omitempty" bson:"expires_at,omitempty"`
+}
+
+func NewSigningKey(algorithm string) (*SigningKey, error) {
+	var private, public any
+	switch algorithm {
+	case SigningAlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, RSA_KEY_BITS)
+		if err != nil {
+			return nil, err
+		}
+		private, public = key, &key.PublicKey
+	case SigningAlgorithmEdDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private, public = priv, pub
+	default:
+		return nil, errors.New("Unsupported signing algorithm " + algorithm)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := utils.EncryptToString(privateDER)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		ID:         utils.GenerateRandomID(),
+		Algorithm:  algorithm,
+		PrivateKey: encrypted,
+		PublicKey:  publicDER,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// Keeps signing until its replacement kicks in, then verifies for a while longer
+func (k *SigningKey) Retire(verifyFor time.Duration) {
+	if k.RetiredAt != nil {
+		return
+	}
+	retiredAt := time.Now().Add(SIGNING_KEY_ACTIVATION_DELAY)
+	expiresAt := retiredAt.Add(verifyFor)
+	k.RetiredAt = &retiredAt
+	k.ExpiresAt = &expiresAt
+}
+
+// Scheduled to retire, it may still be signing for a bit
+func (k *SigningKey) IsRetiring() bool {
+	return k.RetiredAt != nil
+}
+
+func (k *SigningKey) IsExpired() bool {
+	return k.ExpiresAt != nil && !time.Now().Before(*k.ExpiresAt)
+}
+
+// A single key of a JSON Web Key Set, see RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"` // OKP
+	X   string `json:"x,omitempty"`   // OKP
+	N   string `json:"n,omitempty"`   // RSA
+	E   string `json:"e,omitempty"`   // RSA
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+type loadedKey struct {
+	algorithm string
+	method    jwt.SigningMethod
+	private   any
+	public    any
+	createdAt time.Time
+	retiredAt *time.Time
+}
+
+// Signing is for keys that are published long enough and not retired yet
+func (k *loadedKey) canSign(now time.Time) bool {
+	return !now.Before(k.createdAt.Add(SIGNING_KEY_ACTIVATION_DELAY)) && (k.retiredAt == nil || now.Before(*k.retiredAt))
+}
+
+func (k *SigningKey) load() (*loadedKey, error) {
+	privateDER, err := utils.DecryptFromString(k.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	private, err := x509.ParsePKCS8PrivateKey(privateDER)
+	if err != nil {
+		return nil, err
+	}
+	public, err := x509.ParsePKIXPublicKey(k.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := &loadedKey{
+		algorithm: k.Algorithm,
+		private:   private,
+		public:    public,
+		createdAt: k.CreatedAt,
+		retiredAt: k.RetiredAt,
+	}
+
+	switch k.Algorithm {
+	case SigningAlgorithmRS256:
+		loaded.method = jwt.SigningMethodRS256
+		_, okPriv := private.(*rsa.PrivateKey)
+		_, okPub := public.(*rsa.PublicKey)
+		if !okPriv || !okPub {
+			return nil, errors.New("Signing key " + k.ID + " is not an RSA key")
+		}
+	case SigningAlgorithmEdDSA:
+		loaded.method = jwt.SigningMethodEdDSA
+		_, okPriv := private.(ed25519.PrivateKey)
+		_, okPub := public.(ed25519.PublicKey)
+		if !okPriv || !okPub {
+			return nil, errors.New("Signing key " + k.ID + " is not an Ed25519 key")
+		}
+	default:
+		return nil, errors.New("Unsupported signing algorithm " + k.Algorithm)
+	}
+
+	return loaded, nil
+}
+
+func (k *loadedKey) jwk(kid string) JWK {
+	key := JWK{Kid: kid, Use: "sig", Alg: k.algorithm}
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		key.Kty = "RSA"
+		key.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		key.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		key.Kty = "OKP"
+		key.Crv = "Ed25519"
+		key.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+	return key
+}
+
+// Every key tokens can currently be signed or verified with, shared by whoever issues and checks tokens
+type KeyRing struct {
+	mu   sync.RWMutex
+	keys map[string]*loadedKey
+}
+
+func NewKeyRing() *KeyRing {
+	return &KeyRing{keys: make(map[string]*loadedKey)}
+}
+
+// Swaps the whole ring for the given keys
+func (r *KeyRing) Load(keys []*SigningKey) error {
+	loaded := make(map[string]*loadedKey, len(keys))
+	for _, key := range keys {
+		if key.IsExpired() {
+			continue
+		}
+		parsed, err := key.load()
+		if err != nil {
+			return err
+		}
+		loaded[key.ID] = parsed
+	}
+
+	if signingKey(loaded) == "" {
+		return errors.New("No signing key available")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = loaded
+	return nil
+}
+
+// The newest key allowed to sign. When nothing is published long enough yet, like right after
+// the very first key is made, the one that's been out the longest does the job
+func signingKey(keys map[string]*loadedKey) string {
+	now := time.Now()
+	current, fallback := "", ""
+	for id, key := range keys {
+		if key.retiredAt != nil && !now.Before(*key.retiredAt) {
+			continue
+		}
+		if fallback == "" || key.createdAt.Before(keys[fallback].createdAt) {
+			fallback = id
+		}
+		if key.canSign(now) && (current == "" || key.createdAt.After(keys[current].createdAt)) {
+			current = id
+		}
+	}
+	if current == "" {
+		return fallback
+	}
+	return current
+}
+
+// The old single shared secret, nothing to rotate or publish
+func (r *KeyRing) UseSecret(kid, secret string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys = map[string]*loadedKey{
+		kid: {
+			algorithm: SigningAlgorithmHS256,
+			method:    jwt.SigningMethodHS256,
+			private:   []byte(secret),
+			public:    []byte(secret),
+			createdAt: time.Now().Add(-SIGNING_KEY_ACTIVATION_DELAY),
+		},
+	}
+}
+
+func (r *KeyRing) Sign(claims jwt.Claims) (string, error) {
+	r.mu.RLock()
+	kid := signingKey(r.keys)
+	key, ok := r.keys[kid]
+	r.mu.RUnlock()
+	if !ok {
+		return "", errors.New("No signing key available")
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(key.private)
+}
+
+// Picks the verification key by kid, a token can't pick its own algorithm
+func (r *KeyRing) Keyfunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	r.mu.RLock()
+	key, ok := r.keys[kid]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+	if t.Method.Alg() != key.method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return key.public, nil
+}
+
+// The public half of every asymmetric key still verifying, oldest first
+func (r *KeyRing) JWKS() JWKSet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := make([]string, 0, len(r.keys))
+	for id, key := range r.keys {
+		if IsAsymmetricAlgorithm(key.algorithm) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return r.keys[ids[i]].createdAt.Before(r.keys[ids[j]].createdAt)
+	})
+
+	set := JWKSet{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		set.Keys = append(set.Keys, r.keys[id].jwk(id))
+	}
+	return set
+}
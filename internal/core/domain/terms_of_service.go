@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"strings"
+	"time"
+
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+)
+
+const (
+	TERMS_CONTENT_MAX_LENGTH = 100000
+	TERMS_SUMMARY_MAX_LENGTH = 500
+)
+
+// One published version of the terms of service, never edited once out, a change is a new version
+type TermsOfService struct {
+	Version int    `json:"Version" bson:"_id"`
+	Content string `json:"Content" bson:"content"`
+	Summary string `json:"Summary,omitempty" bson:"summary,omitempty"` // What changed since the last version
+
+	PublishedBy int       `json:"PublishedBy" bson:"published_by"`
+	PublishedAt time.Time `json:"PublishedAt" bson:"published_at"`
+}
+
+func NewTermsOfService(version int, content, summary string, publishedBy int) (*TermsOfService, error) {
+	content = strings.TrimSpace(content)
+	summary = strings.TrimSpace(summary)
+
+	if content == "" || len(content) > TERMS_CONTENT_MAX_LENGTH {
+		return nil, domain_errors.InvalidTermsContentError{MaxLength: TERMS_CONTENT_MAX_LENGTH}
+	}
+	if len(summary) > TERMS_SUMMARY_MAX_LENGTH {
+		return nil, domain_errors.TermsSummaryTooLongError{MaxLength: TERMS_SUMMARY_MAX_LENGTH}
+	}
+
+	return &TermsOfService{
+		Version:     version,
+		Content:     content,
+		Summary:     summary,
+		PublishedBy: publishedBy,
+		PublishedAt: time.Now(),
+	}, nil
+}
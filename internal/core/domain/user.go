@@ -3,6 +3,7 @@ package domain
 import (
 	"log"
 	"slices"
+	"strconv"
 	"time"
 
 	value "github.com/afuradanime/backend/internal/core/domain/value"
@@ -12,6 +13,9 @@ import (
 
 const MAX_SOCIALS = 5
 
+// Users get this long to change their mind before their data is gone for good
+const ACCOUNT_DELETION_GRACE_PERIOD = 14 * 24 * time.Hour
+
 type User struct {
 	ID int `json:"ID" bson:"_id"`
 
@@ -32,7 +36,17 @@ type User struct {
 	PrivateAnimeList		bool `json:"PrivateAnimeList" bson:"private_list"`
 	CanPost               	bool `json:"CanPost" bson:"can_post"`
 	CanTranslate          	bool `json:"CanTranslate" bson:"can_translate"`
-	AcceptedTermsOfService	bool `json:"AcceptedTermsOfService" bson:"accepted_terms"`
+
+	// Which terms of service version the user last agreed to, 0 means none yet
+	AcceptedTermsVersion	int        `json:"AcceptedTermsVersion" bson:"accepted_terms_version"`
+	AcceptedTermsAt		*time.Time `json:"AcceptedTermsAt,omitempty" bson:"accepted_terms_at,omitempty"`
+
+	// Who can see if the user is online and when they were last seen
+	PresenceVisibility value.PresenceVisibility `json:"PresenceVisibility" bson:"presence_visibility"`
+	LastSeen           time.Time                `json:"-" bson:"last_seen"` // Only exposed through the activity endpoints
+
+	// Notification types the user opted out of, everything else is delivered
+	DisabledNotifications []value.NotificationType `json:"DisabledNotifications" bson:"disabled_notifications"`
 
 	// Authentication / Authorization
 	Provider   string           `json:"Provider" bson:"provider"`
@@ -43,6 +57,10 @@ type User struct {
 
 	CreatedAt time.Time `json:"CreatedAt" bson:"created_at"`
 	LastLogin time.Time `json:"LastLogin" bson:"last_login"`
+
+	// Deleted accounts stay around anonymised, other data still points at their ID
+	DeletionRequestedAt *time.Time `json:"DeletionRequestedAt,omitempty" bson:"deletion_requested_at,omitempty"`
+	DeletedAt           *time.Time `json:"DeletedAt,omitempty" bson:"deleted_at,omitempty"`
 }
 
 func NewUser(username string, email string) (*User, error) {
@@ -74,8 +92,8 @@ func NewUser(username string, email string) (*User, error) {
 		PrivateAnimeList: 		false,
 		CanPost:               	true,
 		CanTranslate:          	true,
-		AcceptedTermsOfService: false,
 		Badges:                	make([]value.UserBadges, 0),
+		DisabledNotifications: 	make([]value.NotificationType, 0),
 		CreatedAt:             	time.Now(),
 	}, nil
 }
@@ -95,6 +113,19 @@ func (u *User) UpdateEmail(email string) error {
 	return nil
 }
 
+// The email as the user gave it, it's only ever stored encrypted
+func (u *User) DecryptedEmail() (string, error) {
+	if u.Email == "" {
+		return "", nil
+	}
+
+	email, err := utils.DecryptFromString(u.Email)
+	if err != nil {
+		return "", err
+	}
+	return string(email), nil
+}
+
 func (u *User) UpdateProviderInformation(provider, id string) error {
 
 	u.Provider = provider
@@ -188,6 +219,35 @@ func (u *User) UpdateListPrivacy(isPrivate bool) {
 	u.PrivateAnimeList = isPrivate
 }
 
+func (u *User) UpdatePresenceVisibility(visibility value.PresenceVisibility) error {
+	if !visibility.IsValid() {
+		return domain_errors.InvalidPresenceVisibilityError{}
+	}
+
+	u.PresenceVisibility = visibility
+	return nil
+}
+
+func (u *User) WantsNotification(notificationType value.NotificationType) bool {
+	return !slices.Contains(u.DisabledNotifications, notificationType)
+}
+
+func (u *User) UpdateNotificationPreference(notificationType value.NotificationType, enabled bool) error {
+
+	if !notificationType.IsValid() {
+		return domain_errors.InvalidNotificationTypeError{}
+	}
+
+	index := slices.Index(u.DisabledNotifications, notificationType)
+	if enabled && index != -1 {
+		u.DisabledNotifications = slices.Delete(u.DisabledNotifications, index, index+1)
+	} else if !enabled && index == -1 {
+		u.DisabledNotifications = append(u.DisabledNotifications, notificationType)
+	}
+
+	return nil
+}
+
 func (u *User) RewardBadge(badge value.UserBadges) {
 
 	if slices.Contains(u.Badges, badge) {
@@ -226,6 +286,82 @@ func (u *User) RestrictAccesses(canPost, canTranslate bool) {
 	u.CanTranslate = canTranslate
 }
 
-func (u *User) AcceptTermsOfService() {
-	u.AcceptedTermsOfService = true
-}
\ No newline at end of file
+func (u *User) AcceptTermsOfService(version int) {
+	now := time.Now()
+	u.AcceptedTermsVersion = version
+	u.AcceptedTermsAt = &now
+}
+
+// Nothing published yet means there's nothing to agree to
+func (u *User) HasAcceptedTerms(currentVersion int) bool {
+	return currentVersion == 0 || u.AcceptedTermsVersion >= currentVersion
+}
+
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+func (u *User) IsPendingDeletion() bool {
+	return u.DeletionRequestedAt != nil && u.DeletedAt == nil
+}
+
+// When the grace period runs out, nil if no deletion was requested
+func (u *User) DeletionDueAt() *time.Time {
+	if !u.IsPendingDeletion() {
+		return nil
+	}
+	due := u.DeletionRequestedAt.Add(ACCOUNT_DELETION_GRACE_PERIOD)
+	return &due
+}
+
+func (u *User) RequestDeletion() error {
+	if u.IsDeleted() {
+		return domain_errors.AccountDeletedError{}
+	}
+	if u.IsPendingDeletion() {
+		return domain_errors.AccountDeletionAlreadyRequestedError{}
+	}
+
+	now := time.Now()
+	u.DeletionRequestedAt = &now
+	return nil
+}
+
+func (u *User) CancelDeletion() error {
+	if !u.IsPendingDeletion() {
+		return domain_errors.NoAccountDeletionRequestedError{}
+	}
+
+	u.DeletionRequestedAt = nil
+	return nil
+}
+
+// Strips everything that could identify the person, the ID stays so posts and logs still line up
+func (u *User) Anonymise() {
+	now := time.Now()
+
+	u.Email = ""
+	u.Username = value.Username("deleted#" + strconv.Itoa(u.ID)) // Nobody can take it first, the username rules don't allow #
+	u.AvatarURL = ""
+	u.Location = ""
+	u.Pronouns = ""
+	u.Birthday = time.Time{}
+	u.Socials = make([]value.URL, 0)
+	u.Provider = ""
+	u.ProviderID = ""
+	u.Badges = make([]value.UserBadges, 0)
+	u.Roles = []value.UserRole{value.UserRoleUser}
+	u.DisabledNotifications = make([]value.NotificationType, 0)
+	u.LastSeen = time.Time{}
+
+	// Nothing can reach or act as this account anymore
+	u.AllowsFriendRequests = false
+	u.AllowsRecommendations = false
+	u.PrivateAnimeList = true
+	u.CanPost = false
+	u.CanTranslate = false
+	u.PresenceVisibility = value.PresenceVisibleToNobody
+
+	u.DeletionRequestedAt = nil
+	u.DeletedAt = &now
+}
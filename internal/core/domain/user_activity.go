@@ -13,11 +13,28 @@ const ACTIVITY_TIMEOUT = 10
 type UserActivity struct {
 	status value.ActivityStatus
 	timer  time.Time
+
+	// Live presence connections, while there's at least one the status is whatever
+	// the client says it is instead of being guessed from HTTP requests
+	connections int
+}
+
+// What everyone else gets to see, invisible users look offline
+func (u UserActivity) visibleStatus() value.ActivityStatus {
+	if u.status == value.Invisible {
+		return value.Offline
+	}
+	return u.status
 }
 
 type ActivityTracker struct {
 	mu      sync.RWMutex
 	entries map[int]UserActivity
+
+	// Called whenever a user's visible status changes, outside of the lock
+	onStatusChange func(userID int, status value.ActivityStatus)
+	// Called when a visible user is dropped from the tracker, outside of the lock
+	onEvict func(userID int, lastSeen time.Time)
 }
 
 func NewActivityTracker() *ActivityTracker {
@@ -29,13 +46,111 @@ func NewActivityTracker() *ActivityTracker {
 	return &tracker
 }
 
+func (a *ActivityTracker) OnStatusChange(fn func(userID int, status value.ActivityStatus)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onStatusChange = fn
+}
+
+// Invisible users are never reported, a "last seen" would give them away
+func (a *ActivityTracker) OnEvict(fn func(userID int, lastSeen time.Time)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onEvict = fn
+}
+
+// Updates the entry of a user and lets the listener know if what others see changed
+func (a *ActivityTracker) update(userID int, change func(entry UserActivity, existed bool) (UserActivity, bool)) {
+	a.mu.Lock()
+	previous, existed := a.entries[userID]
+
+	before := value.Offline
+	if existed {
+		before = previous.visibleStatus()
+	}
+
+	after := value.Offline
+	evicted := false
+	if entry, keep := change(previous, existed); keep {
+		a.entries[userID] = entry
+		after = entry.visibleStatus()
+	} else if existed {
+		delete(a.entries, userID)
+		evicted = before != value.Offline
+	}
+
+	notify, evict := a.onStatusChange, a.onEvict
+	a.mu.Unlock()
+
+	if notify != nil && before != after {
+		notify(userID, after)
+	}
+	if evict != nil && evicted {
+		evict(userID, time.Now())
+	}
+}
+
+// Activity seen through a regular HTTP request
 func (a *ActivityTracker) RecordActivity(userID int, status value.ActivityStatus) {
+	a.update(userID, func(entry UserActivity, existed bool) (UserActivity, bool) {
+		// Connected clients report their own status, a request doesn't make an invisible user online
+		if existed && entry.connections > 0 {
+			entry.timer = time.Now()
+			return entry, true
+		}
+
+		return UserActivity{
+			status: status,
+			timer:  time.Now(),
+		}, true
+	})
+}
+
+// A presence connection was opened
+func (a *ActivityTracker) Connect(userID int, status value.ActivityStatus) {
+	a.update(userID, func(entry UserActivity, existed bool) (UserActivity, bool) {
+		entry.connections++
+		entry.status = status
+		entry.timer = time.Now()
+		return entry, true
+	})
+}
+
+// A presence connection was closed, once the last one is gone the user is offline
+func (a *ActivityTracker) Disconnect(userID int) {
+	a.update(userID, func(entry UserActivity, existed bool) (UserActivity, bool) {
+		if !existed {
+			return entry, false
+		}
+
+		entry.connections--
+		if entry.connections <= 0 {
+			return entry, false
+		}
+		return entry, true
+	})
+}
+
+// Explicit status sent by a connected client
+func (a *ActivityTracker) SetStatus(userID int, status value.ActivityStatus) {
+	a.update(userID, func(entry UserActivity, existed bool) (UserActivity, bool) {
+		if !existed {
+			return entry, false
+		}
+
+		entry.status = status
+		entry.timer = time.Now()
+		return entry, true
+	})
+}
+
+func (a *ActivityTracker) Heartbeat(userID int) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	a.entries[userID] = UserActivity{
-		status: status,
-		timer:  time.Now(),
+	if entry, ok := a.entries[userID]; ok {
+		entry.timer = time.Now()
+		a.entries[userID] = entry
 	}
 }
 
@@ -46,7 +161,15 @@ func (a *ActivityTracker) IsActive(userID int) int {
 	if !ok {
 		return 0
 	}
-	return int(last.status)
+	return int(last.visibleStatus())
+}
+
+// Whether the user has a live presence connection, invisible users never do as far as others know
+func (a *ActivityTracker) IsConnected(userID int) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	last, ok := a.entries[userID]
+	return ok && last.connections > 0 && last.status != value.Invisible
 }
 
 func (a *ActivityTracker) StartCleanup(interval time.Duration) {
@@ -54,20 +177,45 @@ func (a *ActivityTracker) StartCleanup(interval time.Duration) {
 		ticker := time.NewTicker(interval)
 		defer ticker.Stop()
 		for range ticker.C {
+			changes := map[int]value.ActivityStatus{}
+			evictions := map[int]time.Time{}
+
 			a.mu.Lock()
 			for userID, last := range a.entries {
 
+				// Connections close themselves when heartbeats stop, nothing to guess here
+				if last.connections > 0 {
+					continue
+				}
+
 				if time.Since(last.timer) >= (ACTIVITY_TIMEOUT+ONLINE_TIMEOUT)*time.Minute {
 					delete(a.entries, userID)
+					if last.visibleStatus() != value.Offline {
+						changes[userID] = value.Offline
+						evictions[userID] = last.timer
+					}
 				} else if time.Since(last.timer) >= ONLINE_TIMEOUT*time.Minute && last.status == value.Online {
-					
+
 					a.entries[userID] = UserActivity{
 						status: value.Idle,
 						timer:  last.timer,
 					}
+					changes[userID] = value.Idle
 				}
 			}
+			notify, evict := a.onStatusChange, a.onEvict
 			a.mu.Unlock()
+
+			if notify != nil {
+				for userID, status := range changes {
+					notify(userID, status)
+				}
+			}
+			if evict != nil {
+				for userID, lastSeen := range evictions {
+					evict(userID, lastSeen)
+				}
+			}
 		}
 	}()
 }
@@ -77,7 +225,10 @@ func (a *ActivityTracker) GetActiveUsers() []int {
 	defer a.mu.RUnlock()
 	users := make([]int, 0, len(a.entries))
 	for userID, last := range a.entries {
-		if time.Since(last.timer) < ACTIVITY_TIMEOUT*time.Minute {
+		if last.visibleStatus() == value.Offline {
+			continue
+		}
+		if last.connections > 0 || time.Since(last.timer) < ACTIVITY_TIMEOUT*time.Minute {
 			users = append(users, userID)
 		}
 	}
@@ -6,4 +6,10 @@ const (
 	Offline ActivityStatus = iota + 1
 	Online
 	Idle
+	Invisible // Connected, but shown as offline to everyone else
 )
+
+// Statuses a client can pick for itself
+func (s ActivityStatus) IsSelectable() bool {
+	return s == Online || s == Idle || s == Invisible
+}
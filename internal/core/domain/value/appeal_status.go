@@ -0,0 +1,9 @@
+package value
+
+type AppealStatus uint8
+
+const (
+	AppealPending AppealStatus = iota
+	AppealAccepted
+	AppealRejected
+)
@@ -0,0 +1,48 @@
+package value
+
+type AuditAction uint8
+
+const (
+	AuditTranslationAccepted AuditAction = iota + 1
+	AuditTranslationRejected
+	AuditSanctionIssued
+	AuditSanctionLifted
+	AuditAppealAccepted
+	AuditAppealRejected
+	AuditReportDeleted
+	AuditBadgeRewarded
+	AuditGroupModeratorAdded
+	AuditGroupModeratorRemoved
+	AuditHeldPostReleased
+	AuditHeldPostRejected
+	AuditTermsPublished
+	AuditPostRemoved
+	AuditCaseResolved
+	AuditFilterRuleCreated
+	AuditFilterRuleUpdated
+	AuditFilterRuleDeleted
+	AuditGroupDeleted
+)
+
+func (a AuditAction) IsValid() bool {
+	return a >= AuditTranslationAccepted && a <= AuditGroupDeleted
+}
+
+// What an audit entry's target ID points at
+type AuditTargetType uint8
+
+const (
+	AuditTargetUser AuditTargetType = iota + 1
+	AuditTargetTranslation
+	AuditTargetSanction
+	AuditTargetReport
+	AuditTargetGroup
+	AuditTargetPost
+	AuditTargetTerms
+	AuditTargetCase
+	AuditTargetFilterRule
+)
+
+func (t AuditTargetType) IsValid() bool {
+	return t >= AuditTargetUser && t <= AuditTargetFilterRule
+}
@@ -0,0 +1,27 @@
+package value
+
+type CaseOutcome uint8
+
+const (
+	// Dismissals
+	CaseOutcomeNoViolation CaseOutcome = iota + 1
+	CaseOutcomeDuplicate
+
+	// Actions taken
+	CaseOutcomeContentRemoved
+	CaseOutcomeUserWarned
+	CaseOutcomeUserRestricted
+	CaseOutcomeOther
+)
+
+func (o CaseOutcome) IsValid() bool {
+	return o >= CaseOutcomeNoViolation && o <= CaseOutcomeOther
+}
+
+// The status a case ends up in when resolved with this outcome
+func (o CaseOutcome) Status() CaseStatus {
+	if o == CaseOutcomeNoViolation || o == CaseOutcomeDuplicate {
+		return CaseStatusDismissed
+	}
+	return CaseStatusActioned
+}
@@ -0,0 +1,19 @@
+package value
+
+type CaseStatus uint8
+
+const (
+	CaseStatusOpen CaseStatus = iota
+	CaseStatusInReview
+	CaseStatusActioned
+	CaseStatusDismissed
+)
+
+func (s CaseStatus) IsValid() bool {
+	return s <= CaseStatusDismissed
+}
+
+// Open and in review cases still take new reports, resolved ones are left alone
+func (s CaseStatus) IsResolved() bool {
+	return s == CaseStatusActioned || s == CaseStatusDismissed
+}
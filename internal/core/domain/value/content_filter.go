@@ -0,0 +1,34 @@
+package value
+
+// What happens to a post that trips a filter rule, the strongest one wins
+type FilterAction uint8
+
+const (
+	FilterActionNone   FilterAction = iota
+	FilterActionFlag                // Goes up as usual, moderators get to see it was flagged
+	FilterActionHold                // Hidden from everyone but its author until a moderator looks at it
+	FilterActionReject              // Never stored
+)
+
+func (a FilterAction) IsValid() bool {
+	return a >= FilterActionFlag && a <= FilterActionReject
+}
+
+type FilterRuleKind uint8
+
+const (
+	FilterRuleWord            FilterRuleKind = iota + 1 // Pattern shows up as a whole word, any case
+	FilterRuleRegex                                     // Pattern matches somewhere in the text
+	FilterRuleLinkCount                                 // More than Threshold links
+	FilterRuleRepeatedText                              // Same text posted Threshold times already in the last day
+	FilterRuleNewAccountLinks                           // Any link from an account younger than Threshold hours
+)
+
+func (k FilterRuleKind) IsValid() bool {
+	return k >= FilterRuleWord && k <= FilterRuleNewAccountLinks
+}
+
+// Heuristics look at a threshold, the rest at a pattern
+func (k FilterRuleKind) HasPattern() bool {
+	return k == FilterRuleWord || k == FilterRuleRegex
+}
@@ -0,0 +1,9 @@
+package value
+
+type DataExportStatus uint8
+
+const (
+	DataExportPending DataExportStatus = iota
+	DataExportReady
+	DataExportFailed
+)
@@ -0,0 +1,10 @@
+package value
+
+type EventType string
+
+// Event types pushed to connected clients, they double as the SSE "event:" field
+const (
+	EventNotification EventType = "notification"
+	EventPresence     EventType = "presence"
+	EventNewPost      EventType = "post"
+)
@@ -0,0 +1,17 @@
+package value
+
+type GroupModerationAction uint8
+
+const (
+	GroupActionRemovePost GroupModerationAction = iota + 1
+	GroupActionBan
+	GroupActionUnban
+	GroupActionMute
+	GroupActionUnmute
+	GroupActionAddModerator
+	GroupActionRemoveModerator
+	GroupActionTransferOwnership
+	GroupActionArchive
+	GroupActionUnarchive
+	GroupActionDelete
+)
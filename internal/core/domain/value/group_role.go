@@ -0,0 +1,9 @@
+package value
+
+type GroupRole uint8
+
+const (
+	GroupRoleMember GroupRole = iota
+	GroupRoleModerator
+	GroupRoleOwner
+)
@@ -0,0 +1,29 @@
+package value
+
+type NotificationType uint8
+
+const (
+	NotificationFriendRequest NotificationType = iota + 1
+	NotificationFriendRequestAccepted
+	NotificationRecommendation
+	NotificationReply
+	NotificationMention
+
+	// Account notices, these can't be turned off
+	NotificationSanction
+	NotificationAppealReviewed
+	NotificationDataExportReady
+)
+
+// Every notification type a user can toggle in their preferences
+var NotificationTypes = []NotificationType{
+	NotificationFriendRequest,
+	NotificationFriendRequestAccepted,
+	NotificationRecommendation,
+	NotificationReply,
+	NotificationMention,
+}
+
+func (t NotificationType) IsValid() bool {
+	return t >= NotificationFriendRequest && t <= NotificationMention
+}
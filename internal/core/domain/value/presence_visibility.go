@@ -0,0 +1,14 @@
+package value
+
+type PresenceVisibility uint8
+
+// Who gets to see a user's online status and when they were last seen
+const (
+	PresenceVisibleToEveryone PresenceVisibility = iota // Default, so users from before the setting existed stay visible
+	PresenceVisibleToFriends
+	PresenceVisibleToNobody
+)
+
+func (v PresenceVisibility) IsValid() bool {
+	return v <= PresenceVisibleToNobody
+}
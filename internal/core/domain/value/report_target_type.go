@@ -0,0 +1,15 @@
+package value
+
+type ReportTargetType uint8
+
+// Reports from before posts, groups and translations could be reported have no type, they're all about users
+const (
+	ReportTargetUser ReportTargetType = iota
+	ReportTargetPost
+	ReportTargetGroup
+	ReportTargetTranslation
+)
+
+func (t ReportTargetType) IsValid() bool {
+	return t <= ReportTargetTranslation
+}
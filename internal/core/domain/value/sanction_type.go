@@ -0,0 +1,21 @@
+package value
+
+type SanctionType uint8
+
+const (
+	SanctionNoPosting SanctionType = iota + 1
+	SanctionNoTranslating
+	SanctionRestricted // Both of the above
+)
+
+func (t SanctionType) IsValid() bool {
+	return t >= SanctionNoPosting && t <= SanctionRestricted
+}
+
+func (t SanctionType) BlocksPosting() bool {
+	return t == SanctionNoPosting || t == SanctionRestricted
+}
+
+func (t SanctionType) BlocksTranslating() bool {
+	return t == SanctionNoTranslating || t == SanctionRestricted
+}
@@ -0,0 +1,27 @@
+package value
+
+type TokenScope string
+
+// What a personal token is allowed to do on its owner's behalf
+const (
+	TokenScopeReadList    TokenScope = "list:read"
+	TokenScopeWriteList   TokenScope = "list:write"
+	TokenScopePost        TokenScope = "post"
+	TokenScopeReadProfile TokenScope = "profile:read"
+)
+
+var TokenScopes = []TokenScope{
+	TokenScopeReadList,
+	TokenScopeWriteList,
+	TokenScopePost,
+	TokenScopeReadProfile,
+}
+
+func (s TokenScope) IsValid() bool {
+	for _, scope := range TokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
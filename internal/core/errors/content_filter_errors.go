@@ -0,0 +1,21 @@
+package domain_errors
+
+type FilterRuleNotFoundError struct{}
+
+func (e FilterRuleNotFoundError) Error() string {
+	return "Filter rule not found"
+}
+
+type InvalidFilterRuleError struct {
+	Reason string
+}
+
+func (e InvalidFilterRuleError) Error() string {
+	return "Invalid filter rule, " + e.Reason
+}
+
+type PostRejectedByFilterError struct{}
+
+func (e PostRejectedByFilterError) Error() string {
+	return "This post was blocked by the content filter"
+}
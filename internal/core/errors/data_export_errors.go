@@ -0,0 +1,27 @@
+package domain_errors
+
+type DataExportNotFoundError struct {
+	ExportID string
+}
+
+func (e DataExportNotFoundError) Error() string {
+	return "Data export not found: " + e.ExportID
+}
+
+type DataExportNotReadyError struct{}
+
+func (e DataExportNotReadyError) Error() string {
+	return "This data export isn't ready yet"
+}
+
+type DataExportInProgressError struct{}
+
+func (e DataExportInProgressError) Error() string {
+	return "You already have a data export being prepared"
+}
+
+type DataExportCooldownError struct{}
+
+func (e DataExportCooldownError) Error() string {
+	return "You can only request one data export per day"
+}
@@ -1,6 +1,9 @@
 package domain_errors
 
-import "strconv"
+import (
+	"strconv"
+	"time"
+)
 
 type NoModeratorsLeftError struct{}
 
@@ -25,5 +28,141 @@ type GroupNotFoundError struct {
 }
 
 func (e GroupNotFoundError) Error() string {
-	return "User with ID " + strconv.Itoa(e.GroupID) + " not found"
+	return "Group with ID " + strconv.Itoa(e.GroupID) + " not found"
+}
+
+type AlreadyGroupMemberError struct{}
+
+func (e AlreadyGroupMemberError) Error() string {
+	return "You are already a member of this group"
+}
+
+type NotGroupMemberError struct{}
+
+func (e NotGroupMemberError) Error() string {
+	return "This user is not a member of this group"
+}
+
+type GroupIsPrivateError struct{}
+
+func (e GroupIsPrivateError) Error() string {
+	return "This group is private, ask to join or use an invite"
+}
+
+type GroupMembershipRequiredError struct {
+	GroupID int
+}
+
+func (e GroupMembershipRequiredError) Error() string {
+	return "You must be a member of group " + strconv.Itoa(e.GroupID) + " to do that"
+}
+
+type JoinRequestAlreadySentError struct{}
+
+func (e JoinRequestAlreadySentError) Error() string {
+	return "You have already asked to join this group"
+}
+
+type JoinRequestNotFoundError struct{}
+
+func (e JoinRequestNotFoundError) Error() string {
+	return "There's no pending request from this user"
+}
+
+type GroupInviteNotFoundError struct{}
+
+func (e GroupInviteNotFoundError) Error() string {
+	return "This invite doesn't exist"
+}
+
+type GroupInviteExpiredError struct{}
+
+func (e GroupInviteExpiredError) Error() string {
+	return "This invite has expired or ran out of uses"
+}
+
+type GroupBannedError struct {
+	GroupID int
+}
+
+func (e GroupBannedError) Error() string {
+	return "You are banned from group " + strconv.Itoa(e.GroupID)
+}
+
+type GroupMutedError struct {
+	Until time.Time
+}
+
+func (e GroupMutedError) Error() string {
+	return "You are muted in this group until " + e.Until.Format(time.RFC3339)
+}
+
+type AlreadyBannedFromGroupError struct{}
+
+func (e AlreadyBannedFromGroupError) Error() string {
+	return "This user is already banned from this group"
+}
+
+type NotBannedFromGroupError struct{}
+
+func (e NotBannedFromGroupError) Error() string {
+	return "This user is not banned from this group"
+}
+
+type CannotSanctionModeratorError struct{}
+
+func (e CannotSanctionModeratorError) Error() string {
+	return "Moderators can't be banned or muted, remove them as moderator first"
+}
+
+type InvalidMuteDurationError struct{}
+
+func (e InvalidMuteDurationError) Error() string {
+	return "Mutes must last between a minute and 30 days"
+}
+
+type TooManyGroupTagsError struct{}
+
+func (e TooManyGroupTagsError) Error() string {
+	return "A group can have at most 5 tags"
+}
+
+type InvalidGroupTagError struct {
+	TagID uint32
+}
+
+func (e InvalidGroupTagError) Error() string {
+	return "Tag " + strconv.FormatUint(uint64(e.TagID), 10) + " doesn't exist"
+}
+
+type OwnerCannotStepDownError struct{}
+
+func (e OwnerCannotStepDownError) Error() string {
+	return "The group owner can't step down, transfer ownership first"
+}
+
+type AlreadyGroupOwnerError struct{}
+
+func (e AlreadyGroupOwnerError) Error() string {
+	return "This user already owns this group"
+}
+
+type NoOwnershipTransferError struct{}
+
+func (e NoOwnershipTransferError) Error() string {
+	return "There is no pending ownership transfer for this user"
+}
+
+type GroupArchivedError struct {
+	GroupID int
+}
+
+func (e GroupArchivedError) Error() string {
+	return "Group with ID " + strconv.Itoa(e.GroupID) + " is archived"
+}
+
+type GroupNotArchivedError struct{}
+
+func (e GroupNotArchivedError) Error() string {
+	return "This group is not archived"
 }
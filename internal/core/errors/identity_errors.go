@@ -0,0 +1,35 @@
+package domain_errors
+
+type IdentityNotFoundError struct {
+	Provider string
+}
+
+func (e IdentityNotFoundError) Error() string {
+	return "No " + e.Provider + " account is linked to this user"
+}
+
+type IdentityAlreadyLinkedError struct{}
+
+func (e IdentityAlreadyLinkedError) Error() string {
+	return "This account is already linked to yours"
+}
+
+type IdentityLinkedToAnotherUserError struct{}
+
+func (e IdentityLinkedToAnotherUserError) Error() string {
+	return "This account is already linked to another user"
+}
+
+type ProviderAlreadyLinkedError struct {
+	Provider string
+}
+
+func (e ProviderAlreadyLinkedError) Error() string {
+	return "There is already a " + e.Provider + " account linked, unlink it first"
+}
+
+type CantUnlinkLastIdentityError struct{}
+
+func (e CantUnlinkLastIdentityError) Error() string {
+	return "Can't unlink the only way left to log in"
+}
@@ -0,0 +1,37 @@
+package domain_errors
+
+type CaseNotFoundError struct{}
+
+func (e CaseNotFoundError) Error() string {
+	return "Case not found"
+}
+
+type CaseAlreadyResolvedError struct{}
+
+func (e CaseAlreadyResolvedError) Error() string {
+	return "This case has already been resolved"
+}
+
+type CaseAlreadyOpenError struct{}
+
+func (e CaseAlreadyOpenError) Error() string {
+	return "There's already an open case about this"
+}
+
+type CaseNotResolvedError struct{}
+
+func (e CaseNotResolvedError) Error() string {
+	return "This case hasn't been resolved yet"
+}
+
+type InvalidCaseOutcomeError struct{}
+
+func (e InvalidCaseOutcomeError) Error() string {
+	return "Invalid case outcome"
+}
+
+type CaseAssigneeNotModeratorError struct{}
+
+func (e CaseAssigneeNotModeratorError) Error() string {
+	return "Cases can only be assigned to moderators"
+}
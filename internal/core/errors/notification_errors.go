@@ -0,0 +1,15 @@
+package domain_errors
+
+type NotificationNotFoundError struct {
+	NotificationID string
+}
+
+func (e NotificationNotFoundError) Error() string {
+	return "Notification " + e.NotificationID + " not found"
+}
+
+type InvalidNotificationTypeError struct{}
+
+func (e InvalidNotificationTypeError) Error() string {
+	return "Invalid notification type"
+}
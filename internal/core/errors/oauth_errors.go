@@ -0,0 +1,88 @@
+package domain_errors
+
+import "strconv"
+
+type OAuthAppNotFoundError struct{}
+
+func (e OAuthAppNotFoundError) Error() string {
+	return "App not found"
+}
+
+type InvalidOAuthAppNameError struct {
+	MaxLength int
+}
+
+func (e InvalidOAuthAppNameError) Error() string {
+	return "App names must be between 1 and " + strconv.Itoa(e.MaxLength) + " characters"
+}
+
+type OAuthAppDescriptionTooLongError struct {
+	MaxLength int
+}
+
+func (e OAuthAppDescriptionTooLongError) Error() string {
+	return "App descriptions can't be longer than " + strconv.Itoa(e.MaxLength) + " characters"
+}
+
+type InvalidOAuthAppHomepageError struct {
+	Reason string
+}
+
+func (e InvalidOAuthAppHomepageError) Error() string {
+	return "Invalid homepage, " + e.Reason
+}
+
+type InvalidRedirectURIError struct {
+	URI string
+}
+
+func (e InvalidRedirectURIError) Error() string {
+	if e.URI == "" {
+		return "At least one redirect URI is needed"
+	}
+	return "Invalid redirect URI " + e.URI
+}
+
+type TooManyRedirectURIsError struct {
+	Max int
+}
+
+func (e TooManyRedirectURIsError) Error() string {
+	return "Apps can't have more than " + strconv.Itoa(e.Max) + " redirect URIs"
+}
+
+type TooManyOAuthAppsError struct {
+	Max int
+}
+
+func (e TooManyOAuthAppsError) Error() string {
+	return "You can't register more than " + strconv.Itoa(e.Max) + " apps"
+}
+
+type OAuthGrantNotFoundError struct{}
+
+func (e OAuthGrantNotFoundError) Error() string {
+	return "You haven't given this app access to your account"
+}
+
+// Error codes from RFC 6749, apps expect these exact strings
+const (
+	OAuthInvalidRequest          = "invalid_request"
+	OAuthInvalidClient           = "invalid_client"
+	OAuthInvalidGrant            = "invalid_grant"
+	OAuthInvalidScope            = "invalid_scope"
+	OAuthUnauthorizedClient      = "unauthorized_client"
+	OAuthUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthUnsupportedResponseType = "unsupported_response_type"
+	OAuthAccessDenied            = "access_denied"
+)
+
+// Anything that goes wrong talking to an app, sent back to it as is
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e OAuthError) Error() string {
+	return e.Description
+}
@@ -0,0 +1,45 @@
+package domain_errors
+
+import "strconv"
+
+type PersonalTokenNotFoundError struct{}
+
+func (e PersonalTokenNotFoundError) Error() string {
+	return "Token not found"
+}
+
+type InvalidTokenScopeError struct {
+	Scope string
+}
+
+func (e InvalidTokenScopeError) Error() string {
+	return "Unknown token scope " + e.Scope
+}
+
+type NoTokenScopesError struct{}
+
+func (e NoTokenScopesError) Error() string {
+	return "A token needs at least one scope"
+}
+
+type InvalidTokenNameError struct {
+	MaxLength int
+}
+
+func (e InvalidTokenNameError) Error() string {
+	return "Token names must be between 1 and " + strconv.Itoa(e.MaxLength) + " characters"
+}
+
+type InvalidTokenExpiryError struct{}
+
+func (e InvalidTokenExpiryError) Error() string {
+	return "Token expiry must be in the future"
+}
+
+type TooManyPersonalTokensError struct {
+	Max int
+}
+
+func (e TooManyPersonalTokensError) Error() string {
+	return "You can't have more than " + strconv.Itoa(e.Max) + " tokens, revoke one first"
+}
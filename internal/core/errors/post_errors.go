@@ -24,3 +24,11 @@ type NotPostOwnerError struct {
 func (e NotPostOwnerError) Error() string {
 	return "User " + e.UserID + " is not the owner of post " + e.PostID
 }
+
+type PostNotHeldError struct {
+	PostID string
+}
+
+func (e PostNotHeldError) Error() string {
+	return "Post " + e.PostID + " is not being held for review"
+}
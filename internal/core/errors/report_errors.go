@@ -21,7 +21,7 @@ type AlreadyReportedError struct {
 }
 
 func (e AlreadyReportedError) Error() string {
-	return "You've already reported this account"
+	return "You've already reported this"
 }
 
 type ReportNotFoundError struct{}
@@ -29,3 +29,15 @@ type ReportNotFoundError struct{}
 func (e ReportNotFoundError) Error() string {
 	return "Report not found"
 }
+
+type ReportTargetNotFoundError struct{}
+
+func (e ReportTargetNotFoundError) Error() string {
+	return "The reported content doesn't exist"
+}
+
+type InvalidReportTargetTypeError struct{}
+
+func (e InvalidReportTargetTypeError) Error() string {
+	return "Invalid report target type"
+}
@@ -0,0 +1,37 @@
+package domain_errors
+
+type SanctionNotFoundError struct{}
+
+func (e SanctionNotFoundError) Error() string {
+	return "Sanction not found"
+}
+
+type InvalidSanctionTypeError struct{}
+
+func (e InvalidSanctionTypeError) Error() string {
+	return "Invalid sanction type"
+}
+
+type InvalidSanctionDurationError struct{}
+
+func (e InvalidSanctionDurationError) Error() string {
+	return "Sanctions can last at most a year, leave the duration out for a permanent one"
+}
+
+type SanctionNotActiveError struct{}
+
+func (e SanctionNotActiveError) Error() string {
+	return "This sanction is no longer active"
+}
+
+type AlreadyAppealedError struct{}
+
+func (e AlreadyAppealedError) Error() string {
+	return "This sanction has already been appealed"
+}
+
+type AppealNotPendingError struct{}
+
+func (e AppealNotPendingError) Error() string {
+	return "There is no pending appeal for this sanction"
+}
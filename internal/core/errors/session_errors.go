@@ -0,0 +1,13 @@
+package domain_errors
+
+type SessionNotFoundError struct{}
+
+func (e SessionNotFoundError) Error() string {
+	return "Session not found"
+}
+
+type InvalidRefreshTokenError struct{}
+
+func (e InvalidRefreshTokenError) Error() string {
+	return "Invalid or expired refresh token, please log in again"
+}
@@ -0,0 +1,48 @@
+package domain_errors
+
+import "strconv"
+
+type TermsNotFoundError struct {
+	Version int
+}
+
+func (e TermsNotFoundError) Error() string {
+	return "Terms of service version not found: " + strconv.Itoa(e.Version)
+}
+
+type NoTermsPublishedError struct{}
+
+func (e NoTermsPublishedError) Error() string {
+	return "No terms of service have been published yet"
+}
+
+type InvalidTermsContentError struct {
+	MaxLength int
+}
+
+func (e InvalidTermsContentError) Error() string {
+	return "Terms of service can't be empty or longer than " + strconv.Itoa(e.MaxLength) + " characters"
+}
+
+type TermsSummaryTooLongError struct {
+	MaxLength int
+}
+
+func (e TermsSummaryTooLongError) Error() string {
+	return "Terms of service summary can't be longer than " + strconv.Itoa(e.MaxLength) + " characters"
+}
+
+// Someone published at the same time, the client should look at the new version first
+type TermsVersionConflictError struct{}
+
+func (e TermsVersionConflictError) Error() string {
+	return "A newer terms of service version was just published"
+}
+
+type OutdatedTermsError struct {
+	CurrentVersion int
+}
+
+func (e OutdatedTermsError) Error() string {
+	return "Only the current terms of service can be accepted, that's version " + strconv.Itoa(e.CurrentVersion)
+}
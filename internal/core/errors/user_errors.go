@@ -8,6 +8,12 @@ func (e UserNotFoundError) Error() string {
 	return "User with ID " + e.UserID + " not found"
 }
 
+type UsernameTakenError struct{}
+
+func (e UsernameTakenError) Error() string {
+	return "That username is already taken"
+}
+
 type UserCantTranslate struct{}
 
 func (e UserCantTranslate) Error() string {
@@ -25,3 +31,27 @@ type CantRestrictAnAdmin struct{}
 func (e CantRestrictAnAdmin) Error() string {
 	return "You cannot restrict an admin"
 }
+
+type InvalidPresenceVisibilityError struct{}
+
+func (e InvalidPresenceVisibilityError) Error() string {
+	return "Invalid presence visibility"
+}
+
+type AccountDeletionAlreadyRequestedError struct{}
+
+func (e AccountDeletionAlreadyRequestedError) Error() string {
+	return "Your account is already scheduled for deletion"
+}
+
+type NoAccountDeletionRequestedError struct{}
+
+func (e NoAccountDeletionRequestedError) Error() string {
+	return "Your account isn't scheduled for deletion"
+}
+
+type AccountDeletedError struct{}
+
+func (e AccountDeletedError) Error() string {
+	return "This account was deleted"
+}
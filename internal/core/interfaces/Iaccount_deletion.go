@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+)
+
+type AccountDeletionService interface {
+	RequestDeletion(ctx context.Context, userID int) (time.Time, error)
+	CancelDeletion(ctx context.Context, userID int) error
+	DeleteAccount(ctx context.Context, userID int) error
+	DeleteDueAccounts(ctx context.Context) (int, error)
+}
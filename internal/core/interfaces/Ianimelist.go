@@ -17,6 +17,7 @@ type AnimeListRepository interface {
 
 	FetchUserList(ctx context.Context, userID int) (*domain.UserAnimeList, error)
 	SaveUserList(ctx context.Context, list *domain.UserAnimeList) error
+	DeleteUserList(ctx context.Context, userID int) error
 }
 
 type AnimeListService interface {
@@ -44,4 +45,5 @@ type AnimeListService interface {
 	FetchUserListItem(ctx context.Context, userID int, animeID uint32) (*dtos.UserListItemDTO, error)
 
 	IsInAnimeList(ctx context.Context, receiverID int, animeID int) (bool, error)
+	DeleteUserList(ctx context.Context, userID int) error
 }
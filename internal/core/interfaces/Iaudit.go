@@ -0,0 +1,19 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type AuditService interface {
+	Record(ctx context.Context, entry *domain.AuditEntry)
+	GetEntries(ctx context.Context, filters filters.AuditFilter, pageNumber, pageSize int) ([]*domain.AuditEntry, utils.Pagination, error)
+}
+
+type AuditLogRepository interface {
+	CreateEntry(ctx context.Context, entry *domain.AuditEntry) error
+	GetEntries(ctx context.Context, filters filters.AuditFilter, pageNumber, pageSize int) ([]*domain.AuditEntry, utils.Pagination, error)
+}
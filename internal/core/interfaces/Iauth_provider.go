@@ -0,0 +1,16 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+)
+
+// A place users can log in through, Google, Discord, GitHub or any OIDC issuer
+type AuthProvider interface {
+	Name() string
+	// Where to send the user, verifier is the PKCE code verifier for providers that use it
+	AuthCodeURL(ctx context.Context, state, verifier string) (string, error)
+	// Trades the callback's code for the identity of whoever logged in
+	Authenticate(ctx context.Context, code, verifier string) (*domain.ProviderIdentity, error)
+}
@@ -0,0 +1,35 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type ContentFilterService interface {
+	Check(ctx context.Context, poster *domain.User, text string) (domain.FilterVerdict, error)
+	RecordHit(ctx context.Context, verdict domain.FilterVerdict, post *domain.Post, posterID int, text string)
+	ResolveHold(ctx context.Context, post *domain.Post, released bool, moderatorID int)
+
+	GetRules(ctx context.Context) ([]*domain.FilterRule, error)
+	CreateRule(ctx context.Context, kind value.FilterRuleKind, pattern string, threshold int, action value.FilterAction, description string, adminID int) (*domain.FilterRule, error)
+	UpdateRule(ctx context.Context, id int, pattern string, threshold int, action value.FilterAction, description string, enabled bool, adminID int) (*domain.FilterRule, error)
+	DeleteRule(ctx context.Context, id int, adminID int) error
+
+	GetHits(ctx context.Context, action *value.FilterAction, pageNumber, pageSize int) ([]*domain.FilterHit, utils.Pagination, error)
+}
+
+type FilterRuleRepository interface {
+	CreateRule(ctx context.Context, rule *domain.FilterRule) error
+	GetRule(ctx context.Context, id int) (*domain.FilterRule, error)
+	UpdateRule(ctx context.Context, rule *domain.FilterRule) error
+	DeleteRule(ctx context.Context, id int) error
+	GetRules(ctx context.Context, enabledOnly bool) ([]*domain.FilterRule, error)
+}
+
+type FilterHitRepository interface {
+	CreateHit(ctx context.Context, hit *domain.FilterHit) error
+	GetHits(ctx context.Context, action *value.FilterAction, pageNumber, pageSize int) ([]*domain.FilterHit, utils.Pagination, error)
+}
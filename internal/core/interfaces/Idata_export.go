@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+)
+
+type DataExportService interface {
+	RequestExport(ctx context.Context, userID int) (*domain.DataExport, error)
+	GetExports(ctx context.Context, userID int) ([]*domain.DataExport, error)
+	DownloadExport(ctx context.Context, userID int, exportID string) (*domain.DataExport, io.ReadCloser, error)
+}
+
+type DataExportRepository interface {
+	CreateExport(ctx context.Context, export *domain.DataExport) error
+	GetExport(ctx context.Context, id string) (*domain.DataExport, error)
+	GetUserExports(ctx context.Context, userID int) ([]*domain.DataExport, error)
+	UpdateExport(ctx context.Context, export *domain.DataExport) error
+	SaveArchive(ctx context.Context, exportID string, archive []byte, expiresAt time.Time) error
+	OpenArchive(ctx context.Context, exportID string) (io.ReadCloser, error)
+	DeleteExpiredArchives(ctx context.Context, now time.Time) (int, error)
+	DeleteUserExports(ctx context.Context, userID int) error
+}
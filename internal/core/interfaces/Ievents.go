@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+)
+
+// A pub/sub broker for real-time events
+// The default implementation lives in-process, but anything that can fan out
+// messages by topic (redis, nats, ...) can take its place
+type EventBroker interface {
+	Publish(topic string, eventType value.EventType, data any)
+	// Subscribe to a set of topics, if lastEventID is set the events published after it
+	// that are still retained are returned so the client can catch up after a reconnect
+	Subscribe(topics []string, lastEventID string) (EventSubscription, []*domain.Event)
+}
+
+type EventSubscription interface {
+	// Closed by the broker when the subscription is dropped, e.g. because the client can't keep up
+	Events() <-chan *domain.Event
+	Close()
+}
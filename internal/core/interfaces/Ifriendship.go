@@ -13,6 +13,7 @@ type FriendshipService interface {
 	DeclineFriendRequest(ctx context.Context, initiator int, receiver int) error
 	BlockUser(ctx context.Context, initiator int, receiver int) error
 	GetFriendList(ctx context.Context, userId int, pageNumber, pageSize int) ([]domain.User, utils.Pagination, error)
+	SearchFriends(ctx context.Context, userId int, prefix string, limit int) ([]domain.User, error)
 	GetPendingFriendRequests(ctx context.Context, userId int, pageNumber, pageSize int) ([]domain.User, utils.Pagination, error)
 	FetchFriendshipStatus(ctx context.Context, userA int, userB int) (*domain.Friendship, error)
 }
@@ -23,5 +24,8 @@ type FriendshipRepository interface {
 	DeleteFriendship(ctx context.Context, initiator int, receiver int) error
 	UpdateFriendship(ctx context.Context, f *domain.Friendship) error
 	GetFriends(ctx context.Context, userId int, pageNumber, pageSize int) ([]domain.User, utils.Pagination, error)
+	SearchFriends(ctx context.Context, userId int, prefix string, limit int) ([]domain.User, error)
 	GetPendingFriendRequests(ctx context.Context, userId int, pageNumber, pageSize int) ([]domain.User, utils.Pagination, error)
+	DeleteUserFriendships(ctx context.Context, userID int) error
+	GetUserFriendships(ctx context.Context, userID int) ([]*domain.Friendship, error)
 }
@@ -2,25 +2,107 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
 	"github.com/afuradanime/backend/internal/core/utils"
 )
 
 type GroupService interface {
-	// Not yet defined how to do this
-	// CreateGroup(ctx context.Context, name, description, rules, icon string) error
+	CreateGroup(ctx context.Context, name, description, rules, icon string, public bool, animeID *uint32, tags []uint32, creator int) (*domain.Group, error)
 	GetGroup(ctx context.Context, groupId int) (*domain.Group, error)
-	GetGroups(ctx context.Context, pageNumber, pageSize int) ([]*domain.Group, utils.Pagination, error)
+	GetGroups(ctx context.Context, filters filters.GroupFilter, pageNumber, pageSize int) ([]*domain.Group, utils.Pagination, error)
 
 	UpdateGroup(ctx context.Context, groupId int, name, description, rules, icon string, user int) error
+	UpdateGroupCategories(ctx context.Context, groupId int, animeID *uint32, tags []uint32, user int) error
+	RecordActivity(ctx context.Context, groupId int) error
 	AddGroupModerator(ctx context.Context, groupId int, moderator, user int) error
 	RemoveGroupModerator(ctx context.Context, groupId int, moderator, user int) error
+
+	JoinGroup(ctx context.Context, groupId int, user int) error
+	LeaveGroup(ctx context.Context, groupId int, user int) error
+	GetMembers(ctx context.Context, groupId int, viewer *int, pageNumber, pageSize int) ([]*domain.GroupMembership, utils.Pagination, error)
+	IsMember(ctx context.Context, groupId int, user int) (bool, error)
+
+	// Access checks, for whoever lives under a group (posts, threads, ...)
+	CanViewGroup(ctx context.Context, groupId int, viewer *int) error
+	CanPostInGroup(ctx context.Context, groupId int, user int) error
+
+	RequestToJoin(ctx context.Context, groupId int, user int) error
+	GetJoinRequests(ctx context.Context, groupId int, moderator int, pageNumber, pageSize int) ([]*domain.GroupJoinRequest, utils.Pagination, error)
+	ApproveJoinRequest(ctx context.Context, groupId int, requester, moderator int) error
+	DeclineJoinRequest(ctx context.Context, groupId int, requester, moderator int) error
+
+	CreateInvite(ctx context.Context, groupId int, moderator int, maxUses int, ttl time.Duration) (*domain.GroupInvite, error)
+	GetInvites(ctx context.Context, groupId int, moderator int) ([]*domain.GroupInvite, error)
+	RevokeInvite(ctx context.Context, groupId int, code string, moderator int) error
+	JoinWithInvite(ctx context.Context, code string, user int) (*domain.Group, error)
+
+	CanModerateGroup(ctx context.Context, groupId int, user int) error
+	LogModerationAction(ctx context.Context, entry *domain.GroupModerationEntry)
+	BanMember(ctx context.Context, groupId int, target, moderator int, reason string) error
+	UnbanMember(ctx context.Context, groupId int, target, moderator int) error
+	GetBans(ctx context.Context, groupId int, moderator int, pageNumber, pageSize int) ([]*domain.GroupBan, utils.Pagination, error)
+	MuteMember(ctx context.Context, groupId int, target, moderator int, reason string, duration time.Duration) error
+	UnmuteMember(ctx context.Context, groupId int, target, moderator int) error
+	GetModerationLog(ctx context.Context, groupId int, moderator int, pageNumber, pageSize int) ([]*domain.GroupModerationEntry, utils.Pagination, error)
+
+	TransferOwnership(ctx context.Context, groupId int, newOwner, user int) error
+	AcceptOwnership(ctx context.Context, groupId int, user int) error
+	CancelOwnershipTransfer(ctx context.Context, groupId int, user int) error
+	ArchiveGroup(ctx context.Context, groupId int, user int) error
+	UnarchiveGroup(ctx context.Context, groupId int, user int) error
+	DeleteGroup(ctx context.Context, groupId int, user int) error
+	LeaveAllGroups(ctx context.Context, user int) error
 }
 
 type GroupRepository interface {
 	CreateGroup(ctx context.Context, group *domain.Group) error
 	GetGroup(ctx context.Context, groupId int) (*domain.Group, error)
-	GetGroups(ctx context.Context, pageNumber, pageSize int) ([]*domain.Group, utils.Pagination, error)
+	GetGroups(ctx context.Context, filters filters.GroupFilter, pageNumber, pageSize int) ([]*domain.Group, utils.Pagination, error)
 	UpdateGroup(ctx context.Context, group *domain.Group) error
+	UpdateLastActivity(ctx context.Context, groupId int, at time.Time) error
+	IncrementMemberCount(ctx context.Context, groupId int, delta int) error
+	DeleteGroup(ctx context.Context, groupId int) error
+}
+
+type GroupMembershipRepository interface {
+	CreateMembership(ctx context.Context, membership *domain.GroupMembership) error
+	GetMembership(ctx context.Context, groupId, userId int) (*domain.GroupMembership, error)
+	UpdateMembership(ctx context.Context, membership *domain.GroupMembership) error
+	DeleteMembership(ctx context.Context, groupId, userId int) error
+	GetMembers(ctx context.Context, groupId int, pageNumber, pageSize int) ([]*domain.GroupMembership, utils.Pagination, error)
+	GetUserMemberships(ctx context.Context, userId int) ([]*domain.GroupMembership, error)
+	DeleteGroupMemberships(ctx context.Context, groupId int) error
+}
+
+type GroupJoinRequestRepository interface {
+	CreateRequest(ctx context.Context, request *domain.GroupJoinRequest) error
+	GetRequest(ctx context.Context, groupId, userId int) (*domain.GroupJoinRequest, error)
+	DeleteRequest(ctx context.Context, groupId, userId int) error
+	GetRequests(ctx context.Context, groupId int, pageNumber, pageSize int) ([]*domain.GroupJoinRequest, utils.Pagination, error)
+	DeleteGroupRequests(ctx context.Context, groupId int) error
+}
+
+type GroupInviteRepository interface {
+	CreateInvite(ctx context.Context, invite *domain.GroupInvite) error
+	GetInvite(ctx context.Context, code string) (*domain.GroupInvite, error)
+	GetInvites(ctx context.Context, groupId int) ([]*domain.GroupInvite, error)
+	UseInvite(ctx context.Context, code string) (bool, error)
+	DeleteInvite(ctx context.Context, code string) error
+	DeleteGroupInvites(ctx context.Context, groupId int) error
+}
+
+type GroupBanRepository interface {
+	CreateBan(ctx context.Context, ban *domain.GroupBan) error
+	GetBan(ctx context.Context, groupId, userId int) (*domain.GroupBan, error)
+	DeleteBan(ctx context.Context, groupId, userId int) error
+	GetBans(ctx context.Context, groupId int, pageNumber, pageSize int) ([]*domain.GroupBan, utils.Pagination, error)
+	DeleteGroupBans(ctx context.Context, groupId int) error
+}
+
+type GroupModerationLogRepository interface {
+	CreateEntry(ctx context.Context, entry *domain.GroupModerationEntry) error
+	GetEntries(ctx context.Context, groupId int, pageNumber, pageSize int) ([]*domain.GroupModerationEntry, utils.Pagination, error)
 }
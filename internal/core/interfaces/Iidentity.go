@@ -0,0 +1,23 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+)
+
+type IdentityService interface {
+	LoginWithProvider(ctx context.Context, identity *domain.ProviderIdentity) (*domain.User, bool, error)
+	LinkIdentity(ctx context.Context, userID int, identity *domain.ProviderIdentity) (*domain.LinkedIdentity, error)
+	UnlinkIdentity(ctx context.Context, userID int, provider string) error
+	GetIdentities(ctx context.Context, userID int) ([]*domain.LinkedIdentity, error)
+}
+
+type IdentityRepository interface {
+	CreateIdentity(ctx context.Context, identity *domain.LinkedIdentity) error
+	GetIdentity(ctx context.Context, provider, providerID string) (*domain.LinkedIdentity, error)
+	GetUserIdentities(ctx context.Context, userID int) ([]*domain.LinkedIdentity, error)
+	MarkUsed(ctx context.Context, identity *domain.LinkedIdentity) error
+	DeleteIdentity(ctx context.Context, userID int, provider string) (bool, error)
+	DeleteUserIdentities(ctx context.Context, userID int) error
+}
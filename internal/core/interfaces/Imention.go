@@ -0,0 +1,11 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+)
+
+type MentionService interface {
+	Autocomplete(ctx context.Context, userID int, prefix string, limit int) ([]*domain.User, error)
+}
@@ -0,0 +1,31 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type ModerationCaseService interface {
+	GetCases(ctx context.Context, filters filters.CaseFilter, pageNumber, pageSize int) ([]*domain.ModerationCase, utils.Pagination, error)
+	GetCase(ctx context.Context, id int) (*domain.ModerationCase, []domain.Report, error)
+
+	AssignCase(ctx context.Context, id int, assignee, moderatorID int) error
+	UnassignCase(ctx context.Context, id int, moderatorID int) error
+	AddNote(ctx context.Context, id int, text string, moderatorID int) error
+	ResolveCase(ctx context.Context, id int, outcome value.CaseOutcome, note string, moderatorID int) error
+	ReopenCase(ctx context.Context, id int, moderatorID int) error
+}
+
+type ModerationCaseRepository interface {
+	CreateCase(ctx context.Context, moderationCase *domain.ModerationCase) error
+	GetCase(ctx context.Context, id int) (*domain.ModerationCase, error)
+	GetOpenCase(ctx context.Context, report *domain.Report) (*domain.ModerationCase, error)
+	UpdateCase(ctx context.Context, moderationCase *domain.ModerationCase) error
+	AddNote(ctx context.Context, id int, note domain.CaseNote) error
+	IncrementReportCount(ctx context.Context, id int, delta int) error
+	GetCases(ctx context.Context, filters filters.CaseFilter, pageNumber, pageSize int) ([]*domain.ModerationCase, utils.Pagination, error)
+}
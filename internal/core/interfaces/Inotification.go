@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type NotificationService interface {
+	Notify(ctx context.Context, receiverID, actorID int, notificationType value.NotificationType, target string) error
+	GetNotifications(ctx context.Context, userID int, unreadOnly bool, pageNumber, pageSize int) ([]*domain.Notification, utils.Pagination, error)
+	GetUnreadCount(ctx context.Context, userID int) (*domain.UnreadNotifications, error)
+	MarkAsRead(ctx context.Context, userID int, notificationID string) error
+	MarkAllAsRead(ctx context.Context, userID int) error
+	GetPreferences(ctx context.Context, userID int) ([]domain.NotificationPreference, error)
+	UpdatePreferences(ctx context.Context, userID int, preferences []domain.NotificationPreference) error
+}
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *domain.Notification) error
+	GetById(ctx context.Context, id string) (*domain.Notification, error)
+	GetForUser(ctx context.Context, receiverID int, unreadOnly bool, pageNumber, pageSize int) ([]*domain.Notification, utils.Pagination, error)
+	CountUnread(ctx context.Context, receiverID int) (*domain.UnreadNotifications, error)
+	MarkRead(ctx context.Context, id string) error
+	MarkAllRead(ctx context.Context, receiverID int) error
+}
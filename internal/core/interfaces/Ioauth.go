@@ -0,0 +1,61 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+)
+
+type OAuthService interface {
+	RegisterApp(ctx context.Context, ownerID int, name, description, homepage string, redirectURIs []string, public bool) (*domain.OAuthApp, string, error)
+	GetApps(ctx context.Context, ownerID int) ([]*domain.OAuthApp, error)
+	DeleteApp(ctx context.Context, ownerID int, appID string) error
+
+	GetConsent(ctx context.Context, userID int, request domain.AuthorizationRequest) (*domain.OAuthConsent, error)
+	Authorize(ctx context.Context, userID int, request domain.AuthorizationRequest) (string, error)
+	ExchangeCode(ctx context.Context, clientID, clientSecret, code, redirectURI, verifier string) (*domain.OAuthToken, string, string, error)
+	RefreshToken(ctx context.Context, clientID, clientSecret, refreshToken string) (*domain.OAuthToken, string, string, error)
+
+	GetGrants(ctx context.Context, userID int) ([]*domain.OAuthGrant, error)
+	RevokeGrant(ctx context.Context, userID int, appID string) error
+
+	AuthenticateToken(ctx context.Context, token string) (int, []value.TokenScope, bool)
+}
+
+type OAuthAppRepository interface {
+	CreateApp(ctx context.Context, app *domain.OAuthApp) error
+	GetApp(ctx context.Context, id string) (*domain.OAuthApp, error)
+	GetOwnerApps(ctx context.Context, ownerID int) ([]*domain.OAuthApp, error)
+	CountOwnerApps(ctx context.Context, ownerID int) (int, error)
+	DeleteApp(ctx context.Context, id string, ownerID int) (bool, error)
+}
+
+type OAuthGrantRepository interface {
+	SaveGrant(ctx context.Context, grant *domain.OAuthGrant) error
+	GetGrant(ctx context.Context, userID int, appID string) (*domain.OAuthGrant, error)
+	GetUserGrants(ctx context.Context, userID int) ([]*domain.OAuthGrant, error)
+	DeleteGrant(ctx context.Context, userID int, appID string) (bool, error)
+	DeleteAppGrants(ctx context.Context, appID string) error
+	DeleteUserGrants(ctx context.Context, userID int) error
+}
+
+type OAuthCodeRepository interface {
+	CreateCode(ctx context.Context, code *domain.OAuthAuthorizationCode) error
+	GetCode(ctx context.Context, hash string) (*domain.OAuthAuthorizationCode, error)
+	RedeemCode(ctx context.Context, hash string, at time.Time) (*domain.OAuthAuthorizationCode, error)
+}
+
+type OAuthTokenRepository interface {
+	CreateToken(ctx context.Context, token *domain.OAuthToken) error
+	GetTokenByAccessHash(ctx context.Context, hash string) (*domain.OAuthToken, error)
+	GetTokenByRefreshHash(ctx context.Context, hash string) (*domain.OAuthToken, error)
+	RotateToken(ctx context.Context, token *domain.OAuthToken, oldRefreshHash string) (bool, error)
+	MarkUsed(ctx context.Context, id string, at time.Time) error
+	RevokeGrantTokens(ctx context.Context, userID int, appID string, at time.Time) error
+	RevokeAppTokens(ctx context.Context, appID string, at time.Time) error
+	RevokeCodeTokens(ctx context.Context, codeID string, at time.Time) error
+	RevokeUserTokens(ctx context.Context, userID int, at time.Time) error
+	GetUserTokens(ctx context.Context, userID int) ([]*domain.OAuthToken, error)
+}
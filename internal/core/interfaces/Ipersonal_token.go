@@ -0,0 +1,26 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+)
+
+type PersonalTokenService interface {
+	CreateToken(ctx context.Context, userID int, name string, scopes []value.TokenScope, expiresAt *time.Time) (*domain.PersonalToken, string, error)
+	GetTokens(ctx context.Context, userID int) ([]*domain.PersonalToken, error)
+	RevokeToken(ctx context.Context, userID int, tokenID string) error
+	AuthenticateToken(ctx context.Context, token string) (int, []value.TokenScope, bool)
+}
+
+type PersonalTokenRepository interface {
+	CreateToken(ctx context.Context, token *domain.PersonalToken) error
+	GetTokenByHash(ctx context.Context, hash string) (*domain.PersonalToken, error)
+	GetUserTokens(ctx context.Context, userID int) ([]*domain.PersonalToken, error)
+	CountUserTokens(ctx context.Context, userID int) (int, error)
+	MarkUsed(ctx context.Context, id string, at time.Time) error
+	RevokeToken(ctx context.Context, id string, userID int, at time.Time) (bool, error)
+	RevokeUserTokens(ctx context.Context, userID int, at time.Time) error
+}
@@ -2,9 +2,11 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/afuradanime/backend/internal/core/domain"
 	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
 )
 
 type PostRepository interface {
@@ -13,12 +15,22 @@ type PostRepository interface {
 	CreatePost(ctx context.Context, post *domain.Post) (*domain.Post, error)
 	UpdatePost(ctx context.Context, post *domain.Post) error
 	AddReplyToPost(ctx context.Context, parentPostID string, replyID string) error
+	DeleteThreads(ctx context.Context, parentID string, parentType value.PostParentType) (int64, error)
+	CountPostsWithText(ctx context.Context, userID int, text string, since time.Time) (int, error)
+	GetHeldPosts(ctx context.Context, pageNumber, pageSize int) ([]*domain.Post, utils.Pagination, error)
+	AnonymiseUserPosts(ctx context.Context, userID int) (int64, error)
+	GetUserPosts(ctx context.Context, userID int) ([]*domain.Post, error)
 }
 
 type PostService interface {
-	GetPostById(ctx context.Context, postID string) (*domain.Post, error)
-	GetPostReplies(ctx context.Context, parentID string, parentType value.PostParentType) ([]*domain.Post, error)
+	GetPostById(ctx context.Context, postID string, viewerId *int) (*domain.Post, error)
+	GetPostReplies(ctx context.Context, parentID string, parentType value.PostParentType, viewerId *int) ([]*domain.Post, error)
+	CanViewThread(ctx context.Context, parentType value.PostParentType, parentId string, viewerId *int) error
 	CreatePost(ctx context.Context, parentId string, parentType value.PostParentType, text string, posterId int) (*domain.Post, error)
 	CreateReply(ctx context.Context, replyToPostID string, text string, createdBy int) (*domain.Post, error)
-	DeletePost(ctx context.Context, postID string, deleterId int) error
+	DeletePost(ctx context.Context, postID string, deleterId int, reason string) error
+
+	GetHeldPosts(ctx context.Context, pageNumber, pageSize int) ([]*domain.Post, utils.Pagination, error)
+	ReleaseHeldPost(ctx context.Context, postID string, moderatorId int) error
+	RejectHeldPost(ctx context.Context, postID string, moderatorId int) error
 }
@@ -0,0 +1,18 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+)
+
+type PresenceService interface {
+	GetUserPresence(ctx context.Context, viewerID *int, userID int) (*domain.UserPresence, error)
+	GetVisibleOnlineUsers(ctx context.Context, viewerID *int) ([]int, int, error)
+	SearchVisibleOnlineUsers(ctx context.Context, viewerID int, prefix string, limit int) ([]*domain.User, error)
+	PublishPresence(ctx context.Context, userID int, status value.ActivityStatus) error
+	PublishVisibilityChange(ctx context.Context, userID int, previous, current value.PresenceVisibility)
+	RecordLastSeen(ctx context.Context, userID int, lastSeen time.Time) error
+}
@@ -20,4 +20,6 @@ type RecommendationRepository interface {
 	RecommendationStackCount(ctx context.Context, receiverID int) (int64, error)
 	GetForUser(ctx context.Context, receiverID, pageNumber, pageSize int) ([]*domain.Recommendation, utils.Pagination, error)
 	DismissRecommendation(ctx context.Context, receiverID, anime int) error
+	DeleteUserRecommendations(ctx context.Context, userID int) error
+	GetAllForUser(ctx context.Context, userID int) ([]*domain.Recommendation, error)
 }
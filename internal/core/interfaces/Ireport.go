@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"time"
 
 	"github.com/afuradanime/backend/internal/adapters/repositories"
 	"github.com/afuradanime/backend/internal/core/domain"
@@ -9,19 +10,26 @@ import (
 	"github.com/afuradanime/backend/internal/core/utils"
 )
 
-type UserReportService interface {
+type ReportService interface {
 	SubmitReport(ctx context.Context, reason value.ReportReason, targetUserID, reporterID int) error
-	GetReports(ctx context.Context, pageNumber, pageSize int) ([]repositories.ReportResult, utils.Pagination, error)
-	GetReportsByTarget(ctx context.Context, targetUserID int, pageNumber, pageSize int) ([]domain.UserReport, utils.Pagination, error)
+	SubmitPostReport(ctx context.Context, reason value.ReportReason, postID string, reporterID int) error
+	SubmitGroupReport(ctx context.Context, reason value.ReportReason, groupID int, reporterID int) error
+	SubmitTranslationReport(ctx context.Context, reason value.ReportReason, translationID int, reporterID int) error
+	ResolveContentCase(ctx context.Context, targetType value.ReportTargetType, targetID string, outcome value.CaseOutcome, moderatorID int) error
+	GetReports(ctx context.Context, targetType *value.ReportTargetType, pageNumber, pageSize int) ([]repositories.ReportResult, utils.Pagination, error)
+	GetReportsByTarget(ctx context.Context, targetUserID int, pageNumber, pageSize int) ([]domain.Report, utils.Pagination, error)
 	DeleteReport(ctx context.Context, id int, moderatorID int) error
 }
 
-type UserReportRepository interface {
-	CreateReport(ctx context.Context, report *domain.UserReport) error
-	GetReportByID(ctx context.Context, id int) (*domain.UserReport, error)
+type ReportRepository interface {
+	CreateReport(ctx context.Context, report *domain.Report) error
+	GetReportByID(ctx context.Context, id int) (*domain.Report, error)
 	DeleteReport(ctx context.Context, id int) error
-	GetReports(ctx context.Context, pageNumber, pageSize int) ([]repositories.ReportResult, utils.Pagination, error)
-	GetReportsByTarget(ctx context.Context, targetUserID int, pageNumber, pageSize int) ([]domain.UserReport, utils.Pagination, error)
-	CountReportsByTarget(ctx context.Context, targetUserID int) (int, error)
+	GetReports(ctx context.Context, targetType *value.ReportTargetType, pageNumber, pageSize int) ([]repositories.ReportResult, utils.Pagination, error)
+	GetReportsByTarget(ctx context.Context, targetUserID int, pageNumber, pageSize int) ([]domain.Report, utils.Pagination, error)
+	CountReportersByTarget(ctx context.Context, targetUserID, excluding int, since time.Time) (int, error)
 	HasReported(ctx context.Context, reporterID, targetUserID int) (bool, error)
+	HasReportedContent(ctx context.Context, reporterID int, targetType value.ReportTargetType, targetID string) (bool, error)
+	GetReportsByCase(ctx context.Context, caseID int) ([]domain.Report, error)
+	GetReportsByReporter(ctx context.Context, reporterID int) ([]domain.Report, error)
 }
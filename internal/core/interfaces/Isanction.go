@@ -0,0 +1,33 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type SanctionService interface {
+	IssueSanction(ctx context.Context, userID int, sanctionType value.SanctionType, reason string, duration time.Duration, issuerID int) (*domain.Sanction, error)
+	LiftSanction(ctx context.Context, id int, moderatorID int) error
+	LiftExpired(ctx context.Context) (int, error)
+
+	GetUserSanctions(ctx context.Context, userID int, pageNumber, pageSize int) ([]*domain.Sanction, utils.Pagination, error)
+	GetActiveSanctions(ctx context.Context, userID int) ([]*domain.Sanction, error)
+
+	AppealSanction(ctx context.Context, id int, text string, userID int) error
+	GetPendingAppeals(ctx context.Context, pageNumber, pageSize int) ([]*domain.Sanction, utils.Pagination, error)
+	ReviewAppeal(ctx context.Context, id int, accepted bool, response string, moderatorID int) error
+}
+
+type SanctionRepository interface {
+	CreateSanction(ctx context.Context, sanction *domain.Sanction) error
+	GetSanction(ctx context.Context, id int) (*domain.Sanction, error)
+	UpdateSanction(ctx context.Context, sanction *domain.Sanction) error
+	GetActiveSanctions(ctx context.Context, userID int) ([]*domain.Sanction, error)
+	GetExpiredSanctions(ctx context.Context, now time.Time) ([]*domain.Sanction, error)
+	GetSanctionsByUser(ctx context.Context, userID int, pageNumber, pageSize int) ([]*domain.Sanction, utils.Pagination, error)
+	GetPendingAppeals(ctx context.Context, pageNumber, pageSize int) ([]*domain.Sanction, utils.Pagination, error)
+}
@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+)
+
+type SessionService interface {
+	StartSession(ctx context.Context, user *domain.User, userAgent, ip string) (accessToken string, refreshToken string, err error)
+	Refresh(ctx context.Context, refreshToken string) (accessToken string, newRefreshToken string, err error)
+	IsSessionActive(ctx context.Context, sessionID string) bool
+
+	GetSessions(ctx context.Context, userID int) ([]*domain.Session, error)
+	RevokeSession(ctx context.Context, sessionID string, userID int) error
+	RevokeByRefreshToken(ctx context.Context, refreshToken string) error
+	RevokeAllSessions(ctx context.Context, userID int) (int, error)
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *domain.Session) error
+	GetSession(ctx context.Context, id string) (*domain.Session, error)
+	GetSessionByRefreshHash(ctx context.Context, hash string) (*domain.Session, error)
+	GetSessionByPreviousHash(ctx context.Context, hash string) (*domain.Session, error)
+	RotateSession(ctx context.Context, session *domain.Session, oldHash string) (bool, error)
+	RevokeSession(ctx context.Context, id string, at time.Time) error
+	RevokeUserSessions(ctx context.Context, userID int, at time.Time) (int, error)
+	GetActiveSessions(ctx context.Context, userID int) ([]*domain.Session, error)
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import (
+	"context"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+)
+
+type SigningKeyService interface {
+	Init(ctx context.Context) error
+	Reload(ctx context.Context) ([]*domain.SigningKey, error)
+	Rotate(ctx context.Context) error
+	GetJWKS() domain.JWKSet
+}
+
+type SigningKeyRepository interface {
+	CreateKey(ctx context.Context, key *domain.SigningKey) error
+	GetKeys(ctx context.Context) ([]*domain.SigningKey, error)
+	RetireKey(ctx context.Context, key *domain.SigningKey) error
+	DeleteExpiredKeys(ctx context.Context, now time.Time) (int, error)
+}
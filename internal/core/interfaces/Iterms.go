@@ -0,0 +1,25 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+)
+
+type TermsService interface {
+	PublishTerms(ctx context.Context, content, summary string, adminID int) (*domain.TermsOfService, error)
+	GetCurrentTerms(ctx context.Context) (*domain.TermsOfService, error)
+	GetTerms(ctx context.Context, version int) (*domain.TermsOfService, error)
+	GetTermsHistory(ctx context.Context) ([]*domain.TermsOfService, error)
+	AcceptTerms(ctx context.Context, userID, version int) error
+
+	CurrentTermsVersion(ctx context.Context) int
+	HasAcceptedTerms(ctx context.Context, userID int) bool
+}
+
+type TermsRepository interface {
+	CreateTerms(ctx context.Context, terms *domain.TermsOfService) error
+	GetTerms(ctx context.Context, version int) (*domain.TermsOfService, error)
+	GetLatestTerms(ctx context.Context) (*domain.TermsOfService, error)
+	GetTermsHistory(ctx context.Context) ([]*domain.TermsOfService, error)
+}
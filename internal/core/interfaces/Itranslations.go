@@ -15,6 +15,7 @@ type DescriptionTranslationRepository interface {
 	GetTranslationByAnime(ctx context.Context, anime int) (*domain.DescriptionTranslation, *domain.User, *domain.User, error)
 	GetTranslationsByUser(ctx context.Context, userID int, pageNumber, pageSize int) ([]domain.DescriptionTranslation, utils.Pagination, error)
 	GetTranslationByAnimeFromUser(ctx context.Context, anime int, id int) (*domain.DescriptionTranslation, error)
+	GetAllTranslationsByUser(ctx context.Context, userID int) ([]domain.DescriptionTranslation, error)
 
 	GetPendingTranslations(ctx context.Context, pageNumber, pageSize int) ([]repositories.PendingTranslationResult, utils.Pagination, error)
 
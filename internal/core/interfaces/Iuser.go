@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
 	"github.com/afuradanime/backend/internal/core/utils"
 )
 
@@ -16,16 +17,25 @@ type UserService interface {
 	RegisterUser(ctx context.Context, user *domain.User) (*domain.User, error)
 	UpdatePersonalInfo(ctx context.Context, id int, email *string, username *string, location *string, 
 		pronouns *string, socials *[]string, birthday *time.Time, allowsFR, allowsRec, privateList *bool, 
-		avatarURL *string, acceptTerms *bool) error
-	RestrictAccount(ctx context.Context, id int, canPost, canTranslate bool) error
+		avatarURL *string, presenceVisibility *value.PresenceVisibility) error
+	RestrictAccount(ctx context.Context, id int, canPost, canTranslate bool, moderatorID int) error
 	UpdateLastLogin(ctx context.Context, id int) error
 }
 
 type UserRepository interface {
 	GetUsers(ctx context.Context, pageNumber, pageSize int) ([]*domain.User, utils.Pagination, error)
 	SearchByUsername(ctx context.Context, username string, pageNumber, pageSize int) ([]*domain.User, utils.Pagination, error)
+	SearchByUsernamePrefix(ctx context.Context, prefix string, limit int) ([]*domain.User, error)
+	SearchByUsernamePrefixAmong(ctx context.Context, prefix string, ids []int, limit int) ([]*domain.User, error)
 	GetUserById(ctx context.Context, id int) (*domain.User, error)
+	GetUsersByIds(ctx context.Context, ids []int) ([]*domain.User, error)
 	GetUserByProvider(ctx context.Context, provider string, providerID string) (*domain.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
 	CreateUser(ctx context.Context, user *domain.User) (*domain.User, error) // same as RegisterUser
 	UpdateUser(ctx context.Context, user *domain.User) error
+	UpdateLastSeen(ctx context.Context, id int, lastSeen time.Time) error
+	SetDeletionRequestedAt(ctx context.Context, id int, at *time.Time) error
+	GetUsersDueForDeletion(ctx context.Context, requestedBefore time.Time) ([]*domain.User, error)
+	AnonymiseUser(ctx context.Context, user *domain.User) error
+	DeleteUser(ctx context.Context, id int) error
 }
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+)
+
+// Deletes accounts once their grace period is over, everything tied to the person goes
+// and the user record stays behind anonymised
+type AccountDeletionService struct {
+	userRepository           interfaces.UserRepository
+	identityRepository       interfaces.IdentityRepository
+	sessionService           interfaces.SessionService
+	personalTokenRepository  interfaces.PersonalTokenRepository
+	oauthGrantRepository     interfaces.OAuthGrantRepository
+	oauthTokenRepository     interfaces.OAuthTokenRepository
+	postRepository           interfaces.PostRepository
+	animeListService         interfaces.AnimeListService
+	friendshipRepository     interfaces.FriendshipRepository
+	recommendationRepository interfaces.RecommendationRepository
+	reportRepository         interfaces.ReportRepository
+	caseRepository           interfaces.ModerationCaseRepository
+	groupService             interfaces.GroupService
+	dataExportRepository     interfaces.DataExportRepository
+}
+
+func NewAccountDeletionService(
+	userRepo interfaces.UserRepository,
+	identityRepo interfaces.IdentityRepository,
+	sessionService interfaces.SessionService,
+	personalTokenRepo interfaces.PersonalTokenRepository,
+	oauthGrantRepo interfaces.OAuthGrantRepository,
+	oauthTokenRepo interfaces.OAuthTokenRepository,
+	postRepo interfaces.PostRepository,
+	animeListService interfaces.AnimeListService,
+	friendshipRepo interfaces.FriendshipRepository,
+	recommendationRepo interfaces.RecommendationRepository,
+	reportRepo interfaces.ReportRepository,
+	caseRepo interfaces.ModerationCaseRepository,
+	groupService interfaces.GroupService,
+	dataExportRepo interfaces.DataExportRepository,
+) *AccountDeletionService {
+	return &AccountDeletionService{
+		userRepository:           userRepo,
+		identityRepository:       identityRepo,
+		sessionService:           sessionService,
+		personalTokenRepository:  personalTokenRepo,
+		oauthGrantRepository:     oauthGrantRepo,
+		oauthTokenRepository:     oauthTokenRepo,
+		postRepository:           postRepo,
+		animeListService:         animeListService,
+		friendshipRepository:     friendshipRepo,
+		recommendationRepository: recommendationRepo,
+		reportRepository:         reportRepo,
+		caseRepository:           caseRepo,
+		groupService:             groupService,
+		dataExportRepository:     dataExportRepo,
+	}
+}
+
+func (s *AccountDeletionService) getUser(ctx context.Context, userID int) (*domain.User, error) {
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain_errors.UserNotFoundError{UserID: strconv.Itoa(userID)}
+	}
+	return user, nil
+}
+
+// Schedules the deletion, returns when it will happen
+func (s *AccountDeletionService) RequestDeletion(ctx context.Context, userID int) (time.Time, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if err := user.RequestDeletion(); err != nil {
+		return time.Time{}, err
+	}
+	if err := s.userRepository.SetDeletionRequestedAt(ctx, userID, user.DeletionRequestedAt); err != nil {
+		return time.Time{}, err
+	}
+
+	return *user.DeletionDueAt(), nil
+}
+
+func (s *AccountDeletionService) CancelDeletion(ctx context.Context, userID int) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := user.CancelDeletion(); err != nil {
+		return err
+	}
+	return s.userRepository.SetDeletionRequestedAt(ctx, userID, nil)
+}
+
+// Every step can run again, if one fails the user stays pending and the next run picks it up
+// from the top. The user record is anonymised last so it's only marked deleted once all the rest is gone
+func (s *AccountDeletionService) DeleteAccount(ctx context.Context, userID int) error {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.IsDeleted() {
+		return domain_errors.AccountDeletedError{}
+	}
+	now := time.Now()
+
+	// Nobody gets in anymore, not even with a token
+	if _, err := s.sessionService.RevokeAllSessions(ctx, userID); err != nil {
+		return errors.New("Failed to revoke sessions: " + err.Error())
+	}
+	if err := s.identityRepository.DeleteUserIdentities(ctx, userID); err != nil {
+		return errors.New("Failed to delete linked accounts: " + err.Error())
+	}
+	if err := s.personalTokenRepository.RevokeUserTokens(ctx, userID, now); err != nil {
+		return errors.New("Failed to revoke personal tokens: " + err.Error())
+	}
+	if err := s.oauthTokenRepository.RevokeUserTokens(ctx, userID, now); err != nil {
+		return errors.New("Failed to revoke app tokens: " + err.Error())
+	}
+	if err := s.oauthGrantRepository.DeleteUserGrants(ctx, userID); err != nil {
+		return errors.New("Failed to delete app grants: " + err.Error())
+	}
+
+	// What they wrote stays in the threads it's part of, just without them
+	if _, err := s.postRepository.AnonymiseUserPosts(ctx, userID); err != nil {
+		return errors.New("Failed to anonymise posts: " + err.Error())
+	}
+	if _, err := s.postRepository.DeleteThreads(ctx, strconv.Itoa(userID), value.ParentTypeUser); err != nil {
+		return errors.New("Failed to delete profile posts: " + err.Error())
+	}
+
+	if err := s.animeListService.DeleteUserList(ctx, userID); err != nil {
+		return errors.New("Failed to delete anime list: " + err.Error())
+	}
+	if err := s.friendshipRepository.DeleteUserFriendships(ctx, userID); err != nil {
+		return errors.New("Failed to delete friendships: " + err.Error())
+	}
+	if err := s.recommendationRepository.DeleteUserRecommendations(ctx, userID); err != nil {
+		return errors.New("Failed to delete recommendations: " + err.Error())
+	}
+	// Their groups go on without them
+	if err := s.groupService.LeaveAllGroups(ctx, userID); err != nil {
+		return errors.New("Failed to leave groups: " + err.Error())
+	}
+
+	// Only what they filed goes, the cases those reports were in count one less
+	if err := s.deleteFiledReports(ctx, userID); err != nil {
+		return errors.New("Failed to delete reports: " + err.Error())
+	}
+	if err := s.dataExportRepository.DeleteUserExports(ctx, userID); err != nil {
+		return errors.New("Failed to delete data exports: " + err.Error())
+	}
+
+	user.Anonymise()
+	return s.userRepository.AnonymiseUser(ctx, user)
+}
+
+// Reports about the user are left alone, they're part of the record moderators act on. Each report
+// goes before its case is counted down, a run that fails in between never counts the same one twice
+func (s *AccountDeletionService) deleteFiledReports(ctx context.Context, userID int) error {
+	reports, err := s.reportRepository.GetReportsByReporter(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		if err := s.reportRepository.DeleteReport(ctx, report.ID); err != nil {
+			return err
+		}
+		if report.CaseID == 0 {
+			continue
+		}
+		if err := s.caseRepository.IncrementReportCount(ctx, report.CaseID, -1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deletes every account whose grace period ran out, returns how many went
+func (s *AccountDeletionService) DeleteDueAccounts(ctx context.Context) (int, error) {
+	users, err := s.userRepository.GetUsersDueForDeletion(ctx, time.Now().Add(-domain.ACCOUNT_DELETION_GRACE_PERIOD))
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, user := range users {
+		if err := s.DeleteAccount(ctx, user.ID); err != nil {
+			log.Printf("Failed to delete account %d: %v", user.ID, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+func (s *AccountDeletionService) StartDeletionJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := s.DeleteDueAccounts(context.Background()); err != nil {
+				log.Printf("Failed to delete accounts past their grace period: %v", err)
+			}
+		}
+	}()
+}
@@ -81,11 +81,49 @@ func (s *AnimeListService) RemoveAnimeFromList(ctx context.Context, userID int,
 		return err
 	}
 
+	// The rating goes with the entry
+	if item, ok := list.GetListItem(animeID); ok {
+		s.uncacheRating(ctx, userID, item)
+	}
+
 	list.RemoveListItem(animeID)
 
 	return s.listRepo.SaveUserList(ctx, list)
 }
 
+// Drops the whole list, the list goes first so a retry can't take the same ratings off the caches twice
+func (s *AnimeListService) DeleteUserList(ctx context.Context, userID int) error {
+	list, err := s.listRepo.FetchUserList(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if list == nil {
+		return nil
+	}
+
+	if err := s.listRepo.DeleteUserList(ctx, userID); err != nil {
+		return err
+	}
+
+	for i := range list.UserListItems {
+		s.uncacheRating(ctx, userID, &list.UserListItems[i])
+	}
+	return nil
+}
+
+// Takes the item's rating off the anime's rating cache, if it has one
+func (s *AnimeListService) uncacheRating(ctx context.Context, userID int, item *domain.UserListItem) {
+	if item.Rating == nil {
+		return
+	}
+
+	rating := domain.Uint16ToRating(*item.Rating)
+	err := s.ratingCacheService.RemoveRating(ctx, userID, int(item.AnimeID), rating.Story, rating.Visuals, rating.Soundtrack)
+	if err != nil {
+		log.Printf("Failed to remove rating of user %d from anime %d: %v", userID, item.AnimeID, err)
+	}
+}
+
 func (s *AnimeListService) UpdateProgress(ctx context.Context, userID int, animeID uint32, episodesWatched uint32) error {
 	list, item, err := s.getListAndItem(ctx, userID, animeID)
 	if err != nil {
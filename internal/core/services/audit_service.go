@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type AuditService struct {
+	auditRepository interfaces.AuditLogRepository
+}
+
+func NewAuditService(auditRepo interfaces.AuditLogRepository) *AuditService {
+	return &AuditService{auditRepository: auditRepo}
+}
+
+// The log is there to look back on, failing to write it never undoes the action
+func (s *AuditService) Record(ctx context.Context, entry *domain.AuditEntry) {
+	if err := s.auditRepository.CreateEntry(ctx, entry); err != nil {
+		log.Printf("Failed to audit action %d by user %d: %v", entry.Action, entry.Actor, err)
+	}
+}
+
+func (s *AuditService) GetEntries(ctx context.Context, f filters.AuditFilter, pageNumber, pageSize int) ([]*domain.AuditEntry, utils.Pagination, error) {
+	return s.auditRepository.GetEntries(ctx, f, pageNumber, pageSize)
+}
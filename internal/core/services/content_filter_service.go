@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+// Every post goes through the enabled rules, so they're kept in memory already compiled.
+// Changes made through this service are seen right away, this only bounds how long other instances take to notice
+const FILTER_RULES_CHECK_INTERVAL = time.Minute
+
+type ContentFilterService struct {
+	ruleRepository interfaces.FilterRuleRepository
+	hitRepository  interfaces.FilterHitRepository
+	postRepository interfaces.PostRepository
+	reportService  interfaces.ReportService
+	auditService   interfaces.AuditService
+
+	mu       sync.Mutex
+	rules    []*domain.FilterRule // nil until loaded
+	loadedAt time.Time
+}
+
+func NewContentFilterService(
+	ruleRepo interfaces.FilterRuleRepository,
+	hitRepo interfaces.FilterHitRepository,
+	postRepo interfaces.PostRepository,
+	reportService interfaces.ReportService,
+	auditService interfaces.AuditService,
+) *ContentFilterService {
+	return &ContentFilterService{
+		ruleRepository: ruleRepo,
+		hitRepository:  hitRepo,
+		postRepository: postRepo,
+		reportService:  reportService,
+		auditService:   auditService,
+	}
+}
+
+// Runs the text through every enabled rule, an empty verdict means the post is fine
+func (s *ContentFilterService) Check(ctx context.Context, poster *domain.User, text string) (domain.FilterVerdict, error) {
+	var verdict domain.FilterVerdict
+
+	rules, err := s.enabledRules(ctx)
+	if err != nil {
+		return verdict, err
+	}
+
+	input := domain.FilterInput{
+		Text:       text,
+		AccountAge: time.Since(poster.CreatedAt),
+	}
+
+	// Only go looking for duplicates when some rule cares about them
+	for _, rule := range rules {
+		if rule.Kind == value.FilterRuleRepeatedText {
+			input.RecentDuplicates, err = s.postRepository.CountPostsWithText(ctx, poster.ID, text, time.Now().Add(-domain.REPEATED_TEXT_WINDOW))
+			if err != nil {
+				return verdict, err
+			}
+			break
+		}
+	}
+
+	for _, rule := range rules {
+		if rule.Matches(input) {
+			verdict.Apply(rule)
+		}
+	}
+
+	return verdict, nil
+}
+
+func (s *ContentFilterService) enabledRules(ctx context.Context) ([]*domain.FilterRule, error) {
+	s.mu.Lock()
+	cached, loadedAt := s.rules, s.loadedAt
+	s.mu.Unlock()
+	if cached != nil && time.Since(loadedAt) < FILTER_RULES_CHECK_INTERVAL {
+		return cached, nil
+	}
+
+	rules, err := s.ruleRepository.GetRules(ctx, true)
+	if err != nil {
+		if cached != nil {
+			log.Printf("Failed to reload filter rules: %v", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	compiled := make([]*domain.FilterRule, 0, len(rules))
+	for _, rule := range rules {
+		if err := rule.Compile(); err != nil {
+			log.Printf("Skipping filter rule %d: %v", rule.ID, err)
+			continue
+		}
+		compiled = append(compiled, rule)
+	}
+
+	s.mu.Lock()
+	s.rules, s.loadedAt = compiled, time.Now()
+	s.mu.Unlock()
+	return compiled, nil
+}
+
+// The next post loads the rules again
+func (s *ContentFilterService) forgetRules() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = nil
+}
+
+// Keeps track of what the filter caught, held posts also land in the report queue.
+// Rejected posts were never stored so post is nil for them
+func (s *ContentFilterService) RecordHit(ctx context.Context, verdict domain.FilterVerdict, post *domain.Post, posterID int, text string) {
+	postID := ""
+	if post != nil {
+		postID = post.ID
+	}
+
+	if err := s.hitRepository.CreateHit(ctx, domain.NewFilterHit(verdict, postID, posterID, text)); err != nil {
+		log.Printf("Failed to record filter hit for user %d: %v", posterID, err)
+	}
+
+	if verdict.Action == value.FilterActionHold && post != nil {
+		if err := s.reportService.SubmitPostReport(ctx, value.ReportReasonOther, post.ID, domain.SYSTEM_USER_ID); err != nil {
+			log.Printf("Failed to report held post %s: %v", post.ID, err)
+		}
+	}
+}
+
+// The held post was looked at, the case the filter opened for it goes with it
+func (s *ContentFilterService) ResolveHold(ctx context.Context, post *domain.Post, released bool, moderatorID int) {
+	outcome := value.CaseOutcomeContentRemoved
+	if released {
+		outcome = value.CaseOutcomeNoViolation
+	}
+
+	if err := s.reportService.ResolveContentCase(ctx, value.ReportTargetPost, post.ID, outcome, moderatorID); err != nil {
+		log.Printf("Failed to resolve the case of held post %s: %v", post.ID, err)
+	}
+}
+
+func (s *ContentFilterService) GetRules(ctx context.Context) ([]*domain.FilterRule, error) {
+	return s.ruleRepository.GetRules(ctx, false)
+}
+
+func (s *ContentFilterService) CreateRule(
+	ctx context.Context,
+	kind value.FilterRuleKind,
+	pattern string,
+	threshold int,
+	action value.FilterAction,
+	description string,
+	adminID int,
+) (*domain.FilterRule, error) {
+	rule, err := domain.NewFilterRule(kind, pattern, threshold, action, description, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ruleRepository.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.forgetRules()
+	s.auditService.Record(ctx, domain.NewAuditEntry(adminID, value.AuditFilterRuleCreated, value.AuditTargetFilterRule, strconv.Itoa(rule.ID)).
+		WithDetails(rule.Pattern))
+	return rule, nil
+}
+
+func (s *ContentFilterService) UpdateRule(
+	ctx context.Context,
+	id int,
+	pattern string,
+	threshold int,
+	action value.FilterAction,
+	description string,
+	enabled bool,
+	adminID int,
+) (*domain.FilterRule, error) {
+	rule, err := s.getRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := rule.Update(pattern, threshold, action, description); err != nil {
+		return nil, err
+	}
+	rule.SetEnabled(enabled)
+
+	if err := s.ruleRepository.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	s.forgetRules()
+	s.auditService.Record(ctx, domain.NewAuditEntry(adminID, value.AuditFilterRuleUpdated, value.AuditTargetFilterRule, strconv.Itoa(id)).
+		WithDetails(rule.Pattern))
+	return rule, nil
+}
+
+func (s *ContentFilterService) DeleteRule(ctx context.Context, id int, adminID int) error {
+	rule, err := s.getRule(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.ruleRepository.DeleteRule(ctx, id); err != nil {
+		return err
+	}
+
+	s.forgetRules()
+	s.auditService.Record(ctx, domain.NewAuditEntry(adminID, value.AuditFilterRuleDeleted, value.AuditTargetFilterRule, strconv.Itoa(id)).
+		WithDetails(rule.Pattern))
+	return nil
+}
+
+func (s *ContentFilterService) getRule(ctx context.Context, id int) (*domain.FilterRule, error) {
+	rule, err := s.ruleRepository.GetRule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, domain_errors.FilterRuleNotFoundError{}
+	}
+	return rule, nil
+}
+
+func (s *ContentFilterService) GetHits(ctx context.Context, action *value.FilterAction, pageNumber, pageSize int) ([]*domain.FilterHit, utils.Pagination, error) {
+	return s.hitRepository.GetHits(ctx, action, pageNumber, pageSize)
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type DataExportService struct {
+	exportRepository         interfaces.DataExportRepository
+	userRepository           interfaces.UserRepository
+	animeListRepository      interfaces.AnimeListRepository
+	postRepository           interfaces.PostRepository
+	friendshipRepository     interfaces.FriendshipRepository
+	recommendationRepository interfaces.RecommendationRepository
+	translationRepository    interfaces.DescriptionTranslationRepository
+	reportRepository         interfaces.ReportRepository
+	membershipRepository     interfaces.GroupMembershipRepository
+	identityRepository       interfaces.IdentityRepository
+	sessionRepository        interfaces.SessionRepository
+	personalTokenRepository  interfaces.PersonalTokenRepository
+	oauthGrantRepository     interfaces.OAuthGrantRepository
+	oauthTokenRepository     interfaces.OAuthTokenRepository
+	sanctionRepository       interfaces.SanctionRepository
+	notificationService      interfaces.NotificationService
+}
+
+func NewDataExportService(
+	exportRepo interfaces.DataExportRepository,
+	userRepo interfaces.UserRepository,
+	animeListRepo interfaces.AnimeListRepository,
+	postRepo interfaces.PostRepository,
+	friendshipRepo interfaces.FriendshipRepository,
+	recommendationRepo interfaces.RecommendationRepository,
+	translationRepo interfaces.DescriptionTranslationRepository,
+	reportRepo interfaces.ReportRepository,
+	membershipRepo interfaces.GroupMembershipRepository,
+	identityRepo interfaces.IdentityRepository,
+	sessionRepo interfaces.SessionRepository,
+	personalTokenRepo interfaces.PersonalTokenRepository,
+	oauthGrantRepo interfaces.OAuthGrantRepository,
+	oauthTokenRepo interfaces.OAuthTokenRepository,
+	sanctionRepo interfaces.SanctionRepository,
+	notificationService interfaces.NotificationService,
+) *DataExportService {
+	return &DataExportService{
+		exportRepository:         exportRepo,
+		userRepository:           userRepo,
+		animeListRepository:      animeListRepo,
+		postRepository:           postRepo,
+		friendshipRepository:     friendshipRepo,
+		recommendationRepository: recommendationRepo,
+		translationRepository:    translationRepo,
+		reportRepository:         reportRepo,
+		membershipRepository:     membershipRepo,
+		identityRepository:       identityRepo,
+		sessionRepository:        sessionRepo,
+		personalTokenRepository:  personalTokenRepo,
+		oauthGrantRepository:     oauthGrantRepo,
+		oauthTokenRepository:     oauthTokenRepo,
+		sanctionRepository:       sanctionRepo,
+		notificationService:      notificationService,
+	}
+}
+
+// Queues the export and returns straight away, the user gets a notification once it's ready
+func (s *DataExportService) RequestExport(ctx context.Context, userID int) (*domain.DataExport, error) {
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain_errors.UserNotFoundError{UserID: strconv.Itoa(userID)}
+	}
+	if user.IsDeleted() {
+		return nil, domain_errors.AccountDeletedError{}
+	}
+
+	exports, err := s.exportRepository.GetUserExports(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(exports) > 0 && exports[0].BlocksNewExport() {
+		if exports[0].IsPending() {
+			return nil, domain_errors.DataExportInProgressError{}
+		}
+		return nil, domain_errors.DataExportCooldownError{}
+	}
+
+	export := domain.NewDataExport(userID)
+	if err := s.exportRepository.CreateExport(ctx, export); err != nil {
+		return nil, err
+	}
+
+	go s.buildExport(export)
+	return export, nil
+}
+
+func (s *DataExportService) GetExports(ctx context.Context, userID int) ([]*domain.DataExport, error) {
+	return s.exportRepository.GetUserExports(ctx, userID)
+}
+
+// The archive is streamed out as it's read, the caller closes it
+func (s *DataExportService) DownloadExport(ctx context.Context, userID int, exportID string) (*domain.DataExport, io.ReadCloser, error) {
+	export, err := s.exportRepository.GetExport(ctx, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Pretend other people's exports don't exist
+	if export == nil || export.UserID != userID {
+		return nil, nil, domain_errors.DataExportNotFoundError{ExportID: exportID}
+	}
+	if export.IsPending() {
+		return nil, nil, domain_errors.DataExportNotReadyError{}
+	}
+	if !export.IsAvailable() {
+		return nil, nil, domain_errors.DataExportNotFoundError{ExportID: exportID}
+	}
+
+	archive, err := s.exportRepository.OpenArchive(ctx, exportID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if archive == nil {
+		return nil, nil, domain_errors.DataExportNotFoundError{ExportID: exportID}
+	}
+
+	return export, archive, nil
+}
+
+// Runs outside the request, so it gets its own context
+func (s *DataExportService) buildExport(export *domain.DataExport) {
+	ctx, cancel := context.WithTimeout(context.Background(), domain.DATA_EXPORT_BUILD_TIMEOUT)
+	defer cancel()
+
+	archive, err := s.assembleArchive(ctx, export.UserID)
+	if err == nil {
+		export.MarkReady(len(archive))
+		err = s.exportRepository.SaveArchive(ctx, export.ID, archive, export.ExpiresAt)
+	}
+	if err != nil {
+		log.Printf("Failed to build data export %s: %v", export.ID, err)
+		export.MarkFailed()
+	}
+
+	if err := s.exportRepository.UpdateExport(ctx, export); err != nil {
+		log.Printf("Failed to save data export %s: %v", export.ID, err)
+		return
+	}
+	if export.IsAvailable() {
+		// Nobody in particular sent this one
+		if err := s.notificationService.Notify(ctx, export.UserID, 0, value.NotificationDataExportReady, export.ID); err != nil {
+			log.Printf("Failed to send data export notification: %v", err)
+		}
+	}
+}
+
+type archiveFile struct {
+	name string
+	data any
+}
+
+func (s *DataExportService) assembleArchive(ctx context.Context, userID int) ([]byte, error) {
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain_errors.UserNotFoundError{UserID: strconv.Itoa(userID)}
+	}
+
+	profile := *user
+	if profile.Email, err = user.DecryptedEmail(); err != nil {
+		return nil, errors.New("Failed to decrypt email: " + err.Error())
+	}
+
+	list, err := s.animeListRepository.FetchUserList(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch anime list: " + err.Error())
+	}
+	posts, err := s.postRepository.GetUserPosts(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch posts: " + err.Error())
+	}
+	friendships, err := s.friendshipRepository.GetUserFriendships(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch friendships: " + err.Error())
+	}
+	recommendations, err := s.recommendationRepository.GetAllForUser(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch recommendations: " + err.Error())
+	}
+	translations, err := s.translationRepository.GetAllTranslationsByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch translations: " + err.Error())
+	}
+	reports, err := s.reportRepository.GetReportsByReporter(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch reports: " + err.Error())
+	}
+	memberships, err := s.membershipRepository.GetUserMemberships(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch group memberships: " + err.Error())
+	}
+	identities, err := s.identityRepository.GetUserIdentities(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch linked accounts: " + err.Error())
+	}
+	sessions, err := s.sessionRepository.GetActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch sessions: " + err.Error())
+	}
+	personalTokens, err := s.personalTokenRepository.GetUserTokens(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch personal tokens: " + err.Error())
+	}
+	grants, err := s.oauthGrantRepository.GetUserGrants(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch app grants: " + err.Error())
+	}
+	appTokens, err := s.oauthTokenRepository.GetUserTokens(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch app tokens: " + err.Error())
+	}
+	sanctions, err := s.userSanctions(ctx, userID)
+	if err != nil {
+		return nil, errors.New("Failed to fetch sanctions: " + err.Error())
+	}
+
+	sent := make([]*domain.Recommendation, 0)
+	received := make([]*domain.Recommendation, 0)
+	for _, rec := range recommendations {
+		if rec.Initiator == userID {
+			sent = append(sent, rec)
+		} else {
+			received = append(received, rec)
+		}
+	}
+
+	// The snapshot is whatever they reported, that's someone else's data
+	for i := range reports {
+		reports[i].Snapshot = ""
+	}
+
+	return writeArchive([]archiveFile{
+		{"profile.json", profile},
+		{"anime_list.json", list},
+		{"posts.json", posts},
+		{"friendships.json", friendships},
+		{"recommendations_sent.json", sent},
+		{"recommendations_received.json", received},
+		{"translations.json", translations},
+		{"reports.json", reports},
+		{"group_memberships.json", memberships},
+		{"linked_accounts.json", identities},
+		{"sessions.json", sessions},
+		{"personal_tokens.json", personalTokens},
+		{"app_grants.json", grants},
+		{"app_tokens.json", appTokens},
+		{"sanctions.json", sanctions},
+	})
+}
+
+// Every page of them, the repository only hands them out paginated
+func (s *DataExportService) userSanctions(ctx context.Context, userID int) ([]*domain.Sanction, error) {
+	sanctions := make([]*domain.Sanction, 0)
+	for page := 1; ; page++ {
+		batch, pagination, err := s.sanctionRepository.GetSanctionsByUser(ctx, userID, page, utils.MAX_PAGE_SIZE)
+		if err != nil {
+			return nil, err
+		}
+		sanctions = append(sanctions, batch...)
+		if page >= pagination.TotalPages {
+			return sanctions, nil
+		}
+	}
+}
+
+// Archives outlive their export by up to one interval, the metadata is what decides they're gone
+func (s *DataExportService) StartCleanupJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := s.exportRepository.DeleteExpiredArchives(context.Background(), time.Now()); err != nil {
+				log.Printf("Failed to delete expired data export archives: %v", err)
+			}
+		}
+	}()
+}
+
+func writeArchive(files []archiveFile) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, errors.New("Failed to write " + file.name + ": " + err.Error())
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
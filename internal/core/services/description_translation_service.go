@@ -17,17 +17,20 @@ type DescriptionTranslationService struct {
 	translationRepository interfaces.DescriptionTranslationRepository
 	animeRepository       interfaces.AnimeRepository
 	userRepository        interfaces.UserRepository
+	auditService          interfaces.AuditService
 }
 
 func NewDescriptionTranslationService(
 	translationRepo interfaces.DescriptionTranslationRepository,
 	animeRepo interfaces.AnimeRepository,
 	userRepo interfaces.UserRepository,
+	auditService interfaces.AuditService,
 ) *DescriptionTranslationService {
 	return &DescriptionTranslationService{
 		translationRepository: translationRepo,
 		animeRepository:       animeRepo,
 		userRepository:        userRepo,
+		auditService:          auditService,
 	}
 }
 
@@ -97,7 +100,15 @@ func (s *DescriptionTranslationService) RejectTranslation(ctx context.Context, i
 		return domain_errors.TranslationNotFoundError{}
 	}
 
-	return s.translationRepository.DeleteTranslation(ctx, id)
+	if err := s.translationRepository.DeleteTranslation(ctx, id); err != nil {
+		return err
+	}
+
+	// The translation is gone, the log keeps what it said
+	s.auditService.Record(ctx, domain.NewAuditEntry(moderatorID, value.AuditTranslationRejected, value.AuditTargetTranslation, strconv.Itoa(id)).
+		OnUser(t.CreatedBy).
+		WithDetails(string(t.TranslatedDescription)))
+	return nil
 }
 
 func (s *DescriptionTranslationService) AcceptTranslation(ctx context.Context, id int, moderatorID int) error {
@@ -110,5 +121,11 @@ func (s *DescriptionTranslationService) AcceptTranslation(ctx context.Context, i
 		return err
 	}
 
-	return s.translationRepository.UpdateTranslation(ctx, t)
+	if err := s.translationRepository.UpdateTranslation(ctx, t); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, domain.NewAuditEntry(moderatorID, value.AuditTranslationAccepted, value.AuditTargetTranslation, strconv.Itoa(id)).
+		OnUser(t.CreatedBy))
+	return nil
 }
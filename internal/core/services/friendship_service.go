@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"log"
 	"strconv"
 
 	"github.com/afuradanime/backend/internal/core/domain"
@@ -14,12 +15,18 @@ import (
 type FriendshipService struct {
 	userRepository       interfaces.UserRepository
 	friendshipRepository interfaces.FriendshipRepository
+	notificationService  interfaces.NotificationService
 }
 
-func NewFriendshipService(userRepo interfaces.UserRepository, friendshipRepo interfaces.FriendshipRepository) *FriendshipService {
+func NewFriendshipService(
+	userRepo interfaces.UserRepository,
+	friendshipRepo interfaces.FriendshipRepository,
+	notificationService interfaces.NotificationService,
+) *FriendshipService {
 	return &FriendshipService{
 		userRepository:       userRepo,
 		friendshipRepository: friendshipRepo,
+		notificationService:  notificationService,
 	}
 }
 
@@ -92,7 +99,16 @@ func (s *FriendshipService) SendFriendRequest(ctx context.Context, initiator int
 	}
 
 	friendship := domain.NewFriendRequest(initiator, receiver)
-	return s.friendshipRepository.CreateFriendship(ctx, friendship)
+	if err := s.friendshipRepository.CreateFriendship(ctx, friendship); err != nil {
+		return err
+	}
+
+	// The request went through either way, a failed notification shouldn't undo it
+	if err := s.notificationService.Notify(ctx, receiver, initiator, value.NotificationFriendRequest, ""); err != nil {
+		log.Printf("Failed to notify user %d of friend request from %d: %v", receiver, initiator, err)
+	}
+
+	return nil
 }
 
 func (s *FriendshipService) AcceptFriendRequest(ctx context.Context, initiator int, receiver int) error {
@@ -112,7 +128,15 @@ func (s *FriendshipService) AcceptFriendRequest(ctx context.Context, initiator i
 		return err
 	}
 
-	return s.friendshipRepository.UpdateFriendship(ctx, f)
+	if err := s.friendshipRepository.UpdateFriendship(ctx, f); err != nil {
+		return err
+	}
+
+	if err := s.notificationService.Notify(ctx, f.Initiator, f.Receiver, value.NotificationFriendRequestAccepted, ""); err != nil {
+		log.Printf("Failed to notify user %d of accepted friend request: %v", f.Initiator, err)
+	}
+
+	return nil
 }
 
 func (s *FriendshipService) DeclineFriendRequest(ctx context.Context, initiator int, receiver int) error {
@@ -158,6 +182,10 @@ func (s *FriendshipService) GetFriendList(ctx context.Context, userId int, pageN
 	return s.friendshipRepository.GetFriends(ctx, userId, pageNumber, pageSize)
 }
 
+func (s *FriendshipService) SearchFriends(ctx context.Context, userId int, prefix string, limit int) ([]domain.User, error) {
+	return s.friendshipRepository.SearchFriends(ctx, userId, prefix, limit)
+}
+
 func (s *FriendshipService) GetPendingFriendRequests(ctx context.Context, userId int, pageNumber, pageSize int) ([]domain.User, utils.Pagination, error) {
 	return s.friendshipRepository.GetPendingFriendRequests(ctx, userId, pageNumber, pageSize)
 }
@@ -2,8 +2,13 @@ package services
 
 import (
 	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
 
 	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
 	"github.com/afuradanime/backend/internal/core/domain/value"
 	domain_errors "github.com/afuradanime/backend/internal/core/errors"
 	"github.com/afuradanime/backend/internal/core/interfaces"
@@ -11,23 +16,148 @@ import (
 )
 
 type GroupService struct {
-	groupRepository interfaces.GroupRepository
-	userRepository  interfaces.UserRepository
+	groupRepository       interfaces.GroupRepository
+	membershipRepository  interfaces.GroupMembershipRepository
+	joinRequestRepository interfaces.GroupJoinRequestRepository
+	inviteRepository      interfaces.GroupInviteRepository
+	banRepository         interfaces.GroupBanRepository
+	logRepository         interfaces.GroupModerationLogRepository
+	userRepository        interfaces.UserRepository
+	animeRepository       interfaces.AnimeRepository
+	postRepository        interfaces.PostRepository
+	auditService          interfaces.AuditService
 }
 
-func NewGroupService(repo interfaces.GroupRepository, userRepository interfaces.UserRepository) *GroupService {
+func NewGroupService(
+	repo interfaces.GroupRepository,
+	membershipRepository interfaces.GroupMembershipRepository,
+	joinRequestRepository interfaces.GroupJoinRequestRepository,
+	inviteRepository interfaces.GroupInviteRepository,
+	banRepository interfaces.GroupBanRepository,
+	logRepository interfaces.GroupModerationLogRepository,
+	userRepository interfaces.UserRepository,
+	animeRepository interfaces.AnimeRepository,
+	postRepository interfaces.PostRepository,
+	auditService interfaces.AuditService,
+) *GroupService {
 	return &GroupService{
-		groupRepository: repo,
-		userRepository:  userRepository,
+		groupRepository:       repo,
+		membershipRepository:  membershipRepository,
+		joinRequestRepository: joinRequestRepository,
+		inviteRepository:      inviteRepository,
+		banRepository:         banRepository,
+		logRepository:         logRepository,
+		userRepository:        userRepository,
+		animeRepository:       animeRepository,
+		postRepository:        postRepository,
+		auditService:          auditService,
 	}
 }
 
+func (s *GroupService) CreateGroup(
+	ctx context.Context,
+	name, description, rules, icon string,
+	public bool,
+	animeID *uint32,
+	tags []uint32,
+	creator int,
+) (*domain.Group, error) {
+
+	user, err := s.userRepository.GetUserById(ctx, creator)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain_errors.UserNotFoundError{UserID: strconv.Itoa(creator)}
+	}
+
+	group, err := domain.NewGroup(name, description, rules, icon)
+	if err != nil {
+		return nil, err
+	}
+
+	if !public {
+		group.MakePrivate()
+	}
+
+	if err := s.applyCategories(group, animeID, tags); err != nil {
+		return nil, err
+	}
+
+	// Whoever creates the group owns it until they hand it over
+	group.SetOwner(creator)
+	group.MemberCount = 1
+
+	if err := s.groupRepository.CreateGroup(ctx, group); err != nil {
+		return nil, err
+	}
+
+	// A group nobody owns would be stuck, so it goes away with the membership
+	membership := domain.NewGroupMembership(group.ID, creator, value.GroupRoleOwner)
+	if err := s.membershipRepository.CreateMembership(ctx, membership); err != nil {
+		if err := s.groupRepository.DeleteGroup(ctx, group.ID); err != nil {
+			log.Printf("Failed to undo creation of group %d: %v", group.ID, err)
+		}
+		return nil, err
+	}
+
+	return group, nil
+}
+
 func (s *GroupService) GetGroup(ctx context.Context, groupId int) (*domain.Group, error) {
 	return s.groupRepository.GetGroup(ctx, groupId)
 }
 
-func (s *GroupService) GetGroups(ctx context.Context, pageNumber, pageSize int) ([]*domain.Group, utils.Pagination, error) {
-	return s.groupRepository.GetGroups(ctx, pageNumber, pageSize)
+func (s *GroupService) GetGroups(ctx context.Context, f filters.GroupFilter, pageNumber, pageSize int) ([]*domain.Group, utils.Pagination, error) {
+	return s.groupRepository.GetGroups(ctx, f, pageNumber, pageSize)
+}
+
+// Both are checked against the anime catalog, a group can't be about something that doesn't exist
+func (s *GroupService) applyCategories(group *domain.Group, animeID *uint32, tags []uint32) error {
+
+	if animeID != nil {
+		if _, err := s.animeRepository.FetchAnimeByID(*animeID); err != nil {
+			return err
+		}
+	}
+
+	for _, tag := range tags {
+		// A tag nobody uses is as good as a tag that doesn't exist
+		anime, _, err := s.animeRepository.FetchAnimeFromTag(tag, filters.AnimeFilter{}, 1, 1)
+		if err != nil {
+			return err
+		}
+		if len(anime) == 0 {
+			return domain_errors.InvalidGroupTagError{TagID: tag}
+		}
+	}
+
+	if err := group.UpdateTags(tags); err != nil {
+		return err
+	}
+
+	group.UpdateAnime(animeID)
+	return nil
+}
+
+// Replaces whatever the group was associated with, nil and empty clear them
+func (s *GroupService) UpdateGroupCategories(ctx context.Context, groupId int, animeID *uint32, tags []uint32, user int) error {
+
+	group, err := s.getWritableGroup(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+
+	if err := s.applyCategories(group, animeID, tags); err != nil {
+		return err
+	}
+
+	return s.groupRepository.UpdateGroup(ctx, group)
+}
+
+// Something was posted in the group, it's what trending groups are sorted by
+func (s *GroupService) RecordActivity(ctx context.Context, groupId int) error {
+	return s.groupRepository.UpdateLastActivity(ctx, groupId, time.Now())
 }
 
 func (s *GroupService) UpdateGroup(
@@ -46,6 +176,10 @@ func (s *GroupService) UpdateGroup(
 		return domain_errors.UnauthorizedError{}
 	}
 
+	if group.IsArchived() {
+		return domain_errors.GroupArchivedError{GroupID: groupId}
+	}
+
 	if name != "" {
 		if err := group.UpdateName(name); err != nil {
 			return err
@@ -94,11 +228,38 @@ func (s *GroupService) AddGroupModerator(
 		return domain_errors.UnauthorizedError{}
 	}
 
+	if group.IsArchived() {
+		return domain_errors.GroupArchivedError{GroupID: groupId}
+	}
+
+	target, err := s.userRepository.GetUserById(ctx, moderator)
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		return domain_errors.UserNotFoundError{UserID: strconv.Itoa(moderator)}
+	}
+
+	if err := s.checkNotBanned(ctx, groupId, moderator); err != nil {
+		return err
+	}
+
 	if err := group.AddModerator(moderator); err != nil {
 		return err
 	}
 
-	return s.groupRepository.UpdateGroup(ctx, group)
+	if err := s.groupRepository.UpdateGroup(ctx, group); err != nil {
+		return err
+	}
+
+	if err := s.setMemberRole(ctx, groupId, moderator, value.GroupRoleModerator); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, domain.NewGroupModerationEntry(groupId, user, value.GroupActionAddModerator, "").OnUser(moderator))
+	s.auditService.Record(ctx, domain.NewAuditEntry(user, value.AuditGroupModeratorAdded, value.AuditTargetGroup, strconv.Itoa(groupId)).
+		OnUser(moderator))
+	return nil
 }
 
 func (s *GroupService) RemoveGroupModerator(
@@ -122,9 +283,918 @@ func (s *GroupService) RemoveGroupModerator(
 		return domain_errors.UnauthorizedError{}
 	}
 
+	if group.IsArchived() {
+		return domain_errors.GroupArchivedError{GroupID: groupId}
+	}
+
 	if err := group.RemoveModerator(moderator); err != nil {
 		return err
 	}
 
-	return s.groupRepository.UpdateGroup(ctx, group)
+	if err := s.groupRepository.UpdateGroup(ctx, group); err != nil {
+		return err
+	}
+
+	// They stay in the group, just as a regular member
+	if err := s.setMemberRole(ctx, groupId, moderator, value.GroupRoleMember); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, domain.NewGroupModerationEntry(groupId, user, value.GroupActionRemoveModerator, "").OnUser(moderator))
+	s.auditService.Record(ctx, domain.NewAuditEntry(user, value.AuditGroupModeratorRemoved, value.AuditTargetGroup, strconv.Itoa(groupId)).
+		OnUser(moderator))
+	return nil
+}
+
+// Keeps the membership in line with the group's moderator list, moderators are always members
+func (s *GroupService) setMemberRole(ctx context.Context, groupId, userId int, role value.GroupRole) error {
+
+	membership, err := s.membershipRepository.GetMembership(ctx, groupId, userId)
+	if err != nil {
+		return err
+	}
+
+	if membership == nil {
+		err := s.membershipRepository.CreateMembership(ctx, domain.NewGroupMembership(groupId, userId, role))
+		if err == nil {
+			return s.groupRepository.IncrementMemberCount(ctx, groupId, 1)
+		}
+		if !errors.As(err, &domain_errors.AlreadyGroupMemberError{}) {
+			return err
+		}
+
+		// They joined in the meantime
+		membership, err = s.membershipRepository.GetMembership(ctx, groupId, userId)
+		if err != nil || membership == nil {
+			return err
+		}
+	}
+
+	membership.Role = role
+	return s.membershipRepository.UpdateMembership(ctx, membership)
+}
+
+func (s *GroupService) JoinGroup(ctx context.Context, groupId int, user int) error {
+
+	group, err := s.groupRepository.GetGroup(ctx, groupId)
+	if err != nil || group == nil {
+		return domain_errors.GroupNotFoundError{GroupID: groupId}
+	}
+
+	if !group.Public {
+		return domain_errors.GroupIsPrivateError{}
+	}
+
+	return s.addMember(ctx, group, user)
+}
+
+// Lets a user in as a regular member, whatever door they came through
+func (s *GroupService) addMember(ctx context.Context, group *domain.Group, user int) error {
+	groupId := group.ID
+
+	// Archived groups are frozen as they were
+	if group.IsArchived() {
+		return domain_errors.GroupArchivedError{GroupID: groupId}
+	}
+
+	if err := s.checkNotBanned(ctx, groupId, user); err != nil {
+		return err
+	}
+
+	membership, err := s.membershipRepository.GetMembership(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+	if membership != nil {
+		return domain_errors.AlreadyGroupMemberError{}
+	}
+
+	if err := s.membershipRepository.CreateMembership(ctx, domain.NewGroupMembership(groupId, user, value.GroupRoleMember)); err != nil {
+		return err
+	}
+
+	// Any pending request is answered by getting in
+	if err := s.joinRequestRepository.DeleteRequest(ctx, groupId, user); err != nil {
+		return err
+	}
+
+	return s.groupRepository.IncrementMemberCount(ctx, groupId, 1)
+}
+
+func (s *GroupService) LeaveGroup(ctx context.Context, groupId int, user int) error {
+
+	group, err := s.groupRepository.GetGroup(ctx, groupId)
+	if err != nil || group == nil {
+		return domain_errors.GroupNotFoundError{GroupID: groupId}
+	}
+
+	membership, err := s.membershipRepository.GetMembership(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+	if membership == nil {
+		return domain_errors.NotGroupMemberError{}
+	}
+
+	// Moderators step down on the way out, the last one can't leave the group unattended
+	if group.IsModerator(user) {
+		if err := group.RemoveModerator(user); err != nil {
+			return err
+		}
+		if err := s.groupRepository.UpdateGroup(ctx, group); err != nil {
+			return err
+		}
+	}
+
+	if err := s.membershipRepository.DeleteMembership(ctx, groupId, user); err != nil {
+		return err
+	}
+
+	return s.groupRepository.IncrementMemberCount(ctx, groupId, -1)
+}
+
+func (s *GroupService) GetMembers(ctx context.Context, groupId int, viewer *int, pageNumber, pageSize int) ([]*domain.GroupMembership, utils.Pagination, error) {
+
+	if err := s.CanViewGroup(ctx, groupId, viewer); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return s.membershipRepository.GetMembers(ctx, groupId, pageNumber, pageSize)
+}
+
+func (s *GroupService) IsMember(ctx context.Context, groupId int, user int) (bool, error) {
+	membership, err := s.membershipRepository.GetMembership(ctx, groupId, user)
+	if err != nil {
+		return false, err
+	}
+	return membership != nil, nil
+}
+
+// Whether the user can act as a moderator of the group, admins can always step in
+func (s *GroupService) canModerate(ctx context.Context, group *domain.Group, user int) (bool, error) {
+	if group.IsModerator(user) {
+		return true, nil
+	}
+
+	u, err := s.userRepository.GetUserById(ctx, user)
+	if err != nil {
+		return false, err
+	}
+
+	return u != nil && u.HasRole(value.UserRoleAdmin), nil
+}
+
+// Public groups are open to everyone, private ones only to their members
+func (s *GroupService) CanViewGroup(ctx context.Context, groupId int, viewer *int) error {
+
+	group, err := s.groupRepository.GetGroup(ctx, groupId)
+	if err != nil || group == nil {
+		return domain_errors.GroupNotFoundError{GroupID: groupId}
+	}
+
+	if group.Public {
+		return nil
+	}
+
+	if viewer == nil {
+		return domain_errors.GroupMembershipRequiredError{GroupID: groupId}
+	}
+
+	return s.requireMembership(ctx, group, *viewer)
+}
+
+// Posting needs a membership, even in public groups you have to join first, and no mute on it
+func (s *GroupService) CanPostInGroup(ctx context.Context, groupId int, user int) error {
+
+	group, err := s.groupRepository.GetGroup(ctx, groupId)
+	if err != nil || group == nil {
+		return domain_errors.GroupNotFoundError{GroupID: groupId}
+	}
+
+	if group.IsArchived() {
+		return domain_errors.GroupArchivedError{GroupID: groupId}
+	}
+
+	membership, err := s.membershipRepository.GetMembership(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+	if membership != nil {
+		if membership.IsMuted() {
+			return domain_errors.GroupMutedError{Until: *membership.MutedUntil}
+		}
+		return nil
+	}
+
+	if err := s.checkNotBanned(ctx, groupId, user); err != nil {
+		return err
+	}
+
+	return s.requireMembership(ctx, group, user)
+}
+
+func (s *GroupService) requireMembership(ctx context.Context, group *domain.Group, user int) error {
+
+	isMember, err := s.IsMember(ctx, group.ID, user)
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return nil
+	}
+
+	canModerate, err := s.canModerate(ctx, group, user)
+	if err != nil {
+		return err
+	}
+	if canModerate {
+		return nil
+	}
+
+	return domain_errors.GroupMembershipRequiredError{GroupID: group.ID}
+}
+
+func (s *GroupService) RequestToJoin(ctx context.Context, groupId int, user int) error {
+
+	group, err := s.groupRepository.GetGroup(ctx, groupId)
+	if err != nil || group == nil {
+		return domain_errors.GroupNotFoundError{GroupID: groupId}
+	}
+
+	// Nobody needs to be asked to get into a public group
+	if group.Public {
+		return s.addMember(ctx, group, user)
+	}
+
+	if group.IsArchived() {
+		return domain_errors.GroupArchivedError{GroupID: groupId}
+	}
+
+	if err := s.checkNotBanned(ctx, groupId, user); err != nil {
+		return err
+	}
+
+	isMember, err := s.IsMember(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+	if isMember {
+		return domain_errors.AlreadyGroupMemberError{}
+	}
+
+	request, err := s.joinRequestRepository.GetRequest(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		return domain_errors.JoinRequestAlreadySentError{}
+	}
+
+	return s.joinRequestRepository.CreateRequest(ctx, domain.NewGroupJoinRequest(groupId, user))
+}
+
+func (s *GroupService) GetJoinRequests(
+	ctx context.Context,
+	groupId int,
+	moderator int,
+	pageNumber, pageSize int,
+) ([]*domain.GroupJoinRequest, utils.Pagination, error) {
+
+	if _, err := s.getModeratedGroup(ctx, groupId, moderator); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return s.joinRequestRepository.GetRequests(ctx, groupId, pageNumber, pageSize)
+}
+
+func (s *GroupService) ApproveJoinRequest(ctx context.Context, groupId int, requester, moderator int) error {
+
+	group, err := s.getModeratedGroup(ctx, groupId, moderator)
+	if err != nil {
+		return err
+	}
+
+	request, err := s.joinRequestRepository.GetRequest(ctx, groupId, requester)
+	if err != nil {
+		return err
+	}
+	if request == nil {
+		return domain_errors.JoinRequestNotFoundError{}
+	}
+
+	return s.addMember(ctx, group, requester)
+}
+
+func (s *GroupService) DeclineJoinRequest(ctx context.Context, groupId int, requester, moderator int) error {
+
+	if _, err := s.getModeratedGroup(ctx, groupId, moderator); err != nil {
+		return err
+	}
+
+	request, err := s.joinRequestRepository.GetRequest(ctx, groupId, requester)
+	if err != nil {
+		return err
+	}
+	if request == nil {
+		return domain_errors.JoinRequestNotFoundError{}
+	}
+
+	// They're free to ask again later
+	return s.joinRequestRepository.DeleteRequest(ctx, groupId, requester)
+}
+
+func (s *GroupService) CreateInvite(
+	ctx context.Context,
+	groupId int,
+	moderator int,
+	maxUses int,
+	ttl time.Duration,
+) (*domain.GroupInvite, error) {
+
+	if _, err := s.getModeratedGroup(ctx, groupId, moderator); err != nil {
+		return nil, err
+	}
+
+	invite := domain.NewGroupInvite(groupId, moderator, maxUses, ttl)
+	if err := s.inviteRepository.CreateInvite(ctx, invite); err != nil {
+		return nil, err
+	}
+
+	return invite, nil
+}
+
+func (s *GroupService) GetInvites(ctx context.Context, groupId int, moderator int) ([]*domain.GroupInvite, error) {
+
+	if _, err := s.getModeratedGroup(ctx, groupId, moderator); err != nil {
+		return nil, err
+	}
+
+	return s.inviteRepository.GetInvites(ctx, groupId)
+}
+
+func (s *GroupService) RevokeInvite(ctx context.Context, groupId int, code string, moderator int) error {
+
+	if _, err := s.getModeratedGroup(ctx, groupId, moderator); err != nil {
+		return err
+	}
+
+	invite, err := s.inviteRepository.GetInvite(ctx, code)
+	if err != nil {
+		return err
+	}
+	if invite == nil || invite.GroupID != groupId {
+		return domain_errors.GroupInviteNotFoundError{}
+	}
+
+	return s.inviteRepository.DeleteInvite(ctx, code)
+}
+
+func (s *GroupService) JoinWithInvite(ctx context.Context, code string, user int) (*domain.Group, error) {
+
+	invite, err := s.inviteRepository.GetInvite(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if invite == nil {
+		return nil, domain_errors.GroupInviteNotFoundError{}
+	}
+
+	group, err := s.groupRepository.GetGroup(ctx, invite.GroupID)
+	if err != nil || group == nil {
+		return nil, domain_errors.GroupNotFoundError{GroupID: invite.GroupID}
+	}
+
+	// Check before spending a use on someone that's already in
+	isMember, err := s.IsMember(ctx, group.ID, user)
+	if err != nil {
+		return nil, err
+	}
+	if isMember {
+		return nil, domain_errors.AlreadyGroupMemberError{}
+	}
+	if group.IsArchived() {
+		return nil, domain_errors.GroupArchivedError{GroupID: group.ID}
+	}
+	if err := s.checkNotBanned(ctx, group.ID, user); err != nil {
+		return nil, err
+	}
+
+	used, err := s.inviteRepository.UseInvite(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, domain_errors.GroupInviteExpiredError{}
+	}
+
+	if err := s.addMember(ctx, group, user); err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// Fetches a group the user is allowed to moderate
+func (s *GroupService) getModeratedGroup(ctx context.Context, groupId int, moderator int) (*domain.Group, error) {
+
+	group, err := s.groupRepository.GetGroup(ctx, groupId)
+	if err != nil || group == nil {
+		return nil, domain_errors.GroupNotFoundError{GroupID: groupId}
+	}
+
+	canModerate, err := s.canModerate(ctx, group, moderator)
+	if err != nil {
+		return nil, err
+	}
+	if !canModerate {
+		return nil, domain_errors.UnauthorizedError{}
+	}
+
+	return group, nil
+}
+
+// Moderation and edits stop once a group is archived
+func (s *GroupService) getWritableGroup(ctx context.Context, groupId int, moderator int) (*domain.Group, error) {
+
+	group, err := s.getModeratedGroup(ctx, groupId, moderator)
+	if err != nil {
+		return nil, err
+	}
+
+	if group.IsArchived() {
+		return nil, domain_errors.GroupArchivedError{GroupID: groupId}
+	}
+
+	return group, nil
+}
+
+func (s *GroupService) checkNotBanned(ctx context.Context, groupId int, user int) error {
+	ban, err := s.banRepository.GetBan(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+	if ban != nil {
+		return domain_errors.GroupBannedError{GroupID: groupId}
+	}
+	return nil
+}
+
+// Moderators can't sanction each other, they have to be stripped of the role first
+func (s *GroupService) getSanctionTarget(ctx context.Context, group *domain.Group, target int) (*domain.User, error) {
+
+	if group.IsModerator(target) {
+		return nil, domain_errors.CannotSanctionModeratorError{}
+	}
+
+	user, err := s.userRepository.GetUserById(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain_errors.UserNotFoundError{UserID: strconv.Itoa(target)}
+	}
+
+	return user, nil
+}
+
+func (s *GroupService) CanModerateGroup(ctx context.Context, groupId int, user int) error {
+	_, err := s.getModeratedGroup(ctx, groupId, user)
+	return err
+}
+
+// Same as AuditService.Record, a failed write is only logged
+func (s *GroupService) logAction(ctx context.Context, entry *domain.GroupModerationEntry) {
+	if err := s.logRepository.CreateEntry(ctx, entry); err != nil {
+		log.Printf("Failed to log moderation action %d in group %d: %v", entry.Action, entry.GroupID, err)
+	}
+}
+
+func (s *GroupService) LogModerationAction(ctx context.Context, entry *domain.GroupModerationEntry) {
+	s.logAction(ctx, entry)
+}
+
+func (s *GroupService) BanMember(ctx context.Context, groupId int, target, moderator int, reason string) error {
+
+	group, err := s.getWritableGroup(ctx, groupId, moderator)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.getSanctionTarget(ctx, group, target); err != nil {
+		return err
+	}
+
+	ban, err := s.banRepository.GetBan(ctx, groupId, target)
+	if err != nil {
+		return err
+	}
+	if ban != nil {
+		return domain_errors.AlreadyBannedFromGroupError{}
+	}
+
+	if err := s.banRepository.CreateBan(ctx, domain.NewGroupBan(groupId, target, moderator, reason)); err != nil {
+		return err
+	}
+
+	// Out they go, along with whatever they were waiting on
+	membership, err := s.membershipRepository.GetMembership(ctx, groupId, target)
+	if err != nil {
+		return err
+	}
+	if membership != nil {
+		if err := s.membershipRepository.DeleteMembership(ctx, groupId, target); err != nil {
+			return err
+		}
+		if err := s.groupRepository.IncrementMemberCount(ctx, groupId, -1); err != nil {
+			return err
+		}
+	}
+	if err := s.joinRequestRepository.DeleteRequest(ctx, groupId, target); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, domain.NewGroupModerationEntry(groupId, moderator, value.GroupActionBan, reason).OnUser(target))
+	return nil
+}
+
+func (s *GroupService) UnbanMember(ctx context.Context, groupId int, target, moderator int) error {
+
+	if _, err := s.getWritableGroup(ctx, groupId, moderator); err != nil {
+		return err
+	}
+
+	ban, err := s.banRepository.GetBan(ctx, groupId, target)
+	if err != nil {
+		return err
+	}
+	if ban == nil {
+		return domain_errors.NotBannedFromGroupError{}
+	}
+
+	if err := s.banRepository.DeleteBan(ctx, groupId, target); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, domain.NewGroupModerationEntry(groupId, moderator, value.GroupActionUnban, "").OnUser(target))
+	return nil
+}
+
+func (s *GroupService) GetBans(ctx context.Context, groupId int, moderator int, pageNumber, pageSize int) ([]*domain.GroupBan, utils.Pagination, error) {
+
+	if _, err := s.getModeratedGroup(ctx, groupId, moderator); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return s.banRepository.GetBans(ctx, groupId, pageNumber, pageSize)
+}
+
+func (s *GroupService) MuteMember(
+	ctx context.Context,
+	groupId int,
+	target, moderator int,
+	reason string,
+	duration time.Duration,
+) error {
+
+	if duration < time.Minute || duration > domain.GROUP_MUTE_MAX_DURATION {
+		return domain_errors.InvalidMuteDurationError{}
+	}
+
+	group, err := s.getWritableGroup(ctx, groupId, moderator)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.getSanctionTarget(ctx, group, target); err != nil {
+		return err
+	}
+
+	membership, err := s.membershipRepository.GetMembership(ctx, groupId, target)
+	if err != nil {
+		return err
+	}
+	if membership == nil {
+		return domain_errors.NotGroupMemberError{}
+	}
+
+	// A new mute replaces the old one, even if it's shorter
+	until := time.Now().Add(duration)
+	membership.Mute(until)
+	if err := s.membershipRepository.UpdateMembership(ctx, membership); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, domain.NewGroupModerationEntry(groupId, moderator, value.GroupActionMute, reason).OnUser(target).Until(until))
+	return nil
+}
+
+func (s *GroupService) UnmuteMember(ctx context.Context, groupId int, target, moderator int) error {
+
+	if _, err := s.getWritableGroup(ctx, groupId, moderator); err != nil {
+		return err
+	}
+
+	membership, err := s.membershipRepository.GetMembership(ctx, groupId, target)
+	if err != nil {
+		return err
+	}
+	if membership == nil {
+		return domain_errors.NotGroupMemberError{}
+	}
+
+	membership.Unmute()
+	if err := s.membershipRepository.UpdateMembership(ctx, membership); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, domain.NewGroupModerationEntry(groupId, moderator, value.GroupActionUnmute, "").OnUser(target))
+	return nil
+}
+
+func (s *GroupService) GetModerationLog(
+	ctx context.Context,
+	groupId int,
+	moderator int,
+	pageNumber, pageSize int,
+) ([]*domain.GroupModerationEntry, utils.Pagination, error) {
+
+	if _, err := s.getModeratedGroup(ctx, groupId, moderator); err != nil {
+		return nil, utils.Pagination{}, err
+	}
+
+	return s.logRepository.GetEntries(ctx, groupId, pageNumber, pageSize)
+}
+
+// Fetches a group the user owns, admins can step in for groups left without a reachable owner
+func (s *GroupService) getOwnedGroup(ctx context.Context, groupId int, user int) (*domain.Group, error) {
+
+	group, err := s.groupRepository.GetGroup(ctx, groupId)
+	if err != nil || group == nil {
+		return nil, domain_errors.GroupNotFoundError{GroupID: groupId}
+	}
+
+	if group.IsOwner(user) {
+		return group, nil
+	}
+
+	u, err := s.userRepository.GetUserById(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil || !u.HasRole(value.UserRoleAdmin) {
+		return nil, domain_errors.UnauthorizedError{}
+	}
+
+	return group, nil
+}
+
+// Offers the group to another member, it's only theirs once they accept
+func (s *GroupService) TransferOwnership(ctx context.Context, groupId int, newOwner, user int) error {
+
+	group, err := s.getOwnedGroup(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+
+	isMember, err := s.IsMember(ctx, groupId, newOwner)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return domain_errors.NotGroupMemberError{}
+	}
+
+	if err := group.OfferOwnership(newOwner); err != nil {
+		return err
+	}
+
+	return s.groupRepository.UpdateGroup(ctx, group)
+}
+
+func (s *GroupService) AcceptOwnership(ctx context.Context, groupId int, user int) error {
+
+	group, err := s.groupRepository.GetGroup(ctx, groupId)
+	if err != nil || group == nil {
+		return domain_errors.GroupNotFoundError{GroupID: groupId}
+	}
+
+	// They might have left since it was offered
+	isMember, err := s.IsMember(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return domain_errors.NotGroupMemberError{}
+	}
+
+	previousOwner := group.OwnerID
+	if err := group.AcceptOwnership(user); err != nil {
+		return err
+	}
+
+	if err := s.groupRepository.UpdateGroup(ctx, group); err != nil {
+		return err
+	}
+
+	if err := s.setMemberRole(ctx, groupId, user, value.GroupRoleOwner); err != nil {
+		return err
+	}
+
+	// The old owner keeps moderating, they can step down on their own afterwards
+	previous, err := s.membershipRepository.GetMembership(ctx, groupId, previousOwner)
+	if err != nil {
+		return err
+	}
+	if previous != nil {
+		previous.Role = value.GroupRoleModerator
+		if err := s.membershipRepository.UpdateMembership(ctx, previous); err != nil {
+			return err
+		}
+	}
+
+	s.logAction(ctx, domain.NewGroupModerationEntry(groupId, previousOwner, value.GroupActionTransferOwnership, "").OnUser(user))
+	return nil
+}
+
+// Withdrawn by the owner or declined by whoever it was offered to
+func (s *GroupService) CancelOwnershipTransfer(ctx context.Context, groupId int, user int) error {
+
+	group, err := s.groupRepository.GetGroup(ctx, groupId)
+	if err != nil || group == nil {
+		return domain_errors.GroupNotFoundError{GroupID: groupId}
+	}
+
+	if group.PendingOwnerID == nil || *group.PendingOwnerID != user {
+		if group, err = s.getOwnedGroup(ctx, groupId, user); err != nil {
+			return err
+		}
+	}
+
+	if err := group.CancelOwnershipTransfer(); err != nil {
+		return err
+	}
+
+	return s.groupRepository.UpdateGroup(ctx, group)
+}
+
+// Archived groups stay readable, but nobody can join or post in them anymore
+func (s *GroupService) ArchiveGroup(ctx context.Context, groupId int, user int) error {
+
+	group, err := s.getOwnedGroup(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+
+	if err := group.Archive(); err != nil {
+		return err
+	}
+
+	if err := s.groupRepository.UpdateGroup(ctx, group); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, domain.NewGroupModerationEntry(groupId, user, value.GroupActionArchive, ""))
+	return nil
+}
+
+func (s *GroupService) UnarchiveGroup(ctx context.Context, groupId int, user int) error {
+
+	group, err := s.getOwnedGroup(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+
+	if err := group.Unarchive(); err != nil {
+		return err
+	}
+
+	if err := s.groupRepository.UpdateGroup(ctx, group); err != nil {
+		return err
+	}
+
+	s.logAction(ctx, domain.NewGroupModerationEntry(groupId, user, value.GroupActionUnarchive, ""))
+	return nil
+}
+
+// Gone for everyone, along with its threads and every membership, request, invite and ban. The group
+// is marked deleted first so nothing new gets in while the rest is cleared
+func (s *GroupService) DeleteGroup(ctx context.Context, groupId int, user int) error {
+
+	group, err := s.getOwnedGroup(ctx, groupId, user)
+	if err != nil {
+		return err
+	}
+
+	group.Delete()
+	if err := s.groupRepository.UpdateGroup(ctx, group); err != nil {
+		return err
+	}
+
+	// The moderation log stays, it's the record of what happened to the group
+	s.logAction(ctx, domain.NewGroupModerationEntry(groupId, user, value.GroupActionDelete, string(group.Name)))
+	s.auditService.Record(ctx, domain.NewAuditEntry(user, value.AuditGroupDeleted, value.AuditTargetGroup, strconv.Itoa(groupId)).
+		WithDetails(string(group.Name)))
+
+	if _, err := s.postRepository.DeleteThreads(ctx, strconv.Itoa(groupId), value.ParentTypeGroup); err != nil {
+		return err
+	}
+
+	if err := s.membershipRepository.DeleteGroupMemberships(ctx, groupId); err != nil {
+		return err
+	}
+	if err := s.joinRequestRepository.DeleteGroupRequests(ctx, groupId); err != nil {
+		return err
+	}
+	if err := s.inviteRepository.DeleteGroupInvites(ctx, groupId); err != nil {
+		return err
+	}
+	return s.banRepository.DeleteGroupBans(ctx, groupId)
+}
+
+// Takes a deleted account out of every group. Groups they owned go to the most senior moderator,
+// or member if there's none, and get archived when nobody else is left to run them
+func (s *GroupService) LeaveAllGroups(ctx context.Context, user int) error {
+
+	memberships, err := s.membershipRepository.GetUserMemberships(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	for _, membership := range memberships {
+		group, err := s.groupRepository.GetGroup(ctx, membership.GroupID)
+		if err != nil || group == nil {
+			continue // Deleted groups already lost their memberships
+		}
+
+		if err := s.handOver(ctx, group, user); err != nil {
+			return err
+		}
+
+		if err := s.membershipRepository.DeleteMembership(ctx, group.ID, user); err != nil {
+			return err
+		}
+		if err := s.groupRepository.IncrementMemberCount(ctx, group.ID, -1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Whatever the leaving user held in the group passes on to someone else
+func (s *GroupService) handOver(ctx context.Context, group *domain.Group, user int) error {
+
+	if group.PendingOwnerID != nil && *group.PendingOwnerID == user {
+		if err := group.CancelOwnershipTransfer(); err != nil {
+			return err
+		}
+	}
+
+	if group.IsOwner(user) {
+		successor, err := s.successor(ctx, group.ID, user)
+		if err != nil {
+			return err
+		}
+
+		if successor == nil {
+			// Nobody to take over, the group stays as it was
+			if !group.IsArchived() {
+				if err := group.Archive(); err != nil {
+					return err
+				}
+				s.logAction(ctx, domain.NewGroupModerationEntry(group.ID, domain.SYSTEM_USER_ID, value.GroupActionArchive, ""))
+			}
+			return s.groupRepository.UpdateGroup(ctx, group)
+		}
+
+		group.SetOwner(successor.UserID)
+		if err := s.setMemberRole(ctx, group.ID, successor.UserID, value.GroupRoleOwner); err != nil {
+			return err
+		}
+		s.logAction(ctx, domain.NewGroupModerationEntry(group.ID, user, value.GroupActionTransferOwnership, "").OnUser(successor.UserID))
+	}
+
+	if group.IsModerator(user) {
+		if err := group.RemoveModerator(user); err != nil {
+			return err
+		}
+	}
+
+	return s.groupRepository.UpdateGroup(ctx, group)
+}
+
+// Moderators come first, then whoever has been around the longest
+func (s *GroupService) successor(ctx context.Context, groupId int, user int) (*domain.GroupMembership, error) {
+
+	members, _, err := s.membershipRepository.GetMembers(ctx, groupId, 1, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		if member.UserID != user {
+			return member, nil
+		}
+	}
+	return nil, nil
 }
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type IdentityService struct {
+	identityRepository interfaces.IdentityRepository
+	userRepository     interfaces.UserRepository
+}
+
+func NewIdentityService(identityRepo interfaces.IdentityRepository, userRepo interfaces.UserRepository) *IdentityService {
+	return &IdentityService{
+		identityRepository: identityRepo,
+		userRepository:     userRepo,
+	}
+}
+
+// Finds the user behind a provider login, registering them on their first one
+func (s *IdentityService) LoginWithProvider(ctx context.Context, identity *domain.ProviderIdentity) (*domain.User, bool, error) {
+	linked, err := s.findIdentity(ctx, identity)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if linked != nil {
+		user, err := s.loginLinked(ctx, linked)
+		return user, false, err
+	}
+
+	user, err := domain.NewUser(usernameFromIdentity(identity), identity.Email)
+	if err != nil {
+		return nil, false, err
+	}
+	user.UpdateProviderInformation(identity.Provider, identity.Subject) // What they signed up with
+	user.AvatarURL = identity.AvatarURL
+
+	if err := s.createUser(ctx, user); err != nil {
+		return nil, false, err
+	}
+
+	linked = domain.NewLinkedIdentity(user.ID, identity)
+	linked.MarkUsed()
+	if err := s.identityRepository.CreateIdentity(ctx, linked); err != nil {
+		if !errors.As(err, &domain_errors.IdentityLinkedToAnotherUserError{}) {
+			return nil, false, err
+		}
+
+		// Two first logins raced and the other one linked the identity first, the account
+		// made here would never be reachable so it goes and the login lands on theirs
+		if err := s.userRepository.DeleteUser(ctx, user.ID); err != nil {
+			log.Printf("Failed to delete user %d after losing a sign up race: %v", user.ID, err)
+		}
+
+		winner, getErr := s.identityRepository.GetIdentity(ctx, identity.Provider, identity.Subject)
+		if getErr != nil || winner == nil {
+			return nil, false, err
+		}
+		user, err := s.loginLinked(ctx, winner)
+		return user, false, err
+	}
+	return user, true, nil
+}
+
+func (s *IdentityService) loginLinked(ctx context.Context, linked *domain.LinkedIdentity) (*domain.User, error) {
+	user, err := s.userRepository.GetUserById(ctx, linked.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain_errors.UserNotFoundError{UserID: strconv.Itoa(linked.UserID)}
+	}
+
+	s.markUsed(ctx, linked)
+	user.UpdateLastLogin()
+	if err := s.userRepository.UpdateUser(ctx, user); err != nil {
+		log.Printf("Failed to update last login of user %d: %v", user.ID, err)
+	}
+	return user, nil
+}
+
+func (s *IdentityService) LinkIdentity(ctx context.Context, userID int, identity *domain.ProviderIdentity) (*domain.LinkedIdentity, error) {
+	existing, err := s.findIdentity(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.UserID == userID {
+			return nil, domain_errors.IdentityAlreadyLinkedError{}
+		}
+		return nil, domain_errors.IdentityLinkedToAnotherUserError{}
+	}
+
+	identities, err := s.GetIdentities(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range identities {
+		if other.Provider == identity.Provider {
+			return nil, domain_errors.ProviderAlreadyLinkedError{Provider: identity.Provider}
+		}
+	}
+
+	linked := domain.NewLinkedIdentity(userID, identity)
+	if err := s.identityRepository.CreateIdentity(ctx, linked); err != nil {
+		return nil, err
+	}
+	return linked, nil
+}
+
+func (s *IdentityService) UnlinkIdentity(ctx context.Context, userID int, provider string) error {
+	identities, err := s.GetIdentities(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(identities, func(identity *domain.LinkedIdentity) bool { return identity.Provider == provider })
+	if i == -1 {
+		return domain_errors.IdentityNotFoundError{Provider: provider}
+	}
+	if len(identities) == 1 {
+		return domain_errors.CantUnlinkLastIdentityError{}
+	}
+
+	deleted, err := s.identityRepository.DeleteIdentity(ctx, userID, provider)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return domain_errors.IdentityNotFoundError{Provider: provider}
+	}
+
+	// Another unlink may have gone through since the check, if nothing is left put this one back
+	remaining, err := s.identityRepository.GetUserIdentities(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(remaining) == 0 {
+		if err := s.identityRepository.CreateIdentity(ctx, identities[i]); err != nil {
+			return err
+		}
+		return domain_errors.CantUnlinkLastIdentityError{}
+	}
+	return nil
+}
+
+func (s *IdentityService) GetIdentities(ctx context.Context, userID int) ([]*domain.LinkedIdentity, error) {
+	identities, err := s.identityRepository.GetUserIdentities(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) > 0 {
+		return identities, nil
+	}
+
+	// Accounts from before linking only know their sign up provider
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil || user == nil || user.Provider == "" {
+		return identities, err
+	}
+	linked, err := s.migrateLegacyIdentity(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return []*domain.LinkedIdentity{linked}, nil
+}
+
+// Linked identities first, then the sign up provider of accounts that never had one
+func (s *IdentityService) findIdentity(ctx context.Context, identity *domain.ProviderIdentity) (*domain.LinkedIdentity, error) {
+	linked, err := s.identityRepository.GetIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil || linked != nil {
+		return linked, err
+	}
+
+	user, err := s.userRepository.GetUserByProvider(ctx, identity.Provider, identity.Subject)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	// Once an account has linked identities its sign up provider is just history,
+	// it may well have been unlinked since
+	others, err := s.identityRepository.GetUserIdentities(ctx, user.ID)
+	if err != nil || len(others) > 0 {
+		return nil, err
+	}
+	return s.migrateLegacyIdentity(ctx, user)
+}
+
+func (s *IdentityService) migrateLegacyIdentity(ctx context.Context, user *domain.User) (*domain.LinkedIdentity, error) {
+	linked := domain.NewLinkedIdentity(user.ID, &domain.ProviderIdentity{
+		Provider: user.Provider,
+		Subject:  user.ProviderID,
+		Name:     string(user.Username),
+	})
+	linked.LinkedAt = user.CreatedAt
+	if err := s.identityRepository.CreateIdentity(ctx, linked); err != nil {
+		// Two logins racing to migrate the same account, the other one got there first
+		existing, getErr := s.identityRepository.GetIdentity(ctx, linked.Provider, linked.ProviderID)
+		if getErr == nil && existing != nil && existing.UserID == user.ID {
+			return existing, nil
+		}
+		return nil, err
+	}
+	return linked, nil
+}
+
+func (s *IdentityService) markUsed(ctx context.Context, identity *domain.LinkedIdentity) {
+	identity.MarkUsed()
+	if err := s.identityRepository.MarkUsed(ctx, identity); err != nil {
+		log.Printf("Failed to mark %s identity of user %d as used: %v", identity.Provider, identity.UserID, err)
+	}
+}
+
+const MAX_USERNAME_ATTEMPTS = 5
+
+// Someone may already go by the name the provider gave, a short suffix tells them apart
+func (s *IdentityService) createUser(ctx context.Context, user *domain.User) error {
+	base := []rune(string(user.Username))
+	if len(base) > 58 {
+		base = base[:58]
+	}
+
+	for attempt := 1; ; attempt++ {
+		_, err := s.userRepository.CreateUser(ctx, user)
+		if !errors.As(err, &domain_errors.UsernameTakenError{}) || attempt == MAX_USERNAME_ATTEMPTS {
+			return err
+		}
+		if err := user.UpdateUsername(string(base) + "_" + utils.GenerateRandomToken(5)); err != nil {
+			return err
+		}
+	}
+}
+
+// Provider display names can be anything, keep what fits our username rules and make something up otherwise
+func usernameFromIdentity(identity *domain.ProviderIdentity) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if value.USERNAME_REGEX_PATTERN.MatchString(string(r)) {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(identity.Name))
+	if runes := []rune(cleaned); len(runes) > 64 {
+		cleaned = strings.TrimSpace(string(runes[:64]))
+	}
+
+	if _, err := value.NewUsername(cleaned); err == nil {
+		return cleaned
+	}
+	return "user_" + utils.GenerateRandomToken(8)
+}
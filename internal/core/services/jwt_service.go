@@ -1,38 +1,54 @@
 package services
 
 import (
+	"context"
 	"time"
 
 	"github.com/afuradanime/backend/config"
 	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/interfaces"
 	"github.com/afuradanime/backend/internal/core/utils"
 	"github.com/golang-jwt/jwt/v5"
 )
 
 // Super secret, architecture breaking, JWT generation service!
 type JWTService struct {
-	conf *config.JWTConfig
+	conf    *config.JWTConfig
+	keyRing *domain.KeyRing
+	terms   interfaces.TermsService
 }
 
-func NewJWTService(config *config.JWTConfig) *JWTService {
-	return &JWTService{conf: config}
+func NewJWTService(config *config.JWTConfig, keyRing *domain.KeyRing, terms interfaces.TermsService) *JWTService {
+	return &JWTService{conf: config, keyRing: keyRing, terms: terms}
 }
 
-func (s *JWTService) GenerateJWT(user domain.User) (string, error) {
+// Short lived access token tied to a session, roles get picked up again on every refresh.
+// The frontend asks for the terms again when acceptedTermsOfService is false, the middlewares
+// go by the version in tos
+func (s *JWTService) GenerateJWT(ctx context.Context, user domain.User, sessionID string) (string, error) {
 	claims := jwt.MapClaims{
-		"id":   user.ID,
-		"iss":  s.conf.Issuer,
-		"exp":  time.Now().Add(time.Hour * time.Duration(s.conf.ExpirationHours)).Unix(),
-		"role": user.Roles,
-		"acceptedTermsOfService": user.AcceptedTermsOfService,
+		"id":                     user.ID,
+		"sid":                    sessionID,
+		"iss":                    s.conf.Issuer,
+		"exp":                    time.Now().Add(s.AccessTokenLifetime()).Unix(),
+		"role":                   user.Roles,
+		"tos":                    user.AcceptedTermsVersion,
+		"acceptedTermsOfService": user.HasAcceptedTerms(s.terms.CurrentTermsVersion(ctx)),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.conf.Secret))
+	return s.keyRing.Sign(claims)
+}
+
+func (s *JWTService) AccessTokenLifetime() time.Duration {
+	return time.Minute * time.Duration(s.conf.AccessExpirationMinutes)
+}
+
+func (s *JWTService) SessionLifetime() time.Duration {
+	return time.Hour * time.Duration(s.conf.ExpirationHours)
 }
 
 func (s *JWTService) ValidateJWT(tokenString string) (*jwt.Token, error) {
-	parsed, err := utils.GetParsedJWTClaims(tokenString, s.conf.Secret)
+	parsed, err := utils.GetParsedJWTClaims(tokenString, s.keyRing.Keyfunc)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+const MAX_MENTION_SUGGESTIONS = 10
+
+type MentionService struct {
+	userRepo          interfaces.UserRepository
+	friendshipService interfaces.FriendshipService
+	presenceService   interfaces.PresenceService
+}
+
+func NewMentionService(
+	userRepo interfaces.UserRepository,
+	friendshipService interfaces.FriendshipService,
+	presenceService interfaces.PresenceService,
+) *MentionService {
+	return &MentionService{
+		userRepo:          userRepo,
+		friendshipService: friendshipService,
+		presenceService:   presenceService,
+	}
+}
+
+// Suggests users to mention, ranked by how likely the poster is to want them:
+// friends first, then whoever is currently active, then everyone else
+func (s *MentionService) Autocomplete(ctx context.Context, userID int, prefix string, limit int) ([]*domain.User, error) {
+	limit = utils.Clamp(limit, 1, MAX_MENTION_SUGGESTIONS)
+	prefix = strings.ToLower(strings.TrimSpace(prefix))
+
+	suggestions := make([]*domain.User, 0, limit)
+	seen := map[int]bool{userID: true} // Don't suggest yourself
+
+	add := func(u *domain.User) {
+		if u == nil || seen[u.ID] || len(suggestions) >= limit {
+			return
+		}
+		if !strings.HasPrefix(strings.ToLower(string(u.Username)), prefix) {
+			return
+		}
+		seen[u.ID] = true
+		suggestions = append(suggestions, u)
+	}
+
+	// 1. Friends
+	friends, err := s.friendshipService.SearchFriends(ctx, userID, prefix, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range friends {
+		add(&friends[i])
+	}
+
+	// 2. Recently active users, only those who let the poster see they're around
+	if len(suggestions) < limit {
+		active, err := s.presenceService.SearchVisibleOnlineUsers(ctx, userID, prefix, limit+len(seen))
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range active {
+			add(u)
+		}
+	}
+
+	// 3. Anyone else whose username starts with the prefix
+	if len(suggestions) < limit {
+		users, err := s.userRepo.SearchByUsernamePrefix(ctx, prefix, limit+len(seen))
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			add(u)
+		}
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type ModerationCaseService struct {
+	caseRepository   interfaces.ModerationCaseRepository
+	reportRepository interfaces.ReportRepository
+	userRepository   interfaces.UserRepository
+	auditService     interfaces.AuditService
+}
+
+func NewModerationCaseService(
+	caseRepo interfaces.ModerationCaseRepository,
+	reportRepo interfaces.ReportRepository,
+	userRepo interfaces.UserRepository,
+	auditService interfaces.AuditService,
+) *ModerationCaseService {
+	return &ModerationCaseService{
+		caseRepository:   caseRepo,
+		reportRepository: reportRepo,
+		userRepository:   userRepo,
+		auditService:     auditService,
+	}
+}
+
+func (s *ModerationCaseService) GetCases(ctx context.Context, f filters.CaseFilter, pageNumber, pageSize int) ([]*domain.ModerationCase, utils.Pagination, error) {
+	return s.caseRepository.GetCases(ctx, f, pageNumber, pageSize)
+}
+
+// The case along with every report filed into it
+func (s *ModerationCaseService) GetCase(ctx context.Context, id int) (*domain.ModerationCase, []domain.Report, error) {
+	moderationCase, err := s.getCase(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reports, err := s.reportRepository.GetReportsByCase(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return moderationCase, reports, nil
+}
+
+func (s *ModerationCaseService) getCase(ctx context.Context, id int) (*domain.ModerationCase, error) {
+	moderationCase, err := s.caseRepository.GetCase(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if moderationCase == nil {
+		return nil, domain_errors.CaseNotFoundError{}
+	}
+	return moderationCase, nil
+}
+
+func (s *ModerationCaseService) AssignCase(ctx context.Context, id int, assignee, moderatorID int) error {
+	moderationCase, err := s.getCase(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepository.GetUserById(ctx, assignee)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return domain_errors.UserNotFoundError{UserID: strconv.Itoa(assignee)}
+	}
+	if !user.HasRole(value.UserRoleModerator) && !user.HasRole(value.UserRoleAdmin) {
+		return domain_errors.CaseAssigneeNotModeratorError{}
+	}
+
+	if err := moderationCase.Assign(assignee); err != nil {
+		return err
+	}
+
+	return s.caseRepository.UpdateCase(ctx, moderationCase)
+}
+
+func (s *ModerationCaseService) UnassignCase(ctx context.Context, id int, moderatorID int) error {
+	moderationCase, err := s.getCase(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := moderationCase.Unassign(); err != nil {
+		return err
+	}
+
+	return s.caseRepository.UpdateCase(ctx, moderationCase)
+}
+
+func (s *ModerationCaseService) AddNote(ctx context.Context, id int, text string, moderatorID int) error {
+	moderationCase, err := s.getCase(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := moderationCase.AddNote(moderatorID, text); err != nil {
+		return err
+	}
+
+	return s.caseRepository.AddNote(ctx, id, moderationCase.Notes[len(moderationCase.Notes)-1])
+}
+
+// Closes the case with whatever came out of it, the note is optional and explains why
+func (s *ModerationCaseService) ResolveCase(ctx context.Context, id int, outcome value.CaseOutcome, note string, moderatorID int) error {
+	moderationCase, err := s.getCase(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := moderationCase.Resolve(outcome, moderatorID); err != nil {
+		return err
+	}
+
+	if note != "" {
+		if err := moderationCase.AddNote(moderatorID, note); err != nil {
+			return err
+		}
+	}
+
+	if err := s.caseRepository.UpdateCase(ctx, moderationCase); err != nil {
+		return err
+	}
+
+	entry := domain.NewAuditEntry(moderatorID, value.AuditCaseResolved, value.AuditTargetCase, strconv.Itoa(id)).
+		WithDetails(strconv.Itoa(int(outcome)))
+	if moderationCase.TargetUser != nil {
+		entry.OnUser(*moderationCase.TargetUser)
+	}
+	s.auditService.Record(ctx, entry)
+
+	if note != "" {
+		return s.caseRepository.AddNote(ctx, id, moderationCase.Notes[len(moderationCase.Notes)-1])
+	}
+	return nil
+}
+
+func (s *ModerationCaseService) ReopenCase(ctx context.Context, id int, moderatorID int) error {
+	moderationCase, err := s.getCase(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := moderationCase.Reopen(); err != nil {
+		return err
+	}
+
+	return s.caseRepository.UpdateCase(ctx, moderationCase)
+}
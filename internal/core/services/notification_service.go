@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type NotificationService struct {
+	notificationRepo interfaces.NotificationRepository
+	userRepo         interfaces.UserRepository
+	events           interfaces.EventBroker
+}
+
+func NewNotificationService(
+	notificationRepo interfaces.NotificationRepository,
+	userRepo interfaces.UserRepository,
+	events interfaces.EventBroker,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		events:           events,
+	}
+}
+
+func (s *NotificationService) Notify(ctx context.Context, receiverID, actorID int, notificationType value.NotificationType, target string) error {
+
+	// Nobody needs to be told about what they did themselves
+	if receiverID == actorID {
+		return nil
+	}
+
+	receiver, err := s.userRepo.GetUserById(ctx, receiverID)
+	if err != nil {
+		return err
+	}
+	if receiver == nil {
+		return domain_errors.UserNotFoundError{UserID: strconv.Itoa(receiverID)}
+	}
+
+	if !receiver.WantsNotification(notificationType) {
+		return nil
+	}
+
+	notification := domain.NewNotification(receiverID, actorID, notificationType, target)
+	if err := s.notificationRepo.Create(ctx, notification); err != nil {
+		return err
+	}
+
+	// Push it to the receiver if they're connected
+	s.events.Publish(domain.UserTopic(receiverID), value.EventNotification, notification)
+	return nil
+}
+
+func (s *NotificationService) GetNotifications(ctx context.Context, userID int, unreadOnly bool, pageNumber, pageSize int) ([]*domain.Notification, utils.Pagination, error) {
+	return s.notificationRepo.GetForUser(ctx, userID, unreadOnly, pageNumber, pageSize)
+}
+
+func (s *NotificationService) GetUnreadCount(ctx context.Context, userID int) (*domain.UnreadNotifications, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+func (s *NotificationService) MarkAsRead(ctx context.Context, userID int, notificationID string) error {
+	notification, err := s.notificationRepo.GetById(ctx, notificationID)
+	if err != nil {
+		return err
+	}
+
+	// Pretend other people's notifications don't exist
+	if notification == nil || notification.Receiver != userID {
+		return domain_errors.NotificationNotFoundError{NotificationID: notificationID}
+	}
+
+	if notification.Read {
+		return nil
+	}
+
+	return s.notificationRepo.MarkRead(ctx, notificationID)
+}
+
+func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID int) error {
+	return s.notificationRepo.MarkAllRead(ctx, userID)
+}
+
+func (s *NotificationService) GetPreferences(ctx context.Context, userID int) ([]domain.NotificationPreference, error) {
+	user, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil || user == nil {
+		return nil, domain_errors.UserNotFoundError{UserID: strconv.Itoa(userID)}
+	}
+
+	preferences := make([]domain.NotificationPreference, 0, len(value.NotificationTypes))
+	for _, notificationType := range value.NotificationTypes {
+		preferences = append(preferences, domain.NotificationPreference{
+			Type:    notificationType,
+			Enabled: user.WantsNotification(notificationType),
+		})
+	}
+
+	return preferences, nil
+}
+
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID int, preferences []domain.NotificationPreference) error {
+	user, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil || user == nil {
+		return domain_errors.UserNotFoundError{UserID: strconv.Itoa(userID)}
+	}
+
+	for _, preference := range preferences {
+		if err := user.UpdateNotificationPreference(preference.Type, preference.Enabled); err != nil {
+			return err
+		}
+	}
+
+	return s.userRepo.UpdateUser(ctx, user)
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+// Authorization code flow with PKCE for third party apps, see RFC 6749 and RFC 7636
+type OAuthService struct {
+	appRepository   interfaces.OAuthAppRepository
+	grantRepository interfaces.OAuthGrantRepository
+	codeRepository  interfaces.OAuthCodeRepository
+	tokenRepository interfaces.OAuthTokenRepository
+}
+
+func NewOAuthService(
+	appRepo interfaces.OAuthAppRepository,
+	grantRepo interfaces.OAuthGrantRepository,
+	codeRepo interfaces.OAuthCodeRepository,
+	tokenRepo interfaces.OAuthTokenRepository,
+) *OAuthService {
+	return &OAuthService{
+		appRepository:   appRepo,
+		grantRepository: grantRepo,
+		codeRepository:  codeRepo,
+		tokenRepository: tokenRepo,
+	}
+}
+
+func (s *OAuthService) RegisterApp(ctx context.Context, ownerID int, name, description, homepage string, redirectURIs []string, public bool) (*domain.OAuthApp, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > domain.MAX_OAUTH_APP_NAME_LENGTH {
+		return nil, "", domain_errors.InvalidOAuthAppNameError{MaxLength: domain.MAX_OAUTH_APP_NAME_LENGTH}
+	}
+
+	description = strings.TrimSpace(description)
+	if utf8.RuneCountInString(description) > domain.MAX_OAUTH_APP_DESCRIPTION_LENGTH {
+		return nil, "", domain_errors.OAuthAppDescriptionTooLongError{MaxLength: domain.MAX_OAUTH_APP_DESCRIPTION_LENGTH}
+	}
+
+	if homepage != "" {
+		url, err := value.NewURL(homepage)
+		if err != nil {
+			return nil, "", domain_errors.InvalidOAuthAppHomepageError{Reason: err.Error()}
+		}
+		homepage = string(*url)
+	}
+
+	if len(redirectURIs) == 0 {
+		return nil, "", domain_errors.InvalidRedirectURIError{}
+	}
+	if len(redirectURIs) > domain.MAX_OAUTH_REDIRECT_URIS {
+		return nil, "", domain_errors.TooManyRedirectURIsError{Max: domain.MAX_OAUTH_REDIRECT_URIS}
+	}
+	for _, uri := range redirectURIs {
+		if !domain.IsValidRedirectURI(uri) {
+			return nil, "", domain_errors.InvalidRedirectURIError{URI: uri}
+		}
+	}
+
+	count, err := s.appRepository.CountOwnerApps(ctx, ownerID)
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= domain.MAX_OAUTH_APPS {
+		return nil, "", domain_errors.TooManyOAuthAppsError{Max: domain.MAX_OAUTH_APPS}
+	}
+
+	app, secret := domain.NewOAuthApp(ownerID, name, description, homepage, redirectURIs, public)
+	if err := s.appRepository.CreateApp(ctx, app); err != nil {
+		return nil, "", err
+	}
+	return app, secret, nil
+}
+
+func (s *OAuthService) GetApps(ctx context.Context, ownerID int) ([]*domain.OAuthApp, error) {
+	return s.appRepository.GetOwnerApps(ctx, ownerID)
+}
+
+// Everyone who used the app gets logged out of it
+func (s *OAuthService) DeleteApp(ctx context.Context, ownerID int, appID string) error {
+	deleted, err := s.appRepository.DeleteApp(ctx, appID, ownerID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return domain_errors.OAuthAppNotFoundError{}
+	}
+
+	if err := s.tokenRepository.RevokeAppTokens(ctx, appID, time.Now()); err != nil {
+		log.Printf("Failed to revoke tokens of deleted app: %v", err)
+	}
+	if err := s.grantRepository.DeleteAppGrants(ctx, appID); err != nil {
+		log.Printf("Failed to delete grants of deleted app: %v", err)
+	}
+	return nil
+}
+
+// Scopes come space separated, as the spec has them
+func parseScopes(scope string) ([]value.TokenScope, error) {
+	var scopes []value.TokenScope
+	for _, field := range strings.Fields(scope) {
+		parsed := value.TokenScope(field)
+		if !parsed.IsValid() {
+			return nil, domain_errors.OAuthError{Code: domain_errors.OAuthInvalidScope, Description: "Unknown scope " + field}
+		}
+		if !slices.Contains(scopes, parsed) {
+			scopes = append(scopes, parsed)
+		}
+	}
+
+	if len(scopes) == 0 {
+		return nil, domain_errors.OAuthError{Code: domain_errors.OAuthInvalidScope, Description: "At least one scope is needed"}
+	}
+	return scopes, nil
+}
+
+// The app and redirect URI are checked first, until then nothing can be sent back to the app safely
+func (s *OAuthService) validateRequest(ctx context.Context, request domain.AuthorizationRequest) (*domain.OAuthApp, []value.TokenScope, error) {
+	app, err := s.appRepository.GetApp(ctx, request.ClientID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if app == nil {
+		return nil, nil, domain_errors.OAuthAppNotFoundError{}
+	}
+	if !app.HasRedirectURI(request.RedirectURI) {
+		return nil, nil, domain_errors.InvalidRedirectURIError{URI: request.RedirectURI}
+	}
+
+	if request.ResponseType != "code" {
+		return nil, nil, domain_errors.OAuthError{Code: domain_errors.OAuthUnsupportedResponseType, Description: "Only the code response type is supported"}
+	}
+
+	// PKCE is required for every app, public or not
+	if request.CodeChallengeMethod != domain.PKCE_METHOD_S256 || len(request.CodeChallenge) != len(domain.PKCEChallenge("")) {
+		return nil, nil, domain_errors.OAuthError{Code: domain_errors.OAuthInvalidRequest, Description: "A S256 code challenge is required"}
+	}
+
+	scopes, err := parseScopes(request.Scope)
+	if err != nil {
+		return nil, nil, err
+	}
+	return app, scopes, nil
+}
+
+func (s *OAuthService) GetConsent(ctx context.Context, userID int, request domain.AuthorizationRequest) (*domain.OAuthConsent, error) {
+	app, scopes, err := s.validateRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := s.grantRepository.GetGrant(ctx, userID, app.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.OAuthConsent{
+		App:     app,
+		Scopes:  scopes,
+		Granted: grant != nil && grant.Covers(scopes),
+	}, nil
+}
+
+// The user said yes, remembers it and hands back the code for the redirect
+func (s *OAuthService) Authorize(ctx context.Context, userID int, request domain.AuthorizationRequest) (string, error) {
+	app, scopes, err := s.validateRequest(ctx, request)
+	if err != nil {
+		return "", err
+	}
+
+	grant, err := s.grantRepository.GetGrant(ctx, userID, app.ID)
+	if err != nil {
+		return "", err
+	}
+	if grant == nil {
+		grant = domain.NewOAuthGrant(userID, app, scopes)
+	} else {
+		grant.AddScopes(scopes)
+	}
+	if err := s.grantRepository.SaveGrant(ctx, grant); err != nil {
+		return "", err
+	}
+
+	code, plain := domain.NewOAuthAuthorizationCode(app.ID, userID, request.RedirectURI, scopes, request.CodeChallenge)
+	if err := s.codeRepository.CreateCode(ctx, code); err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// Public apps only identify themselves, the rest also prove it with their secret
+func (s *OAuthService) authenticateClient(ctx context.Context, clientID, clientSecret string) (*domain.OAuthApp, error) {
+	invalid := domain_errors.OAuthError{Code: domain_errors.OAuthInvalidClient, Description: "Unknown client or wrong secret"}
+
+	app, err := s.appRepository.GetApp(ctx, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if app == nil {
+		return nil, invalid
+	}
+	if app.Public {
+		return app, nil
+	}
+
+	if clientSecret == "" || subtle.ConstantTimeCompare([]byte(utils.HashToken(clientSecret)), []byte(app.SecretHash)) != 1 {
+		return nil, invalid
+	}
+	return app, nil
+}
+
+func (s *OAuthService) ExchangeCode(ctx context.Context, clientID, clientSecret, code, redirectURI, verifier string) (*domain.OAuthToken, string, string, error) {
+	app, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	invalid := domain_errors.OAuthError{Code: domain_errors.OAuthInvalidGrant, Description: "Invalid, expired or already used authorization code"}
+	if code == "" {
+		return nil, "", "", invalid
+	}
+
+	hash := utils.HashToken(code)
+	redeemed, err := s.codeRepository.RedeemCode(ctx, hash, time.Now())
+	if err != nil {
+		return nil, "", "", err
+	}
+	if redeemed == nil {
+		// A code showing up twice was intercepted somewhere, whatever it got is no longer trusted
+		if used, err := s.codeRepository.GetCode(ctx, hash); err == nil && used != nil && used.AppID == app.ID {
+			if err := s.tokenRepository.RevokeCodeTokens(ctx, hash, time.Now()); err != nil {
+				log.Printf("Failed to revoke tokens of a replayed code: %v", err)
+			}
+		}
+		return nil, "", "", invalid
+	}
+
+	if redeemed.AppID != app.ID || redeemed.IsExpired() || redeemed.RedirectURI != redirectURI {
+		return nil, "", "", invalid
+	}
+	if !redeemed.VerifyChallenge(verifier) {
+		return nil, "", "", domain_errors.OAuthError{Code: domain_errors.OAuthInvalidGrant, Description: "Code verifier doesn't match the challenge"}
+	}
+
+	// Revoked while the app was still on its way here
+	grant, err := s.grantRepository.GetGrant(ctx, redeemed.UserID, app.ID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if grant == nil {
+		return nil, "", "", invalid
+	}
+
+	token, access, refresh := domain.NewOAuthToken(app.ID, redeemed.UserID, redeemed.Scopes, hash)
+	if err := s.tokenRepository.CreateToken(ctx, token); err != nil {
+		return nil, "", "", err
+	}
+	return token, access, refresh, nil
+}
+
+// Swaps the refresh token for a new pair, the old refresh token is spent
+func (s *OAuthService) RefreshToken(ctx context.Context, clientID, clientSecret, refreshToken string) (*domain.OAuthToken, string, string, error) {
+	app, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	invalid := domain_errors.OAuthError{Code: domain_errors.OAuthInvalidGrant, Description: "Invalid or expired refresh token"}
+	if !strings.HasPrefix(refreshToken, domain.OAUTH_REFRESH_TOKEN_PREFIX) {
+		return nil, "", "", invalid
+	}
+
+	oldHash := utils.HashToken(refreshToken)
+	token, err := s.tokenRepository.GetTokenByRefreshHash(ctx, oldHash)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if token == nil || token.AppID != app.ID || !token.IsRefreshValid() {
+		return nil, "", "", invalid
+	}
+
+	access, refresh := token.Rotate()
+	rotated, err := s.tokenRepository.RotateToken(ctx, token, oldHash)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if !rotated {
+		return nil, "", "", invalid
+	}
+	return token, access, refresh, nil
+}
+
+func (s *OAuthService) GetGrants(ctx context.Context, userID int) ([]*domain.OAuthGrant, error) {
+	return s.grantRepository.GetUserGrants(ctx, userID)
+}
+
+// Takes the app's access away, every token it holds for the user stops working
+func (s *OAuthService) RevokeGrant(ctx context.Context, userID int, appID string) error {
+	deleted, err := s.grantRepository.DeleteGrant(ctx, userID, appID)
+	if err != nil {
+		return err
+	}
+
+	// Tokens go even if the grant was already gone, in case a previous revoke failed halfway
+	if err := s.tokenRepository.RevokeGrantTokens(ctx, userID, appID, time.Now()); err != nil {
+		return err
+	}
+
+	if !deleted {
+		return domain_errors.OAuthGrantNotFoundError{}
+	}
+	return nil
+}
+
+// Who the access token belongs to and what it may do, for the middlewares
+func (s *OAuthService) AuthenticateToken(ctx context.Context, token string) (int, []value.TokenScope, bool) {
+	if !strings.HasPrefix(token, domain.OAUTH_ACCESS_TOKEN_PREFIX) {
+		return 0, nil, false
+	}
+
+	stored, err := s.tokenRepository.GetTokenByAccessHash(ctx, utils.HashToken(token))
+	if err != nil {
+		log.Printf("Failed to look up app token: %v", err)
+		return 0, nil, false
+	}
+	if stored == nil || !stored.IsAccessValid() {
+		return 0, nil, false
+	}
+
+	now := time.Now()
+	if stored.ShouldRecordUse(now) {
+		if err := s.tokenRepository.MarkUsed(ctx, stored.ID, now); err != nil {
+			log.Printf("Failed to record app token use: %v", err)
+		}
+	}
+
+	return stored.UserID, stored.Scopes, true
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type PersonalTokenService struct {
+	tokenRepository interfaces.PersonalTokenRepository
+}
+
+func NewPersonalTokenService(tokenRepo interfaces.PersonalTokenRepository) *PersonalTokenService {
+	return &PersonalTokenService{
+		tokenRepository: tokenRepo,
+	}
+}
+
+func (s *PersonalTokenService) CreateToken(ctx context.Context, userID int, name string, scopes []value.TokenScope, expiresAt *time.Time) (*domain.PersonalToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > domain.MAX_PERSONAL_TOKEN_NAME_LENGTH {
+		return nil, "", domain_errors.InvalidTokenNameError{MaxLength: domain.MAX_PERSONAL_TOKEN_NAME_LENGTH}
+	}
+
+	if len(scopes) == 0 {
+		return nil, "", domain_errors.NoTokenScopesError{}
+	}
+	unique := make([]value.TokenScope, 0, len(scopes))
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return nil, "", domain_errors.InvalidTokenScopeError{Scope: string(scope)}
+		}
+		if !slices.Contains(unique, scope) {
+			unique = append(unique, scope)
+		}
+	}
+
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", domain_errors.InvalidTokenExpiryError{}
+	}
+
+	count, err := s.tokenRepository.CountUserTokens(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if count >= domain.MAX_PERSONAL_TOKENS {
+		return nil, "", domain_errors.TooManyPersonalTokensError{Max: domain.MAX_PERSONAL_TOKENS}
+	}
+
+	token, plain := domain.NewPersonalToken(userID, name, unique, expiresAt)
+	if err := s.tokenRepository.CreateToken(ctx, token); err != nil {
+		return nil, "", err
+	}
+	return token, plain, nil
+}
+
+func (s *PersonalTokenService) GetTokens(ctx context.Context, userID int) ([]*domain.PersonalToken, error) {
+	return s.tokenRepository.GetUserTokens(ctx, userID)
+}
+
+func (s *PersonalTokenService) RevokeToken(ctx context.Context, userID int, tokenID string) error {
+	revoked, err := s.tokenRepository.RevokeToken(ctx, tokenID, userID, time.Now())
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return domain_errors.PersonalTokenNotFoundError{}
+	}
+	return nil
+}
+
+// Who the token belongs to and what it may do, for the middlewares
+func (s *PersonalTokenService) AuthenticateToken(ctx context.Context, token string) (int, []value.TokenScope, bool) {
+	if !strings.HasPrefix(token, domain.PERSONAL_TOKEN_PREFIX) {
+		return 0, nil, false
+	}
+
+	stored, err := s.tokenRepository.GetTokenByHash(ctx, utils.HashToken(token))
+	if err != nil {
+		log.Printf("Failed to look up personal token: %v", err)
+		return 0, nil, false
+	}
+	if stored == nil || !stored.IsActive() {
+		return 0, nil, false
+	}
+
+	now := time.Now()
+	if stored.ShouldRecordUse(now) {
+		if err := s.tokenRepository.MarkUsed(ctx, stored.ID, now); err != nil {
+			log.Printf("Failed to record personal token use: %v", err)
+		}
+	}
+
+	return stored.UserID, stored.Scopes, true
+}
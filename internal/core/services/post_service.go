@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
 	"strconv"
 
 	"github.com/afuradanime/backend/internal/adapters/middlewares"
@@ -13,12 +14,18 @@ import (
 	"github.com/afuradanime/backend/internal/core/utils"
 )
 
+const MAX_THREAD_DEPTH = 200 // Walking up a reply chain stops here, legacy posts don't know their root
+
 type PostService struct {
-	postRepo          interfaces.PostRepository
-	userRepo          interfaces.UserRepository
-	friendshipService interfaces.FriendshipService
-	groupService      interfaces.GroupService
-	animeService      interfaces.AnimeService
+	postRepo            interfaces.PostRepository
+	userRepo            interfaces.UserRepository
+	friendshipService   interfaces.FriendshipService
+	groupService        interfaces.GroupService
+	animeService        interfaces.AnimeService
+	notificationService interfaces.NotificationService
+	events              interfaces.EventBroker
+	contentFilter       interfaces.ContentFilterService
+	auditService        interfaces.AuditService
 }
 
 func NewPostService(
@@ -27,24 +34,40 @@ func NewPostService(
 	friendshipService interfaces.FriendshipService,
 	animeService interfaces.AnimeService,
 	groupService interfaces.GroupService,
+	notificationService interfaces.NotificationService,
+	events interfaces.EventBroker,
+	contentFilter interfaces.ContentFilterService,
+	auditService interfaces.AuditService,
 ) *PostService {
 	return &PostService{
-		postRepo:          postRepo,
-		userRepo:          userRepo,
-		friendshipService: friendshipService,
-		animeService:      animeService,
-		groupService:      groupService,
+		postRepo:            postRepo,
+		userRepo:            userRepo,
+		friendshipService:   friendshipService,
+		animeService:        animeService,
+		groupService:        groupService,
+		notificationService: notificationService,
+		events:              events,
+		contentFilter:       contentFilter,
+		auditService:        auditService,
 	}
 }
 
-func (s *PostService) GetPostById(ctx context.Context, postId string) (*domain.Post, error) {
-	// no domain rules, anyone can fetch any post and see it, even if it's deleted
-	// the only thing we're limiting are interactions like posting and replying
+func (s *PostService) GetPostById(ctx context.Context, postId string, viewerId *int) (*domain.Post, error) {
+	// anyone can fetch any post and see it, even if it's deleted
+	// the only exception are posts living inside a private group
 	post, err := s.postRepo.GetPostById(ctx, postId)
 	if err != nil {
 		return nil, err
 	}
 
+	if !post.IsVisibleTo(viewerId) {
+		return nil, domain_errors.PostNotFoundError{PostID: postId}
+	}
+
+	if err := s.CanViewThread(ctx, post.ParentType, post.ParentId, viewerId); err != nil {
+		return nil, err
+	}
+
 	// Parse markdown
 	// We do this server side because today user 1 might be called Makoto naegi
 	// But tomorrow he may be called Nagito komaeda or something we never know
@@ -53,18 +76,27 @@ func (s *PostService) GetPostById(ctx context.Context, postId string) (*domain.P
 	return post, err
 }
 
-func (s *PostService) GetPostReplies(ctx context.Context, parentID string, parentType value.PostParentType) ([]*domain.Post, error) {
+func (s *PostService) GetPostReplies(ctx context.Context, parentID string, parentType value.PostParentType, viewerId *int) ([]*domain.Post, error) {
+	if err := s.CanViewThread(ctx, parentType, parentID, viewerId); err != nil {
+		return nil, err
+	}
+
 	posts, err := s.postRepo.GetPostReplies(ctx, parentID, parentType)
 
 	if err != nil {
 		return nil, err
 	}
 
+	visible := make([]*domain.Post, 0, len(posts))
 	for _, element := range posts {
+		if !element.IsVisibleTo(viewerId) {
+			continue
+		}
 		middlewares.ParsePost(element, ctx, s.animeService, s.userRepo)
+		visible = append(visible, element)
 	}
 
-	return posts, err
+	return visible, err
 }
 
 // Create a top most post, which is a post that is not a reply to another post
@@ -85,6 +117,7 @@ func (s *PostService) CreatePost(ctx context.Context, parentId string, parentTyp
 	}
 
 	// Checkpoint 2 - Profile Context Blockage
+	var root *domain.Post // Top most post of the thread, only for replies
 	switch parentType {
 	case value.ParentTypeUser:
 		userOfProfileId, err := strconv.Atoi(parentId)
@@ -121,22 +154,30 @@ func (s *PostService) CreatePost(ctx context.Context, parentId string, parentTyp
 			return nil, errors.New("Invalid parent id: " + err.Error())
 		}
 
-		group, err := s.groupService.GetGroup(ctx, groupId)
-		if err != nil {
-			return nil, errors.New("Invalid parent id: " + err.Error())
-		}
-
-		// Check if group private
-		if !group.Public {
-			if !group.IsModerator(posterId) && !poster.HasRole(value.UserRoleAdmin) {
-				return nil, domain_errors.UnauthorizedError{}
-			}
+		// Only members get to post in a group
+		if err := s.groupService.CanPostInGroup(ctx, groupId, posterId); err != nil {
+			return nil, err
 		}
 	case value.ParentTypePost:
-		_, err := s.postRepo.GetPostById(ctx, parentId)
+		parent, err := s.postRepo.GetPostById(ctx, parentId)
 		if err != nil {
 			return nil, errors.New("Invalid parent post id: " + err.Error())
 		}
+
+		// Replies follow the rules of wherever the thread started
+		root, err = s.threadRoot(ctx, parent)
+		if err != nil {
+			return nil, errors.New("Failed to resolve thread: " + err.Error())
+		}
+		if root.ParentType == value.ParentTypeGroup {
+			groupId, err := strconv.Atoi(root.ParentId)
+			if err != nil {
+				return nil, errors.New("Invalid thread group id: " + err.Error())
+			}
+			if err := s.groupService.CanPostInGroup(ctx, groupId, posterId); err != nil {
+				return nil, err
+			}
+		}
 	default:
 		return nil, errors.New("Unsupported thread context")
 	}
@@ -147,15 +188,164 @@ func (s *PostService) CreatePost(ctx context.Context, parentId string, parentTyp
 		return nil, errors.New("post content cannot be empty after sanitization")
 	}
 
+	// Checkpoint 4 - Resolve @username mentions into @id, usernames can change but ids can't
+	cleanText = middlewares.ResolveUsernameMentions(ctx, cleanText, s.userRepo)
+
+	// Checkpoint 5 - Content filter, the post may be turned away, held for review or quietly flagged
+	verdict, err := s.contentFilter.Check(ctx, poster, cleanText)
+	if err != nil {
+		return nil, errors.New("Failed to run content filter: " + err.Error())
+	}
+	if verdict.Action == value.FilterActionReject {
+		s.contentFilter.RecordHit(ctx, verdict, nil, poster.ID, cleanText)
+		return nil, domain_errors.PostRejectedByFilterError{}
+	}
+
 	// Checkpoint X - Anything else, we could add group blockage or forum blockage, etc..
-	// Maybe even a content filter checkpoint should be added here to prevent certain words, etc...
 
 	// All checkpoints cleared, we can create the post
 	newPost := domain.NewPost(
 		parentId, parentType, cleanText, poster.ID,
 	)
+	if root != nil {
+		newPost.TopMostPostId = &root.ID
+	}
+	if verdict.Action == value.FilterActionHold {
+		newPost.Hold()
+	}
 
-	return s.postRepo.CreatePost(ctx, newPost)
+	created, err := s.postRepo.CreatePost(ctx, newPost)
+	if err != nil {
+		return nil, err
+	}
+
+	if verdict.Action != value.FilterActionNone {
+		s.contentFilter.RecordHit(ctx, verdict, created, poster.ID, cleanText)
+	}
+
+	// Nobody hears about a held post until it's released
+	if created.Held {
+		return created, nil
+	}
+
+	s.notifyMentions(ctx, created)
+	s.publishPost(ctx, created)
+	s.recordGroupActivity(ctx, created, root)
+
+	return created, nil
+}
+
+// Posts and replies both count as activity for the group the thread lives in
+func (s *PostService) recordGroupActivity(ctx context.Context, post *domain.Post, root *domain.Post) {
+	if root == nil {
+		root = post
+	}
+	if root.ParentType != value.ParentTypeGroup {
+		return
+	}
+
+	groupId, err := strconv.Atoi(root.ParentId)
+	if err != nil {
+		return
+	}
+
+	if err := s.groupService.RecordActivity(ctx, groupId); err != nil {
+		log.Printf("Failed to record activity for group %d: %v", groupId, err)
+	}
+}
+
+// Checks if the viewer can read posts under the given parent, only private groups are closed off
+func (s *PostService) CanViewThread(ctx context.Context, parentType value.PostParentType, parentId string, viewerId *int) error {
+	switch parentType {
+	case value.ParentTypeGroup:
+		groupId, err := strconv.Atoi(parentId)
+		if err != nil {
+			return errors.New("Invalid parent id: " + err.Error())
+		}
+		return s.groupService.CanViewGroup(ctx, groupId, viewerId)
+	case value.ParentTypePost:
+		parent, err := s.postRepo.GetPostById(ctx, parentId)
+		if err != nil {
+			return domain_errors.PostNotFoundError{PostID: parentId}
+		}
+		root, err := s.threadRoot(ctx, parent)
+		if err != nil {
+			return err
+		}
+		if root.ParentType == value.ParentTypeGroup {
+			return s.CanViewThread(ctx, root.ParentType, root.ParentId, viewerId)
+		}
+	}
+	return nil
+}
+
+// Finds the top most post of the thread a post belongs to
+func (s *PostService) threadRoot(ctx context.Context, post *domain.Post) (*domain.Post, error) {
+	if post.ParentType != value.ParentTypePost {
+		return post, nil
+	}
+
+	if post.TopMostPostId != nil {
+		return s.postRepo.GetPostById(ctx, *post.TopMostPostId)
+	}
+
+	// Older replies don't know their root, climb up to it
+	current := post
+	for depth := 0; current.ParentType == value.ParentTypePost; depth++ {
+		if depth >= MAX_THREAD_DEPTH {
+			return nil, errors.New("thread is too deep")
+		}
+		parent, err := s.postRepo.GetPostById(ctx, current.ParentId)
+		if err != nil {
+			return nil, err
+		}
+		current = parent
+	}
+	return current, nil
+}
+
+// Push the new post to whoever is viewing its parent thread
+func (s *PostService) publishPost(ctx context.Context, post *domain.Post) {
+	// Parse a copy, the caller gets the post as it was stored
+	parsed := *post
+	middlewares.ParsePost(&parsed, ctx, s.animeService, s.userRepo)
+
+	s.events.Publish(domain.ThreadTopic(post.ParentType, post.ParentId), value.EventNewPost, &parsed)
+}
+
+// Let everyone mentioned in the post know, except the ones that want nothing to do with the poster
+// and the ones that can't see the thread. Whoever is replied to already hears about it as a reply
+func (s *PostService) notifyMentions(ctx context.Context, post *domain.Post) {
+	mentionedIds, _ := middlewares.ExtractMentions(*post.Text)
+	if len(mentionedIds) == 0 {
+		return
+	}
+
+	repliedTo := 0
+	if post.IsReply() {
+		if parent, err := s.postRepo.GetPostById(ctx, post.ParentId); err == nil && parent != nil && parent.CreatedBy != nil {
+			repliedTo = *parent.CreatedBy
+		}
+	}
+
+	for _, mentionedId := range mentionedIds {
+		if mentionedId == repliedTo {
+			continue
+		}
+
+		friendship, err := s.friendshipService.FetchFriendshipStatus(ctx, *post.CreatedBy, mentionedId)
+		if err == nil && friendship != nil && friendship.Status == value.FriendshipStatusBlocked {
+			continue
+		}
+
+		if err := s.CanViewThread(ctx, post.ParentType, post.ParentId, &mentionedId); err != nil {
+			continue
+		}
+
+		if err := s.notificationService.Notify(ctx, mentionedId, *post.CreatedBy, value.NotificationMention, post.ID); err != nil {
+			log.Printf("Failed to notify user %d of mention in post %s: %v", mentionedId, post.ID, err)
+		}
+	}
 }
 
 func (s *PostService) CreateReply(ctx context.Context, replyToPostID string, text string, createdBy int) (*domain.Post, error) {
@@ -165,7 +355,7 @@ func (s *PostService) CreateReply(ctx context.Context, replyToPostID string, tex
 	postBeingRepliedTo, err := s.postRepo.GetPostById(ctx, replyToPostID)
 	if err != nil {
 		return nil, errors.New("Failed to fetch post being replied to: " + err.Error())
-	} else if postBeingRepliedTo == nil {
+	} else if postBeingRepliedTo == nil || !postBeingRepliedTo.IsVisibleTo(&createdBy) {
 		return nil, domain_errors.PostNotFoundError{PostID: replyToPostID}
 	}
 
@@ -208,10 +398,26 @@ func (s *PostService) CreateReply(ctx context.Context, replyToPostID string, tex
 		return nil, errors.New("failed to update parent post replies: " + err.Error())
 	}
 
+	if !reply.Held {
+		s.notifyReply(ctx, reply)
+	}
+
 	return reply, nil
 }
 
-func (s *PostService) DeletePost(ctx context.Context, postID string, deleterId int) error {
+func (s *PostService) notifyReply(ctx context.Context, reply *domain.Post) {
+	parent, err := s.postRepo.GetPostById(ctx, reply.ParentId)
+	if err != nil || parent.CreatedBy == nil {
+		return
+	}
+
+	if err := s.notificationService.Notify(ctx, *parent.CreatedBy, *reply.CreatedBy, value.NotificationReply, reply.ID); err != nil {
+		log.Printf("Failed to notify user %d of reply to post %s: %v", *parent.CreatedBy, parent.ID, err)
+	}
+}
+
+// Posts are deleted by their owner, or removed by a moderator of the group they live in
+func (s *PostService) DeletePost(ctx context.Context, postID string, deleterId int, reason string) error {
 	post, err := s.postRepo.GetPostById(ctx, postID)
 	if err != nil {
 		return errors.New("Failed to fetch post: " + err.Error())
@@ -224,9 +430,114 @@ func (s *PostService) DeletePost(ctx context.Context, postID string, deleterId i
 	}
 
 	if *post.CreatedBy != deleterId {
-		return domain_errors.NotPostOwnerError{UserID: strconv.Itoa(deleterId), PostID: postID}
+		return s.removeGroupPost(ctx, post, deleterId, reason)
 	}
 
 	post.Delete()
 	return s.postRepo.UpdatePost(ctx, post)
 }
+
+func (s *PostService) removeGroupPost(ctx context.Context, post *domain.Post, moderatorId int, reason string) error {
+	notOwner := domain_errors.NotPostOwnerError{UserID: strconv.Itoa(moderatorId), PostID: post.ID}
+
+	root, err := s.threadRoot(ctx, post)
+	if err != nil {
+		return errors.New("Failed to resolve thread: " + err.Error())
+	}
+	if root.ParentType != value.ParentTypeGroup {
+		return notOwner
+	}
+
+	groupId, err := strconv.Atoi(root.ParentId)
+	if err != nil {
+		return errors.New("Invalid thread group id: " + err.Error())
+	}
+	if err := s.groupService.CanModerateGroup(ctx, groupId, moderatorId); err != nil {
+		return notOwner
+	}
+
+	// Keep what it said in the log, the post itself is wiped like any other deletion
+	author := *post.CreatedBy
+	entry := domain.NewGroupModerationEntry(groupId, moderatorId, value.GroupActionRemovePost, reason).
+		OnUser(author).
+		OnPost(post.ID, *post.Text)
+
+	post.Delete()
+	if err := s.postRepo.UpdatePost(ctx, post); err != nil {
+		return err
+	}
+
+	s.groupService.LogModerationAction(ctx, entry)
+	s.auditService.Record(ctx, domain.NewAuditEntry(moderatorId, value.AuditPostRemoved, value.AuditTargetPost, post.ID).
+		OnUser(author).
+		WithDetails(reason))
+	return nil
+}
+
+func (s *PostService) GetHeldPosts(ctx context.Context, pageNumber, pageSize int) ([]*domain.Post, utils.Pagination, error) {
+	return s.postRepo.GetHeldPosts(ctx, pageNumber, pageSize)
+}
+
+func (s *PostService) getHeldPost(ctx context.Context, postID string) (*domain.Post, error) {
+	post, err := s.postRepo.GetPostById(ctx, postID)
+	if err != nil {
+		return nil, domain_errors.PostNotFoundError{PostID: postID}
+	}
+	if !post.Held {
+		return nil, domain_errors.PostNotHeldError{PostID: postID}
+	}
+	return post, nil
+}
+
+// The post goes up as if it was just posted, everyone who would have heard about it hears about it now
+func (s *PostService) ReleaseHeldPost(ctx context.Context, postID string, moderatorId int) error {
+	post, err := s.getHeldPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+
+	post.Release()
+	if err := s.postRepo.UpdatePost(ctx, post); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, domain.NewAuditEntry(moderatorId, value.AuditHeldPostReleased, value.AuditTargetPost, post.ID).
+		OnUser(*post.CreatedBy))
+	s.contentFilter.ResolveHold(ctx, post, true, moderatorId)
+
+	root, err := s.threadRoot(ctx, post)
+	if err != nil {
+		log.Printf("Failed to resolve thread of post %s: %v", post.ID, err)
+		root = nil
+	}
+
+	s.notifyMentions(ctx, post)
+	if post.IsReply() {
+		s.notifyReply(ctx, post)
+	}
+	s.publishPost(ctx, post)
+	s.recordGroupActivity(ctx, post, root)
+
+	return nil
+}
+
+// Wiped like any other deleted post, the log and the filter hit keep what it said
+func (s *PostService) RejectHeldPost(ctx context.Context, postID string, moderatorId int) error {
+	post, err := s.getHeldPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+
+	entry := domain.NewAuditEntry(moderatorId, value.AuditHeldPostRejected, value.AuditTargetPost, post.ID).
+		OnUser(*post.CreatedBy).
+		WithDetails(*post.Text)
+
+	post.Delete()
+	if err := s.postRepo.UpdatePost(ctx, post); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, entry)
+	s.contentFilter.ResolveHold(ctx, post, false, moderatorId)
+	return nil
+}
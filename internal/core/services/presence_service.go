@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+)
+
+type PresenceService struct {
+	userRepo          interfaces.UserRepository
+	friendshipService interfaces.FriendshipService
+	tracker           *domain.ActivityTracker
+	events            interfaces.EventBroker
+}
+
+func NewPresenceService(
+	userRepo interfaces.UserRepository,
+	friendshipService interfaces.FriendshipService,
+	tracker *domain.ActivityTracker,
+	events interfaces.EventBroker,
+) *PresenceService {
+	return &PresenceService{
+		userRepo:          userRepo,
+		friendshipService: friendshipService,
+		tracker:           tracker,
+		events:            events,
+	}
+}
+
+// Whether the viewer (nil when anonymous) may see the user's presence
+func (s *PresenceService) canSee(ctx context.Context, viewerID *int, user *domain.User) (bool, error) {
+	if viewerID != nil && *viewerID == user.ID {
+		return true, nil
+	}
+
+	switch user.PresenceVisibility {
+	case value.PresenceVisibleToEveryone:
+		return true, nil
+	case value.PresenceVisibleToFriends:
+		if viewerID == nil {
+			return false, nil
+		}
+		friendship, err := s.friendshipService.FetchFriendshipStatus(ctx, *viewerID, user.ID)
+		if err != nil {
+			return false, err
+		}
+		return friendship != nil && friendship.AreFriends(), nil
+	default:
+		return false, nil
+	}
+}
+
+func (s *PresenceService) GetUserPresence(ctx context.Context, viewerID *int, userID int) (*domain.UserPresence, error) {
+	user, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil || user == nil {
+		return nil, domain_errors.UserNotFoundError{UserID: strconv.Itoa(userID)}
+	}
+
+	visible, err := s.canSee(ctx, viewerID, user)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return &domain.UserPresence{UserID: userID, Status: value.Offline, Hidden: true}, nil
+	}
+
+	presence := &domain.UserPresence{
+		UserID:    userID,
+		Status:    value.ActivityStatus(s.tracker.IsActive(userID)),
+		Connected: s.tracker.IsConnected(userID),
+	}
+
+	if presence.Status == 0 || presence.Status == value.Offline {
+		presence.Status = value.Offline
+		if !user.LastSeen.IsZero() {
+			presence.LastSeen = &user.LastSeen
+		}
+	}
+
+	return presence, nil
+}
+
+// Online users the viewer is allowed to see, along with how many are online in total
+func (s *PresenceService) GetVisibleOnlineUsers(ctx context.Context, viewerID *int) ([]int, int, error) {
+	online := s.tracker.GetActiveUsers()
+	if len(online) == 0 {
+		return online, 0, nil
+	}
+
+	users, err := s.userRepo.GetUsersByIds(ctx, online)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	visible := make([]int, 0, len(users))
+	for _, user := range users {
+		ok, err := s.canSee(ctx, viewerID, user)
+		if err != nil {
+			return nil, 0, err
+		}
+		if ok {
+			visible = append(visible, user.ID)
+		}
+	}
+
+	// The total is just a number, it doesn't give anyone away
+	return visible, len(online), nil
+}
+
+// Online users whose username starts with the prefix, only the ones the viewer is allowed to see
+func (s *PresenceService) SearchVisibleOnlineUsers(ctx context.Context, viewerID int, prefix string, limit int) ([]*domain.User, error) {
+	online := s.tracker.GetActiveUsers()
+	if len(online) == 0 {
+		return nil, nil
+	}
+
+	users, err := s.userRepo.SearchByUsernamePrefixAmong(ctx, prefix, online, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]*domain.User, 0, len(users))
+	for _, user := range users {
+		ok, err := s.canSee(ctx, &viewerID, user)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			visible = append(visible, user)
+		}
+	}
+	return visible, nil
+}
+
+// Pushes a presence change to the user's friends, unless they'd rather nobody knew
+func (s *PresenceService) PublishPresence(ctx context.Context, userID int, status value.ActivityStatus) error {
+	user, err := s.userRepo.GetUserById(ctx, userID)
+	if err != nil || user == nil {
+		return domain_errors.UserNotFoundError{UserID: strconv.Itoa(userID)}
+	}
+
+	// Only friends subscribe to presence topics, so friends-only needs no extra check
+	if user.PresenceVisibility == value.PresenceVisibleToNobody {
+		return nil
+	}
+
+	s.events.Publish(domain.PresenceTopic(userID), value.EventPresence, domain.PresenceEvent{
+		UserID: userID,
+		Status: status,
+	})
+	return nil
+}
+
+// Friends keep the last status they were sent, so hiding sends them one last offline
+// and showing up again sends them where the user is at
+func (s *PresenceService) PublishVisibilityChange(ctx context.Context, userID int, previous, current value.PresenceVisibility) {
+	hidden := current == value.PresenceVisibleToNobody
+	if hidden == (previous == value.PresenceVisibleToNobody) {
+		return
+	}
+
+	status := value.ActivityStatus(s.tracker.IsActive(userID))
+	if hidden || status == 0 {
+		status = value.Offline
+	}
+
+	s.events.Publish(domain.PresenceTopic(userID), value.EventPresence, domain.PresenceEvent{
+		UserID: userID,
+		Status: status,
+	})
+}
+
+func (s *PresenceService) RecordLastSeen(ctx context.Context, userID int, lastSeen time.Time) error {
+	return s.userRepo.UpdateLastSeen(ctx, userID, lastSeen)
+}
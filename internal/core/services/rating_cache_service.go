@@ -49,7 +49,7 @@ func (s *RatingCacheService) UpdateExistingRating(ctx context.Context, userID in
 }
 
 func (s *RatingCacheService) RemoveRating(ctx context.Context, userID int, animeID int, oldStory, oldVisuals, oldSoundtrack uint8) error {
-	cache, err := s.repo.GetRatingCache(nil, animeID)
+	cache, err := s.repo.GetRatingCache(ctx, animeID)
 	if err != nil {
 		return err
 	}
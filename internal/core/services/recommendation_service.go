@@ -2,19 +2,22 @@ package services
 
 import (
 	"context"
+	"log"
 	"strconv"
 
 	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
 	domain_errors "github.com/afuradanime/backend/internal/core/errors"
 	"github.com/afuradanime/backend/internal/core/interfaces"
 	"github.com/afuradanime/backend/internal/core/utils"
 )
 
 type RecommendationService struct {
-	recommendationRepo interfaces.RecommendationRepository
-	userRepo           interfaces.UserRepository
-	friendshipService  interfaces.FriendshipService
-	animeListService   interfaces.AnimeListService
+	recommendationRepo  interfaces.RecommendationRepository
+	userRepo            interfaces.UserRepository
+	friendshipService   interfaces.FriendshipService
+	animeListService    interfaces.AnimeListService
+	notificationService interfaces.NotificationService
 }
 
 func NewRecommendationService(
@@ -22,12 +25,14 @@ func NewRecommendationService(
 	userRepo interfaces.UserRepository,
 	friendshipService interfaces.FriendshipService,
 	animeListService interfaces.AnimeListService,
+	notificationService interfaces.NotificationService,
 ) *RecommendationService {
 	return &RecommendationService{
-		recommendationRepo: recommendationRepo,
-		userRepo:           userRepo,
-		friendshipService:  friendshipService,
-		animeListService:   animeListService,
+		recommendationRepo:  recommendationRepo,
+		userRepo:            userRepo,
+		friendshipService:   friendshipService,
+		animeListService:    animeListService,
+		notificationService: notificationService,
 	}
 }
 
@@ -84,7 +89,15 @@ func (s *RecommendationService) Send(ctx context.Context, initiatorID, receiverI
 	}
 
 	rec := domain.NewRecommendation(initiatorID, receiverID, animeID)
-	return s.recommendationRepo.Create(ctx, rec)
+	if err := s.recommendationRepo.Create(ctx, rec); err != nil {
+		return err
+	}
+
+	if err := s.notificationService.Notify(ctx, receiverID, initiatorID, value.NotificationRecommendation, strconv.Itoa(animeID)); err != nil {
+		log.Printf("Failed to notify user %d of recommendation from %d: %v", receiverID, initiatorID, err)
+	}
+
+	return nil
 }
 
 func (s *RecommendationService) GetUserRecommendations(ctx context.Context, userID, pageNumber, pageSize int) ([]*domain.Recommendation, utils.Pagination, error) {
@@ -2,6 +2,10 @@ package services
 
 import (
 	"context"
+	"errors"
+	"log"
+	"strconv"
+	"time"
 
 	"github.com/afuradanime/backend/internal/adapters/repositories"
 	"github.com/afuradanime/backend/internal/core/domain"
@@ -12,23 +16,42 @@ import (
 )
 
 const MAX_REPORT_NUMBER = 5
+const REPORT_SANCTION_DURATION = 7 * 24 * time.Hour
 
-type UserReportService struct {
-	reportRepository interfaces.UserReportRepository
-	userRepository   interfaces.UserRepository
+type ReportService struct {
+	reportRepository      interfaces.ReportRepository
+	userRepository        interfaces.UserRepository
+	postRepository        interfaces.PostRepository
+	groupRepository       interfaces.GroupRepository
+	translationRepository interfaces.DescriptionTranslationRepository
+	caseRepository        interfaces.ModerationCaseRepository
+	sanctionService       interfaces.SanctionService
+	auditService          interfaces.AuditService
 }
 
-func NewUserReportService(
-	reportRepo interfaces.UserReportRepository,
+func NewReportService(
+	reportRepo interfaces.ReportRepository,
 	userRepo interfaces.UserRepository,
-) *UserReportService {
-	return &UserReportService{
-		reportRepository: reportRepo,
-		userRepository:   userRepo,
+	postRepo interfaces.PostRepository,
+	groupRepo interfaces.GroupRepository,
+	translationRepo interfaces.DescriptionTranslationRepository,
+	caseRepo interfaces.ModerationCaseRepository,
+	sanctionService interfaces.SanctionService,
+	auditService interfaces.AuditService,
+) *ReportService {
+	return &ReportService{
+		reportRepository:      reportRepo,
+		userRepository:        userRepo,
+		postRepository:        postRepo,
+		groupRepository:       groupRepo,
+		translationRepository: translationRepo,
+		caseRepository:        caseRepo,
+		sanctionService:       sanctionService,
+		auditService:          auditService,
 	}
 }
 
-func (s *UserReportService) SubmitReport(ctx context.Context, reason value.ReportReason, targetUserID, reporterID int) error {
+func (s *ReportService) SubmitReport(ctx context.Context, reason value.ReportReason, targetUserID, reporterID int) error {
 	if targetUserID == reporterID {
 		return domain_errors.CannotReportYourselfError{}
 	}
@@ -52,34 +75,209 @@ func (s *UserReportService) SubmitReport(ctx context.Context, reason value.Repor
 		return domain_errors.AlreadyReportedError{}
 	}
 
-	reportCount, err := s.reportRepository.CountReportsByTarget(ctx, targetUserID)
-	if reportCount+1 >= MAX_REPORT_NUMBER {
+	snapshot := "Username: " + string(target.Username) +
+		"\nPronouns: " + string(target.Pronouns) +
+		"\nLocation: " + string(target.Location) +
+		"\nAvatar: " + target.AvatarURL
 
-		if !target.HasRole(value.UserRoleAdmin) && !target.HasRole(value.UserRoleModerator) {
+	return s.submit(ctx, domain.NewUserReport(reason, targetUserID, reporterID, snapshot))
+}
+
+func (s *ReportService) SubmitPostReport(ctx context.Context, reason value.ReportReason, postID string, reporterID int) error {
+	post, err := s.postRepository.GetPostById(ctx, postID)
+	if err != nil || post == nil {
+		return domain_errors.ReportTargetNotFoundError{}
+	}
+
+	// Nothing left to look at
+	if post.IsDeleted() {
+		return domain_errors.PostDeletedError{PostID: postID}
+	}
+	if *post.CreatedBy == reporterID {
+		return domain_errors.CannotReportYourselfError{}
+	}
+
+	report := domain.NewContentReport(reason, value.ReportTargetPost, post.ID, post.CreatedBy, *post.Text, reporterID)
+	return s.submitContent(ctx, report)
+}
+
+func (s *ReportService) SubmitGroupReport(ctx context.Context, reason value.ReportReason, groupID int, reporterID int) error {
+	group, err := s.groupRepository.GetGroup(ctx, groupID)
+	if err != nil || group == nil {
+		return domain_errors.ReportTargetNotFoundError{}
+	}
+
+	snapshot := string(group.Name) + "\n" + string(group.Description) + "\n" + string(group.Rules)
+
+	// A group is everyone's doing, its owner doesn't take the blame for it
+	report := domain.NewContentReport(reason, value.ReportTargetGroup, strconv.Itoa(group.ID), nil, snapshot, reporterID)
+	return s.submitContent(ctx, report)
+}
+
+func (s *ReportService) SubmitTranslationReport(ctx context.Context, reason value.ReportReason, translationID int, reporterID int) error {
+	translation, err := s.translationRepository.GetTranslationByID(ctx, translationID)
+	if err != nil || translation == nil {
+		return domain_errors.ReportTargetNotFoundError{}
+	}
+
+	if translation.CreatedBy == reporterID {
+		return domain_errors.CannotReportYourselfError{}
+	}
+
+	author := translation.CreatedBy
+	report := domain.NewContentReport(reason, value.ReportTargetTranslation, strconv.Itoa(translation.ID), &author,
+		string(translation.TranslatedDescription), reporterID)
+	return s.submitContent(ctx, report)
+}
+
+func (s *ReportService) submitContent(ctx context.Context, report *domain.Report) error {
+	already, err := s.reportRepository.HasReportedContent(ctx, report.CreatedBy, report.TargetType, report.TargetID)
+	if err != nil {
+		return err
+	}
+	if already {
+		return domain_errors.AlreadyReportedError{}
+	}
+
+	return s.submit(ctx, report)
+}
+
+// Stores the report, enough of them against the same user and they're sanctioned until a moderator takes a look
+func (s *ReportService) submit(ctx context.Context, report *domain.Report) error {
+	if report.TargetUser != nil {
+		target, err := s.userRepository.GetUserById(ctx, *report.TargetUser)
+		if err != nil {
+			return err
+		}
+
+		if target != nil && (target.CanPost || target.CanTranslate) {
+			since, err := s.reportsCountSince(ctx, target.ID)
+			if err != nil {
+				return err
+			}
+
+			// One user reporting over and over still counts once
+			reporters, err := s.reportRepository.CountReportersByTarget(ctx, target.ID, report.CreatedBy, since)
+			if err != nil {
+				return err
+			}
+			if report.CreatedBy != domain.SYSTEM_USER_ID {
+				reporters++
+			}
+			if reporters >= MAX_REPORT_NUMBER {
+
+				if !target.HasRole(value.UserRoleAdmin) && !target.HasRole(value.UserRoleModerator) {
 
-			target.RestrictAccesses(false, false)
-			s.userRepository.UpdateUser(ctx, target)
-		} else {
-			// Calma lá nos ja vemos o que fazer
+					_, err := s.sanctionService.IssueSanction(ctx, target.ID, value.SanctionRestricted,
+						"Reported too many times", REPORT_SANCTION_DURATION, domain.SYSTEM_USER_ID)
+					if err != nil {
+						log.Printf("Failed to sanction user %d after reports: %v", target.ID, err)
+					}
+				} else {
+					// Calma lá nos ja vemos o que fazer
+				}
+			}
 		}
 	}
 
-	report := domain.NewUserReport(reason, targetUserID, reporterID)
-	return s.reportRepository.CreateReport(ctx, report)
+	moderationCase, err := s.caseFor(ctx, report)
+	if err != nil {
+		return err
+	}
+	report.CaseID = moderationCase.ID
+
+	if err := s.reportRepository.CreateReport(ctx, report); err != nil {
+		return err
+	}
+
+	return s.caseRepository.IncrementReportCount(ctx, moderationCase.ID, 1)
+}
+
+// Reports from before the user's last sanction were already dealt with, only the ones since it ended count
+// towards the next. A sanction still running counts from when it started
+func (s *ReportService) reportsCountSince(ctx context.Context, userID int) (time.Time, error) {
+	sanctions, _, err := s.sanctionService.GetUserSanctions(ctx, userID, 1, 1)
+	if err != nil || len(sanctions) == 0 {
+		return time.Time{}, err
+	}
+
+	last := sanctions[0]
+	if last.LiftedAt != nil {
+		return *last.LiftedAt, nil
+	}
+	return last.StartsAt, nil
 }
 
-func (s *UserReportService) GetReports(ctx context.Context, pageNumber, pageSize int) ([]repositories.ReportResult, utils.Pagination, error) {
-	return s.reportRepository.GetReports(ctx, pageNumber, pageSize)
+// Reports on the same target pile up in the same case until it's resolved, then a new one opens
+func (s *ReportService) caseFor(ctx context.Context, report *domain.Report) (*domain.ModerationCase, error) {
+	moderationCase, err := s.caseRepository.GetOpenCase(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+	if moderationCase != nil {
+		return moderationCase, nil
+	}
+
+	// Someone else reporting the same thing may have opened it in the meantime
+	moderationCase = domain.NewModerationCase(report)
+	if err := s.caseRepository.CreateCase(ctx, moderationCase); err != nil {
+		if errors.Is(err, domain_errors.CaseAlreadyOpenError{}) {
+			return s.caseRepository.GetOpenCase(ctx, report)
+		}
+		return nil, err
+	}
+	return moderationCase, nil
 }
 
-func (s *UserReportService) GetReportsByTarget(ctx context.Context, targetUserID int, pageNumber, pageSize int) ([]domain.UserReport, utils.Pagination, error) {
+// Closes whatever case is open on the content, a moderator already dealt with it some other way
+func (s *ReportService) ResolveContentCase(
+	ctx context.Context,
+	targetType value.ReportTargetType,
+	targetID string,
+	outcome value.CaseOutcome,
+	moderatorID int,
+) error {
+	moderationCase, err := s.caseRepository.GetOpenCase(ctx, &domain.Report{TargetType: targetType, TargetID: targetID})
+	if err != nil || moderationCase == nil {
+		return err
+	}
+
+	if err := moderationCase.Resolve(outcome, moderatorID); err != nil {
+		return err
+	}
+	return s.caseRepository.UpdateCase(ctx, moderationCase)
+}
+
+func (s *ReportService) GetReports(ctx context.Context, targetType *value.ReportTargetType, pageNumber, pageSize int) ([]repositories.ReportResult, utils.Pagination, error) {
+	if targetType != nil && !targetType.IsValid() {
+		return nil, utils.Pagination{}, domain_errors.InvalidReportTargetTypeError{}
+	}
+	return s.reportRepository.GetReports(ctx, targetType, pageNumber, pageSize)
+}
+
+func (s *ReportService) GetReportsByTarget(ctx context.Context, targetUserID int, pageNumber, pageSize int) ([]domain.Report, utils.Pagination, error) {
 	return s.reportRepository.GetReportsByTarget(ctx, targetUserID, pageNumber, pageSize)
 }
 
-func (s *UserReportService) DeleteReport(ctx context.Context, id int, moderatorID int) error {
+func (s *ReportService) DeleteReport(ctx context.Context, id int, moderatorID int) error {
 	report, err := s.reportRepository.GetReportByID(ctx, id)
 	if err != nil || report == nil {
 		return domain_errors.ReportNotFoundError{}
 	}
-	return s.reportRepository.DeleteReport(ctx, id)
+
+	if err := s.reportRepository.DeleteReport(ctx, id); err != nil {
+		return err
+	}
+
+	entry := domain.NewAuditEntry(moderatorID, value.AuditReportDeleted, value.AuditTargetReport, strconv.Itoa(id)).
+		WithDetails(report.Snapshot)
+	if report.TargetUser != nil {
+		entry.OnUser(*report.TargetUser)
+	}
+	s.auditService.Record(ctx, entry)
+
+	if report.CaseID != 0 {
+		return s.caseRepository.IncrementReportCount(ctx, report.CaseID, -1)
+	}
+	return nil
 }
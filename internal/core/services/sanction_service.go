@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+type SanctionService struct {
+	sanctionRepository  interfaces.SanctionRepository
+	userRepository      interfaces.UserRepository
+	notificationService interfaces.NotificationService
+	auditService        interfaces.AuditService
+}
+
+func NewSanctionService(
+	sanctionRepo interfaces.SanctionRepository,
+	userRepo interfaces.UserRepository,
+	notificationService interfaces.NotificationService,
+	auditService interfaces.AuditService,
+) *SanctionService {
+	return &SanctionService{
+		sanctionRepository:  sanctionRepo,
+		userRepository:      userRepo,
+		notificationService: notificationService,
+		auditService:        auditService,
+	}
+}
+
+func (s *SanctionService) IssueSanction(ctx context.Context, userID int, sanctionType value.SanctionType, reason string, duration time.Duration, issuerID int) (*domain.Sanction, error) {
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domain_errors.UserNotFoundError{UserID: strconv.Itoa(userID)}
+	}
+
+	if user.HasRole(value.UserRoleAdmin) {
+		// Népia
+		return nil, domain_errors.CantRestrictAnAdmin{}
+	}
+
+	sanction, err := domain.NewSanction(userID, sanctionType, reason, issuerID, duration)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sanctionRepository.CreateSanction(ctx, sanction); err != nil {
+		return nil, err
+	}
+
+	if err := s.refreshRestrictions(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, domain.NewAuditEntry(issuerID, value.AuditSanctionIssued, value.AuditTargetSanction, strconv.Itoa(sanction.ID)).
+		OnUser(userID).
+		WithDetails(reason))
+
+	if err := s.notificationService.Notify(ctx, userID, issuerID, value.NotificationSanction, strconv.Itoa(sanction.ID)); err != nil {
+		log.Printf("Failed to notify user %d of sanction %d: %v", userID, sanction.ID, err)
+	}
+
+	return sanction, nil
+}
+
+func (s *SanctionService) LiftSanction(ctx context.Context, id int, moderatorID int) error {
+	sanction, err := s.getSanction(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := sanction.Lift(moderatorID); err != nil {
+		return err
+	}
+
+	if err := s.sanctionRepository.UpdateSanction(ctx, sanction); err != nil {
+		return err
+	}
+
+	if err := s.refreshRestrictions(ctx, sanction.UserID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, domain.NewAuditEntry(moderatorID, value.AuditSanctionLifted, value.AuditTargetSanction, strconv.Itoa(id)).
+		OnUser(sanction.UserID))
+	return nil
+}
+
+// Lifts every sanction that ran out, returns how many were lifted
+func (s *SanctionService) LiftExpired(ctx context.Context) (int, error) {
+	expired, err := s.sanctionRepository.GetExpiredSanctions(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	lifted := 0
+	users := map[int]bool{}
+	for _, sanction := range expired {
+		if err := sanction.Lift(domain.SYSTEM_USER_ID); err != nil {
+			continue
+		}
+		if err := s.sanctionRepository.UpdateSanction(ctx, sanction); err != nil {
+			log.Printf("Failed to lift expired sanction %d: %v", sanction.ID, err)
+			continue
+		}
+		users[sanction.UserID] = true
+		lifted++
+	}
+
+	for userID := range users {
+		if err := s.refreshRestrictions(ctx, userID); err != nil {
+			log.Printf("Failed to refresh restrictions of user %d: %v", userID, err)
+		}
+	}
+
+	return lifted, nil
+}
+
+// Runs LiftExpired every so often for as long as the server is up
+func (s *SanctionService) StartExpiryJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := s.LiftExpired(context.Background()); err != nil {
+				log.Printf("Failed to lift expired sanctions: %v", err)
+			}
+		}
+	}()
+}
+
+// CanPost and CanTranslate are whatever the active sanctions leave them at,
+// so overlapping sanctions don't give rights back when only one of them ends
+func (s *SanctionService) refreshRestrictions(ctx context.Context, userID int) error {
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	active, err := s.sanctionRepository.GetActiveSanctions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	canPost, canTranslate := true, true
+	for _, sanction := range active {
+		if sanction.Type.BlocksPosting() {
+			canPost = false
+		}
+		if sanction.Type.BlocksTranslating() {
+			canTranslate = false
+		}
+	}
+
+	if user.CanPost == canPost && user.CanTranslate == canTranslate {
+		return nil
+	}
+
+	user.RestrictAccesses(canPost, canTranslate)
+	return s.userRepository.UpdateUser(ctx, user)
+}
+
+func (s *SanctionService) getSanction(ctx context.Context, id int) (*domain.Sanction, error) {
+	sanction, err := s.sanctionRepository.GetSanction(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sanction == nil {
+		return nil, domain_errors.SanctionNotFoundError{}
+	}
+	return sanction, nil
+}
+
+func (s *SanctionService) GetUserSanctions(ctx context.Context, userID int, pageNumber, pageSize int) ([]*domain.Sanction, utils.Pagination, error) {
+	return s.sanctionRepository.GetSanctionsByUser(ctx, userID, pageNumber, pageSize)
+}
+
+func (s *SanctionService) GetActiveSanctions(ctx context.Context, userID int) ([]*domain.Sanction, error) {
+	return s.sanctionRepository.GetActiveSanctions(ctx, userID)
+}
+
+// Only the sanctioned user can appeal, and only while the sanction still holds
+func (s *SanctionService) AppealSanction(ctx context.Context, id int, text string, userID int) error {
+	sanction, err := s.getSanction(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	// Someone else's sanctions are none of their business
+	if sanction.UserID != userID {
+		return domain_errors.SanctionNotFoundError{}
+	}
+
+	if err := sanction.FileAppeal(text); err != nil {
+		return err
+	}
+
+	return s.sanctionRepository.UpdateSanction(ctx, sanction)
+}
+
+func (s *SanctionService) GetPendingAppeals(ctx context.Context, pageNumber, pageSize int) ([]*domain.Sanction, utils.Pagination, error) {
+	return s.sanctionRepository.GetPendingAppeals(ctx, pageNumber, pageSize)
+}
+
+func (s *SanctionService) ReviewAppeal(ctx context.Context, id int, accepted bool, response string, moderatorID int) error {
+	sanction, err := s.getSanction(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := sanction.ReviewAppeal(accepted, moderatorID, response); err != nil {
+		return err
+	}
+
+	if err := s.sanctionRepository.UpdateSanction(ctx, sanction); err != nil {
+		return err
+	}
+
+	action := value.AuditAppealRejected
+	if accepted {
+		if err := s.refreshRestrictions(ctx, sanction.UserID); err != nil {
+			return err
+		}
+		action = value.AuditAppealAccepted
+	}
+
+	s.auditService.Record(ctx, domain.NewAuditEntry(moderatorID, action, value.AuditTargetSanction, strconv.Itoa(id)).
+		OnUser(sanction.UserID).
+		WithDetails(response))
+
+	if err := s.notificationService.Notify(ctx, sanction.UserID, moderatorID, value.NotificationAppealReviewed, strconv.Itoa(sanction.ID)); err != nil {
+		log.Printf("Failed to notify user %d of appeal review: %v", sanction.UserID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/afuradanime/backend/internal/core/utils"
+)
+
+// How long a session is trusted to still be active before the database is asked again.
+// Revocations through this service are seen right away, this only bounds the ones from elsewhere
+const SESSION_CHECK_INTERVAL = 30 * time.Second
+const SESSION_CACHE_SIZE = 10000
+
+type cachedSession struct {
+	userID    int
+	checkedAt time.Time
+}
+
+type SessionService struct {
+	sessionRepository interfaces.SessionRepository
+	userRepository    interfaces.UserRepository
+	jwtService        *JWTService
+
+	// Every authenticated request checks its session, keep the known good ones around for a bit
+	mu     sync.Mutex
+	active map[string]cachedSession
+}
+
+func NewSessionService(
+	sessionRepo interfaces.SessionRepository,
+	userRepo interfaces.UserRepository,
+	jwtService *JWTService,
+) *SessionService {
+	return &SessionService{
+		sessionRepository: sessionRepo,
+		userRepository:    userRepo,
+		jwtService:        jwtService,
+		active:            make(map[string]cachedSession),
+	}
+}
+
+func (s *SessionService) StartSession(ctx context.Context, user *domain.User, userAgent, ip string) (string, string, error) {
+	session, refreshToken := domain.NewSession(user.ID, userAgent, ip, s.jwtService.SessionLifetime())
+	if err := s.sessionRepository.CreateSession(ctx, session); err != nil {
+		return "", "", err
+	}
+
+	accessToken, err := s.jwtService.GenerateJWT(ctx, *user, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// Trades a refresh token for a new access token and a new refresh token, the old one is spent
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string) (string, string, error) {
+	if refreshToken == "" {
+		return "", "", domain_errors.InvalidRefreshTokenError{}
+	}
+	hash := utils.HashToken(refreshToken)
+
+	session, err := s.sessionRepository.GetSessionByRefreshHash(ctx, hash)
+	if err != nil {
+		return "", "", err
+	}
+	if session == nil {
+		s.handleReuse(ctx, hash)
+		return "", "", domain_errors.InvalidRefreshTokenError{}
+	}
+	if !session.IsActive() {
+		return "", "", domain_errors.InvalidRefreshTokenError{}
+	}
+
+	// Fresh roles and terms go into the new access token
+	user, err := s.userRepository.GetUserById(ctx, session.UserID)
+	if err != nil {
+		return "", "", err
+	}
+	if user == nil {
+		s.revoke(ctx, session.ID)
+		return "", "", domain_errors.InvalidRefreshTokenError{}
+	}
+
+	newRefreshToken := session.Rotate(s.jwtService.SessionLifetime())
+	rotated, err := s.sessionRepository.RotateSession(ctx, session, hash)
+	if err != nil {
+		return "", "", err
+	}
+	if !rotated {
+		// Someone else spent it first
+		return "", "", domain_errors.InvalidRefreshTokenError{}
+	}
+
+	accessToken, err := s.jwtService.GenerateJWT(ctx, *user, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	s.remember(session.ID, session.UserID)
+	return accessToken, newRefreshToken, nil
+}
+
+// An already rotated refresh token came back, past the grace period that means
+// someone else has a copy of it, so nobody gets to keep the session
+func (s *SessionService) handleReuse(ctx context.Context, hash string) {
+	session, err := s.sessionRepository.GetSessionByPreviousHash(ctx, hash)
+	if err != nil || session == nil || !session.IsActive() {
+		return
+	}
+	if session.InReuseGrace() {
+		return
+	}
+
+	log.Printf("Refresh token reuse on session %s of user %d, revoking it", session.ID, session.UserID)
+	s.revoke(ctx, session.ID)
+}
+
+func (s *SessionService) IsSessionActive(ctx context.Context, sessionID string) bool {
+	if sessionID == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	cached, ok := s.active[sessionID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.checkedAt) < SESSION_CHECK_INTERVAL {
+		return true
+	}
+
+	session, err := s.sessionRepository.GetSession(ctx, sessionID)
+	if err != nil {
+		// Don't log everyone out because the database hiccuped, fall back to what we last knew
+		log.Printf("Failed to check session %s: %v", sessionID, err)
+		return ok
+	}
+	if session == nil || !session.IsActive() {
+		s.forget(sessionID)
+		return false
+	}
+
+	s.remember(session.ID, session.UserID)
+	return true
+}
+
+func (s *SessionService) GetSessions(ctx context.Context, userID int) ([]*domain.Session, error) {
+	return s.sessionRepository.GetActiveSessions(ctx, userID)
+}
+
+func (s *SessionService) RevokeSession(ctx context.Context, sessionID string, userID int) error {
+	session, err := s.sessionRepository.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	// Other people's sessions look exactly like missing ones
+	if session == nil || session.UserID != userID || !session.IsActive() {
+		return domain_errors.SessionNotFoundError{}
+	}
+
+	return s.revoke(ctx, session.ID)
+}
+
+// Logging out only has the refresh cookie to go on, an unknown token is already as logged out as it gets
+func (s *SessionService) RevokeByRefreshToken(ctx context.Context, refreshToken string) error {
+	if refreshToken == "" {
+		return nil
+	}
+
+	session, err := s.sessionRepository.GetSessionByRefreshHash(ctx, utils.HashToken(refreshToken))
+	if err != nil {
+		return err
+	}
+	if session == nil {
+		return nil
+	}
+
+	return s.revoke(ctx, session.ID)
+}
+
+// Log out everywhere
+func (s *SessionService) RevokeAllSessions(ctx context.Context, userID int) (int, error) {
+	count, err := s.sessionRepository.RevokeUserSessions(ctx, userID, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	for id, cached := range s.active {
+		if cached.userID == userID {
+			delete(s.active, id)
+		}
+	}
+	s.mu.Unlock()
+
+	return count, nil
+}
+
+func (s *SessionService) revoke(ctx context.Context, sessionID string) error {
+	s.forget(sessionID)
+	return s.sessionRepository.RevokeSession(ctx, sessionID, time.Now())
+}
+
+func (s *SessionService) remember(sessionID string, userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Stale entries would be checked again anyway, clear them out before the map gets big
+	if len(s.active) >= SESSION_CACHE_SIZE {
+		for id, cached := range s.active {
+			if time.Since(cached.checkedAt) >= SESSION_CHECK_INTERVAL {
+				delete(s.active, id)
+			}
+		}
+	}
+
+	s.active[sessionID] = cachedSession{userID: userID, checkedAt: time.Now()}
+}
+
+func (s *SessionService) forget(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, sessionID)
+}
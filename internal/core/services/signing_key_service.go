@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/afuradanime/backend/config"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+)
+
+// Retired keys verify for as long as an access token lives, plus this much for clock drift
+const SIGNING_KEY_LEEWAY = 5 * time.Minute
+
+// The key used with HS256, there's only ever the one
+const SECRET_KEY_ID = "secret"
+
+type SigningKeyService struct {
+	signingKeyRepository interfaces.SigningKeyRepository
+	keyRing              *domain.KeyRing
+	conf                 *config.JWTConfig
+}
+
+func NewSigningKeyService(
+	signingKeyRepo interfaces.SigningKeyRepository,
+	keyRing *domain.KeyRing,
+	conf *config.JWTConfig,
+) *SigningKeyService {
+	return &SigningKeyService{
+		signingKeyRepository: signingKeyRepo,
+		keyRing:              keyRing,
+		conf:                 conf,
+	}
+}
+
+// Fills the key ring, making the first key if there's none to sign with yet
+func (s *SigningKeyService) Init(ctx context.Context) error {
+	if s.conf.Algorithm == domain.SigningAlgorithmHS256 {
+		if s.conf.Secret == "" {
+			return errors.New("JWT_SECRET is required with HS256")
+		}
+		s.keyRing.UseSecret(SECRET_KEY_ID, s.conf.Secret)
+		return nil
+	}
+	if !domain.IsAsymmetricAlgorithm(s.conf.Algorithm) {
+		return errors.New("Unsupported JWT algorithm " + s.conf.Algorithm)
+	}
+
+	keys, err := s.Reload(ctx)
+	if err != nil || s.dueForRotation(keys) {
+		return s.Rotate(ctx)
+	}
+	return nil
+}
+
+// Picks up keys made or retired by other instances
+func (s *SigningKeyService) Reload(ctx context.Context) ([]*domain.SigningKey, error) {
+	keys, err := s.signingKeyRepository.GetKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.keyRing.Load(keys); err != nil {
+		return keys, err
+	}
+	return keys, nil
+}
+
+// Makes a new key to sign with, the ones before it keep verifying until their tokens are gone
+func (s *SigningKeyService) Rotate(ctx context.Context) error {
+	key, err := domain.NewSigningKey(s.conf.Algorithm)
+	if err != nil {
+		return err
+	}
+	if err := s.signingKeyRepository.CreateKey(ctx, key); err != nil {
+		return err
+	}
+
+	keys, err := s.signingKeyRepository.GetKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	// They keep signing until the new key is published long enough, then verify until their last tokens expire.
+	// Only keys older than ours go, another instance rotating at the same time may have made a newer one
+	// and retiring it would leave nothing to sign with. Theirs retires ours instead
+	verifyFor := time.Duration(s.conf.AccessExpirationMinutes)*time.Minute + SIGNING_KEY_LEEWAY
+	for _, old := range keys {
+		if old.ID == key.ID || old.IsRetiring() || !old.CreatedAt.Before(key.CreatedAt) {
+			continue
+		}
+		old.Retire(verifyFor)
+		if err := s.signingKeyRepository.RetireKey(ctx, old); err != nil {
+			log.Printf("Failed to retire signing key %s: %v", old.ID, err)
+		}
+	}
+
+	_, err = s.Reload(ctx)
+	return err
+}
+
+// No key of the configured algorithm to sign with, or the newest one is too old
+func (s *SigningKeyService) dueForRotation(keys []*domain.SigningKey) bool {
+	rotateAfter := time.Duration(s.conf.KeyRotationDays) * 24 * time.Hour
+	for _, key := range keys {
+		if key.IsRetiring() || key.Algorithm != s.conf.Algorithm {
+			continue
+		}
+		return time.Since(key.CreatedAt) >= rotateAfter
+	}
+	return true
+}
+
+func (s *SigningKeyService) GetJWKS() domain.JWKSet {
+	return s.keyRing.JWKS()
+}
+
+// Keeps the ring in sync with the database, rotates when it's time and drops keys nothing verifies with anymore.
+// Keys another instance rotated in are only known here after the next tick, so keep the interval short
+func (s *SigningKeyService) StartRotationJob(interval time.Duration) {
+	if !domain.IsAsymmetricAlgorithm(s.conf.Algorithm) {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ctx := context.Background()
+
+			keys, err := s.Reload(ctx)
+			if err != nil {
+				log.Printf("Failed to reload signing keys: %v", err)
+			}
+			if err == nil && s.dueForRotation(keys) {
+				if err := s.Rotate(ctx); err != nil {
+					log.Printf("Failed to rotate signing keys: %v", err)
+				} else {
+					log.Println("Rotated the JWT signing key")
+				}
+			}
+
+			if _, err := s.signingKeyRepository.DeleteExpiredKeys(ctx, time.Now()); err != nil {
+				log.Printf("Failed to delete expired signing keys: %v", err)
+			}
+		}
+	}()
+}
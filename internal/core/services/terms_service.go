@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+)
+
+// Every write asks for the current version, so it's kept in memory. Publishing through this
+// service is seen right away, this only bounds how long other instances take to notice
+const TERMS_CHECK_INTERVAL = time.Minute
+
+type TermsService struct {
+	termsRepository interfaces.TermsRepository
+	userRepository  interfaces.UserRepository
+	auditService    interfaces.AuditService
+
+	mu        sync.Mutex
+	current   int
+	checkedAt time.Time
+}
+
+func NewTermsService(termsRepo interfaces.TermsRepository, userRepo interfaces.UserRepository, auditService interfaces.AuditService) *TermsService {
+	return &TermsService{
+		termsRepository: termsRepo,
+		userRepository:  userRepo,
+		auditService:    auditService,
+	}
+}
+
+// Everyone has to accept the new version before writing anything again
+func (s *TermsService) PublishTerms(ctx context.Context, content, summary string, adminID int) (*domain.TermsOfService, error) {
+	latest, err := s.termsRepository.GetLatestTerms(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	version := 1
+	if latest != nil {
+		version = latest.Version + 1
+	}
+
+	terms, err := domain.NewTermsOfService(version, content, summary, adminID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.termsRepository.CreateTerms(ctx, terms); err != nil {
+		return nil, err
+	}
+
+	s.remember(terms.Version)
+	s.auditService.Record(ctx, domain.NewAuditEntry(adminID, value.AuditTermsPublished, value.AuditTargetTerms, strconv.Itoa(terms.Version)).
+		WithDetails(terms.Summary))
+
+	return terms, nil
+}
+
+func (s *TermsService) GetCurrentTerms(ctx context.Context) (*domain.TermsOfService, error) {
+	terms, err := s.termsRepository.GetLatestTerms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if terms == nil {
+		return nil, domain_errors.NoTermsPublishedError{}
+	}
+	return terms, nil
+}
+
+func (s *TermsService) GetTerms(ctx context.Context, version int) (*domain.TermsOfService, error) {
+	terms, err := s.termsRepository.GetTerms(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	if terms == nil {
+		return nil, domain_errors.TermsNotFoundError{Version: version}
+	}
+	return terms, nil
+}
+
+func (s *TermsService) GetTermsHistory(ctx context.Context) ([]*domain.TermsOfService, error) {
+	return s.termsRepository.GetTermsHistory(ctx)
+}
+
+// The client says which version it showed, so nobody agrees to text they never saw
+func (s *TermsService) AcceptTerms(ctx context.Context, userID, version int) error {
+	current, err := s.GetCurrentTerms(ctx)
+	if err != nil {
+		return err
+	}
+	if version != current.Version {
+		return domain_errors.OutdatedTermsError{CurrentVersion: current.Version}
+	}
+
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return domain_errors.UserNotFoundError{UserID: strconv.Itoa(userID)}
+	}
+
+	user.AcceptTermsOfService(version)
+	return s.userRepository.UpdateUser(ctx, user)
+}
+
+// 0 when nothing was published yet
+func (s *TermsService) CurrentTermsVersion(ctx context.Context) int {
+	s.mu.Lock()
+	current, checkedAt := s.current, s.checkedAt
+	s.mu.Unlock()
+	if time.Since(checkedAt) < TERMS_CHECK_INTERVAL {
+		return current
+	}
+
+	latest, err := s.termsRepository.GetLatestTerms(ctx)
+	if err != nil {
+		// Don't lock everyone out because the database hiccuped, fall back to what we last knew
+		log.Printf("Failed to check the current terms of service: %v", err)
+		return current
+	}
+	if latest == nil {
+		s.remember(0)
+		return 0
+	}
+
+	s.remember(latest.Version)
+	return latest.Version
+}
+
+// For requests that don't carry the accepted version in a JWT, like personal and app tokens
+func (s *TermsService) HasAcceptedTerms(ctx context.Context, userID int) bool {
+	current := s.CurrentTermsVersion(ctx)
+	if current == 0 {
+		return true
+	}
+
+	user, err := s.userRepository.GetUserById(ctx, userID)
+	if err != nil || user == nil {
+		return false
+	}
+	return user.HasAcceptedTerms(current)
+}
+
+func (s *TermsService) remember(version int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A slow lookup can't take the version back down
+	if version >= s.current {
+		s.current = version
+	}
+	s.checkedAt = time.Now()
+}
@@ -13,11 +13,15 @@ import (
 )
 
 type UserService struct {
-	userRepository interfaces.UserRepository
+	userRepository  interfaces.UserRepository
+	auditService    interfaces.AuditService
+	sanctionService interfaces.SanctionService
+	presenceService interfaces.PresenceService
 }
 
-func NewUserService(repo interfaces.UserRepository) *UserService {
-	return &UserService{userRepository: repo}
+func NewUserService(repo interfaces.UserRepository, auditService interfaces.AuditService, sanctionService interfaces.SanctionService,
+	presenceService interfaces.PresenceService) *UserService {
+	return &UserService{userRepository: repo, auditService: auditService, sanctionService: sanctionService, presenceService: presenceService}
 }
 
 func (s *UserService) GetUsers(ctx context.Context, pageNumber, pageSize int) ([]*domain.User, utils.Pagination, error) {
@@ -46,7 +50,7 @@ func (s *UserService) RegisterUser(ctx context.Context, user *domain.User) (*dom
 
 func (s *UserService) UpdatePersonalInfo(ctx context.Context, id int, email *string, username *string, location *string, 
 	pronouns *string, socials *[]string, birthday *time.Time, allowsFR, allowsRec, privateList *bool, avatarURL *string, 
-	acceptTerms *bool) error {
+	presenceVisibility *value.PresenceVisibility) error {
 
 	user, err := s.GetUserByID(ctx, id)
 	if err != nil || user == nil {
@@ -89,26 +93,19 @@ func (s *UserService) UpdatePersonalInfo(ctx context.Context, id int, email *str
 	if avatarURL != nil {
 		user.UpdateAvatarURL(*avatarURL)
 	}
-	if acceptTerms != nil && *acceptTerms {
-		user.AcceptTermsOfService()
+	previousVisibility := user.PresenceVisibility
+	if presenceVisibility != nil {
+		if err := user.UpdatePresenceVisibility(*presenceVisibility); err != nil {
+			return err
+		}
 	}
 
-	return s.userRepository.UpdateUser(ctx, user)
-}
-
-func (s *UserService) RestrictAccount(ctx context.Context, id int, canPost, canTranslate bool) error {
-	user, err := s.GetUserByID(ctx, id)
-	if err != nil || user == nil {
+	if err := s.userRepository.UpdateUser(ctx, user); err != nil {
 		return err
 	}
 
-	if user.HasRole(value.UserRoleAdmin) {
-		// Népia
-		return domain_errors.CantRestrictAnAdmin{}
-	}
-
-	user.RestrictAccesses(canPost, canTranslate)
-	return s.userRepository.UpdateUser(ctx, user)
+	s.presenceService.PublishVisibilityChange(ctx, user.ID, previousVisibility, user.PresenceVisibility)
+	return nil
 }
 
 func (s *UserService) UpdateLastLogin(ctx context.Context, id int) error {
@@ -120,11 +117,55 @@ func (s *UserService) UpdateLastLogin(ctx context.Context, id int) error {
 	return s.userRepository.UpdateUser(ctx, user)
 }
 
+// The old on/off switch, kept for the tools that still use it. Whatever is taken away becomes
+// a sanction that never runs out, whatever is given back lifts the sanctions taking it away
+func (s *UserService) RestrictAccount(ctx context.Context, id int, canPost, canTranslate bool, moderatorID int) error {
+	active, err := s.sanctionService.GetActiveSanctions(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	postingBlocked, translatingBlocked := false, false
+	for _, sanction := range active {
+		if (canPost && sanction.Type.BlocksPosting()) || (canTranslate && sanction.Type.BlocksTranslating()) {
+			if err := s.sanctionService.LiftSanction(ctx, sanction.ID, moderatorID); err != nil {
+				return err
+			}
+			continue
+		}
+		postingBlocked = postingBlocked || sanction.Type.BlocksPosting()
+		translatingBlocked = translatingBlocked || sanction.Type.BlocksTranslating()
+	}
+
+	blockPosting, blockTranslating := !canPost && !postingBlocked, !canTranslate && !translatingBlocked
+	var sanctionType value.SanctionType
+	switch {
+	case blockPosting && blockTranslating:
+		sanctionType = value.SanctionRestricted
+	case blockPosting:
+		sanctionType = value.SanctionNoPosting
+	case blockTranslating:
+		sanctionType = value.SanctionNoTranslating
+	default:
+		return nil
+	}
+
+	_, err = s.sanctionService.IssueSanction(ctx, id, sanctionType, "Restricted by a moderator", 0, moderatorID)
+	return err
+}
+
 func (s *UserService) RewardBadge(ctx context.Context, moderatorID int, targetUserID int, badge value.UserBadges) error {
 	user, err := s.GetUserByID(ctx, targetUserID)
 	if err != nil || user == nil {
 		return domain_errors.UserNotFoundError{UserID: strconv.Itoa(targetUserID)}
 	}
 	user.RewardBadge(badge)
-	return s.userRepository.UpdateUser(ctx, user)
+	if err := s.userRepository.UpdateUser(ctx, user); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, domain.NewAuditEntry(moderatorID, value.AuditBadgeRewarded, value.AuditTargetUser, strconv.Itoa(targetUserID)).
+		OnUser(targetUserID).
+		WithDetails("Badge " + strconv.Itoa(int(badge))))
+	return nil
 }
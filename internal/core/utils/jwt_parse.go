@@ -7,13 +7,9 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GetParsedJWTClaims(tokenString string, secret string) (*jwt.Token, error) {
-	parsed, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return []byte(secret), nil
-	})
+// The keyfunc picks the verification key from the token's kid, see domain.KeyRing
+func GetParsedJWTClaims(tokenString string, keys jwt.Keyfunc) (*jwt.Token, error) {
+	parsed, err := jwt.Parse(tokenString, keys)
 
 	if err != nil || !parsed.Valid {
 		return nil, err
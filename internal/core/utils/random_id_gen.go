@@ -31,3 +31,14 @@ func GenerateRandomID() string {
 
 	return string(id)
 }
+
+// generates a fully random alphanumeric string, for things that must not be guessable
+// (invite codes, tokens), unlike GenerateRandomID there's no timestamp in it
+func GenerateRandomToken(length int) string {
+	token := make([]byte, length)
+	for i := range token {
+		n, _ := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+		token[i] = alphabet[n.Int64()]
+	}
+	return string(token)
+}
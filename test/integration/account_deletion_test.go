@@ -0,0 +1,147 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestAccountDeletion(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	ratingCacheRepo := repositories.NewRatingCacheRepository(app.Mongo)
+	animeListService := services.NewAnimeListService(repositories.NewAnimeListRepository(app.Mongo), repositories.NewAnimeRepository(),
+		services.NewRatingCacheService(*ratingCacheRepo), userRepo)
+	reportRepo := repositories.NewReportRepository(app.Mongo)
+	groupService := newGroupService(app)
+	sessionService := services.NewSessionService(repositories.NewSessionRepository(app.Mongo), userRepo,
+		services.NewJWTService(app.JWTConfig, app.KeyRing, newTermsService(app)))
+
+	service := services.NewAccountDeletionService(
+		userRepo,
+		repositories.NewIdentityRepository(app.Mongo),
+		sessionService,
+		repositories.NewPersonalTokenRepository(app.Mongo),
+		repositories.NewOAuthGrantRepository(app.Mongo),
+		repositories.NewOAuthTokenRepository(app.Mongo),
+		repositories.NewPostRepository(app.Mongo),
+		animeListService,
+		repositories.NewFriendshipRepository(app.Mongo),
+		repositories.NewRecommendationRepository(app.Mongo),
+		reportRepo,
+		repositories.NewModerationCaseRepository(app.Mongo),
+		groupService,
+		repositories.NewDataExportRepository(app.Mongo),
+	)
+
+	_, err := app.Mongo.Collection("friendships").InsertOne(ctx, bson.M{
+		"initiator": USER3, "receiver": USER1, "status": 1, "created_at": time.Now(),
+	})
+	require.NoError(t, err)
+
+	// One group is handed over, the other has nobody left to run it
+	handedOver, err := groupService.CreateGroup(ctx, "Handed over", "Someone takes over", "", "https://afurada.anime/icon.png", true, nil, nil, USER3)
+	require.NoError(t, err)
+	require.NoError(t, groupService.JoinGroup(ctx, handedOver.ID, USER1))
+	abandoned, err := groupService.CreateGroup(ctx, "Abandoned", "Nobody left", "", "https://afurada.anime/icon.png", true, nil, nil, USER3)
+	require.NoError(t, err)
+
+	require.NoError(t, reportRepo.CreateReport(ctx, domain.NewUserReport(value.ReportReasonSpam, USER1, USER3, "")))
+	require.NoError(t, reportRepo.CreateReport(ctx, domain.NewUserReport(value.ReportReasonSpam, USER3, USER2, "")))
+
+	require.ErrorAs(t, service.CancelDeletion(ctx, USER3), &domain_errors.NoAccountDeletionRequestedError{})
+
+	dueAt, err := service.RequestDeletion(ctx, USER3)
+	require.NoError(t, err)
+	require.True(t, dueAt.After(time.Now()))
+
+	_, err = service.RequestDeletion(ctx, USER3)
+	require.ErrorAs(t, err, &domain_errors.AccountDeletionAlreadyRequestedError{})
+
+	// Still inside the grace period, nothing happens
+	deleted, err := service.DeleteDueAccounts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 0, deleted)
+
+	// Changing their mind puts everything back
+	require.NoError(t, service.CancelDeletion(ctx, USER3))
+	user, err := userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+	require.False(t, user.IsPendingDeletion())
+
+	_, err = service.RequestDeletion(ctx, USER3)
+	require.NoError(t, err)
+
+	_, err = app.Mongo.Collection("users").UpdateOne(ctx, bson.M{"_id": USER3}, bson.M{
+		"$set": bson.M{"deletion_requested_at": time.Now().Add(-15 * 24 * time.Hour)},
+	})
+	require.NoError(t, err)
+
+	deleted, err = service.DeleteDueAccounts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	user, err = userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+	require.True(t, user.IsDeleted())
+	require.False(t, user.IsPendingDeletion())
+	require.Empty(t, user.Email)
+	require.Equal(t, "deleted#3", string(user.Username))
+
+	// Nobody could have registered it before the deletion got to it
+	_, err = value.NewUsername(string(user.Username))
+	require.Error(t, err)
+
+	count, err := app.Mongo.Collection("friendships").CountDocuments(ctx, bson.M{"initiator": USER3})
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	group, err := groupService.GetGroup(ctx, handedOver.ID)
+	require.NoError(t, err)
+	require.True(t, group.IsOwner(USER1))
+	require.False(t, group.IsModerator(USER3))
+	require.Equal(t, 1, group.MemberCount)
+
+	group, err = groupService.GetGroup(ctx, abandoned.ID)
+	require.NoError(t, err)
+	require.True(t, group.IsArchived())
+	require.Zero(t, group.MemberCount)
+
+	isMember, err := groupService.IsMember(ctx, abandoned.ID, USER3)
+	require.NoError(t, err)
+	require.False(t, isMember)
+
+	// What they filed goes, what was filed about them stays
+	filed, err := reportRepo.GetReportsByReporter(ctx, USER3)
+	require.NoError(t, err)
+	require.Empty(t, filed)
+	reported, _, err := reportRepo.GetReportsByTarget(ctx, USER3, 1, 10)
+	require.NoError(t, err)
+	require.Len(t, reported, 1)
+
+	// Gone from listings and can't be deleted twice
+	users, _, err := userRepo.GetUsers(ctx, 1, 100)
+	require.NoError(t, err)
+	for _, u := range users {
+		require.NotEqual(t, USER3, u.ID)
+	}
+	require.ErrorAs(t, service.DeleteAccount(ctx, USER3), &domain_errors.AccountDeletedError{})
+}
@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModeratorActionsAreAudited(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	auditService := services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo))
+	sanctionService := services.NewSanctionService(repositories.NewSanctionRepository(app.Mongo), userRepo,
+		services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events), auditService)
+	userService := services.NewUserService(userRepo, auditService, sanctionService, newPresenceService(app))
+
+	sanction, err := sanctionService.IssueSanction(ctx, USER3, value.SanctionNoPosting, "Flooding", time.Hour, USER1)
+	require.NoError(t, err)
+	require.NoError(t, sanctionService.LiftSanction(ctx, sanction.ID, USER2))
+	require.NoError(t, userService.RewardBadge(ctx, USER2, USER3, value.UserBadgeBetaTester))
+
+	entries, _, err := auditService.GetEntries(ctx, filters.AuditFilter{Actor: &USER1}, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, value.AuditSanctionIssued, entries[0].Action)
+	require.Equal(t, "Flooding", entries[0].Details)
+
+	entries, _, err = auditService.GetEntries(ctx, filters.AuditFilter{TargetUser: &USER3}, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	badge := value.AuditBadgeRewarded
+	entries, _, err = auditService.GetEntries(ctx, filters.AuditFilter{Action: &badge}, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	tomorrow := time.Now().Add(24 * time.Hour)
+	entries, _, err = auditService.GetEntries(ctx, filters.AuditFilter{From: &tomorrow}, 1, 20)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestFilterRuleChangesAreAudited(t *testing.T) {
+
+	USER1 := 1
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	auditService := services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo))
+	filterService := newContentFilterService(app, repositories.NewPostRepository(app.Mongo),
+		services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events), auditService)
+
+	rule, err := filterService.CreateRule(ctx, value.FilterRuleWord, "spam", 0, value.FilterActionFlag, "Spam", USER1)
+	require.NoError(t, err)
+	_, err = filterService.UpdateRule(ctx, rule.ID, "spammy", 0, value.FilterActionHold, "Spam", true, USER1)
+	require.NoError(t, err)
+	require.NoError(t, filterService.DeleteRule(ctx, rule.ID, USER1))
+
+	entries, _, err := auditService.GetEntries(ctx, filters.AuditFilter{Actor: &USER1}, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	// Newest first
+	require.Equal(t, value.AuditFilterRuleDeleted, entries[0].Action)
+	require.Equal(t, value.AuditFilterRuleUpdated, entries[1].Action)
+	require.Equal(t, value.AuditFilterRuleCreated, entries[2].Action)
+	require.Equal(t, "spammy", entries[0].Details)
+}
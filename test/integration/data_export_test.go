@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataExport(t *testing.T) {
+
+	USER1 := 1
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events)
+	exportRepo := repositories.NewDataExportRepository(app.Mongo)
+	service := services.NewDataExportService(
+		exportRepo,
+		userRepo,
+		repositories.NewAnimeListRepository(app.Mongo),
+		repositories.NewPostRepository(app.Mongo),
+		repositories.NewFriendshipRepository(app.Mongo),
+		repositories.NewRecommendationRepository(app.Mongo),
+		repositories.NewDescriptionTranslationRepository(app.Mongo),
+		repositories.NewReportRepository(app.Mongo),
+		repositories.NewGroupMembershipRepository(app.Mongo),
+		repositories.NewIdentityRepository(app.Mongo),
+		repositories.NewSessionRepository(app.Mongo),
+		repositories.NewPersonalTokenRepository(app.Mongo),
+		repositories.NewOAuthGrantRepository(app.Mongo),
+		repositories.NewOAuthTokenRepository(app.Mongo),
+		repositories.NewSanctionRepository(app.Mongo),
+		notificationService,
+	)
+
+	export, err := service.RequestExport(ctx, USER3)
+	require.NoError(t, err)
+
+	// It's put together in the background
+	require.Eventually(t, func() bool {
+		exports, err := service.GetExports(ctx, USER3)
+		return err == nil && len(exports) == 1 && exports[0].Status == value.DataExportReady
+	}, 5*time.Second, 50*time.Millisecond)
+
+	unread, err := notificationService.GetUnreadCount(ctx, USER3)
+	require.NoError(t, err)
+	require.Equal(t, 1, unread.ByType[value.NotificationDataExportReady])
+
+	// Someone else's export is as good as missing
+	_, _, err = service.DownloadExport(ctx, USER1, export.ID)
+	require.ErrorAs(t, err, &domain_errors.DataExportNotFoundError{})
+
+	downloaded, stream, err := service.DownloadExport(ctx, USER3, export.ID)
+	require.NoError(t, err)
+	content, err := io.ReadAll(stream)
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+	require.Equal(t, len(content), downloaded.Size)
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, file := range archive.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		files[file.Name], err = io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+	}
+	require.Contains(t, files, "anime_list.json")
+	require.Contains(t, files, "posts.json")
+	require.Contains(t, files, "recommendations_sent.json")
+	require.Contains(t, files, "reports.json")
+	require.Contains(t, files, "group_memberships.json")
+	require.Contains(t, files, "sessions.json")
+	require.Contains(t, files, "sanctions.json")
+
+	// The email comes out readable
+	user, err := userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+	email, err := user.DecryptedEmail()
+	require.NoError(t, err)
+
+	var profile domain.User
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	require.Equal(t, email, profile.Email)
+
+	// One a day
+	_, err = service.RequestExport(ctx, USER3)
+	require.ErrorAs(t, err, &domain_errors.DataExportCooldownError{})
+
+	// The archive is cleared once it runs out
+	deleted, err := exportRepo.DeleteExpiredArchives(ctx, time.Now().Add(domain.DATA_EXPORT_LIFETIME+time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, deleted)
+
+	stream, err = exportRepo.OpenArchive(ctx, export.ID)
+	require.NoError(t, err)
+	require.Nil(t, stream)
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"testing"
 
+	"github.com/afuradanime/backend/cmd/api/app"
 	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
 	"github.com/afuradanime/backend/internal/core/domain/value"
 	"github.com/afuradanime/backend/internal/core/services"
 	tests "github.com/afuradanime/backend/test"
@@ -28,7 +30,7 @@ func TestSendFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 
@@ -57,7 +59,7 @@ func TestSendAlreadyExistingFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -83,7 +85,7 @@ func TestAcceptNonExistentFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.AcceptFriendRequest(ctx, USER1, USER2)
 	require.Error(t, err)
@@ -104,7 +106,7 @@ func TestRejectNonExistentFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.DeclineFriendRequest(ctx, USER2, USER1)
 	require.Error(t, err)
@@ -126,7 +128,7 @@ func TestDoubleSendFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -151,7 +153,7 @@ func TestSendToBlockedUserFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	// Block USER1 by USER2
 	err := service.BlockUser(ctx, USER2, USER1)
@@ -178,7 +180,7 @@ func TestAcceptFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -208,7 +210,7 @@ func TestAcceptMyOwnFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.AcceptFriendRequest(ctx, USER1, USER1)
 	require.Error(t, err)
@@ -230,7 +232,7 @@ func TestAcceptAlreadyAcceptedFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -259,7 +261,7 @@ func TestAcceptFriendRequestByNonReceiver(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -285,7 +287,7 @@ func TestRejectFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -313,7 +315,7 @@ func TestRejectAlreadyDeclinedFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -342,7 +344,7 @@ func TestRejectFriendRequestByNonReceiver(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -368,7 +370,7 @@ func TestBlockUser(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.BlockUser(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -396,7 +398,7 @@ func TestBlockAlreadyBlockedUser(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.BlockUser(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -421,7 +423,7 @@ func TestBlockMyself(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.BlockUser(ctx, USER1, USER1)
 	require.Error(t, err)
@@ -443,7 +445,7 @@ func TestBlockUserThenSendFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.BlockUser(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -469,7 +471,7 @@ func TestBlockUserThenAcceptFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -498,7 +500,7 @@ func TestBlockUserThenRejectFriendRequest(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER2)
 	require.NoError(t, err)
@@ -526,8 +528,91 @@ func TestFriendMyself(t *testing.T) {
 	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
 	userRepo := repositories.NewUserRepository(app.Mongo)
 
-	service := services.NewFriendshipService(userRepo, friendshipRepo)
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
 
 	err := service.SendFriendRequest(ctx, USER1, USER1)
 	require.Error(t, err)
 }
+
+func TestSearchFriendsByUsernamePrefix(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	// Clean friendship collection before test
+	app.Mongo.Collection("friendships").Drop(context.Background())
+
+	ctx := context.Background()
+
+	friendshipRepo := repositories.NewFriendshipRepository(app.Mongo)
+	userRepo := repositories.NewUserRepository(app.Mongo)
+
+	service := services.NewFriendshipService(userRepo, friendshipRepo, services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events))
+
+	require.NoError(t, service.SendFriendRequest(ctx, USER1, USER2))
+	require.NoError(t, service.AcceptFriendRequest(ctx, USER1, USER2))
+
+	friends, err := service.SearchFriends(ctx, USER1, "SAG", 5)
+	require.NoError(t, err)
+	require.Len(t, friends, 1)
+	assert.Equal(t, USER2, friends[0].ID)
+
+	// Users that aren't friends don't show up, whatever their name
+	friends, err = service.SearchFriends(ctx, USER1, "afu", 5)
+	require.NoError(t, err)
+	assert.Empty(t, friends)
+}
+
+func TestHidingPresenceSendsFriendsOneLastOffline(t *testing.T) {
+
+	USER1 := 1
+
+	application, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(application.Mongo)
+	auditService := services.NewAuditService(repositories.NewAuditLogRepository(application.Mongo))
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(application.Mongo), userRepo, application.Events)
+	sanctionService := services.NewSanctionService(repositories.NewSanctionRepository(application.Mongo), userRepo, notificationService, auditService)
+
+	tracker := domain.NewActivityTracker()
+	tracker.Connect(USER1, value.Online)
+	presenceService := services.NewPresenceService(userRepo, services.NewFriendshipService(userRepo,
+		repositories.NewFriendshipRepository(application.Mongo), notificationService), tracker, application.Events)
+	userService := services.NewUserService(userRepo, auditService, sanctionService, presenceService)
+
+	sub, _ := application.Events.Subscribe([]string{domain.PresenceTopic(USER1)}, "")
+	defer sub.Close()
+
+	setVisibility := func(visibility value.PresenceVisibility) {
+		require.NoError(t, userService.UpdatePersonalInfo(ctx, USER1, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, &visibility))
+	}
+	nextStatus := func() value.ActivityStatus {
+		event := <-sub.Events()
+		return event.Data.(domain.PresenceEvent).Status
+	}
+
+	setVisibility(value.PresenceVisibleToNobody)
+	require.Equal(t, value.Offline, nextStatus())
+
+	// Hidden changes stay hidden, showing up again sends where the user is at
+	require.NoError(t, presenceService.PublishPresence(ctx, USER1, value.Idle))
+	setVisibility(value.PresenceVisibleToFriends)
+	require.Equal(t, value.Online, nextStatus())
+
+	// Friends could already see them, nothing to send
+	setVisibility(value.PresenceVisibleToEveryone)
+	require.Empty(t, sub.Events())
+}
+
+func newPresenceService(app *app.Application) *services.PresenceService {
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events)
+	return services.NewPresenceService(userRepo, services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(app.Mongo), notificationService),
+		domain.NewActivityTracker(), app.Events)
+}
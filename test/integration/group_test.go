@@ -0,0 +1,307 @@
+package integration
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/cmd/api/app"
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupMembershipLifecycle(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	service := newGroupService(app)
+
+	group, err := service.CreateGroup(ctx, "Testers", "A group for testing", "Be nice", "https://afurada.anime/icon.png", true, nil, nil, USER1)
+	require.NoError(t, err)
+	require.True(t, group.IsModerator(USER1))
+
+	// The creator is the first member
+	isMember, err := service.IsMember(ctx, group.ID, USER1)
+	require.NoError(t, err)
+	require.True(t, isMember)
+
+	require.NoError(t, service.JoinGroup(ctx, group.ID, USER2))
+	require.ErrorAs(t, service.JoinGroup(ctx, group.ID, USER2), &domain_errors.AlreadyGroupMemberError{})
+
+	// Even a join that slipped past the check above can't get in twice
+	duplicate := domain.NewGroupMembership(group.ID, USER2, value.GroupRoleMember)
+	require.ErrorAs(t, repositories.NewGroupMembershipRepository(app.Mongo).CreateMembership(ctx, duplicate), &domain_errors.AlreadyGroupMemberError{})
+
+	members, _, err := service.GetMembers(ctx, group.ID, nil, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	require.Equal(t, value.GroupRoleModerator, members[0].Role)
+
+	stored, err := service.GetGroup(ctx, group.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, stored.MemberCount)
+
+	// The last moderator can't walk away
+	require.ErrorAs(t, service.LeaveGroup(ctx, group.ID, USER1), &domain_errors.NoModeratorsLeftError{})
+
+	require.NoError(t, service.LeaveGroup(ctx, group.ID, USER2))
+	require.ErrorAs(t, service.LeaveGroup(ctx, group.ID, USER2), &domain_errors.NotGroupMemberError{})
+}
+
+func TestPrivateGroupJoinRequestsAndInvites(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	service := newGroupService(app)
+
+	group, err := service.CreateGroup(ctx, "Secret", "Nothing to see here", "Shh", "https://afurada.anime/icon.png", false, nil, nil, USER1)
+	require.NoError(t, err)
+
+	require.ErrorAs(t, service.JoinGroup(ctx, group.ID, USER2), &domain_errors.GroupIsPrivateError{})
+
+	// Outsiders can't see anything inside
+	require.ErrorAs(t, service.CanViewGroup(ctx, group.ID, nil), &domain_errors.GroupMembershipRequiredError{})
+	require.ErrorAs(t, service.CanViewGroup(ctx, group.ID, &USER2), &domain_errors.GroupMembershipRequiredError{})
+
+	// Asking once is enough, and only moderators get to answer
+	require.NoError(t, service.RequestToJoin(ctx, group.ID, USER2))
+	require.ErrorAs(t, service.RequestToJoin(ctx, group.ID, USER2), &domain_errors.JoinRequestAlreadySentError{})
+	require.ErrorAs(t, service.ApproveJoinRequest(ctx, group.ID, USER2, USER2), &domain_errors.UnauthorizedError{})
+
+	require.NoError(t, service.ApproveJoinRequest(ctx, group.ID, USER2, USER1))
+	require.NoError(t, service.CanViewGroup(ctx, group.ID, &USER2))
+
+	// Invites with a single use let exactly one person in
+	require.NoError(t, service.LeaveGroup(ctx, group.ID, USER2))
+	invite, err := service.CreateInvite(ctx, group.ID, USER1, 1, 0)
+	require.NoError(t, err)
+
+	joined, err := service.JoinWithInvite(ctx, invite.Code, USER2)
+	require.NoError(t, err)
+	require.Equal(t, group.ID, joined.ID)
+
+	require.NoError(t, service.LeaveGroup(ctx, group.ID, USER2))
+	_, err = service.JoinWithInvite(ctx, invite.Code, USER2)
+	require.ErrorAs(t, err, &domain_errors.GroupInviteExpiredError{})
+}
+
+func TestGroupBansAndMutes(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	service := newGroupService(app)
+
+	group, err := service.CreateGroup(ctx, "Strict", "Behave", "No spam", "https://afurada.anime/icon.png", true, nil, nil, USER1)
+	require.NoError(t, err)
+	require.NoError(t, service.JoinGroup(ctx, group.ID, USER2))
+
+	// Muted members stay in but can't post
+	require.ErrorAs(t, service.MuteMember(ctx, group.ID, USER2, USER1, "Calm down", 0), &domain_errors.InvalidMuteDurationError{})
+	require.NoError(t, service.MuteMember(ctx, group.ID, USER2, USER1, "Calm down", time.Hour))
+	require.ErrorAs(t, service.CanPostInGroup(ctx, group.ID, USER2), &domain_errors.GroupMutedError{})
+
+	require.NoError(t, service.UnmuteMember(ctx, group.ID, USER2, USER1))
+	require.NoError(t, service.CanPostInGroup(ctx, group.ID, USER2))
+
+	// Banned ones are thrown out and can't come back
+	require.ErrorAs(t, service.BanMember(ctx, group.ID, USER1, USER2, "Coup"), &domain_errors.UnauthorizedError{})
+	require.NoError(t, service.BanMember(ctx, group.ID, USER2, USER1, "Spam"))
+	require.ErrorAs(t, service.JoinGroup(ctx, group.ID, USER2), &domain_errors.GroupBannedError{})
+	require.ErrorAs(t, service.CanPostInGroup(ctx, group.ID, USER2), &domain_errors.GroupBannedError{})
+
+	require.NoError(t, service.UnbanMember(ctx, group.ID, USER2, USER1))
+	require.NoError(t, service.JoinGroup(ctx, group.ID, USER2))
+
+	// Every action made it into the log, newest first
+	entries, _, err := service.GetModerationLog(ctx, group.ID, USER1, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.Equal(t, value.GroupActionUnban, entries[0].Action)
+	require.Equal(t, USER2, *entries[0].TargetUser)
+}
+
+func TestGroupSearch(t *testing.T) {
+
+	USER1 := 1
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	service := newGroupService(app)
+
+	_, err := service.CreateGroup(ctx, "Mecha Fans", "Giant robots (and pilots)", "", "https://afurada.anime/icon.png", true, nil, nil, USER1)
+	require.NoError(t, err)
+	_, err = service.CreateGroup(ctx, "Slice of Life", "Cozy shows only", "", "https://afurada.anime/icon.png", true, nil, nil, USER1)
+	require.NoError(t, err)
+
+	// Words are stemmed, and the group matching the most of them comes first
+	query := "robot mecha"
+	groups, _, err := service.GetGroups(ctx, filters.GroupFilter{Query: &query}, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, groups, 1)
+	require.Equal(t, value.TinyStr("Mecha Fans"), groups[0].Name)
+
+	query = "cozy robots mecha"
+	groups, _, err = service.GetGroups(ctx, filters.GroupFilter{Query: &query}, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	require.Equal(t, value.TinyStr("Mecha Fans"), groups[0].Name)
+
+	// Regex characters mean nothing to a text search
+	query = "(.*"
+	groups, _, err = service.GetGroups(ctx, filters.GroupFilter{Query: &query}, 1, 20)
+	require.NoError(t, err)
+	require.Empty(t, groups)
+}
+
+func TestGroupOwnershipAndDeletion(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	postRepo := repositories.NewPostRepository(app.Mongo)
+	service := newGroupService(app)
+
+	group, err := service.CreateGroup(ctx, "Heirloom", "Handed down", "", "https://afurada.anime/icon.png", true, nil, nil, USER1)
+	require.NoError(t, err)
+	require.True(t, group.IsOwner(USER1))
+
+	// The owner can't just walk away
+	require.ErrorAs(t, service.LeaveGroup(ctx, group.ID, USER1), &domain_errors.OwnerCannotStepDownError{})
+
+	// Only members can be offered the group, and nothing changes until they accept
+	require.ErrorAs(t, service.TransferOwnership(ctx, group.ID, USER2, USER1), &domain_errors.NotGroupMemberError{})
+	require.NoError(t, service.JoinGroup(ctx, group.ID, USER2))
+	require.NoError(t, service.TransferOwnership(ctx, group.ID, USER2, USER1))
+	require.ErrorAs(t, service.AcceptOwnership(ctx, group.ID, USER1), &domain_errors.NoOwnershipTransferError{})
+
+	stored, err := service.GetGroup(ctx, group.ID)
+	require.NoError(t, err)
+	require.True(t, stored.IsOwner(USER1))
+
+	require.NoError(t, service.AcceptOwnership(ctx, group.ID, USER2))
+	stored, err = service.GetGroup(ctx, group.ID)
+	require.NoError(t, err)
+	require.True(t, stored.IsOwner(USER2))
+	require.True(t, stored.IsModerator(USER1))
+
+	// Archived groups are read only
+	require.ErrorAs(t, service.ArchiveGroup(ctx, group.ID, USER1), &domain_errors.UnauthorizedError{})
+	require.NoError(t, service.ArchiveGroup(ctx, group.ID, USER2))
+	require.ErrorAs(t, service.CanPostInGroup(ctx, group.ID, USER1), &domain_errors.GroupArchivedError{})
+	require.ErrorAs(t, service.UpdateGroup(ctx, group.ID, "Renamed", "", "", "", USER2), &domain_errors.GroupArchivedError{})
+	require.ErrorAs(t, service.MuteMember(ctx, group.ID, USER1, USER2, "Hush", time.Hour), &domain_errors.GroupArchivedError{})
+	require.ErrorAs(t, service.AddGroupModerator(ctx, group.ID, USER1, USER2), &domain_errors.GroupArchivedError{})
+	require.ErrorAs(t, service.RemoveGroupModerator(ctx, group.ID, USER2, USER2), &domain_errors.GroupArchivedError{})
+	require.NoError(t, service.UnarchiveGroup(ctx, group.ID, USER2))
+
+	// Deleting takes every thread with it, replies included
+	post, err := postRepo.CreatePost(ctx, domain.NewPost(strconv.Itoa(group.ID), value.ParentTypeGroup, "Hello", USER1))
+	require.NoError(t, err)
+	reply, err := postRepo.CreatePost(ctx, domain.NewReply(post, "Hi", USER2))
+	require.NoError(t, err)
+	nested, err := postRepo.CreatePost(ctx, domain.NewReply(reply, "Hey", USER1))
+	require.NoError(t, err)
+
+	require.NoError(t, service.DeleteGroup(ctx, group.ID, USER2))
+
+	for _, id := range []string{post.ID, reply.ID, nested.ID} {
+		_, err := postRepo.GetPostById(ctx, id)
+		require.Error(t, err)
+	}
+	require.ErrorAs(t, service.CanViewGroup(ctx, group.ID, &USER1), &domain_errors.GroupNotFoundError{})
+	isMember, err := service.IsMember(ctx, group.ID, USER1)
+	require.NoError(t, err)
+	require.False(t, isMember)
+	_, err = service.GetGroup(ctx, group.ID)
+	require.Error(t, err)
+
+	// The log keeps a record of it
+	logs, _, err := repositories.NewGroupModerationLogRepository(app.Mongo).GetEntries(ctx, group.ID, 1, 20)
+	require.NoError(t, err)
+	require.Equal(t, value.GroupActionDelete, logs[0].Action)
+}
+
+func newGroupService(app *app.Application) *services.GroupService {
+	return services.NewGroupService(repositories.NewGroupRepository(app.Mongo), repositories.NewGroupMembershipRepository(app.Mongo),
+		repositories.NewGroupJoinRequestRepository(app.Mongo), repositories.NewGroupInviteRepository(app.Mongo), repositories.NewGroupBanRepository(app.Mongo),
+		repositories.NewGroupModerationLogRepository(app.Mongo), repositories.NewUserRepository(app.Mongo), repositories.NewAnimeRepository(),
+		repositories.NewPostRepository(app.Mongo), services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo)))
+}
+
+func TestGroupMembershipsAreBackfilled(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+	USER3 := 3
+
+	application, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	groupRepo := repositories.NewGroupRepository(application.Mongo)
+	postRepo := repositories.NewPostRepository(application.Mongo)
+	service := newGroupService(application)
+
+	// Made before memberships, only the moderators are written down
+	group, err := domain.NewGroup("Old Timers", "From before", "", "https://afurada.anime/icon.png")
+	require.NoError(t, err)
+	require.NoError(t, group.AddModerator(USER1))
+	require.NoError(t, groupRepo.CreateGroup(ctx, group))
+
+	thread, err := postRepo.CreatePost(ctx, domain.NewPost(strconv.Itoa(group.ID), value.ParentTypeGroup, "First!", USER2))
+	require.NoError(t, err)
+	_, err = postRepo.CreatePost(ctx, domain.NewPost(thread.ID, value.ParentTypePost, "Second", USER3))
+	require.NoError(t, err)
+
+	isMember, err := service.IsMember(ctx, group.ID, USER3)
+	require.NoError(t, err)
+	require.False(t, isMember)
+
+	require.NoError(t, app.MigrateGroupMemberships(ctx, application.Mongo))
+	require.NoError(t, app.MigrateGroupMemberships(ctx, application.Mongo))
+
+	// Whoever posted there, down to the replies
+	for _, user := range []int{USER1, USER2, USER3} {
+		isMember, err := service.IsMember(ctx, group.ID, user)
+		require.NoError(t, err)
+		require.True(t, isMember)
+	}
+
+	group, err = groupRepo.GetGroup(ctx, group.ID)
+	require.NoError(t, err)
+	require.Equal(t, 3, group.MemberCount)
+}
@@ -0,0 +1,141 @@
+package integration
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/afuradanime/backend/cmd/api/app"
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLinkAndUnlinkIdentities(t *testing.T) {
+
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	service := services.NewIdentityService(
+		repositories.NewIdentityRepository(app.Mongo),
+		repositories.NewUserRepository(app.Mongo),
+	)
+
+	github := &domain.ProviderIdentity{Provider: "github", Subject: "gh-42", Email: "someone@example.com", Name: "someone"}
+	discord := &domain.ProviderIdentity{Provider: "discord", Subject: "dc-42", Email: "someone@example.com", Name: "someone"}
+
+	// First login creates the account
+	user, firstLogin, err := service.LoginWithProvider(ctx, github)
+	require.NoError(t, err)
+	require.True(t, firstLogin)
+
+	_, err = service.LinkIdentity(ctx, user.ID, discord)
+	require.NoError(t, err)
+
+	// Either account now logs into the same user
+	again, firstLogin, err := service.LoginWithProvider(ctx, discord)
+	require.NoError(t, err)
+	require.False(t, firstLogin)
+	require.Equal(t, user.ID, again.ID)
+
+	_, err = service.LinkIdentity(ctx, user.ID, discord)
+	require.ErrorAs(t, err, &domain_errors.IdentityAlreadyLinkedError{})
+
+	// Someone else can't take it
+	_, err = service.LinkIdentity(ctx, USER3, github)
+	require.ErrorAs(t, err, &domain_errors.IdentityLinkedToAnotherUserError{})
+
+	identities, err := service.GetIdentities(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, identities, 2)
+
+	require.NoError(t, service.UnlinkIdentity(ctx, user.ID, "discord"))
+	require.ErrorAs(t, service.UnlinkIdentity(ctx, user.ID, "discord"), &domain_errors.IdentityNotFoundError{})
+
+	// The last way in stays put
+	require.ErrorAs(t, service.UnlinkIdentity(ctx, user.ID, "github"), &domain_errors.CantUnlinkLastIdentityError{})
+
+	// Unlinking both at once still leaves a way in
+	_, err = service.LinkIdentity(ctx, user.ID, discord)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for _, provider := range []string{"github", "discord"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service.UnlinkIdentity(ctx, user.ID, provider)
+		}()
+	}
+	wg.Wait()
+
+	identities, err = service.GetIdentities(ctx, user.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, identities)
+}
+
+func TestUsernamesAreUniqueRegardlessOfCase(t *testing.T) {
+
+	USER1 := 1
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	service := services.NewIdentityService(repositories.NewIdentityRepository(app.Mongo), userRepo)
+
+	found, err := userRepo.GetUserByUsername(ctx, "krayrui")
+	require.NoError(t, err)
+	require.Equal(t, USER1, found.ID)
+
+	// Someone signing up under a name that's taken gets it with a suffix
+	user, firstLogin, err := service.LoginWithProvider(ctx,
+		&domain.ProviderIdentity{Provider: "github", Subject: "gh-7", Email: "kray@example.com", Name: "KRAYRUI"})
+	require.NoError(t, err)
+	require.True(t, firstLogin)
+	require.True(t, strings.HasPrefix(string(user.Username), "KRAYRUI_"))
+
+	user.UpdateUsername("KrayRui")
+	require.ErrorAs(t, userRepo.UpdateUser(ctx, user), &domain_errors.UsernameTakenError{})
+}
+
+func TestUsernameMigrationSuffixesOnlyIntoFreeNames(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+	USER3 := 3
+
+	application, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// From before usernames were unique, one of them already has the name a suffix would make
+	users := application.Mongo.Collection("users")
+	_, err := users.Indexes().DropOne(ctx, "username_lower_1")
+	require.NoError(t, err)
+	for id, username := range map[int]string{USER1: "bob_3", USER2: "Bob", USER3: "bob"} {
+		_, err := users.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"username": username}, "$unset": bson.M{"username_lower": ""}})
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, app.MigrateUsernamesLower(ctx, application.Mongo))
+
+	userRepo := repositories.NewUserRepository(application.Mongo)
+	for id, username := range map[int]string{USER1: "bob_3", USER2: "Bob", USER3: "bob_3_1"} {
+		user, err := userRepo.GetUserById(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, username, string(user.Username))
+	}
+}
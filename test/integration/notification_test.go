@@ -0,0 +1,83 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFriendRequestNotifiesReceiver(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	app.Mongo.Collection("friendships").Drop(context.Background())
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events)
+	friendshipService := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(app.Mongo), notificationService)
+
+	err := friendshipService.SendFriendRequest(ctx, USER1, USER2)
+	require.NoError(t, err)
+
+	unread, err := notificationService.GetUnreadCount(ctx, USER2)
+	require.NoError(t, err)
+	require.Equal(t, 1, unread.Total)
+	require.Equal(t, 1, unread.ByType[value.NotificationFriendRequest])
+
+	notifications, _, err := notificationService.GetNotifications(ctx, USER2, true, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	require.Equal(t, USER1, notifications[0].Actor)
+
+	// Other users can't touch it
+	err = notificationService.MarkAsRead(ctx, USER1, notifications[0].ID)
+	require.Error(t, err)
+
+	err = notificationService.MarkAsRead(ctx, USER2, notifications[0].ID)
+	require.NoError(t, err)
+
+	unread, err = notificationService.GetUnreadCount(ctx, USER2)
+	require.NoError(t, err)
+	require.Equal(t, 0, unread.Total)
+}
+
+func TestDisabledNotificationTypeIsNotDelivered(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	app.Mongo.Collection("friendships").Drop(context.Background())
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events)
+	friendshipService := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(app.Mongo), notificationService)
+
+	err := notificationService.UpdatePreferences(ctx, USER2, []domain.NotificationPreference{
+		{Type: value.NotificationFriendRequest, Enabled: false},
+	})
+	require.NoError(t, err)
+
+	err = friendshipService.SendFriendRequest(ctx, USER1, USER2)
+	require.NoError(t, err)
+
+	unread, err := notificationService.GetUnreadCount(ctx, USER2)
+	require.NoError(t, err)
+	require.Equal(t, 0, unread.Total)
+}
@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthAuthorizationCodeFlow(t *testing.T) {
+
+	USER1 := 1
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	service := services.NewOAuthService(
+		repositories.NewOAuthAppRepository(app.Mongo),
+		repositories.NewOAuthGrantRepository(app.Mongo),
+		repositories.NewOAuthCodeRepository(app.Mongo),
+		repositories.NewOAuthTokenRepository(app.Mongo),
+	)
+
+	_, _, err := service.RegisterApp(ctx, USER1, "tracker", "", "", []string{"http://tracker.example.com/cb"}, false)
+	require.ErrorAs(t, err, &domain_errors.InvalidRedirectURIError{})
+
+	tracker, secret, err := service.RegisterApp(ctx, USER1, "tracker", "Keeps your list in sync", "", []string{"https://tracker.example.com/cb"}, false)
+	require.NoError(t, err)
+	require.NotEmpty(t, secret)
+
+	verifier := "a-very-long-code-verifier-that-is-at-least-43-characters"
+	request := domain.AuthorizationRequest{
+		ClientID:            tracker.ID,
+		RedirectURI:         "https://tracker.example.com/cb",
+		ResponseType:        "code",
+		Scope:               "list:read list:write",
+		State:               "xyz",
+		CodeChallenge:       domain.PKCEChallenge(verifier),
+		CodeChallengeMethod: domain.PKCE_METHOD_S256,
+	}
+
+	// Redirect URIs have to match exactly
+	wrongRedirect := request
+	wrongRedirect.RedirectURI = "https://tracker.example.com/cb/other"
+	_, err = service.GetConsent(ctx, USER3, wrongRedirect)
+	require.ErrorAs(t, err, &domain_errors.InvalidRedirectURIError{})
+
+	consent, err := service.GetConsent(ctx, USER3, request)
+	require.NoError(t, err)
+	require.False(t, consent.Granted)
+	require.Len(t, consent.Scopes, 2)
+
+	code, err := service.Authorize(ctx, USER3, request)
+	require.NoError(t, err)
+
+	// Once allowed the screen can be skipped
+	consent, err = service.GetConsent(ctx, USER3, request)
+	require.NoError(t, err)
+	require.True(t, consent.Granted)
+
+	_, _, _, err = service.ExchangeCode(ctx, tracker.ID, "wrong", code, request.RedirectURI, verifier)
+	require.ErrorAs(t, err, &domain_errors.OAuthError{})
+
+	token, access, refresh, err := service.ExchangeCode(ctx, tracker.ID, secret, code, request.RedirectURI, verifier)
+	require.NoError(t, err)
+	require.Equal(t, USER3, token.UserID)
+
+	userID, scopes, ok := service.AuthenticateToken(ctx, access)
+	require.True(t, ok)
+	require.Equal(t, USER3, userID)
+	require.Len(t, scopes, 2)
+
+	// Refreshing spends the old refresh token
+	_, access, rotated, err := service.RefreshToken(ctx, tracker.ID, secret, refresh)
+	require.NoError(t, err)
+	_, _, _, err = service.RefreshToken(ctx, tracker.ID, secret, refresh)
+	require.ErrorAs(t, err, &domain_errors.OAuthError{})
+
+	// A replayed code takes down whatever it handed out
+	_, _, _, err = service.ExchangeCode(ctx, tracker.ID, secret, code, request.RedirectURI, verifier)
+	require.ErrorAs(t, err, &domain_errors.OAuthError{})
+	_, _, ok = service.AuthenticateToken(ctx, access)
+	require.False(t, ok)
+
+	// Fresh tokens again, then the user takes the access away
+	code, err = service.Authorize(ctx, USER3, request)
+	require.NoError(t, err)
+	_, access, _, err = service.ExchangeCode(ctx, tracker.ID, secret, code, request.RedirectURI, verifier)
+	require.NoError(t, err)
+
+	grants, err := service.GetGrants(ctx, USER3)
+	require.NoError(t, err)
+	require.Len(t, grants, 1)
+
+	require.NoError(t, service.RevokeGrant(ctx, USER3, tracker.ID))
+	_, _, ok = service.AuthenticateToken(ctx, access)
+	require.False(t, ok)
+	_, _, _, err = service.RefreshToken(ctx, tracker.ID, secret, rotated)
+	require.ErrorAs(t, err, &domain_errors.OAuthError{})
+
+	require.ErrorAs(t, service.RevokeGrant(ctx, USER3, tracker.ID), &domain_errors.OAuthGrantNotFoundError{})
+}
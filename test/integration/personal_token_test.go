@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestPersonalTokens(t *testing.T) {
+
+	USER1 := 1
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	service := services.NewPersonalTokenService(repositories.NewPersonalTokenRepository(app.Mongo))
+
+	_, _, err := service.CreateToken(ctx, USER3, "bot", nil, nil)
+	require.ErrorAs(t, err, &domain_errors.NoTokenScopesError{})
+
+	_, _, err = service.CreateToken(ctx, USER3, "bot", []value.TokenScope{"admin"}, nil)
+	require.ErrorAs(t, err, &domain_errors.InvalidTokenScopeError{})
+
+	token, plain, err := service.CreateToken(ctx, USER3, "discord bot", []value.TokenScope{value.TokenScopeReadList, value.TokenScopeReadList}, nil)
+	require.NoError(t, err)
+	require.Len(t, token.Scopes, 1)
+	require.Nil(t, token.LastUsedAt)
+
+	// Only the hash is kept around
+	require.NotEqual(t, plain, token.Hash)
+
+	userID, scopes, ok := service.AuthenticateToken(ctx, plain)
+	require.True(t, ok)
+	require.Equal(t, USER3, userID)
+	require.Equal(t, []value.TokenScope{value.TokenScopeReadList}, scopes)
+
+	_, _, ok = service.AuthenticateToken(ctx, plain+"x")
+	require.False(t, ok)
+
+	tokens, err := service.GetTokens(ctx, USER3)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.NotNil(t, tokens[0].LastUsedAt)
+
+	// Someone else's token is as good as missing
+	require.ErrorAs(t, service.RevokeToken(ctx, USER1, token.ID), &domain_errors.PersonalTokenNotFoundError{})
+
+	require.NoError(t, service.RevokeToken(ctx, USER3, token.ID))
+	_, _, ok = service.AuthenticateToken(ctx, plain)
+	require.False(t, ok)
+
+	// Expired tokens stop working on their own
+	expiresAt := time.Now().Add(time.Hour)
+	_, expiring, err := service.CreateToken(ctx, USER3, "short lived", []value.TokenScope{value.TokenScopePost}, &expiresAt)
+	require.NoError(t, err)
+
+	_, err = app.Mongo.Collection("personal_tokens").UpdateMany(ctx, bson.M{"user": USER3}, bson.M{
+		"$set": bson.M{"expires_at": time.Now().Add(-time.Minute)},
+	})
+	require.NoError(t, err)
+
+	_, _, ok = service.AuthenticateToken(ctx, expiring)
+	require.False(t, ok)
+}
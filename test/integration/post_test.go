@@ -5,8 +5,11 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/afuradanime/backend/cmd/api/app"
 	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
 	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
 	"github.com/afuradanime/backend/internal/core/services"
 	tests "github.com/afuradanime/backend/test"
 	"github.com/stretchr/testify/require"
@@ -26,15 +29,242 @@ func TestSendPost(t *testing.T) {
 
 	animeSrv := services.NewAnimeService(repositories.NewAnimeRepository())
 
-	groupRepo := repositories.NewGroupRepository(app.Mongo)
-	groupServ := services.NewGroupService(groupRepo, userRepo)
+	groupServ := newGroupService(app)
 
 	friendRepo := repositories.NewFriendshipRepository(app.Mongo)
-	friendServ := services.NewFriendshipService(userRepo, friendRepo)
+	notificationServ := services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events)
+	friendServ := services.NewFriendshipService(userRepo, friendRepo, notificationServ)
 
-	service := services.NewPostService(postRepo, userRepo, friendServ, animeSrv, groupServ)
+	auditServ := services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo))
+	service := services.NewPostService(postRepo, userRepo, friendServ, animeSrv, groupServ, notificationServ, app.Events,
+		newContentFilterService(app, postRepo, notificationServ, auditServ), auditServ)
 
 	p, err := service.CreatePost(ctx, strconv.Itoa(USER1), value.ParentTypeUser, "Test post", USER1)
 	require.NoError(t, err)
 	require.NotNil(t, p)
 }
+
+func TestSendPostResolvesUsernameMentions(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	postRepo := repositories.NewPostRepository(app.Mongo)
+	userRepo := repositories.NewUserRepository(app.Mongo)
+
+	animeSrv := services.NewAnimeService(repositories.NewAnimeRepository())
+
+	groupServ := newGroupService(app)
+
+	friendRepo := repositories.NewFriendshipRepository(app.Mongo)
+	notificationServ := services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events)
+	friendServ := services.NewFriendshipService(userRepo, friendRepo, notificationServ)
+
+	auditServ := services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo))
+	service := services.NewPostService(postRepo, userRepo, friendServ, animeSrv, groupServ, notificationServ, app.Events,
+		newContentFilterService(app, postRepo, notificationServ, auditServ), auditServ)
+
+	mentioned, err := userRepo.GetUserById(ctx, USER2)
+	require.NoError(t, err)
+	require.NotNil(t, mentioned)
+
+	p, err := service.CreatePost(ctx, strconv.Itoa(USER1), value.ParentTypeUser, "Hello @"+string(mentioned.Username)+" and @nobody_here", USER1)
+	require.NoError(t, err)
+	require.NotNil(t, p)
+
+	// Known usernames are stored by id, unknown ones are left alone
+	require.Equal(t, "Hello @"+strconv.Itoa(USER2)+" and @nobody_here", *p.Text)
+
+	// Usernames with spaces are mentioned within []
+	spaced, err := userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+	spaced.Username = "Afurada Anime"
+	require.NoError(t, userRepo.UpdateUser(ctx, spaced))
+
+	p, err = service.CreatePost(ctx, strconv.Itoa(USER1), value.ParentTypeUser, "Hi @[afurada anime], not @[no one]", USER1)
+	require.NoError(t, err)
+	require.Equal(t, "Hi @"+strconv.Itoa(USER3)+", not @[no one]", *p.Text)
+}
+
+func TestGroupPostsRequireMembership(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	postRepo := repositories.NewPostRepository(app.Mongo)
+	userRepo := repositories.NewUserRepository(app.Mongo)
+
+	animeSrv := services.NewAnimeService(repositories.NewAnimeRepository())
+
+	groupServ := newGroupService(app)
+
+	friendRepo := repositories.NewFriendshipRepository(app.Mongo)
+	notificationServ := services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events)
+	friendServ := services.NewFriendshipService(userRepo, friendRepo, notificationServ)
+
+	auditServ := services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo))
+	service := services.NewPostService(postRepo, userRepo, friendServ, animeSrv, groupServ, notificationServ, app.Events,
+		newContentFilterService(app, postRepo, notificationServ, auditServ), auditServ)
+
+	group, err := groupServ.CreateGroup(ctx, "Members only", "Private stuff", "None", "https://afurada.anime/icon.png", false, nil, nil, USER1)
+	require.NoError(t, err)
+
+	p, err := service.CreatePost(ctx, strconv.Itoa(group.ID), value.ParentTypeGroup, "Hello members", USER1)
+	require.NoError(t, err)
+
+	// Outsiders can neither post, reply nor read
+	_, err = service.CreatePost(ctx, strconv.Itoa(group.ID), value.ParentTypeGroup, "Let me in", USER2)
+	require.ErrorAs(t, err, &domain_errors.GroupMembershipRequiredError{})
+
+	_, err = service.CreateReply(ctx, p.ID, "Let me in", USER2)
+	require.ErrorAs(t, err, &domain_errors.GroupMembershipRequiredError{})
+
+	_, err = service.GetPostById(ctx, p.ID, &USER2)
+	require.ErrorAs(t, err, &domain_errors.GroupMembershipRequiredError{})
+
+	_, err = service.GetPostReplies(ctx, strconv.Itoa(group.ID), value.ParentTypeGroup, nil)
+	require.ErrorAs(t, err, &domain_errors.GroupMembershipRequiredError{})
+
+	// Replies remember where the thread started
+	reply, err := service.CreateReply(ctx, p.ID, "Talking to myself", USER1)
+	require.NoError(t, err)
+	require.Equal(t, p.ID, *reply.TopMostPostId)
+
+	_, err = service.GetPostById(ctx, reply.ID, &USER2)
+	require.ErrorAs(t, err, &domain_errors.GroupMembershipRequiredError{})
+}
+
+func TestContentFilterRejectsAndHoldsPosts(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	postRepo := repositories.NewPostRepository(app.Mongo)
+	userRepo := repositories.NewUserRepository(app.Mongo)
+
+	groupServ := newGroupService(app)
+
+	notificationServ := services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events)
+	friendServ := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(app.Mongo), notificationServ)
+
+	auditServ := services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo))
+	filterServ := newContentFilterService(app, postRepo, notificationServ, auditServ)
+	service := services.NewPostService(postRepo, userRepo, friendServ, services.NewAnimeService(repositories.NewAnimeRepository()),
+		groupServ, notificationServ, app.Events, filterServ, auditServ)
+
+	_, err := filterServ.CreateRule(ctx, value.FilterRuleWord, "scam", 0, value.FilterActionReject, "", USER1)
+	require.NoError(t, err)
+	_, err = filterServ.CreateRule(ctx, value.FilterRuleLinkCount, "", 1, value.FilterActionHold, "Link spam", USER1)
+	require.NoError(t, err)
+
+	_, err = service.CreatePost(ctx, strconv.Itoa(USER2), value.ParentTypeUser, "Totally not a scam", USER2)
+	require.ErrorAs(t, err, &domain_errors.PostRejectedByFilterError{})
+
+	held, err := service.CreatePost(ctx, strconv.Itoa(USER2), value.ParentTypeUser, "Go to https://a.pt and https://b.pt", USER2)
+	require.NoError(t, err)
+	require.True(t, held.Held)
+
+	// Only its author sees it for now, and the system reported it
+	_, err = service.GetPostById(ctx, held.ID, &USER1)
+	require.ErrorAs(t, err, &domain_errors.PostNotFoundError{})
+	_, err = service.GetPostById(ctx, held.ID, &USER2)
+	require.NoError(t, err)
+
+	reported, err := repositories.NewReportRepository(app.Mongo).HasReportedContent(ctx, 0, value.ReportTargetPost, held.ID)
+	require.NoError(t, err)
+	require.True(t, reported)
+
+	hits, _, err := filterServ.GetHits(ctx, nil, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+
+	require.NoError(t, service.ReleaseHeldPost(ctx, held.ID, USER1))
+	require.ErrorAs(t, service.ReleaseHeldPost(ctx, held.ID, USER1), &domain_errors.PostNotHeldError{})
+
+	_, err = service.GetPostById(ctx, held.ID, &USER1)
+	require.NoError(t, err)
+
+	// Releasing it was the verdict, nothing is left in the queue
+	open, err := repositories.NewModerationCaseRepository(app.Mongo).GetOpenCase(ctx,
+		&domain.Report{TargetType: value.ReportTargetPost, TargetID: held.ID})
+	require.NoError(t, err)
+	require.Nil(t, open)
+}
+
+func TestMentionsOnlyReachThoseWhoCanSeeThem(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	postRepo := repositories.NewPostRepository(app.Mongo)
+	userRepo := repositories.NewUserRepository(app.Mongo)
+
+	groupServ := newGroupService(app)
+
+	notificationServ := services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events)
+	friendServ := services.NewFriendshipService(userRepo, repositories.NewFriendshipRepository(app.Mongo), notificationServ)
+
+	auditServ := services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo))
+	service := services.NewPostService(postRepo, userRepo, friendServ, services.NewAnimeService(repositories.NewAnimeRepository()),
+		groupServ, notificationServ, app.Events, newContentFilterService(app, postRepo, notificationServ, auditServ), auditServ)
+
+	mentioned, err := userRepo.GetUserById(ctx, USER2)
+	require.NoError(t, err)
+
+	notificationsOf := func(user int) int {
+		notifications, _, err := notificationServ.GetNotifications(ctx, user, false, 1, 100)
+		require.NoError(t, err)
+		return len(notifications)
+	}
+	before := notificationsOf(USER2)
+
+	// Nothing about a private group reaches someone outside it
+	group, err := groupServ.CreateGroup(ctx, "Members only", "Private stuff", "None", "https://afurada.anime/icon.png", false, nil, nil, USER1)
+	require.NoError(t, err)
+	_, err = service.CreatePost(ctx, strconv.Itoa(group.ID), value.ParentTypeGroup, "Don't tell @"+string(mentioned.Username), USER1)
+	require.NoError(t, err)
+	require.Equal(t, before, notificationsOf(USER2))
+
+	// Replying to someone and mentioning them is one notification, not two
+	post, err := service.CreatePost(ctx, strconv.Itoa(USER2), value.ParentTypeUser, "Anyone there?", USER2)
+	require.NoError(t, err)
+	_, err = service.CreateReply(ctx, post.ID, "Here @"+string(mentioned.Username), USER1)
+	require.NoError(t, err)
+	require.Equal(t, before+1, notificationsOf(USER2))
+}
+
+func newContentFilterService(
+	app *app.Application,
+	postRepo *repositories.PostRepository,
+	notificationServ *services.NotificationService,
+	auditServ *services.AuditService,
+) *services.ContentFilterService {
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	sanctionServ := services.NewSanctionService(repositories.NewSanctionRepository(app.Mongo), userRepo, notificationServ, auditServ)
+	reportServ := services.NewReportService(repositories.NewReportRepository(app.Mongo), userRepo, postRepo,
+		repositories.NewGroupRepository(app.Mongo), repositories.NewDescriptionTranslationRepository(app.Mongo),
+		repositories.NewModerationCaseRepository(app.Mongo), sanctionServ, auditServ)
+	return services.NewContentFilterService(repositories.NewFilterRuleRepository(app.Mongo),
+		repositories.NewFilterHitRepository(app.Mongo), postRepo, reportServ, auditServ)
+}
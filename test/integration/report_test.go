@@ -0,0 +1,201 @@
+package integration
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/cmd/api/app"
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/filters"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportPostsAndGroups(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	postRepo := repositories.NewPostRepository(app.Mongo)
+	groupRepo := repositories.NewGroupRepository(app.Mongo)
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	auditService := services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo))
+	sanctionService := services.NewSanctionService(repositories.NewSanctionRepository(app.Mongo), userRepo,
+		services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events), auditService)
+	service := services.NewReportService(repositories.NewReportRepository(app.Mongo), userRepo,
+		postRepo, groupRepo, repositories.NewDescriptionTranslationRepository(app.Mongo), repositories.NewModerationCaseRepository(app.Mongo),
+		sanctionService, auditService)
+
+	post, err := postRepo.CreatePost(ctx, domain.NewPost(strconv.Itoa(USER1), value.ParentTypeUser, "Something awful", USER1))
+	require.NoError(t, err)
+
+	require.ErrorAs(t, service.SubmitPostReport(ctx, value.ReportReasonSpam, post.ID, USER1), &domain_errors.CannotReportYourselfError{})
+	require.NoError(t, service.SubmitPostReport(ctx, value.ReportReasonSpam, post.ID, USER2))
+	require.ErrorAs(t, service.SubmitPostReport(ctx, value.ReportReasonSpam, post.ID, USER2), &domain_errors.AlreadyReportedError{})
+
+	group, err := domain.NewGroup("Shady", "Up to no good", "None", "https://afurada.anime/icon.png")
+	require.NoError(t, err)
+	require.NoError(t, groupRepo.CreateGroup(ctx, group))
+	require.NoError(t, service.SubmitGroupReport(ctx, value.ReportReasonIllegalActivities, group.ID, USER2))
+
+	// The post report keeps what it said, and who said it
+	postType := value.ReportTargetPost
+	reports, _, err := service.GetReports(ctx, &postType, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.Equal(t, post.ID, reports[0].Report.TargetID)
+	require.Equal(t, "Something awful", reports[0].Report.Snapshot)
+	require.Equal(t, USER1, reports[0].Target.ID)
+
+	groupType := value.ReportTargetGroup
+	reports, _, err = service.GetReports(ctx, &groupType, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.Nil(t, reports[0].Report.TargetUser)
+}
+
+func TestReportsAreGroupedIntoCases(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	reportRepo := repositories.NewReportRepository(app.Mongo)
+	caseRepo := repositories.NewModerationCaseRepository(app.Mongo)
+	postRepo := repositories.NewPostRepository(app.Mongo)
+	auditService := services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo))
+	sanctionService := services.NewSanctionService(repositories.NewSanctionRepository(app.Mongo), userRepo,
+		services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events), auditService)
+	reportService := services.NewReportService(reportRepo, userRepo, postRepo, repositories.NewGroupRepository(app.Mongo),
+		repositories.NewDescriptionTranslationRepository(app.Mongo), caseRepo, sanctionService, auditService)
+	caseService := services.NewModerationCaseService(caseRepo, reportRepo, userRepo, auditService)
+
+	post, err := postRepo.CreatePost(ctx, domain.NewPost(strconv.Itoa(USER1), value.ParentTypeUser, "Buy cheap stuff", USER1))
+	require.NoError(t, err)
+
+	// Two people reporting the same post end up in the same case
+	require.NoError(t, reportService.SubmitPostReport(ctx, value.ReportReasonSpam, post.ID, USER2))
+	require.NoError(t, reportService.SubmitPostReport(ctx, value.ReportReasonSpam, post.ID, USER3))
+
+	postType := value.ReportTargetPost
+	cases, _, err := caseService.GetCases(ctx, filters.CaseFilter{TargetType: &postType}, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, cases, 1)
+	require.Equal(t, 2, cases[0].ReportCount)
+	require.Equal(t, value.CaseStatusOpen, cases[0].Status)
+
+	moderationCase, reports, err := caseService.GetCase(ctx, cases[0].ID)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+
+	// Only moderators can take cases
+	require.ErrorAs(t, caseService.AssignCase(ctx, moderationCase.ID, USER3, USER2), &domain_errors.CaseAssigneeNotModeratorError{})
+	require.NoError(t, caseService.AssignCase(ctx, moderationCase.ID, USER2, USER2))
+
+	require.NoError(t, caseService.AddNote(ctx, moderationCase.ID, "Looks like a bot", USER2))
+	require.ErrorAs(t, caseService.ResolveCase(ctx, moderationCase.ID, 0, "", USER2), &domain_errors.InvalidCaseOutcomeError{})
+	require.NoError(t, caseService.ResolveCase(ctx, moderationCase.ID, value.CaseOutcomeContentRemoved, "Removed", USER2))
+
+	moderationCase, _, err = caseService.GetCase(ctx, moderationCase.ID)
+	require.NoError(t, err)
+	require.Equal(t, value.CaseStatusActioned, moderationCase.Status)
+	require.Len(t, moderationCase.Notes, 2)
+
+	// Once it's closed, new reports start a new case
+	require.NoError(t, reportService.SubmitPostReport(ctx, value.ReportReasonSpam, post.ID, 4))
+	cases, _, err = caseService.GetCases(ctx, filters.CaseFilter{TargetType: &postType}, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+}
+
+func TestReportersAreCountedOnce(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	postRepo := repositories.NewPostRepository(app.Mongo)
+	reportRepo := repositories.NewReportRepository(app.Mongo)
+
+	// One user reporting everything someone posted, plus whatever the content filter flagged
+	for _, reporter := range []int{USER2, USER2, USER2, domain.SYSTEM_USER_ID, domain.SYSTEM_USER_ID} {
+		post, err := postRepo.CreatePost(ctx, domain.NewPost(strconv.Itoa(USER1), value.ParentTypeUser, "Hmm", USER1))
+		require.NoError(t, err)
+
+		report := domain.NewContentReport(value.ReportReasonSpam, value.ReportTargetPost, post.ID, &USER1, "Hmm", reporter)
+		require.NoError(t, reportRepo.CreateReport(ctx, report))
+	}
+
+	reporters, err := reportRepo.CountReportersByTarget(ctx, USER1, USER3, time.Time{})
+	require.NoError(t, err)
+	require.Equal(t, 1, reporters)
+
+	reporters, err = reportRepo.CountReportersByTarget(ctx, USER1, USER2, time.Time{})
+	require.NoError(t, err)
+	require.Zero(t, reporters)
+
+	// Reports from before a sanction ended don't count towards the next one
+	reporters, err = reportRepo.CountReportersByTarget(ctx, USER1, USER3, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, reporters)
+}
+
+func TestOnlyOneCaseOpensPerTarget(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+	USER3 := 3
+
+	application, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	postRepo := repositories.NewPostRepository(application.Mongo)
+	reportRepo := repositories.NewReportRepository(application.Mongo)
+	caseRepo := repositories.NewModerationCaseRepository(application.Mongo)
+
+	post, err := postRepo.CreatePost(ctx, domain.NewPost(strconv.Itoa(USER1), value.ParentTypeUser, "Buy cheap stuff", USER1))
+	require.NoError(t, err)
+
+	report := domain.NewContentReport(value.ReportReasonSpam, value.ReportTargetPost, post.ID, &USER1, "Buy cheap stuff", USER2)
+	require.NoError(t, caseRepo.CreateCase(ctx, domain.NewModerationCase(report)))
+	require.ErrorAs(t, caseRepo.CreateCase(ctx, domain.NewModerationCase(report)), &domain_errors.CaseAlreadyOpenError{})
+
+	// Reports from before cases all land in the one that's open
+	require.NoError(t, reportRepo.CreateReport(ctx, report))
+	require.NoError(t, reportRepo.CreateReport(ctx,
+		domain.NewContentReport(value.ReportReasonSpam, value.ReportTargetPost, post.ID, &USER1, "Buy cheap stuff", USER3)))
+
+	require.NoError(t, app.MigrateReportsToCases(ctx, application.Mongo))
+
+	moderationCase, err := caseRepo.GetOpenCase(ctx, report)
+	require.NoError(t, err)
+	require.Equal(t, 2, moderationCase.ReportCount)
+
+	reports, err := reportRepo.GetReportsByCase(ctx, moderationCase.ID)
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+}
@@ -0,0 +1,165 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/cmd/api/app"
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanctionsAppealsAndExpiry(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	sanctionRepo := repositories.NewSanctionRepository(app.Mongo)
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events)
+	service := services.NewSanctionService(sanctionRepo, userRepo, notificationService, services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo)))
+
+	// Admins are off limits
+	_, err := service.IssueSanction(ctx, USER2, value.SanctionRestricted, "Nope", time.Hour, USER1)
+	require.ErrorAs(t, err, &domain_errors.CantRestrictAnAdmin{})
+
+	sanction, err := service.IssueSanction(ctx, USER3, value.SanctionNoPosting, "Spamming the news group", time.Hour, USER1)
+	require.NoError(t, err)
+
+	user, err := userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+	require.False(t, user.CanPost)
+	require.True(t, user.CanTranslate)
+
+	// The user gets told, and can see why
+	unread, err := notificationService.GetUnreadCount(ctx, USER3)
+	require.NoError(t, err)
+	require.Equal(t, 1, unread.ByType[value.NotificationSanction])
+
+	sanctions, _, err := service.GetUserSanctions(ctx, USER3, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, sanctions, 1)
+	require.Equal(t, "Spamming the news group", string(sanctions[0].Reason))
+
+	// Only the sanctioned user can appeal, and only once
+	require.ErrorAs(t, service.AppealSanction(ctx, sanction.ID, "Let me off", USER2), &domain_errors.SanctionNotFoundError{})
+	require.NoError(t, service.AppealSanction(ctx, sanction.ID, "It was a joke", USER3))
+	require.ErrorAs(t, service.AppealSanction(ctx, sanction.ID, "Please", USER3), &domain_errors.AlreadyAppealedError{})
+
+	appeals, _, err := service.GetPendingAppeals(ctx, 1, 20)
+	require.NoError(t, err)
+	require.Len(t, appeals, 1)
+
+	// Accepting it gives the rights back
+	require.NoError(t, service.ReviewAppeal(ctx, sanction.ID, true, "Fair enough", USER1))
+	require.ErrorAs(t, service.ReviewAppeal(ctx, sanction.ID, false, "", USER1), &domain_errors.AppealNotPendingError{})
+
+	user, err = userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+	require.True(t, user.CanPost)
+
+	// One that already ran out is lifted by the expiry job, the permanent one stays
+	_, err = service.IssueSanction(ctx, USER3, value.SanctionNoTranslating, "Machine translations", 0, USER1)
+	require.NoError(t, err)
+
+	expired, err := domain.NewSanction(USER3, value.SanctionNoPosting, "Old news", USER1, time.Hour)
+	require.NoError(t, err)
+	past := time.Now().Add(-time.Minute)
+	expired.ExpiresAt = &past
+	require.NoError(t, sanctionRepo.CreateSanction(ctx, expired))
+
+	lifted, err := service.LiftExpired(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, lifted)
+
+	active, err := service.GetActiveSanctions(ctx, USER3)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, value.SanctionNoTranslating, active[0].Type)
+
+	user, err = userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+	require.True(t, user.CanPost)
+	require.False(t, user.CanTranslate)
+}
+
+func TestRestrictAccountGoesThroughSanctions(t *testing.T) {
+
+	USER1 := 1
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	auditService := services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo))
+	sanctionService := services.NewSanctionService(repositories.NewSanctionRepository(app.Mongo), userRepo,
+		services.NewNotificationService(repositories.NewNotificationRepository(app.Mongo), userRepo, app.Events), auditService)
+	userService := services.NewUserService(userRepo, auditService, sanctionService, newPresenceService(app))
+
+	require.NoError(t, userService.RestrictAccount(ctx, USER3, false, false, USER1))
+	active, err := sanctionService.GetActiveSanctions(ctx, USER3)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, value.SanctionRestricted, active[0].Type)
+	require.Nil(t, active[0].ExpiresAt)
+
+	// Giving posting back lifts the restriction, translating stays taken away
+	require.NoError(t, userService.RestrictAccount(ctx, USER3, true, false, USER1))
+	active, err = sanctionService.GetActiveSanctions(ctx, USER3)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, value.SanctionNoTranslating, active[0].Type)
+
+	user, err := userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+	require.True(t, user.CanPost)
+	require.False(t, user.CanTranslate)
+
+	require.NoError(t, userService.RestrictAccount(ctx, USER3, true, true, USER1))
+	active, err = sanctionService.GetActiveSanctions(ctx, USER3)
+	require.NoError(t, err)
+	require.Empty(t, active)
+}
+
+func TestLegacyRestrictionsBecomeSanctions(t *testing.T) {
+
+	USER3 := 3
+
+	application, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(application.Mongo)
+	sanctionRepo := repositories.NewSanctionRepository(application.Mongo)
+
+	// Restricted the old way, nothing behind it
+	user, err := userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+	user.RestrictAccesses(false, true)
+	require.NoError(t, userRepo.UpdateUser(ctx, user))
+
+	require.NoError(t, app.MigrateRestrictionsToSanctions(ctx, application.Mongo))
+	require.NoError(t, app.MigrateRestrictionsToSanctions(ctx, application.Mongo))
+
+	active, err := sanctionRepo.GetActiveSanctions(ctx, USER3)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	require.Equal(t, value.SanctionNoPosting, active[0].Type)
+	require.Equal(t, domain.SYSTEM_USER_ID, active[0].IssuedBy)
+}
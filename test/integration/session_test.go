@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/services"
+	"github.com/afuradanime/backend/internal/core/utils"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestSessionsRotateAndRevoke(t *testing.T) {
+
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	sessionRepo := repositories.NewSessionRepository(app.Mongo)
+	service := services.NewSessionService(sessionRepo, userRepo, services.NewJWTService(app.JWTConfig, app.KeyRing, newTermsService(app)))
+
+	user, err := userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+
+	access, refresh, err := service.StartSession(ctx, user, "test-agent", "127.0.0.1")
+	require.NoError(t, err)
+
+	// The access token points at its session
+	parsed, err := utils.GetParsedJWTClaims(access, app.KeyRing.Keyfunc)
+	require.NoError(t, err)
+	sessionID := parsed.Claims.(jwt.MapClaims)["sid"].(string)
+	require.True(t, service.IsSessionActive(ctx, sessionID))
+	require.Equal(t, true, parsed.Claims.(jwt.MapClaims)["acceptedTermsOfService"])
+
+	// Refreshing spends the token
+	_, rotated, err := service.Refresh(ctx, refresh)
+	require.NoError(t, err)
+	require.NotEqual(t, refresh, rotated)
+
+	// Two tabs racing get the benefit of the doubt
+	_, _, err = service.Refresh(ctx, refresh)
+	require.ErrorAs(t, err, &domain_errors.InvalidRefreshTokenError{})
+	require.True(t, service.IsSessionActive(ctx, sessionID))
+
+	// Well after the rotation, the old token showing up means it was stolen
+	_, err = app.Mongo.Collection("sessions").UpdateOne(ctx, bson.M{"_id": sessionID}, bson.M{
+		"$set": bson.M{"rotated_at": time.Now().Add(-time.Hour)},
+	})
+	require.NoError(t, err)
+
+	_, _, err = service.Refresh(ctx, refresh)
+	require.ErrorAs(t, err, &domain_errors.InvalidRefreshTokenError{})
+	require.False(t, service.IsSessionActive(ctx, sessionID))
+
+	_, _, err = service.Refresh(ctx, rotated)
+	require.ErrorAs(t, err, &domain_errors.InvalidRefreshTokenError{})
+
+	// Other people's sessions can't be touched, and log out everywhere gets them all
+	_, _, err = service.StartSession(ctx, user, "phone", "127.0.0.1")
+	require.NoError(t, err)
+	_, laptopRefresh, err := service.StartSession(ctx, user, "laptop", "127.0.0.1")
+	require.NoError(t, err)
+
+	sessions, err := service.GetSessions(ctx, USER3)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+
+	require.ErrorAs(t, service.RevokeSession(ctx, sessions[0].ID, 1), &domain_errors.SessionNotFoundError{})
+
+	revoked, err := service.RevokeAllSessions(ctx, USER3)
+	require.NoError(t, err)
+	require.Equal(t, 2, revoked)
+
+	for _, session := range sessions {
+		require.False(t, service.IsSessionActive(ctx, session.ID))
+	}
+	_, _, err = service.Refresh(ctx, laptopRefresh)
+	require.ErrorAs(t, err, &domain_errors.InvalidRefreshTokenError{})
+}
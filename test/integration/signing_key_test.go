@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotationLeavesConcurrentKeysAlone(t *testing.T) {
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	conf := *app.JWTConfig
+	conf.Algorithm = domain.SigningAlgorithmEdDSA
+	keyRepo := repositories.NewSigningKeyRepository(app.Mongo)
+	service := services.NewSigningKeyService(keyRepo, domain.NewKeyRing(), &conf)
+	require.NoError(t, service.Rotate(ctx))
+
+	// Another instance rotating at the same time, its key is newer than the one about to be made
+	theirs, err := domain.NewSigningKey(domain.SigningAlgorithmEdDSA)
+	require.NoError(t, err)
+	theirs.CreatedAt = time.Now().Add(time.Minute)
+	require.NoError(t, keyRepo.CreateKey(ctx, theirs))
+
+	require.NoError(t, service.Rotate(ctx))
+
+	keys, err := keyRepo.GetKeys(ctx)
+	require.NoError(t, err)
+
+	// Newest first, theirs and the key just made keep signing, everything before them retires
+	require.Equal(t, theirs.ID, keys[0].ID)
+	for i, key := range keys {
+		require.Equal(t, i >= 2, key.IsRetiring())
+	}
+}
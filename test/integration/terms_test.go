@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/afuradanime/backend/cmd/api/app"
+	"github.com/afuradanime/backend/internal/adapters/repositories"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/afuradanime/backend/internal/core/services"
+	tests "github.com/afuradanime/backend/test"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestTermsOfServiceVersions(t *testing.T) {
+
+	ADMIN := 1
+	USER3 := 3
+
+	app, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := repositories.NewUserRepository(app.Mongo)
+	service := newTermsService(app)
+
+	// Everyone seeded agreed to the first version
+	current := service.CurrentTermsVersion(ctx)
+	require.True(t, service.HasAcceptedTerms(ctx, USER3))
+
+	_, err := service.PublishTerms(ctx, "   ", "", ADMIN)
+	require.ErrorAs(t, err, &domain_errors.InvalidTermsContentError{})
+
+	terms, err := service.PublishTerms(ctx, "Be even nicer.", "Niceness requirements went up", ADMIN)
+	require.NoError(t, err)
+	require.Equal(t, current+1, terms.Version)
+
+	// A new version has to be accepted again
+	require.Equal(t, terms.Version, service.CurrentTermsVersion(ctx))
+	require.False(t, service.HasAcceptedTerms(ctx, USER3))
+
+	// Agreeing to what you saw last week doesn't count
+	require.ErrorAs(t, service.AcceptTerms(ctx, USER3, current), &domain_errors.OutdatedTermsError{})
+
+	require.NoError(t, service.AcceptTerms(ctx, USER3, terms.Version))
+	require.True(t, service.HasAcceptedTerms(ctx, USER3))
+
+	user, err := userRepo.GetUserById(ctx, USER3)
+	require.NoError(t, err)
+	require.Equal(t, terms.Version, user.AcceptedTermsVersion)
+	require.NotNil(t, user.AcceptedTermsAt)
+
+	// Older versions stay readable
+	history, err := service.GetTermsHistory(ctx)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Empty(t, history[0].Content)
+
+	_, err = service.GetTerms(ctx, 99)
+	require.ErrorAs(t, err, &domain_errors.TermsNotFoundError{})
+}
+
+func newTermsService(app *app.Application) *services.TermsService {
+	return services.NewTermsService(repositories.NewTermsRepository(app.Mongo), repositories.NewUserRepository(app.Mongo),
+		services.NewAuditService(repositories.NewAuditLogRepository(app.Mongo)))
+}
+
+func TestLegacyTermsAcceptanceIsMigrated(t *testing.T) {
+
+	USER1 := 1
+	USER2 := 2
+
+	application, cleanup := tests.SetupTestApp(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Back when accepting was a yes or no
+	users := application.Mongo.Collection("users")
+	_, err := users.UpdateOne(ctx, bson.M{"_id": USER1}, bson.M{
+		"$set":   bson.M{"accepted_terms": true},
+		"$unset": bson.M{"accepted_terms_version": ""},
+	})
+	require.NoError(t, err)
+	_, err = users.UpdateOne(ctx, bson.M{"_id": USER2}, bson.M{
+		"$set":   bson.M{"accepted_terms": false},
+		"$unset": bson.M{"accepted_terms_version": ""},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, app.MigrateTermsVersions(ctx, application.Mongo))
+	require.NoError(t, app.MigrateTermsVersions(ctx, application.Mongo))
+
+	service := newTermsService(application)
+	require.True(t, service.HasAcceptedTerms(ctx, USER1))
+	require.False(t, service.HasAcceptedTerms(ctx, USER2))
+
+	count, err := users.CountDocuments(ctx, bson.M{"accepted_terms": bson.M{"$exists": true}})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
@@ -0,0 +1,43 @@
+package unitary
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresenceConnectIsNotRecordedAsOnline(t *testing.T) {
+	ring := domain.NewKeyRing()
+	ring.UseSecret("test", "secret")
+
+	tracker := domain.NewActivityTracker()
+	changes := 0
+	tracker.OnStatusChange(func(userID int, status value.ActivityStatus) { changes++ })
+
+	token, err := ring.Sign(jwt.MapClaims{"id": 1, "sid": "s", "exp": time.Now().Add(time.Minute).Unix()})
+	require.NoError(t, err)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	handler := middlewares.ActivityMiddleware(ring, tracker)(middlewares.JWTMiddleware(ring, tracker, activeSessions{}, nil)(ok))
+
+	// The socket says how the user shows up, invisible ones never appear online
+	r := httptest.NewRequest(http.MethodGet, "/activity/ws?status=4", nil)
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	r.AddCookie(&http.Cookie{Name: "jwt", Value: token})
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	require.Zero(t, changes)
+	require.False(t, tracker.IsConnected(1))
+
+	// Any other request counts
+	r = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	r.AddCookie(&http.Cookie{Name: "jwt", Value: token})
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+	require.NotZero(t, changes)
+}
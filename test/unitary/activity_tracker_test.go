@@ -0,0 +1,62 @@
+package unitary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerConnectionDrivesStatus(t *testing.T) {
+	tracker := domain.NewActivityTracker()
+
+	changes := []value.ActivityStatus{}
+	tracker.OnStatusChange(func(userID int, status value.ActivityStatus) {
+		changes = append(changes, status)
+	})
+	evicted := []int{}
+	tracker.OnEvict(func(userID int, lastSeen time.Time) {
+		evicted = append(evicted, userID)
+	})
+
+	tracker.Connect(1, value.Online)
+	require.Equal(t, int(value.Online), tracker.IsActive(1))
+	require.True(t, tracker.IsConnected(1))
+
+	// A regular request doesn't override what the client said
+	tracker.SetStatus(1, value.Idle)
+	tracker.RecordActivity(1, value.Online)
+	require.Equal(t, int(value.Idle), tracker.IsActive(1))
+
+	tracker.Disconnect(1)
+	require.Equal(t, 0, tracker.IsActive(1))
+	require.False(t, tracker.IsConnected(1))
+
+	require.Equal(t, []value.ActivityStatus{value.Online, value.Idle, value.Offline}, changes)
+	require.Equal(t, []int{1}, evicted)
+}
+
+func TestTrackerInvisibleLooksOffline(t *testing.T) {
+	tracker := domain.NewActivityTracker()
+
+	changes := []value.ActivityStatus{}
+	tracker.OnStatusChange(func(userID int, status value.ActivityStatus) {
+		changes = append(changes, status)
+	})
+	evicted := []int{}
+	tracker.OnEvict(func(userID int, lastSeen time.Time) {
+		evicted = append(evicted, userID)
+	})
+
+	tracker.Connect(1, value.Invisible)
+	require.Equal(t, int(value.Offline), tracker.IsActive(1))
+	require.False(t, tracker.IsConnected(1))
+	require.NotContains(t, tracker.GetActiveUsers(), 1)
+
+	// Nobody was told anything, as far as they know the user never showed up
+	tracker.Disconnect(1)
+	require.Empty(t, changes)
+	require.Empty(t, evicted)
+}
@@ -0,0 +1,61 @@
+package unitary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	domain_errors "github.com/afuradanime/backend/internal/core/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterRulesMatch(t *testing.T) {
+	word, err := domain.NewFilterRule(value.FilterRuleWord, "burro", 0, value.FilterActionHold, "", 1)
+	require.NoError(t, err)
+
+	// Whole words only, whatever the case, accents count as letters
+	require.True(t, word.Matches(domain.FilterInput{Text: "És mesmo BURRO!"}))
+	require.False(t, word.Matches(domain.FilterInput{Text: "Um burrito por favor"}))
+	require.False(t, word.Matches(domain.FilterInput{Text: "burroé"}))
+
+	links, err := domain.NewFilterRule(value.FilterRuleLinkCount, "", 2, value.FilterActionFlag, "", 1)
+	require.NoError(t, err)
+	require.False(t, links.Matches(domain.FilterInput{Text: "https://a.pt and www.b.pt"}))
+	require.True(t, links.Matches(domain.FilterInput{Text: "https://a.pt and www.b.pt and http://c.pt"}))
+
+	newAccount, err := domain.NewFilterRule(value.FilterRuleNewAccountLinks, "", 24, value.FilterActionHold, "", 1)
+	require.NoError(t, err)
+	require.True(t, newAccount.Matches(domain.FilterInput{Text: "https://a.pt", AccountAge: time.Hour}))
+	require.False(t, newAccount.Matches(domain.FilterInput{Text: "no links", AccountAge: time.Hour}))
+	require.False(t, newAccount.Matches(domain.FilterInput{Text: "https://a.pt", AccountAge: 48 * time.Hour}))
+
+	repeated, err := domain.NewFilterRule(value.FilterRuleRepeatedText, "", 3, value.FilterActionReject, "", 1)
+	require.NoError(t, err)
+	require.False(t, repeated.Matches(domain.FilterInput{RecentDuplicates: 2}))
+	require.True(t, repeated.Matches(domain.FilterInput{RecentDuplicates: 3}))
+
+	// Rules read back from the database match once compiled
+	stored := &domain.FilterRule{Kind: value.FilterRuleRegex, Pattern: `\d{9}`, Action: value.FilterActionFlag}
+	require.False(t, stored.Matches(domain.FilterInput{Text: "Call 912345678"}))
+	require.NoError(t, stored.Compile())
+	require.True(t, stored.Matches(domain.FilterInput{Text: "Call 912345678"}))
+
+	_, err = domain.NewFilterRule(value.FilterRuleRegex, "([a-z", 0, value.FilterActionHold, "", 1)
+	require.ErrorAs(t, err, &domain_errors.InvalidFilterRuleError{})
+	_, err = domain.NewFilterRule(value.FilterRuleLinkCount, "", 0, value.FilterActionHold, "", 1)
+	require.ErrorAs(t, err, &domain_errors.InvalidFilterRuleError{})
+}
+
+func TestFilterVerdictKeepsStrongestAction(t *testing.T) {
+	flag := &domain.FilterRule{ID: 1, Action: value.FilterActionFlag, Description: "Links"}
+	reject := &domain.FilterRule{ID: 2, Action: value.FilterActionReject, Kind: value.FilterRuleWord, Pattern: "scam"}
+
+	var verdict domain.FilterVerdict
+	verdict.Apply(reject)
+	verdict.Apply(flag)
+
+	require.Equal(t, value.FilterActionReject, verdict.Action)
+	require.Equal(t, []int{2, 1}, verdict.Rules)
+	require.Equal(t, []string{"scam", "Links"}, verdict.Reasons)
+}
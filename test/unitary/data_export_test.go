@@ -0,0 +1,34 @@
+package unitary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataExportLifecycle(t *testing.T) {
+	export := domain.NewDataExport(3)
+	require.True(t, export.IsPending())
+	require.False(t, export.IsAvailable())
+	require.True(t, export.BlocksNewExport())
+
+	// A build that died with the server doesn't lock the user out
+	export.CreatedAt = time.Now().Add(-2 * domain.DATA_EXPORT_BUILD_TIMEOUT)
+	require.False(t, export.BlocksNewExport())
+
+	export.CreatedAt = time.Now()
+	export.MarkReady(3)
+	require.True(t, export.IsAvailable())
+	require.Equal(t, 3, export.Size)
+	require.True(t, export.BlocksNewExport())
+
+	// Past its lifetime it's gone, even if Mongo hasn't noticed yet
+	export.ExpiresAt = time.Now().Add(-time.Minute)
+	require.False(t, export.IsAvailable())
+
+	failed := domain.NewDataExport(3)
+	failed.MarkFailed()
+	require.False(t, failed.BlocksNewExport())
+}
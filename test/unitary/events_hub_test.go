@@ -0,0 +1,59 @@
+package unitary
+
+import (
+	"testing"
+
+	"github.com/afuradanime/backend/internal/adapters/events"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHubDeliversOnlySubscribedTopics(t *testing.T) {
+	hub := events.NewHub()
+
+	sub, missed := hub.Subscribe([]string{domain.UserTopic(1)}, "")
+	defer sub.Close()
+	require.Empty(t, missed)
+
+	hub.Publish(domain.UserTopic(2), value.EventNotification, "not for you")
+	hub.Publish(domain.UserTopic(1), value.EventNotification, "for you")
+
+	event := <-sub.Events()
+	require.Equal(t, "for you", event.Data)
+	require.Len(t, sub.Events(), 0)
+}
+
+func TestHubReplaysAfterLastEventID(t *testing.T) {
+	hub := events.NewHub()
+	topic := domain.UserTopic(1)
+
+	first, _ := hub.Subscribe([]string{topic}, "")
+	hub.Publish(topic, value.EventNotification, 1)
+	seen := <-first.Events()
+	first.Close()
+
+	// Published while the client was disconnected
+	hub.Publish(topic, value.EventNotification, 2)
+	hub.Publish(domain.UserTopic(2), value.EventNotification, "someone else's")
+	hub.Publish(topic, value.EventNotification, 3)
+
+	second, missed := hub.Subscribe([]string{topic}, seen.ID)
+	defer second.Close()
+
+	require.Len(t, missed, 2)
+	require.Equal(t, 2, missed[0].Data)
+	require.Equal(t, 3, missed[1].Data)
+}
+
+func TestHubIgnoresUnknownLastEventID(t *testing.T) {
+	hub := events.NewHub()
+	topic := domain.UserTopic(1)
+
+	hub.Publish(topic, value.EventNotification, 1)
+
+	// Ids from a previous process can't be trusted
+	sub, missed := hub.Subscribe([]string{topic}, "someotherepoch-0")
+	defer sub.Close()
+	require.Empty(t, missed)
+}
@@ -0,0 +1,46 @@
+package unitary
+
+import (
+	"testing"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/domain/value"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPKCEChallenge(t *testing.T) {
+	// Example from RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	require.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", domain.PKCEChallenge(verifier))
+
+	code, _ := domain.NewOAuthAuthorizationCode("app", 1, "https://example.com/cb", []value.TokenScope{value.TokenScopeReadList}, domain.PKCEChallenge(verifier))
+	require.True(t, code.VerifyChallenge(verifier))
+	require.False(t, code.VerifyChallenge(verifier[:len(verifier)-1]+"x"))
+	require.False(t, code.VerifyChallenge("short"))
+}
+
+func TestRedirectURIs(t *testing.T) {
+	require.True(t, domain.IsValidRedirectURI("https://tracker.example.com/callback"))
+	require.True(t, domain.IsValidRedirectURI("http://localhost:3000/callback"))
+	require.True(t, domain.IsValidRedirectURI("http://127.0.0.1/callback"))
+	require.True(t, domain.IsValidRedirectURI("com.example.tracker:/oauth"))
+
+	require.False(t, domain.IsValidRedirectURI("http://tracker.example.com/callback"))
+	require.False(t, domain.IsValidRedirectURI("https://tracker.example.com/callback#fragment"))
+	require.False(t, domain.IsValidRedirectURI("javascript:alert(1)"))
+	require.False(t, domain.IsValidRedirectURI("/relative"))
+}
+
+func TestOAuthGrantScopes(t *testing.T) {
+	app, secret := domain.NewOAuthApp(1, "tracker", "", "", []string{"https://example.com/cb"}, true)
+	require.Empty(t, secret)
+	require.Empty(t, app.SecretHash)
+
+	grant := domain.NewOAuthGrant(2, app, []value.TokenScope{value.TokenScopeReadList})
+	require.True(t, grant.Covers([]value.TokenScope{value.TokenScopeReadList}))
+	require.False(t, grant.Covers([]value.TokenScope{value.TokenScopeReadList, value.TokenScopeWriteList}))
+
+	// Asking for more later keeps what was there
+	grant.AddScopes([]value.TokenScope{value.TokenScopeWriteList, value.TokenScopeReadList})
+	require.Equal(t, []value.TokenScope{value.TokenScopeReadList, value.TokenScopeWriteList}, grant.Scopes)
+}
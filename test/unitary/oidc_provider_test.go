@@ -0,0 +1,196 @@
+package unitary
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/oauth"
+	"github.com/afuradanime/backend/internal/core/interfaces"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+type stubOIDCServer struct {
+	*httptest.Server
+	Audience string // Who the ID tokens are issued to
+	Nonce    string // Overrides the nonce of the login when set
+}
+
+// Just enough of an OpenID Connect issuer to log someone in
+func newStubOIDCServer(t *testing.T) *stubOIDCServer {
+	stub := &stubOIDCServer{Audience: "client"}
+	var server *httptest.Server
+	challenge, nonce := "", ""
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	discovery := func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"userinfo_endpoint":      server.URL + "/userinfo",
+			"jwks_uri":               server.URL + "/jwks",
+		})
+	}
+	mux.HandleFunc("/.well-known/openid-configuration", discovery)
+	// Someone else serving our document as their own
+	mux.HandleFunc("/other/.well-known/openid-configuration", discovery)
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		challenge = r.URL.Query().Get("code_challenge")
+		nonce = r.URL.Query().Get("nonce")
+		http.Redirect(w, r, r.URL.Query().Get("redirect_uri")+"?code=good-code&state="+r.URL.Query().Get("state"), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if r.PostForm.Get("code") != "good-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		if stub.Nonce != "" {
+			nonce = stub.Nonce
+		}
+		idToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, jwt.MapClaims{
+			"iss":   server.URL,
+			"aud":   stub.Audience,
+			"sub":   "stub-123",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": nonce,
+		})
+		idToken.Header["kid"] = "stub-key"
+		signed, err := idToken.SignedString(private)
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "stub-token", "token_type": "Bearer", "expires_in": 3600, "id_token": signed})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "OKP", "crv": "Ed25519", "kid": "stub-key", "use": "sig",
+			"x": base64.RawURLEncoding.EncodeToString(public),
+		}}})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer stub-token" {
+			http.Error(w, "nope", http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"sub":            "stub-123",
+			"email":          "user@afurada.anime",
+			"email_verified": true,
+			"name":           "Zé Stub",
+			"picture":        "https://afurada.anime/ze.png",
+		})
+	})
+
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	stub.Server = server
+	return stub
+}
+
+// Goes through the consent page and hands back the code
+func loginAtStub(t *testing.T, provider interfaces.AuthProvider, verifier string) string {
+	authURL, err := provider.AuthCodeURL(context.Background(), "some-state", verifier)
+	require.NoError(t, err)
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(authURL)
+	require.NoError(t, err)
+	res.Body.Close()
+	callback, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+	return callback.Query().Get("code")
+}
+
+func TestOIDCProviderLogin(t *testing.T) {
+	server := newStubOIDCServer(t)
+	ctx := context.Background()
+
+	registry := oauth.NewRegistry(oauth.NewOIDCProvider("stub", server.URL+"/", "client", "secret", "http://localhost/auth/stub/callback", []string{"openid", "email"}))
+	require.Equal(t, []string{"stub"}, registry.Names())
+
+	provider, ok := registry.Get("stub")
+	require.True(t, ok)
+
+	verifier := oauth2.GenerateVerifier()
+	authURL, err := provider.AuthCodeURL(ctx, "some-state", verifier)
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	require.Equal(t, server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	require.Equal(t, "some-state", parsed.Query().Get("state"))
+	require.Equal(t, "S256", parsed.Query().Get("code_challenge_method"))
+
+	// Go through the consent page, which hands back the code
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	res, err := client.Get(authURL)
+	require.NoError(t, err)
+	res.Body.Close()
+	callback, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+	require.Equal(t, "some-state", callback.Query().Get("state"))
+
+	identity, err := provider.Authenticate(ctx, callback.Query().Get("code"), verifier)
+	require.NoError(t, err)
+	require.Equal(t, "stub", identity.Provider)
+	require.Equal(t, "stub-123", identity.Subject)
+	require.Equal(t, "user@afurada.anime", identity.Email)
+	require.True(t, identity.EmailVerified)
+	require.Equal(t, "Zé Stub", identity.Name)
+
+	// Wrong verifier or code gets nowhere
+	_, err = provider.Authenticate(ctx, callback.Query().Get("code"), oauth2.GenerateVerifier())
+	require.Error(t, err)
+	_, err = provider.Authenticate(ctx, "bad-code", verifier)
+	require.Error(t, err)
+}
+
+func TestOIDCProviderRejectsForeignDiscovery(t *testing.T) {
+	server := newStubOIDCServer(t)
+
+	// The document claims a different issuer than the one we were configured with
+	provider := oauth.NewOIDCProvider("impostor", server.URL+"/other", "client", "secret", "http://localhost/cb", nil)
+	_, err := provider.AuthCodeURL(context.Background(), "state", oauth2.GenerateVerifier())
+	require.Error(t, err)
+}
+
+func TestOIDCProviderChecksTheIDToken(t *testing.T) {
+	server := newStubOIDCServer(t)
+	ctx := context.Background()
+	provider := oauth.NewOIDCProvider("stub", server.URL, "client", "secret", "http://localhost/cb", []string{"openid"})
+
+	// Issued to some other app
+	server.Audience = "someone-else"
+	verifier := oauth2.GenerateVerifier()
+	_, err := provider.Authenticate(ctx, loginAtStub(t, provider, verifier), verifier)
+	require.Error(t, err)
+
+	// Ours, but from another login
+	server.Audience = "client"
+	server.Nonce = "replayed"
+	verifier = oauth2.GenerateVerifier()
+	_, err = provider.Authenticate(ctx, loginAtStub(t, provider, verifier), verifier)
+	require.Error(t, err)
+
+	server.Nonce = ""
+	verifier = oauth2.GenerateVerifier()
+	identity, err := provider.Authenticate(ctx, loginAtStub(t, provider, verifier), verifier)
+	require.NoError(t, err)
+	require.Equal(t, "stub-123", identity.Subject)
+}
@@ -0,0 +1,76 @@
+package unitary
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIPBehindProxies(t *testing.T) {
+	resolver, err := middlewares.NewClientIPResolver([]string{"10.0.0.0/8", "127.0.0.1", ""})
+	require.NoError(t, err)
+
+	request := func(remote string, forwarded ...string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remote
+		for _, f := range forwarded {
+			r.Header.Add("X-Forwarded-For", f)
+		}
+		return r
+	}
+
+	// Straight from the client, the port doesn't matter and the header is whatever they made up
+	require.Equal(t, "203.0.113.7", resolver.ClientIP(request("203.0.113.7:51234", "1.1.1.1")))
+
+	// Through our proxies the first address that isn't ours is the client
+	require.Equal(t, "203.0.113.7", resolver.ClientIP(request("127.0.0.1:8000", "203.0.113.7")))
+	require.Equal(t, "203.0.113.7", resolver.ClientIP(request("127.0.0.1:8000", "1.1.1.1, 203.0.113.7, 10.1.2.3")))
+	require.Equal(t, "203.0.113.7", resolver.ClientIP(request("[::ffff:10.0.0.5]:8000", "1.1.1.1", "203.0.113.7")))
+
+	// Garbage stops the walk where it is
+	require.Equal(t, "10.1.2.3", resolver.ClientIP(request("127.0.0.1:8000", "203.0.113.7, nonsense, 10.1.2.3")))
+	require.Equal(t, "127.0.0.1", resolver.ClientIP(request("127.0.0.1:8000")))
+
+	_, err = middlewares.NewClientIPResolver([]string{"not-an-ip"})
+	require.Error(t, err)
+}
+
+func TestRateLimiterHeaders(t *testing.T) {
+	limiter := middlewares.NewRateLimiter(middlewares.RateLimitPolicy{Rps: 0.5, Burst: 2})
+	handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	send := func(remote string, userID *int) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/", nil)
+		r.RemoteAddr = remote
+		if userID != nil {
+			r = r.WithContext(context.WithValue(r.Context(), middlewares.UserIDKey, *userID))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	w := send("203.0.113.7:1000", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "2;w=4", w.Header().Get("RateLimit-Policy"))
+
+	// Another port is still the same client
+	require.Equal(t, http.StatusOK, send("203.0.113.7:2000", nil).Code)
+
+	w = send("203.0.113.7:3000", nil)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	require.Equal(t, "2", w.Header().Get("Retry-After"))
+
+	// Logged in users get their own bucket, wherever they connect from
+	user := 7
+	require.Equal(t, http.StatusOK, send("203.0.113.7:4000", &user).Code)
+	require.Equal(t, http.StatusOK, send("198.51.100.1:4000", &user).Code)
+	require.Equal(t, http.StatusTooManyRequests, send("198.51.100.2:4000", &user).Code)
+}
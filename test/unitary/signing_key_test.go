@@ -0,0 +1,98 @@
+package unitary
+
+import (
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/afuradanime/backend/internal/core/utils"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func signedKid(t *testing.T, ring *domain.KeyRing) string {
+	signed, err := ring.Sign(jwt.MapClaims{"id": 1, "exp": time.Now().Add(time.Minute).Unix()})
+	require.NoError(t, err)
+
+	parsed, err := utils.GetParsedJWTClaims(signed, ring.Keyfunc)
+	require.NoError(t, err)
+	require.True(t, parsed.Valid)
+	return parsed.Header["kid"].(string)
+}
+
+func TestKeyRingRotation(t *testing.T) {
+	utils.InitEncryption("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+
+	old, err := domain.NewSigningKey(domain.SigningAlgorithmEdDSA)
+	require.NoError(t, err)
+
+	// The very first key signs right away, nothing else could
+	ring := domain.NewKeyRing()
+	require.NoError(t, ring.Load([]*domain.SigningKey{old}))
+	require.Equal(t, old.ID, signedKid(t, ring))
+	tokenFromOld, err := ring.Sign(jwt.MapClaims{"id": 1, "exp": time.Now().Add(time.Minute).Unix()})
+	require.NoError(t, err)
+
+	// A rotated in key is published before it signs
+	next, err := domain.NewSigningKey(domain.SigningAlgorithmRS256)
+	require.NoError(t, err)
+	old.Retire(15 * time.Minute)
+	require.NoError(t, ring.Load([]*domain.SigningKey{old, next}))
+	require.Equal(t, old.ID, signedKid(t, ring))
+
+	jwks := ring.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	require.Equal(t, "OKP", jwks.Keys[0].Kty)
+	require.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+	require.Equal(t, "RSA", jwks.Keys[1].Kty)
+	require.Equal(t, "AQAB", jwks.Keys[1].E)
+
+	// Once it's been out long enough it takes over, and the old key still verifies
+	next.CreatedAt = time.Now().Add(-domain.SIGNING_KEY_ACTIVATION_DELAY)
+	retiredAt := time.Now().Add(-time.Second)
+	expiresAt := time.Now().Add(time.Minute)
+	old.RetiredAt, old.ExpiresAt = &retiredAt, &expiresAt
+	require.NoError(t, ring.Load([]*domain.SigningKey{old, next}))
+	require.Equal(t, next.ID, signedKid(t, ring))
+
+	_, err = utils.GetParsedJWTClaims(tokenFromOld, ring.Keyfunc)
+	require.NoError(t, err)
+
+	// Expired keys are gone, and so are their tokens
+	expiresAt = time.Now().Add(-time.Second)
+	require.NoError(t, ring.Load([]*domain.SigningKey{old, next}))
+	require.Len(t, ring.JWKS().Keys, 1)
+	_, err = utils.GetParsedJWTClaims(tokenFromOld, ring.Keyfunc)
+	require.Error(t, err)
+}
+
+func TestKeyRingRejectsForeignTokens(t *testing.T) {
+	utils.InitEncryption("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+
+	key, err := domain.NewSigningKey(domain.SigningAlgorithmRS256)
+	require.NoError(t, err)
+	ring := domain.NewKeyRing()
+	require.NoError(t, ring.Load([]*domain.SigningKey{key}))
+
+	// The public key used as an HMAC secret must not pass for our signature
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"id": 1})
+	forged.Header["kid"] = key.ID
+	signed, err := forged.SignedString(key.PublicKey)
+	require.NoError(t, err)
+	_, err = utils.GetParsedJWTClaims(signed, ring.Keyfunc)
+	require.Error(t, err)
+
+	// Unknown kids go nowhere
+	unknown := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"id": 1})
+	unknown.Header["kid"] = "nope"
+	signed, err = unknown.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = utils.GetParsedJWTClaims(signed, ring.Keyfunc)
+	require.Error(t, err)
+
+	// The old shared secret still works when asked for, and isn't published
+	secretRing := domain.NewKeyRing()
+	secretRing.UseSecret("secret", "shh")
+	require.Equal(t, "secret", signedKid(t, secretRing))
+	require.Empty(t, secretRing.JWKS().Keys)
+}
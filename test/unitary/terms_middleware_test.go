@@ -0,0 +1,65 @@
+package unitary
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/afuradanime/backend/internal/adapters/middlewares"
+	"github.com/afuradanime/backend/internal/core/domain"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+type activeSessions struct{}
+
+func (activeSessions) IsSessionActive(ctx context.Context, sessionID string) bool { return true }
+
+type fakeTerms struct {
+	current  int
+	accepted map[int]int
+}
+
+func (f *fakeTerms) CurrentTermsVersion(ctx context.Context) int { return f.current }
+
+func (f *fakeTerms) HasAcceptedTerms(ctx context.Context, userID int) bool {
+	return f.accepted[userID] >= f.current
+}
+
+func TestTermsEnforcement(t *testing.T) {
+	ring := domain.NewKeyRing()
+	ring.UseSecret("test", "secret")
+
+	terms := &fakeTerms{current: 2, accepted: map[int]int{1: 1}}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	status := func(method string, checker middlewares.TermsChecker, tos int) int {
+		token, err := ring.Sign(jwt.MapClaims{"id": 1, "sid": "s", "tos": tos, "exp": time.Now().Add(time.Minute).Unix()})
+		require.NoError(t, err)
+
+		r := httptest.NewRequest(method, "/", nil)
+		r.AddCookie(&http.Cookie{Name: "jwt", Value: token})
+		w := httptest.NewRecorder()
+
+		middlewares.JWTMiddleware(ring, domain.NewActivityTracker(), activeSessions{}, checker)(ok).ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// Reading is fine on old terms, writing isn't
+	require.Equal(t, http.StatusOK, status(http.MethodGet, terms, 1))
+	require.Equal(t, http.StatusForbidden, status(http.MethodPost, terms, 1))
+
+	// Routes without a checker, like the accept flow, stay open
+	require.Equal(t, http.StatusOK, status(http.MethodPost, nil, 1))
+
+	// Just accepted, the token hasn't caught up yet
+	terms.accepted[1] = 2
+	require.Equal(t, http.StatusOK, status(http.MethodPost, terms, 1))
+
+	// A new version locks writes again until it's accepted
+	terms.current = 3
+	require.Equal(t, http.StatusForbidden, status(http.MethodPut, terms, 2))
+	require.Equal(t, http.StatusOK, status(http.MethodDelete, terms, 3))
+}